@@ -3,6 +3,7 @@ package authorizer
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
@@ -23,14 +24,14 @@ func NewBackupService(s influxdb.BackupService) *BackupService {
 	}
 }
 
-func (b BackupService) CreateBackup(ctx context.Context) (int, []string, error) {
+func (b BackupService) CreateBackup(ctx context.Context, filter influxdb.BackupFilter) (int, []string, error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
 	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
 		return 0, nil, err
 	}
-	return b.s.CreateBackup(ctx)
+	return b.s.CreateBackup(ctx, filter)
 }
 
 func (b BackupService) FetchBackupFile(ctx context.Context, backupID int, backupFile string, w io.Writer) error {
@@ -46,3 +47,28 @@ func (b BackupService) FetchBackupFile(ctx context.Context, backupID int, backup
 func (b BackupService) InternalBackupPath(backupID int) string {
 	return b.s.InternalBackupPath(backupID)
 }
+
+var _ influxdb.RestoreService = (*RestoreService)(nil)
+
+// RestoreService wraps a influxdb.RestoreService and authorizes actions
+// against it appropriately.
+type RestoreService struct {
+	s influxdb.RestoreService
+}
+
+// NewRestoreService constructs an instance of an authorizing restore service.
+func NewRestoreService(s influxdb.RestoreService) *RestoreService {
+	return &RestoreService{
+		s: s,
+	}
+}
+
+func (b RestoreService) RestoreShard(ctx context.Context, bucketID influxdb.ID, start time.Time, r io.Reader) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return b.s.RestoreShard(ctx, bucketID, start, r)
+}
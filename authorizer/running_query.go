@@ -0,0 +1,65 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.RunningQueryService = (*RunningQueryService)(nil)
+
+// RunningQueryService wraps a influxdb.RunningQueryService and authorizes actions
+// against it appropriately. Listing and killing the queries of an organization
+// requires write access to the organization.
+type RunningQueryService struct {
+	s influxdb.RunningQueryService
+}
+
+// NewRunningQueryService constructs an instance of an authorizing running query service.
+func NewRunningQueryService(s influxdb.RunningQueryService) *RunningQueryService {
+	return &RunningQueryService{
+		s: s,
+	}
+}
+
+// FindRunningQueries retrieves the running queries that match the provided filter and
+// then filters the list down to the queries of the organizations the authorizer may write to.
+func (s *RunningQueryService) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	qs, err := s.s.FindRunningQueries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := qs[:0]
+	for _, q := range qs {
+		if _, _, err := AuthorizeWriteOrg(ctx, q.OrgID); err != nil {
+			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+				continue
+			}
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
+
+// KillQuery checks to see if the authorizer on context has write access to the organization of the query.
+func (s *RunningQueryService) KillQuery(ctx context.Context, id uint64) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	qs, err := s.s.FindRunningQueries(ctx, influxdb.RunningQueryFilter{ID: &id})
+	if err != nil {
+		return err
+	} else if len(qs) == 0 {
+		return influxdb.ErrRunningQueryNotFound
+	}
+	if _, _, err := AuthorizeWriteOrg(ctx, qs[0].OrgID); err != nil {
+		return err
+	}
+	return s.s.KillQuery(ctx, id)
+}
@@ -59,6 +59,9 @@ func (s *ScraperTargetStoreService) AddTarget(ctx context.Context, st *influxdb.
 	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, st.BucketID, st.OrgID); err != nil {
 		return err
 	}
+	if err := authorizeScraperSecrets(ctx, st, st.OrgID); err != nil {
+		return err
+	}
 	return s.s.AddTarget(ctx, st, userID)
 }
 
@@ -74,6 +77,13 @@ func (s *ScraperTargetStoreService) UpdateTarget(ctx context.Context, upd *influ
 	if _, _, err := AuthorizeWrite(ctx, influxdb.BucketsResourceType, st.BucketID, st.OrgID); err != nil {
 		return nil, err
 	}
+	orgID := upd.OrgID
+	if !orgID.Valid() {
+		orgID = st.OrgID
+	}
+	if err := authorizeScraperSecrets(ctx, upd, orgID); err != nil {
+		return nil, err
+	}
 	return s.s.UpdateTarget(ctx, upd, userID)
 }
 
@@ -88,3 +98,14 @@ func (s *ScraperTargetStoreService) RemoveTarget(ctx context.Context, id influxd
 	}
 	return s.s.RemoveTarget(ctx, id)
 }
+
+// authorizeScraperSecrets checks that the authorizer on context can read the
+// secrets of the org that a target scrapes with, since the scrapes would
+// send them to the URL of the target.
+func authorizeScraperSecrets(ctx context.Context, st *influxdb.ScraperTarget, orgID influxdb.ID) error {
+	if len(st.SecretKeys()) == 0 {
+		return nil
+	}
+	_, _, err := AuthorizeOrgReadResource(ctx, influxdb.SecretsResourceType, orgID)
+	return err
+}
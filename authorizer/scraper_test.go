@@ -251,6 +251,7 @@ func TestScraperTargetStoreService_UpdateTarget(t *testing.T) {
 	type args struct {
 		id          influxdb.ID
 		bucketID    influxdb.ID
+		auth        *influxdb.ScraperAuth
 		permissions []influxdb.Permission
 	}
 	type wants struct {
@@ -394,6 +395,54 @@ func TestScraperTargetStoreService_UpdateTarget(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "unauthorized to read secrets",
+			fields: fields{
+				ScraperTargetStoreService: &mock.ScraperTargetStoreService{
+					GetTargetByIDF: func(ctc context.Context, id influxdb.ID) (*influxdb.ScraperTarget, error) {
+						return &influxdb.ScraperTarget{
+							ID:       1,
+							OrgID:    10,
+							BucketID: 100,
+						}, nil
+					},
+					UpdateTargetF: func(ctx context.Context, upd *influxdb.ScraperTarget, userID influxdb.ID) (*influxdb.ScraperTarget, error) {
+						return &influxdb.ScraperTarget{
+							ID:       1,
+							OrgID:    10,
+							BucketID: 100,
+						}, nil
+					},
+				},
+			},
+			args: args{
+				id:       1,
+				bucketID: 100,
+				auth:     &influxdb.ScraperAuth{BearerTokenSecret: "token"},
+				permissions: []influxdb.Permission{
+					{
+						Action: influxdb.WriteAction,
+						Resource: influxdb.Resource{
+							Type: influxdb.ScraperResourceType,
+							ID:   influxdbtesting.IDPtr(1),
+						},
+					},
+					{
+						Action: influxdb.WriteAction,
+						Resource: influxdb.Resource{
+							Type: influxdb.BucketsResourceType,
+							ID:   influxdbtesting.IDPtr(100),
+						},
+					},
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "read:orgs/000000000000000a/secrets is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -404,7 +453,7 @@ func TestScraperTargetStoreService_UpdateTarget(t *testing.T) {
 			ctx := context.Background()
 			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, tt.args.permissions))
 
-			_, err := s.UpdateTarget(ctx, &influxdb.ScraperTarget{ID: tt.args.id, BucketID: tt.args.bucketID}, influxdb.ID(1))
+			_, err := s.UpdateTarget(ctx, &influxdb.ScraperTarget{ID: tt.args.id, BucketID: tt.args.bucketID, Auth: tt.args.auth}, influxdb.ID(1))
 			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
 		})
 	}
@@ -524,6 +573,8 @@ func TestScraperTargetStoreService_AddTarget(t *testing.T) {
 		permissions []influxdb.Permission
 		orgID       influxdb.ID
 		bucketID    influxdb.ID
+		auth        *influxdb.ScraperAuth
+		headers     map[string]string
 	}
 	type wants struct {
 		err error
@@ -640,6 +691,121 @@ func TestScraperTargetStoreService_AddTarget(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "authorized to create scraper with secrets",
+			fields: fields{
+				ScraperTargetStoreService: &mock.ScraperTargetStoreService{
+					AddTargetF: func(ctx context.Context, st *influxdb.ScraperTarget, userID influxdb.ID) error {
+						return nil
+					},
+				},
+			},
+			args: args{
+				orgID:    10,
+				bucketID: 100,
+				auth:     &influxdb.ScraperAuth{BearerTokenSecret: "token"},
+				permissions: []influxdb.Permission{
+					{
+						Action: influxdb.WriteAction,
+						Resource: influxdb.Resource{
+							Type:  influxdb.ScraperResourceType,
+							OrgID: influxdbtesting.IDPtr(10),
+						},
+					},
+					{
+						Action: influxdb.WriteAction,
+						Resource: influxdb.Resource{
+							Type: influxdb.BucketsResourceType,
+							ID:   influxdbtesting.IDPtr(100),
+						},
+					},
+					{
+						Action: influxdb.ReadAction,
+						Resource: influxdb.Resource{
+							Type:  influxdb.SecretsResourceType,
+							OrgID: influxdbtesting.IDPtr(10),
+						},
+					},
+				},
+			},
+			wants: wants{
+				err: nil,
+			},
+		},
+		{
+			name: "unauthorized to read secrets",
+			fields: fields{
+				ScraperTargetStoreService: &mock.ScraperTargetStoreService{
+					AddTargetF: func(ctx context.Context, st *influxdb.ScraperTarget, userID influxdb.ID) error {
+						return nil
+					},
+				},
+			},
+			args: args{
+				orgID:    10,
+				bucketID: 100,
+				auth:     &influxdb.ScraperAuth{Username: "user", PasswordSecret: "password"},
+				permissions: []influxdb.Permission{
+					{
+						Action: influxdb.WriteAction,
+						Resource: influxdb.Resource{
+							Type:  influxdb.ScraperResourceType,
+							OrgID: influxdbtesting.IDPtr(10),
+						},
+					},
+					{
+						Action: influxdb.WriteAction,
+						Resource: influxdb.Resource{
+							Type: influxdb.BucketsResourceType,
+							ID:   influxdbtesting.IDPtr(100),
+						},
+					},
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "read:orgs/000000000000000a/secrets is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
+		{
+			name: "unauthorized to read header secrets",
+			fields: fields{
+				ScraperTargetStoreService: &mock.ScraperTargetStoreService{
+					AddTargetF: func(ctx context.Context, st *influxdb.ScraperTarget, userID influxdb.ID) error {
+						return nil
+					},
+				},
+			},
+			args: args{
+				orgID:    10,
+				bucketID: 100,
+				headers:  map[string]string{"X-Api-Key": "api-key"},
+				permissions: []influxdb.Permission{
+					{
+						Action: influxdb.WriteAction,
+						Resource: influxdb.Resource{
+							Type:  influxdb.ScraperResourceType,
+							OrgID: influxdbtesting.IDPtr(10),
+						},
+					},
+					{
+						Action: influxdb.WriteAction,
+						Resource: influxdb.Resource{
+							Type: influxdb.BucketsResourceType,
+							ID:   influxdbtesting.IDPtr(100),
+						},
+					},
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Msg:  "read:orgs/000000000000000a/secrets is unauthorized",
+					Code: influxdb.EUnauthorized,
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -650,7 +816,7 @@ func TestScraperTargetStoreService_AddTarget(t *testing.T) {
 			ctx := context.Background()
 			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, tt.args.permissions))
 
-			err := s.AddTarget(ctx, &influxdb.ScraperTarget{OrgID: tt.args.orgID, BucketID: tt.args.bucketID}, influxdb.ID(1))
+			err := s.AddTarget(ctx, &influxdb.ScraperTarget{OrgID: tt.args.orgID, BucketID: tt.args.bucketID, Auth: tt.args.auth, Headers: tt.args.headers}, influxdb.ID(1))
 			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
 		})
 	}
@@ -2,22 +2,127 @@ package influxdb
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"time"
 )
 
+// BackupManifestFilename is the name of the file describing the buckets and
+// shards contained in a backup fileset.
+const BackupManifestFilename = "manifest.json"
+
 // BackupService represents the data backup functions of InfluxDB.
 type BackupService interface {
-	// CreateBackup creates a local copy (hard links) of the TSM data for all orgs and buckets.
+	// CreateBackup creates a local copy (hard links) of the TSM data for the buckets matching the filter,
+	// or for all orgs and buckets if the filter is empty.
 	// The return values are used to download each backup file.
-	CreateBackup(context.Context) (backupID int, backupFiles []string, err error)
+	CreateBackup(ctx context.Context, filter BackupFilter) (backupID int, backupFiles []string, err error)
 	// FetchBackupFile downloads one backup file, data or metadata.
 	FetchBackupFile(ctx context.Context, backupID int, backupFile string, w io.Writer) error
 	// InternalBackupPath is a utility to determine the on-disk location of a backup fileset.
 	InternalBackupPath(backupID int) string
 }
 
+// BackupFilter limits a backup to a subset of the data of an instance.
+type BackupFilter struct {
+	// BucketIDs are the buckets to back up. All buckets are backed up if empty.
+	BucketIDs []ID `json:"bucketIDs,omitempty"`
+
+	// Since limits the backup to the files changed after it, making the backup
+	// incremental to a backup created at or after Since.
+	Since time.Time `json:"since"`
+
+	// Start and End limit the backup to the shards overlapping the time range.
+	// A zero value leaves that end of the range unbounded.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Selective reports whether the filter limits the backup to some buckets only.
+// A selective backup does not contain the metadata database.
+func (f BackupFilter) Selective() bool {
+	return len(f.BucketIDs) > 0
+}
+
+// Incremental reports whether the filter limits the backup to recent changes.
+func (f BackupFilter) Incremental() bool {
+	return !f.Since.IsZero()
+}
+
+// Overlaps reports whether the time range of the filter overlaps the range
+// from start up to, but not including, end.
+func (f BackupFilter) Overlaps(start, end time.Time) bool {
+	if !f.Start.IsZero() && !end.After(f.Start) {
+		return false
+	}
+	if !f.End.IsZero() && !start.Before(f.End) {
+		return false
+	}
+	return true
+}
+
+// ShardBackupFilename returns the name of the backup file holding the data of a shard.
+func ShardBackupFilename(bucketID ID, shardID uint64) string {
+	return fmt.Sprintf("%s.%05d.tar", bucketID, shardID)
+}
+
 // KVBackupService represents the meta data backup functions of InfluxDB.
 type KVBackupService interface {
 	// Backup creates a live backup copy of the metadata database.
 	Backup(ctx context.Context, w io.Writer) error
 }
+
+// BackupManifest describes the files and buckets contained in a backup fileset,
+// so they can be verified and restored into a running instance.
+type BackupManifest struct {
+	// CreatedAt is the time the backup was started. All changes made before it
+	// are contained in the backup, or in the backups it is incremental to.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Filter is the filter the backup was created with.
+	Filter BackupFilter `json:"filter"`
+
+	Files   []BackupFile   `json:"files"`
+	Buckets []BucketBackup `json:"buckets"`
+}
+
+// BackupFile is a file of a backup fileset.
+type BackupFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	// Checksum is the hex encoded SHA-256 checksum of the file.
+	Checksum string `json:"checksum"`
+}
+
+// File returns the manifest entry of the named file.
+func (m *BackupManifest) File(name string) (BackupFile, bool) {
+	for _, f := range m.Files {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return BackupFile{}, false
+}
+
+// BucketBackup is the metadata and the shards of one bucket in a backup.
+type BucketBackup struct {
+	Bucket Bucket          `json:"bucket"`
+	DBRPs  []DBRPMappingV2 `json:"dbrps"`
+	Shards []ShardBackup   `json:"shards"`
+}
+
+// ShardBackup is a shard in a backup and the file holding its data.
+type ShardBackup struct {
+	ID        uint64    `json:"id"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	FileName  string    `json:"fileName"`
+}
+
+// RestoreService represents the online restore functions of InfluxDB.
+type RestoreService interface {
+	// RestoreShard imports a shard archive written by a backup into the bucket.
+	// The data is placed into the shard of the bucket covering start, which is
+	// created if it does not exist.
+	RestoreShard(ctx context.Context, bucketID ID, start time.Time, r io.Reader) error
+}
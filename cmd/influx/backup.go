@@ -2,9 +2,13 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/bolt"
@@ -20,8 +24,27 @@ func cmdBackup(f *globalFlags, opt genericCLIOpts) *cobra.Command {
 		`Backs up data and meta data for the running InfluxDB instance.
 Downloaded files are written to the directory indicated by --path.
 The target directory, and any parent directories, are created automatically.
-Data file have extension .tsm; meta data is written to %s in the same directory.`,
-		bolt.DefaultFilename)
+Data files have extension .tar; meta data is written to %s in the same directory,
+and %s describes the buckets and shards of the backup.
+
+With --org, --org-id, --bucket or --bucket-id only the data of the matching buckets
+is backed up, along with the bucket and DBRP mapping meta data in %[2]s.
+Such a backup can be restored into a running instance with "influx restore".
+
+With --since only the files changed since a time, or since an earlier backup, are
+backed up. Deletes made since then are not part of such an incremental backup, so
+restoring it brings back data deleted after the earlier backup. With --start and
+--end only the shards overlapping the time range are backed up. The checksums of the downloaded files are verified against %[2]s.`,
+		bolt.DefaultFilename, influxdb.BackupManifestFilename)
+	cmd.Example = `
+# back up a single bucket
+influx backup --org my-org --bucket my-bucket --path /backups/my-bucket
+
+# back up the changes made since a full backup
+influx backup --path /backups/2020-10-13 --since /backups/2020-10-12
+
+# back up the data of October
+influx backup --path /backups/october --start 2020-10-01T00:00:00Z --end 2020-11-01T00:00:00Z`
 
 	f.registerFlags(cmd)
 
@@ -37,11 +60,28 @@ Data file have extension .tsm; meta data is written to %s in the same directory.
 	}
 	opts.mustRegister(cmd)
 
+	// The filter flags are not bound to environment variables, so a configured
+	// org never turns a full backup into a partial one.
+	cmd.Flags().StringVar(&backupFlags.OrgID, "org-id", "", "The ID of the organization to back up")
+	cmd.Flags().StringVar(&backupFlags.Org, "org", "", "The name of the organization to back up")
+	cmd.Flags().StringVar(&backupFlags.BucketID, "bucket-id", "", "The ID of the bucket to back up")
+	cmd.Flags().StringVar(&backupFlags.Bucket, "bucket", "", "The name of the bucket to back up, requires --org or --org-id")
+	cmd.Flags().StringVar(&backupFlags.Since, "since", "", "Only back up the files changed since this RFC3339 time, or since the backup in this directory was created")
+	cmd.Flags().StringVar(&backupFlags.Start, "start", "", "Only back up the shards ending after this RFC3339 time")
+	cmd.Flags().StringVar(&backupFlags.End, "end", "", "Only back up the shards starting before this RFC3339 time")
+
 	return cmd
 }
 
 var backupFlags struct {
-	Path string
+	Path     string
+	OrgID    string
+	Org      string
+	BucketID string
+	Bucket   string
+	Since    string
+	Start    string
+	End      string
 }
 
 func newBackupService() (influxdb.BackupService, error) {
@@ -69,7 +109,12 @@ func backupF(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	id, backupFilenames, err := backupService.CreateBackup(ctx)
+	filter, err := backupFilter(ctx)
+	if err != nil {
+		return err
+	}
+
+	id, backupFilenames, err := backupService.CreateBackup(ctx, filter)
 	if err != nil {
 		return err
 	}
@@ -91,7 +136,151 @@ func backupF(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	manifest, err := readBackupManifest(backupFlags.Path)
+	if err != nil {
+		return err
+	}
+	if err := verifyBackupFiles(backupFlags.Path, manifest); err != nil {
+		return err
+	}
+
 	fmt.Printf("Backup complete")
 
 	return nil
 }
+
+// verifyBackupFiles compares the size and checksum of the files in path with
+// the ones recorded in the manifest of the backup.
+func verifyBackupFiles(path string, manifest *influxdb.BackupManifest) error {
+	for _, f := range manifest.Files {
+		size, checksum, err := checksumFile(filepath.Join(path, f.Name))
+		if err != nil {
+			return fmt.Errorf("failed to verify backup file %s: %v", f.Name, err)
+		}
+		if size != f.Size || checksum != f.Checksum {
+			return fmt.Errorf("backup file %s does not match %s", f.Name, influxdb.BackupManifestFilename)
+		}
+	}
+	return nil
+}
+
+func checksumFile(name string) (int64, string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// backupSince parses the since flag, which is either a time or the path of an
+// earlier backup.
+func backupSince(since string) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+
+	manifest, err := readBackupManifest(since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("since must be an RFC3339 time or the path of an earlier backup: %v", err)
+	}
+	return manifest.CreatedAt, nil
+}
+
+// backupFilter resolves the flags to the filter of the backup.
+func backupFilter(ctx context.Context) (influxdb.BackupFilter, error) {
+	var (
+		filter influxdb.BackupFilter
+		err    error
+	)
+	if filter.Since, err = backupSince(backupFlags.Since); err != nil {
+		return filter, err
+	}
+	if backupFlags.Start != "" {
+		if filter.Start, err = time.Parse(time.RFC3339, backupFlags.Start); err != nil {
+			return filter, fmt.Errorf("invalid start time: %v", err)
+		}
+	}
+	if backupFlags.End != "" {
+		if filter.End, err = time.Parse(time.RFC3339, backupFlags.End); err != nil {
+			return filter, fmt.Errorf("invalid end time: %v", err)
+		}
+	}
+	if !filter.Start.IsZero() && !filter.End.IsZero() && filter.End.Before(filter.Start) {
+		return filter, fmt.Errorf("end must not be before start")
+	}
+
+	filter.BucketIDs, err = backupBucketIDs(ctx)
+	return filter, err
+}
+
+// backupBucketIDs resolves the bucket and org flags to the buckets to back up.
+func backupBucketIDs(ctx context.Context) ([]influxdb.ID, error) {
+	if backupFlags.OrgID == "" && backupFlags.Org == "" && backupFlags.BucketID == "" && backupFlags.Bucket == "" {
+		return nil, nil
+	}
+	if backupFlags.OrgID != "" && backupFlags.Org != "" {
+		return nil, fmt.Errorf("must specify org-id, or org name not both")
+	}
+	if backupFlags.BucketID != "" && backupFlags.Bucket != "" {
+		return nil, fmt.Errorf("must specify bucket-id, or bucket name not both")
+	}
+
+	bucketSVC, err := newBucketService()
+	if err != nil {
+		return nil, err
+	}
+
+	if backupFlags.BucketID != "" {
+		id, err := influxdb.IDFromString(backupFlags.BucketID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket ID provided: %v", err)
+		}
+		return []influxdb.ID{*id}, nil
+	}
+
+	var bucketFilter influxdb.BucketFilter
+	if backupFlags.OrgID != "" {
+		orgID, err := influxdb.IDFromString(backupFlags.OrgID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid org ID provided: %v", err)
+		}
+		bucketFilter.OrganizationID = orgID
+	} else if backupFlags.Org != "" {
+		bucketFilter.Org = &backupFlags.Org
+	} else {
+		return nil, fmt.Errorf("must specify org-id, or org name with the bucket name")
+	}
+	if backupFlags.Bucket != "" {
+		bucketFilter.Name = &backupFlags.Bucket
+	}
+
+	var ids []influxdb.ID
+	opt := influxdb.FindOptions{Limit: influxdb.MaxPageSize}
+	for {
+		buckets, _, err := bucketSVC.FindBuckets(ctx, bucketFilter, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find buckets to back up: %v", err)
+		}
+		for _, b := range buckets {
+			ids = append(ids, b.ID)
+		}
+		if len(buckets) < opt.Limit {
+			break
+		}
+		opt.Offset += len(buckets)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no buckets to back up")
+	}
+	return ids, nil
+}
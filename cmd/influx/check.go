@@ -0,0 +1,608 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/spf13/cobra"
+)
+
+// checkService is the subset of the http.CheckService client used by the check commands.
+type checkService interface {
+	FindCheckByID(ctx context.Context, id influxdb.ID) (*http.Check, error)
+	FindChecks(ctx context.Context, filter influxdb.CheckFilter, opt ...influxdb.FindOptions) ([]*http.Check, int, error)
+	CreateCheck(ctx context.Context, c *http.Check) (*http.Check, error)
+	UpdateCheck(ctx context.Context, id influxdb.ID, c *http.Check) (*http.Check, error)
+	PatchCheck(ctx context.Context, id influxdb.ID, u influxdb.CheckUpdate) (*http.Check, error)
+	DeleteCheck(ctx context.Context, id influxdb.ID) error
+}
+
+type checkSVCsFn func() (checkService, influxdb.OrganizationService, error)
+
+func cmdCheck(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	builder := newCmdCheckBuilder(newCheckSVCs, f, opt)
+	return builder.cmd()
+}
+
+type cmdCheckBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn checkSVCsFn
+
+	json        bool
+	hideHeaders bool
+	id          string
+	name        string
+	description string
+	status      string
+	file        string
+	org         organization
+
+	checkType       string
+	query           string
+	every           string
+	offset          string
+	messageTemplate string
+	tags            []string
+	thresholds      []string
+	timeSince       string
+	staleTime       string
+	reportZero      bool
+	level           string
+}
+
+func newCmdCheckBuilder(svcsFn checkSVCsFn, f *globalFlags, opt genericCLIOpts) *cmdCheckBuilder {
+	return &cmdCheckBuilder{
+		genericCLIOpts: opt,
+		globalFlags:    f,
+		svcFn:          svcsFn,
+	}
+}
+
+func (b *cmdCheckBuilder) cmd() *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd("check", nil, false)
+	cmd.Short = "Check management commands"
+	cmd.Run = seeHelp
+	cmd.AddCommand(
+		b.cmdCreate(),
+		b.cmdDelete(),
+		b.cmdFind(),
+		b.cmdStatus(),
+		b.cmdUpdate(),
+	)
+	return cmd
+}
+
+func (b *cmdCheckBuilder) cmdCreate() *cobra.Command {
+	cmd := b.newCmd("create", b.cmdCreateRunEFn)
+	cmd.Short = "Create a check"
+	cmd.Long = `Create a check from a JSON or YAML file with the same body as the API,
+or create a threshold or deadman check from flags.
+
+Thresholds are provided as LEVEL:OPERATOR:VALUE[:VALUE] where the operator is
+one of above, below, inside or outside.`
+	cmd.Example = `
+# create a check from a file
+influx check create -f check.yml
+
+# create a threshold check from flags
+influx check create --type threshold -n cpu \
+	--query 'from(bucket: "telegraf") |> range(start: -1m) |> filter(fn: (r) => r._field == "usage_user")' \
+	--every 1m --threshold crit:above:90 --threshold warn:inside:70:90
+
+# create a deadman check from flags
+influx check create --type deadman -n heartbeat \
+	--query 'from(bucket: "telegraf") |> range(start: -1m) |> filter(fn: (r) => r._measurement == "system")' \
+	--every 1m --time-since 90s --level crit`
+
+	cmd.Flags().StringVarP(&b.file, "file", "f", "", "Path to a JSON or YAML check body; use - for stdin")
+	cmd.Flags().StringVarP(&b.checkType, "type", "", "", "The check type, one of threshold or deadman, when not creating from a file")
+	cmd.Flags().StringVarP(&b.name, "name", "n", "", "The check name")
+	cmd.Flags().StringVarP(&b.description, "description", "d", "", "The check description")
+	cmd.Flags().StringVarP(&b.status, "status", "", "", "The check status, one of active or inactive; defaults to active")
+	cmd.Flags().StringVarP(&b.query, "query", "q", "", "The Flux query of the check")
+	cmd.Flags().StringVarP(&b.every, "every", "", "", "How often the check runs, e.g. 1m")
+	cmd.Flags().StringVarP(&b.offset, "offset", "", "", "The delay before the check runs, e.g. 10s")
+	cmd.Flags().StringVarP(&b.messageTemplate, "message-template", "m", "", "The template of the status message")
+	cmd.Flags().StringArrayVarP(&b.tags, "tag", "", nil, "A tag added to the statuses, as key=value")
+	cmd.Flags().StringArrayVarP(&b.thresholds, "threshold", "", nil, "A threshold of a threshold check, as LEVEL:OPERATOR:VALUE[:VALUE]")
+	cmd.Flags().StringVarP(&b.timeSince, "time-since", "", "", "The time since the last value after which a deadman check alerts, e.g. 90s")
+	cmd.Flags().StringVarP(&b.staleTime, "stale-time", "", "", "The time after which a deadman check stops alerting, e.g. 10m")
+	cmd.Flags().BoolVarP(&b.reportZero, "report-zero", "", false, "Only alert when a deadman check saw only zero values")
+	cmd.Flags().StringVarP(&b.level, "level", "", "crit", "The level of the statuses of a deadman check")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdCheckBuilder) cmdCreateRunEFn(cmd *cobra.Command, args []string) error {
+	checkSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	var chk *http.Check
+	switch {
+	case b.file != "" && b.checkType != "":
+		return errors.New("please specify one of file or type")
+	case b.file != "":
+		chk, err = b.checkFromFile()
+	case b.checkType != "":
+		chk, err = b.checkFromFlags()
+	default:
+		return errors.New("please specify one of file or type")
+	}
+	if err != nil {
+		return err
+	}
+
+	if b.org.id != "" || b.org.name != "" || !chk.OrgID.Valid() {
+		orgID, err := b.org.getID(orgSVC)
+		if err != nil {
+			return err
+		}
+		chk.OrgID = orgID
+	}
+
+	newCheck, err := checkSVC.CreateCheck(context.Background(), chk)
+	if err != nil {
+		return fmt.Errorf("failed to create check: %v", err)
+	}
+
+	return b.printChecks(checkPrintOpt{check: newCheck})
+}
+
+func (b *cmdCheckBuilder) checkFromFile() (*http.Check, error) {
+	body, err := readResourceBody(b.file, b.in)
+	if err != nil {
+		return nil, err
+	}
+
+	var chk http.Check
+	if err := json.Unmarshal(body, &chk); err != nil {
+		return nil, fmt.Errorf("failed to decode check: %v", err)
+	}
+	return &chk, nil
+}
+
+func (b *cmdCheckBuilder) checkFromFlags() (*http.Check, error) {
+	if b.name == "" {
+		return nil, errors.New("a check created from flags requires a name")
+	}
+	if b.query == "" {
+		return nil, errors.New("a check created from flags requires a query")
+	}
+	if b.every == "" {
+		return nil, errors.New("a check created from flags requires every")
+	}
+	tags, err := parseCheckTags(b.tags)
+	if err != nil {
+		return nil, err
+	}
+
+	status := influxdb.Active
+	if b.status != "" {
+		status = influxdb.Status(b.status)
+	}
+
+	chk := &http.Check{
+		Name:        b.name,
+		Description: b.description,
+		Status:      status,
+		Query: &http.CheckQuery{
+			Text:     b.query,
+			EditMode: "advanced",
+		},
+		Every:                 b.every,
+		Offset:                b.offset,
+		StatusMessageTemplate: b.messageTemplate,
+		Tags:                  tags,
+	}
+
+	switch b.checkType {
+	case "threshold":
+		if len(b.thresholds) == 0 {
+			return nil, errors.New("a threshold check requires at least one threshold")
+		}
+		chk.Type = "threshold"
+		for _, raw := range b.thresholds {
+			t, err := parseCheckThreshold(raw)
+			if err != nil {
+				return nil, err
+			}
+			chk.Thresholds = append(chk.Thresholds, t)
+		}
+	case "deadman":
+		if b.timeSince == "" {
+			return nil, errors.New("a deadman check requires time-since")
+		}
+		level := notification.ParseCheckLevel(strings.ToUpper(b.level))
+		if level == notification.Unknown {
+			return nil, fmt.Errorf("invalid level %q", b.level)
+		}
+		chk.Type = "deadman"
+		chk.TimeSince = b.timeSince
+		chk.StaleTime = b.staleTime
+		chk.ReportZero = b.reportZero
+		chk.Level = level.String()
+	default:
+		return nil, fmt.Errorf("invalid check type %q, the type must be one of threshold or deadman", b.checkType)
+	}
+
+	return chk, nil
+}
+
+// parseCheckThreshold parses a threshold provided as LEVEL:OPERATOR:VALUE[:VALUE].
+func parseCheckThreshold(raw string) (*http.CheckThreshold, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("invalid threshold %q, expected LEVEL:OPERATOR:VALUE[:VALUE]", raw)
+	}
+
+	level := notification.ParseCheckLevel(strings.ToUpper(parts[0]))
+	if level == notification.Unknown {
+		return nil, fmt.Errorf("invalid threshold %q, unknown level %q", raw, parts[0])
+	}
+
+	values := make([]float64, 0, len(parts)-2)
+	for _, p := range parts[2:] {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold %q: %v", raw, err)
+		}
+		values = append(values, v)
+	}
+
+	t := &http.CheckThreshold{
+		ThresholdConfigBase: check.ThresholdConfigBase{Level: level},
+	}
+	switch op := parts[1]; op {
+	case "above", "below":
+		if len(values) != 1 {
+			return nil, fmt.Errorf("invalid threshold %q, %s requires a single value", raw, op)
+		}
+		t.Type, t.Value = "greater", values[0]
+		if op == "below" {
+			t.Type = "lesser"
+		}
+	case "inside", "outside":
+		if len(values) != 2 {
+			return nil, fmt.Errorf("invalid threshold %q, %s requires a min and a max value", raw, op)
+		}
+		t.Type, t.Min, t.Max, t.Within = "range", values[0], values[1], op == "inside"
+	default:
+		return nil, fmt.Errorf("invalid threshold %q, the operator must be one of above, below, inside or outside", raw)
+	}
+	return t, nil
+}
+
+func parseCheckTags(rawTags []string) ([]*influxdb.Tag, error) {
+	tags := make([]*influxdb.Tag, 0, len(rawTags))
+	for _, raw := range rawTags {
+		parts := strings.SplitN(raw, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid tag %q, expected key=value", raw)
+		}
+		tags = append(tags, &influxdb.Tag{Key: parts[0], Value: parts[1]})
+	}
+	return tags, nil
+}
+
+func (b *cmdCheckBuilder) cmdDelete() *cobra.Command {
+	cmd := b.newCmd("delete", b.cmdDeleteRunEFn)
+	cmd.Short = "Delete a check"
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The check ID (required)")
+	cmd.MarkFlagRequired("id")
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdCheckBuilder) cmdDeleteRunEFn(cmd *cobra.Command, args []string) error {
+	checkSVC, _, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	id, err := influxdb.IDFromString(b.id)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	chk, err := checkSVC.FindCheckByID(ctx, *id)
+	if err != nil {
+		return fmt.Errorf("failed to find check with id %q: %v", b.id, err)
+	}
+
+	if err := checkSVC.DeleteCheck(ctx, *id); err != nil {
+		return fmt.Errorf("failed to delete check with id %q: %v", b.id, err)
+	}
+
+	return b.printChecks(checkPrintOpt{
+		deleted: true,
+		check:   chk,
+	})
+}
+
+func (b *cmdCheckBuilder) cmdFind() *cobra.Command {
+	cmd := b.newCmd("list", b.cmdFindRunEFn)
+	cmd.Short = "List checks"
+	cmd.Aliases = []string{"find", "ls"}
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The check ID")
+	cmd.Flags().StringVarP(&b.name, "name", "n", "", "The check name")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdCheckBuilder) cmdFindRunEFn(cmd *cobra.Command, args []string) error {
+	checkSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	checks, err := b.findChecks(checkSVC, orgSVC)
+	if err != nil {
+		return err
+	}
+
+	return b.printChecks(checkPrintOpt{checks: checks})
+}
+
+func (b *cmdCheckBuilder) findChecks(checkSVC checkService, orgSVC influxdb.OrganizationService) ([]*http.Check, error) {
+	ctx := context.Background()
+	if b.id != "" {
+		id, err := influxdb.IDFromString(b.id)
+		if err != nil {
+			return nil, err
+		}
+		chk, err := checkSVC.FindCheckByID(ctx, *id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find check with id %q: %v", b.id, err)
+		}
+		return []*http.Check{chk}, nil
+	}
+
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return nil, err
+	}
+	filter := influxdb.CheckFilter{OrgID: &orgID}
+	if b.name != "" {
+		filter.Name = &b.name
+	}
+
+	checks, _, err := checkSVC.FindChecks(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve checks: %v", err)
+	}
+	if checks == nil {
+		checks = []*http.Check{}
+	}
+	return checks, nil
+}
+
+func (b *cmdCheckBuilder) cmdStatus() *cobra.Command {
+	cmd := b.newCmd("status", b.cmdStatusRunEFn)
+	cmd.Short = "Show the status and the latest run of checks"
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The check ID")
+	cmd.Flags().StringVarP(&b.name, "name", "n", "", "The check name")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdCheckBuilder) cmdStatusRunEFn(cmd *cobra.Command, args []string) error {
+	checkSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	checks, err := b.findChecks(checkSVC, orgSVC)
+	if err != nil {
+		return err
+	}
+
+	if b.json {
+		return b.writeJSON(checks)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+	w.WriteHeaders("ID", "Name", "Status", "Latest Completed", "Last Run Status", "Last Run Error")
+	for _, c := range checks {
+		var latestCompleted string
+		if !c.LatestCompleted.IsZero() {
+			latestCompleted = c.LatestCompleted.Format(time.RFC3339)
+		}
+		w.Write(map[string]interface{}{
+			"ID":               c.ID.String(),
+			"Name":             c.Name,
+			"Status":           c.Status,
+			"Latest Completed": latestCompleted,
+			"Last Run Status":  c.LastRunStatus,
+			"Last Run Error":   c.LastRunError,
+		})
+	}
+
+	return nil
+}
+
+func (b *cmdCheckBuilder) cmdUpdate() *cobra.Command {
+	cmd := b.newCmd("update", b.cmdUpdateRunEFn)
+	cmd.Short = "Update a check"
+	cmd.Long = `Update the name, description or status of a check, or replace the whole
+check with a JSON or YAML file with the same body as the API.`
+	cmd.Example = `
+# mute a check
+influx check update -i 0000000000000001 --status inactive
+
+# replace a check
+influx check update -i 0000000000000001 -f check.yml`
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The check ID (required)")
+	cmd.Flags().StringVarP(&b.file, "file", "f", "", "Path to a JSON or YAML check body replacing the check; use - for stdin")
+	cmd.Flags().StringVarP(&b.name, "name", "n", "", "The new check name")
+	cmd.Flags().StringVarP(&b.description, "description", "d", "", "The new check description")
+	cmd.Flags().StringVarP(&b.status, "status", "", "", "The new check status, one of active or inactive")
+	cmd.MarkFlagRequired("id")
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdCheckBuilder) cmdUpdateRunEFn(cmd *cobra.Command, args []string) error {
+	checkSVC, _, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	id, err := influxdb.IDFromString(b.id)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if b.file != "" {
+		chk, err := b.checkFromFile()
+		if err != nil {
+			return err
+		}
+		chk, err = checkSVC.UpdateCheck(ctx, *id, chk)
+		if err != nil {
+			return fmt.Errorf("failed to update check with id %q: %v", b.id, err)
+		}
+		return b.printChecks(checkPrintOpt{check: chk})
+	}
+
+	var upd influxdb.CheckUpdate
+	if b.name != "" {
+		upd.Name = &b.name
+	}
+	if b.description != "" {
+		upd.Description = &b.description
+	}
+	if b.status != "" {
+		status := influxdb.Status(b.status)
+		upd.Status = &status
+	}
+	if err := upd.Valid(); err != nil {
+		return err
+	}
+
+	chk, err := checkSVC.PatchCheck(ctx, *id, upd)
+	if err != nil {
+		return fmt.Errorf("failed to update check with id %q: %v", b.id, err)
+	}
+
+	return b.printChecks(checkPrintOpt{check: chk})
+}
+
+func (b *cmdCheckBuilder) newCmd(use string, runE func(*cobra.Command, []string) error) *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd(use, runE, true)
+	b.globalFlags.registerFlags(cmd)
+	return cmd
+}
+
+func (b *cmdCheckBuilder) registerPrintFlags(cmd *cobra.Command) {
+	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
+}
+
+type checkPrintOpt struct {
+	deleted bool
+	check   *http.Check
+	checks  []*http.Check
+}
+
+func (b *cmdCheckBuilder) printChecks(opt checkPrintOpt) error {
+	if b.json {
+		var v interface{} = opt.checks
+		if opt.checks == nil {
+			v = opt.check
+		}
+		return b.writeJSON(v)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+
+	headers := []string{"ID", "Name", "Type", "Status", "Every", "Organization ID"}
+	if opt.deleted {
+		headers = append(headers, "Deleted")
+	}
+	w.WriteHeaders(headers...)
+
+	if opt.checks == nil {
+		opt.checks = append(opt.checks, opt.check)
+	}
+
+	for _, c := range opt.checks {
+		m := map[string]interface{}{
+			"ID":              c.ID.String(),
+			"Name":            c.Name,
+			"Type":            c.Type,
+			"Status":          c.Status,
+			"Every":           c.Every,
+			"Organization ID": c.OrgID.String(),
+		}
+		if opt.deleted {
+			m["Deleted"] = true
+		}
+		w.Write(m)
+	}
+
+	return nil
+}
+
+// readResourceBody reads a JSON or YAML resource body from a file, or from in when
+// file is "-", and returns it as JSON.
+func readResourceBody(file string, in io.Reader) ([]byte, error) {
+	var (
+		body []byte
+		err  error
+	)
+	if file == "-" {
+		body, err = ioutil.ReadAll(in)
+	} else {
+		body, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %v", file, err)
+	}
+
+	body, err = yaml.YAMLToJSON(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %q: %v", file, err)
+	}
+	return body, nil
+}
+
+func newCheckSVCs() (checkService, influxdb.OrganizationService, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &http.CheckService{Client: httpClient}, &http.OrganizationService{Client: httpClient}, nil
+}
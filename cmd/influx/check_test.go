@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdCheck(t *testing.T) {
+	orgID := influxdb.ID(9000)
+
+	cmdFn := func(svc *fakeCheckService) func(*globalFlags, genericCLIOpts) *cobra.Command {
+		return func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+			svcsFn := func() (checkService, influxdb.OrganizationService, error) {
+				return svc, &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+						return &influxdb.Organization{ID: orgID, Name: "influxdata"}, nil
+					},
+				}, nil
+			}
+			return newCmdCheckBuilder(svcsFn, g, opt).cmd()
+		}
+	}
+
+	t.Run("create from flags", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			flags    []string
+			expected *http.Check
+		}{
+			{
+				name: "threshold",
+				flags: []string{
+					"--type=threshold",
+					"--name=cpu",
+					"--query=from(bucket: \"b\")",
+					"--every=1m",
+					"--tag=team=ops",
+					"--threshold=crit:above:90",
+					"--threshold=warn:inside:70:90",
+					"--threshold=ok:below:10",
+					"--threshold=info:outside:1:2",
+				},
+				expected: &http.Check{
+					Name:   "cpu",
+					OrgID:  orgID,
+					Type:   "threshold",
+					Status: influxdb.Active,
+					Query:  &http.CheckQuery{Text: "from(bucket: \"b\")", EditMode: "advanced"},
+					Every:  "1m",
+					Tags:   []*influxdb.Tag{{Key: "team", Value: "ops"}},
+					Thresholds: []*http.CheckThreshold{
+						{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Critical}, Type: "greater", Value: 90},
+						{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Warn}, Type: "range", Min: 70, Max: 90, Within: true},
+						{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Ok}, Type: "lesser", Value: 10},
+						{ThresholdConfigBase: check.ThresholdConfigBase{Level: notification.Info}, Type: "range", Min: 1, Max: 2},
+					},
+				},
+			},
+			{
+				name: "deadman",
+				flags: []string{
+					"--type=deadman",
+					"--name=heartbeat",
+					"--query=from(bucket: \"b\")",
+					"--every=1m",
+					"--offset=10s",
+					"--time-since=90s",
+					"--stale-time=10m",
+					"--level=warn",
+					"--status=inactive",
+				},
+				expected: &http.Check{
+					Name:      "heartbeat",
+					OrgID:     orgID,
+					Type:      "deadman",
+					Status:    influxdb.Inactive,
+					Query:     &http.CheckQuery{Text: "from(bucket: \"b\")", EditMode: "advanced"},
+					Every:     "1m",
+					Offset:    "10s",
+					Tags:      []*influxdb.Tag{},
+					TimeSince: "90s",
+					StaleTime: "10m",
+					Level:     "WARN",
+				},
+			},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				defer addEnvVars(t, envVarsZeroMap)()
+
+				var created *http.Check
+				svc := &fakeCheckService{
+					CreateCheckFn: func(ctx context.Context, c *http.Check) (*http.Check, error) {
+						created = c
+						return c, nil
+					},
+				}
+
+				builder := newInfluxCmdBuilder(
+					in(new(bytes.Buffer)),
+					out(ioutil.Discard),
+				)
+				cmd := builder.cmd(cmdFn(svc))
+				cmd.SetArgs(append([]string{"check", "create", "--org=influxdata"}, tt.flags...))
+
+				require.NoError(t, cmd.Execute())
+				assert.Equal(t, tt.expected, created)
+			}
+
+			t.Run(tt.name, fn)
+		}
+	})
+
+	t.Run("create with invalid threshold", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		for _, threshold := range []string{"crit:above", "bad:above:1", "crit:near:1", "crit:inside:1", "crit:above:x"} {
+			builder := newInfluxCmdBuilder(
+				in(new(bytes.Buffer)),
+				out(ioutil.Discard),
+			)
+			cmd := builder.cmd(cmdFn(&fakeCheckService{}))
+			cmd.SetArgs([]string{
+				"check", "create", "--org=influxdata",
+				"--type=threshold", "--name=cpu", "--query=q", "--every=1m",
+				"--threshold=" + threshold,
+			})
+
+			assert.Error(t, cmd.Execute(), threshold)
+		}
+	})
+
+	t.Run("create from yaml file", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		dir, err := ioutil.TempDir("", "influx-check")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		file := filepath.Join(dir, "check.yml")
+		body := `
+name: mem
+type: threshold
+orgID: "` + influxdb.ID(1).String() + `"
+status: active
+every: 5m
+query:
+  text: 'from(bucket: "b")'
+thresholds:
+  - type: greater
+    level: CRIT
+    value: 95
+`
+		require.NoError(t, ioutil.WriteFile(file, []byte(body), 0600))
+
+		var created *http.Check
+		svc := &fakeCheckService{
+			CreateCheckFn: func(ctx context.Context, c *http.Check) (*http.Check, error) {
+				created = c
+				return c, nil
+			},
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"check", "create", "-f", file})
+
+		require.NoError(t, cmd.Execute())
+		require.NotNil(t, created)
+		assert.Equal(t, "mem", created.Name)
+		assert.Equal(t, influxdb.ID(1), created.OrgID)
+		assert.Equal(t, "5m", created.Every)
+		require.Len(t, created.Thresholds, 1)
+		assert.Equal(t, notification.Critical, created.Thresholds[0].Level)
+		assert.Equal(t, 95.0, created.Thresholds[0].Value)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		svc := &fakeCheckService{
+			FindChecksFn: func(ctx context.Context, filter influxdb.CheckFilter, opt ...influxdb.FindOptions) ([]*http.Check, int, error) {
+				return []*http.Check{{ID: 1, Name: "cpu", Status: influxdb.Active, LastRunStatus: "failed", LastRunError: "boom"}}, 1, nil
+			},
+		}
+
+		buf := new(bytes.Buffer)
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(buf),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"check", "status", "--org=influxdata", "--hide-headers"})
+
+		require.NoError(t, cmd.Execute())
+		assert.Contains(t, buf.String(), "cpu")
+		assert.Contains(t, buf.String(), "boom")
+	})
+
+	t.Run("update status", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		var upd influxdb.CheckUpdate
+		svc := &fakeCheckService{
+			PatchCheckFn: func(ctx context.Context, id influxdb.ID, u influxdb.CheckUpdate) (*http.Check, error) {
+				upd = u
+				return &http.Check{ID: id, Status: *u.Status}, nil
+			},
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"check", "update", "-i", influxdb.ID(1).String(), "--status=inactive"})
+
+		require.NoError(t, cmd.Execute())
+		require.NotNil(t, upd.Status)
+		assert.Equal(t, influxdb.Inactive, *upd.Status)
+		assert.Nil(t, upd.Name)
+	})
+}
+
+type fakeCheckService struct {
+	FindCheckByIDFn func(ctx context.Context, id influxdb.ID) (*http.Check, error)
+	FindChecksFn    func(ctx context.Context, filter influxdb.CheckFilter, opt ...influxdb.FindOptions) ([]*http.Check, int, error)
+	CreateCheckFn   func(ctx context.Context, c *http.Check) (*http.Check, error)
+	UpdateCheckFn   func(ctx context.Context, id influxdb.ID, c *http.Check) (*http.Check, error)
+	PatchCheckFn    func(ctx context.Context, id influxdb.ID, u influxdb.CheckUpdate) (*http.Check, error)
+	DeleteCheckFn   func(ctx context.Context, id influxdb.ID) error
+}
+
+func (s *fakeCheckService) FindCheckByID(ctx context.Context, id influxdb.ID) (*http.Check, error) {
+	return s.FindCheckByIDFn(ctx, id)
+}
+
+func (s *fakeCheckService) FindChecks(ctx context.Context, filter influxdb.CheckFilter, opt ...influxdb.FindOptions) ([]*http.Check, int, error) {
+	return s.FindChecksFn(ctx, filter, opt...)
+}
+
+func (s *fakeCheckService) CreateCheck(ctx context.Context, c *http.Check) (*http.Check, error) {
+	return s.CreateCheckFn(ctx, c)
+}
+
+func (s *fakeCheckService) UpdateCheck(ctx context.Context, id influxdb.ID, c *http.Check) (*http.Check, error) {
+	return s.UpdateCheckFn(ctx, id, c)
+}
+
+func (s *fakeCheckService) PatchCheck(ctx context.Context, id influxdb.ID, u influxdb.CheckUpdate) (*http.Check, error) {
+	return s.PatchCheckFn(ctx, id, u)
+}
+
+func (s *fakeCheckService) DeleteCheck(ctx context.Context, id influxdb.ID) error {
+	return s.DeleteCheckFn(ctx, id)
+}
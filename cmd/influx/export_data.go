@@ -0,0 +1,541 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/spf13/cobra"
+	writerfile "github.com/xitongsys/parquet-go-source/writer"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const (
+	exportFormatLineProtocol = "lp"
+	exportFormatCSV          = "csv"
+	exportFormatParquet      = "parquet"
+)
+
+// fluxCSVQuerier runs a Flux query and returns its result as annotated CSV.
+type fluxCSVQuerier interface {
+	QueryCSV(ctx context.Context, org organization, query string) (io.ReadCloser, error)
+}
+
+type exportDataSVCsFn func() (fluxCSVQuerier, error)
+
+type cmdExportDataBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn exportDataSVCsFn
+
+	org        organization
+	bucket     string
+	bucketID   string
+	start      string
+	stop       string
+	format     string
+	file       string
+	chunk      time.Duration
+	checkpoint string
+}
+
+func newCmdExportDataBuilder(svcFn exportDataSVCsFn, f *globalFlags, opt genericCLIOpts) *cmdExportDataBuilder {
+	return &cmdExportDataBuilder{
+		genericCLIOpts: opt,
+		globalFlags:    f,
+		svcFn:          svcFn,
+	}
+}
+
+func (b *cmdExportDataBuilder) cmd() *cobra.Command {
+	cmd := b.newCmd("data", b.exportDataRunEFn, true)
+	cmd.Short = "Export the data of a bucket as line protocol, annotated CSV or Parquet"
+	cmd.Long = `Export the data of a bucket in a time range through the query API.
+
+The range is queried in chunks of --chunk. With --format lp or csv the chunks are
+appended to --file, or written to stdout without it. With --format parquet --file
+is a directory, and each chunk holding data is written to a file named after
+the start of the chunk.
+
+With --checkpoint the progress of the export is recorded after each chunk, and an
+interrupted export is resumed from the last completed chunk when it is run again
+with the same flags. The checkpoint file is removed when the export completes.
+
+Line protocol and annotated CSV can be written again with "influx write".`
+	cmd.Example = `
+# export a day of data as line protocol
+influx export data --bucket my-bucket --start 2020-10-12T00:00:00Z --stop 2020-10-13T00:00:00Z --file my-bucket.lp
+
+# export a year of data to Parquet, resuming an interrupted export
+influx export data --bucket my-bucket --start 2019-01-01T00:00:00Z --stop 2020-01-01T00:00:00Z \
+	--format parquet --file /exports/my-bucket --checkpoint /exports/my-bucket.checkpoint`
+
+	b.globalFlags.registerFlags(cmd)
+	b.org.register(cmd, false)
+	cmd.Flags().StringVarP(&b.bucket, "bucket", "b", "", "The name of the bucket to export")
+	cmd.Flags().StringVar(&b.bucketID, "bucket-id", "", "The ID of the bucket to export")
+	cmd.Flags().StringVar(&b.start, "start", "", "The RFC3339 start of the time range to export (required)")
+	cmd.Flags().StringVar(&b.stop, "stop", "", "The RFC3339 stop of the time range to export; defaults to now")
+	cmd.Flags().StringVar(&b.format, "format", exportFormatLineProtocol, "The format of the export: lp, csv or parquet")
+	cmd.Flags().StringVarP(&b.file, "file", "f", "", "The file to export to, or the directory with --format parquet; defaults to stdout")
+	cmd.Flags().DurationVar(&b.chunk, "chunk", 24*time.Hour, "The time range queried at once")
+	cmd.Flags().StringVar(&b.checkpoint, "checkpoint", "", "The file to record the progress of the export in, to resume it")
+	cmd.MarkFlagRequired("start")
+
+	return cmd
+}
+
+func (b *cmdExportDataBuilder) exportDataRunEFn(cmd *cobra.Command, args []string) error {
+	if err := b.org.validOrgFlags(b.globalFlags); err != nil {
+		return err
+	}
+	if (b.bucket == "") == (b.bucketID == "") {
+		return errors.New("must specify bucket-id, or bucket name")
+	}
+	switch b.format {
+	case exportFormatLineProtocol, exportFormatCSV:
+	case exportFormatParquet:
+		if b.file == "" {
+			return errors.New("format parquet requires a file")
+		}
+	default:
+		return fmt.Errorf("unsupported format %q; use lp, csv or parquet", b.format)
+	}
+	if b.chunk <= 0 {
+		return errors.New("chunk must be positive")
+	}
+	if b.checkpoint != "" && (b.file == "" || b.stop == "") {
+		return errors.New("checkpoint requires a file and a stop time")
+	}
+
+	start, err := time.Parse(time.RFC3339Nano, b.start)
+	if err != nil {
+		return fmt.Errorf("invalid start time: %v", err)
+	}
+	stop := time.Now().UTC()
+	if b.stop != "" {
+		if stop, err = time.Parse(time.RFC3339Nano, b.stop); err != nil {
+			return fmt.Errorf("invalid stop time: %v", err)
+		}
+	}
+	if !stop.After(start) {
+		return errors.New("stop must be after start")
+	}
+
+	querier, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	cp := exportCheckpoint{
+		Bucket:   b.bucket,
+		BucketID: b.bucketID,
+		Start:    start,
+		Stop:     stop,
+		Format:   b.format,
+		Next:     start,
+	}
+	if b.checkpoint != "" {
+		if cp, err = readExportCheckpoint(b.checkpoint, cp); err != nil {
+			return err
+		}
+	}
+
+	out, err := b.openExportOutput(cp)
+	if err != nil {
+		return err
+	}
+	defer out.close()
+
+	ctx := context.Background()
+	for cp.Next.Before(stop) {
+		chunkStop := cp.Next.Add(b.chunk)
+		if chunkStop.After(stop) {
+			chunkStop = stop
+		}
+
+		if err := b.exportChunk(ctx, querier, out, cp.Next, chunkStop); err != nil {
+			return fmt.Errorf("failed to export %s to %s: %v", cp.Next.Format(time.RFC3339Nano), chunkStop.Format(time.RFC3339Nano), err)
+		}
+
+		cp.Next = chunkStop
+		if b.checkpoint != "" {
+			if cp.Size, err = out.size(); err != nil {
+				return err
+			}
+			if err := writeExportCheckpoint(b.checkpoint, cp); err != nil {
+				return err
+			}
+		}
+	}
+
+	if b.checkpoint != "" {
+		return os.Remove(b.checkpoint)
+	}
+	return nil
+}
+
+func (b *cmdExportDataBuilder) exportChunk(ctx context.Context, querier fluxCSVQuerier, out *exportOutput, start, stop time.Time) error {
+	rc, err := querier.QueryCSV(ctx, b.org, b.exportQuery(start, stop))
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	switch b.format {
+	case exportFormatCSV:
+		if _, err := io.Copy(out.w, rc); err != nil {
+			return err
+		}
+		return out.w.Flush()
+	case exportFormatParquet:
+		return out.writeParquet(rc, start)
+	default:
+		return out.writeLineProtocol(rc)
+	}
+}
+
+func (b *cmdExportDataBuilder) exportQuery(start, stop time.Time) string {
+	from := fmt.Sprintf("bucket: %q", b.bucket)
+	if b.bucketID != "" {
+		from = fmt.Sprintf("bucketID: %q", b.bucketID)
+	}
+	return fmt.Sprintf("from(%s)\n  |> range(start: %s, stop: %s)",
+		from, start.Format(time.RFC3339Nano), stop.Format(time.RFC3339Nano))
+}
+
+// exportCheckpoint records the progress of an export. An export is resumed
+// only with the flags it was started with.
+type exportCheckpoint struct {
+	Bucket   string    `json:"bucket,omitempty"`
+	BucketID string    `json:"bucketID,omitempty"`
+	Start    time.Time `json:"start"`
+	Stop     time.Time `json:"stop"`
+	Format   string    `json:"format"`
+
+	// Next is the start of the first chunk not yet exported.
+	Next time.Time `json:"next"`
+	// Size is the size of the export file after the last exported chunk.
+	Size int64 `json:"size"`
+}
+
+func (cp exportCheckpoint) resumes(other exportCheckpoint) bool {
+	return cp.Bucket == other.Bucket &&
+		cp.BucketID == other.BucketID &&
+		cp.Start.Equal(other.Start) &&
+		cp.Stop.Equal(other.Stop) &&
+		cp.Format == other.Format
+}
+
+// readExportCheckpoint returns the checkpoint in path, or def if there is none.
+func readExportCheckpoint(path string, def exportCheckpoint) (exportCheckpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return def, nil
+	} else if err != nil {
+		return def, err
+	}
+
+	var cp exportCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return def, fmt.Errorf("failed to decode checkpoint %s: %v", path, err)
+	}
+	if !cp.resumes(def) {
+		return def, fmt.Errorf("checkpoint %s belongs to another export; remove it to start over", path)
+	}
+	return cp, nil
+}
+
+// writeExportCheckpoint replaces the checkpoint in path, so an interruption
+// never leaves a partial checkpoint.
+func writeExportCheckpoint(path string, cp exportCheckpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// exportOutput is the destination of an export.
+type exportOutput struct {
+	f   *os.File
+	w   *bufio.Writer
+	dir string
+}
+
+func (b *cmdExportDataBuilder) openExportOutput(cp exportCheckpoint) (*exportOutput, error) {
+	if b.format == exportFormatParquet {
+		if err := os.MkdirAll(b.file, 0777); err != nil {
+			return nil, err
+		}
+		return &exportOutput{dir: b.file}, nil
+	}
+
+	if b.file == "" {
+		return &exportOutput{w: bufio.NewWriter(b.w)}, nil
+	}
+
+	// A resumed export drops anything written after the last checkpoint.
+	f, err := os.OpenFile(b.file, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(cp.Size); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(cp.Size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &exportOutput{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+func (o *exportOutput) size() (int64, error) {
+	if o.f == nil {
+		return 0, nil
+	}
+	if err := o.f.Sync(); err != nil {
+		return 0, err
+	}
+	return o.f.Seek(0, io.SeekCurrent)
+}
+
+func (o *exportOutput) close() error {
+	if o.f == nil {
+		return nil
+	}
+	return o.f.Close()
+}
+
+func (o *exportOutput) writeLineProtocol(r io.Reader) error {
+	err := readExportRows(r, func(row exportRow) error {
+		line, err := row.lineProtocol()
+		if err != nil {
+			return err
+		}
+		if _, err := o.w.WriteString(line); err != nil {
+			return err
+		}
+		return o.w.WriteByte('\n')
+	})
+	if err != nil {
+		return err
+	}
+	return o.w.Flush()
+}
+
+// parquetRow is a row of a Parquet export. The value is in the column of its type.
+type parquetRow struct {
+	Time          int64             `parquet:"name=time, type=INT64"`
+	Measurement   string            `parquet:"name=measurement, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	Field         string            `parquet:"name=field, type=UTF8, encoding=PLAIN_DICTIONARY"`
+	Tags          map[string]string `parquet:"name=tags, type=MAP, keytype=UTF8, valuetype=UTF8"`
+	FloatValue    *float64          `parquet:"name=float_value, type=DOUBLE, repetitiontype=OPTIONAL"`
+	IntegerValue  *int64            `parquet:"name=integer_value, type=INT64, repetitiontype=OPTIONAL"`
+	UnsignedValue *uint64           `parquet:"name=unsigned_value, type=UINT_64, repetitiontype=OPTIONAL"`
+	BooleanValue  *bool             `parquet:"name=boolean_value, type=BOOLEAN, repetitiontype=OPTIONAL"`
+	StringValue   *string           `parquet:"name=string_value, type=UTF8, repetitiontype=OPTIONAL"`
+}
+
+func newParquetRow(row exportRow) parquetRow {
+	pr := parquetRow{
+		Time:        row.time,
+		Measurement: row.measurement,
+		Field:       row.field,
+		Tags:        row.tags,
+	}
+	switch v := row.value.(type) {
+	case float64:
+		pr.FloatValue = &v
+	case int64:
+		pr.IntegerValue = &v
+	case uint64:
+		pr.UnsignedValue = &v
+	case bool:
+		pr.BooleanValue = &v
+	case string:
+		pr.StringValue = &v
+	}
+	return pr
+}
+
+// parquetFilename returns the name of the Parquet file of the chunk starting at start.
+func parquetFilename(start time.Time) string {
+	return start.UTC().Format("20060102T150405.000000000Z") + ".parquet"
+}
+
+func (o *exportOutput) writeParquet(r io.Reader, start time.Time) error {
+	path := filepath.Join(o.dir, parquetFilename(start))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	pw, err := writer.NewParquetWriter(writerfile.NewWriterFile(f), new(parquetRow), 1)
+	if err != nil {
+		return err
+	}
+
+	var rows int
+	err = readExportRows(r, func(row exportRow) error {
+		rows++
+		return pw.Write(newParquetRow(row))
+	})
+	if err != nil {
+		return err
+	}
+	if err := pw.WriteStop(); err != nil {
+		return err
+	}
+
+	// Chunks without data leave no file behind.
+	if rows == 0 {
+		f.Close()
+		return os.Remove(path)
+	}
+	return f.Sync()
+}
+
+// exportRow is a row of a query result in the shape of a point.
+type exportRow struct {
+	measurement string
+	field       string
+	tags        map[string]string
+	value       interface{}
+	time        int64
+}
+
+// lineProtocol returns the row as a line of line protocol.
+func (row exportRow) lineProtocol() (string, error) {
+	p, err := models.NewPoint(row.measurement, models.NewTags(row.tags), models.Fields{row.field: row.value}, time.Unix(0, row.time))
+	if err != nil {
+		return "", err
+	}
+	return p.String(), nil
+}
+
+// readExportRows decodes the annotated CSV of a query and calls fn with each
+// row holding a value.
+func readExportRows(r io.Reader, fn func(exportRow) error) error {
+	dec := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{})
+	results, err := dec.Decode(ioutil.NopCloser(r))
+	if err != nil {
+		return fmt.Errorf("query decode error: %s", err)
+	}
+	defer results.Release()
+
+	for results.More() {
+		res := results.Next()
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				return readExportColumns(cr, fn)
+			})
+		}); err != nil {
+			return err
+		}
+	}
+	results.Release()
+	return results.Err()
+}
+
+func readExportColumns(cr flux.ColReader, fn func(exportRow) error) error {
+	timeIdx, measurementIdx, fieldIdx, valueIdx := -1, -1, -1, -1
+	var tagIdxs []int
+	for j, c := range cr.Cols() {
+		switch c.Label {
+		case "_time":
+			timeIdx = j
+		case "_measurement":
+			measurementIdx = j
+		case "_field":
+			fieldIdx = j
+		case "_value":
+			valueIdx = j
+		default:
+			if c.Type == flux.TString && !strings.HasPrefix(c.Label, "_") && c.Label != "result" && c.Label != "table" {
+				tagIdxs = append(tagIdxs, j)
+			}
+		}
+	}
+	if timeIdx < 0 || measurementIdx < 0 || fieldIdx < 0 || valueIdx < 0 {
+		return errors.New("query result is missing _time, _measurement, _field or _value")
+	}
+
+	for i := 0; i < cr.Len(); i++ {
+		value := exportValue(cr, valueIdx, i)
+		if value == nil || !cr.Times(timeIdx).IsValid(i) {
+			continue
+		}
+
+		row := exportRow{
+			measurement: cr.Strings(measurementIdx).ValueString(i),
+			field:       cr.Strings(fieldIdx).ValueString(i),
+			tags:        make(map[string]string, len(tagIdxs)),
+			value:       value,
+			time:        cr.Times(timeIdx).Value(i),
+		}
+		for _, j := range tagIdxs {
+			if cr.Strings(j).IsValid(i) {
+				row.tags[cr.Cols()[j].Label] = cr.Strings(j).ValueString(i)
+			}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportValue returns the value of a column in a row, or nil if it is null.
+func exportValue(cr flux.ColReader, j, i int) interface{} {
+	switch cr.Cols()[j].Type {
+	case flux.TFloat:
+		if cr.Floats(j).IsValid(i) {
+			return cr.Floats(j).Value(i)
+		}
+	case flux.TInt:
+		if cr.Ints(j).IsValid(i) {
+			return cr.Ints(j).Value(i)
+		}
+	case flux.TUInt:
+		if cr.UInts(j).IsValid(i) {
+			return cr.UInts(j).Value(i)
+		}
+	case flux.TBool:
+		if cr.Bools(j).IsValid(i) {
+			return cr.Bools(j).Value(i)
+		}
+	case flux.TString:
+		if cr.Strings(j).IsValid(i) {
+			return cr.Strings(j).ValueString(i)
+		}
+	}
+	return nil
+}
+
+func newExportDataSVCs() (fluxCSVQuerier, error) {
+	ac := flags.config()
+	return &httpFluxCSVQuerier{
+		addr:               ac.Host,
+		token:              ac.Token,
+		insecureSkipVerify: flags.skipVerify,
+	}, nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func TestCmdExportData(t *testing.T) {
+	start := time.Date(2020, 10, 12, 0, 0, 0, 0, time.UTC)
+
+	chunkCSV := func(chunkStart time.Time) string {
+		chunkStop := chunkStart.Add(12 * time.Hour)
+		return fmt.Sprintf(`#group,false,false,true,true,false,false,true,true,true
+#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,double,string,string,string
+#default,_result,,,,,,,,
+,result,table,_start,_stop,_time,_value,_field,_measurement,host
+,,0,%[1]s,%[2]s,%[3]s,1.5,usage,cpu,a
+
+#group,false,false,true,true,false,false,true,true
+#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,long,string,string
+#default,_result,,,,,,,
+,result,table,_start,_stop,_time,_value,_field,_measurement
+,,1,%[1]s,%[2]s,%[3]s,3,free,disk space
+
+`, chunkStart.Format(time.RFC3339), chunkStop.Format(time.RFC3339), chunkStart.Add(time.Hour).Format(time.RFC3339))
+	}
+
+	type exportCalls struct {
+		queries []string
+		failAt  int
+	}
+
+	cmdFn := func(calls *exportCalls) func(*globalFlags, genericCLIOpts) *cobra.Command {
+		return func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+			svcFn := func() (fluxCSVQuerier, error) {
+				return &fakeFluxCSVQuerier{
+					QueryCSVFn: func(ctx context.Context, org organization, query string) (io.ReadCloser, error) {
+						calls.queries = append(calls.queries, query)
+						if len(calls.queries) == calls.failAt {
+							return nil, errors.New("connection reset")
+						}
+						chunkStart := start.Add(time.Duration(len(calls.queries)-1) * 12 * time.Hour)
+						if calls.failAt > 0 && len(calls.queries) > calls.failAt {
+							chunkStart = start.Add(12 * time.Hour)
+						}
+						return ioutil.NopCloser(strings.NewReader(chunkCSV(chunkStart))), nil
+					},
+				}, nil
+			}
+			return newCmdExportDataBuilder(svcFn, g, opt).cmd()
+		}
+	}
+
+	exportArgs := func(args ...string) []string {
+		return append([]string{
+			"data",
+			"--org-id", "0000000000000001",
+			"--bucket", "my-bucket",
+			"--start", start.Format(time.RFC3339),
+			"--stop", start.Add(24 * time.Hour).Format(time.RFC3339),
+			"--chunk", "12h",
+		}, args...)
+	}
+
+	wantLP := "cpu,host=a usage=1.5 1602464400000000000\n" +
+		"disk\\ space free=3i 1602464400000000000\n" +
+		"cpu,host=a usage=1.5 1602507600000000000\n" +
+		"disk\\ space free=3i 1602507600000000000\n"
+
+	t.Run("line protocol to stdout", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		calls := new(exportCalls)
+		buf := new(bytes.Buffer)
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(buf),
+		)
+		cmd := builder.cmd(cmdFn(calls))
+		cmd.SetArgs(exportArgs())
+
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, wantLP, buf.String())
+		require.Len(t, calls.queries, 2)
+		assert.Equal(t, "from(bucket: \"my-bucket\")\n  |> range(start: 2020-10-12T00:00:00Z, stop: 2020-10-12T12:00:00Z)", calls.queries[0])
+	})
+
+	t.Run("annotated csv", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		calls := new(exportCalls)
+		buf := new(bytes.Buffer)
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(buf),
+		)
+		cmd := builder.cmd(cmdFn(calls))
+		cmd.SetArgs(exportArgs("--format", "csv"))
+
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, chunkCSV(start)+chunkCSV(start.Add(12*time.Hour)), buf.String())
+	})
+
+	t.Run("parquet", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+		dir, err := ioutil.TempDir("", "influx-export")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		calls := new(exportCalls)
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(calls))
+		cmd.SetArgs(exportArgs("--format", "parquet", "--file", dir))
+
+		require.NoError(t, cmd.Execute())
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, parquetFilename(start)))
+		require.NoError(t, err)
+		pf, err := buffer.NewBufferFile(data)
+		require.NoError(t, err)
+		pr, err := reader.NewParquetReader(pf, new(parquetRow), 1)
+		require.NoError(t, err)
+		defer pr.ReadStop()
+
+		rows := make([]parquetRow, pr.GetNumRows())
+		require.NoError(t, pr.Read(&rows))
+		require.Len(t, rows, 2)
+		assert.Equal(t, "cpu", rows[0].Measurement)
+		assert.Equal(t, map[string]string{"host": "a"}, rows[0].Tags)
+		require.NotNil(t, rows[0].FloatValue)
+		assert.Equal(t, 1.5, *rows[0].FloatValue)
+		assert.Equal(t, start.Add(time.Hour).UnixNano(), rows[0].Time)
+		require.NotNil(t, rows[1].IntegerValue)
+		assert.Equal(t, int64(3), *rows[1].IntegerValue)
+
+		_, err = os.Stat(filepath.Join(dir, parquetFilename(start.Add(12*time.Hour))))
+		assert.NoError(t, err)
+	})
+
+	t.Run("resume from checkpoint", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+		dir, err := ioutil.TempDir("", "influx-export")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		file := filepath.Join(dir, "export.lp")
+		checkpoint := filepath.Join(dir, "export.checkpoint")
+		args := exportArgs("--file", file, "--checkpoint", checkpoint)
+
+		calls := &exportCalls{failAt: 2}
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(calls))
+		cmd.SetArgs(args)
+		require.Error(t, cmd.Execute())
+
+		cp, err := readExportCheckpoint(checkpoint, exportCheckpoint{
+			Bucket: "my-bucket",
+			Start:  start,
+			Stop:   start.Add(24 * time.Hour),
+			Format: exportFormatLineProtocol,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, start.Add(12*time.Hour), cp.Next)
+
+		_, err = readExportCheckpoint(checkpoint, exportCheckpoint{Bucket: "other"})
+		assert.Error(t, err, "checkpoint must not resume another export")
+
+		// A partial write of the failed chunk is dropped on resume.
+		f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0666)
+		require.NoError(t, err)
+		_, err = f.WriteString("cpu,host=a usage=")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		cmd = builder.cmd(cmdFn(calls))
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+		assert.Len(t, calls.queries, 3)
+
+		data, err := ioutil.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, wantLP, string(data))
+		_, err = os.Stat(checkpoint)
+		assert.True(t, os.IsNotExist(err), "checkpoint should be removed")
+	})
+
+	t.Run("checkpoint requires a stop time", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(new(exportCalls)))
+		cmd.SetArgs([]string{"data", "--org-id", "0000000000000001", "--bucket", "b", "--start", start.Format(time.RFC3339), "--file", "out.lp", "--checkpoint", "out.checkpoint"})
+		assert.Error(t, cmd.Execute())
+	})
+}
+
+type fakeFluxCSVQuerier struct {
+	QueryCSVFn func(ctx context.Context, org organization, query string) (io.ReadCloser, error)
+}
+
+func (q *fakeFluxCSVQuerier) QueryCSV(ctx context.Context, org organization, query string) (io.ReadCloser, error) {
+	return q.QueryCSVFn(ctx, org, query)
+}
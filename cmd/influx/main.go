@@ -323,13 +323,17 @@ func influxCmd(opts ...genericCLIOptFn) *cobra.Command {
 		cmdAuth,
 		cmdBackup,
 		cmdBucket,
+		cmdCheck,
 		cmdConfig,
 		cmdDashboard,
 		cmdDelete,
+		cmdEndpoint,
 		cmdExport,
 		cmdOrganization,
 		cmdPing,
 		cmdQuery,
+		cmdRestore,
+		cmdRule,
 		cmdSecret,
 		cmdSetup,
 		cmdStack,
@@ -339,6 +343,7 @@ func influxCmd(opts ...genericCLIOptFn) *cobra.Command {
 		cmdApply,
 		cmdTranspile,
 		cmdUser,
+		cmdV1,
 		cmdWrite,
 	)
 }
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/spf13/cobra"
+)
+
+type endpointSVCsFn func() (influxdb.NotificationEndpointService, influxdb.OrganizationService, error)
+
+func cmdEndpoint(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	builder := newCmdEndpointBuilder(newEndpointSVCs, f, opt)
+	return builder.cmd()
+}
+
+type cmdEndpointBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn endpointSVCsFn
+
+	json        bool
+	hideHeaders bool
+	id          string
+	name        string
+	description string
+	status      string
+	file        string
+	org         organization
+}
+
+func newCmdEndpointBuilder(svcsFn endpointSVCsFn, f *globalFlags, opt genericCLIOpts) *cmdEndpointBuilder {
+	return &cmdEndpointBuilder{
+		genericCLIOpts: opt,
+		globalFlags:    f,
+		svcFn:          svcsFn,
+	}
+}
+
+func (b *cmdEndpointBuilder) cmd() *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd("endpoint", nil, false)
+	cmd.Short = "Notification endpoint management commands"
+	cmd.Run = seeHelp
+	cmd.AddCommand(
+		b.cmdCreate(),
+		b.cmdDelete(),
+		b.cmdFind(),
+		b.cmdUpdate(),
+	)
+	return cmd
+}
+
+func (b *cmdEndpointBuilder) cmdCreate() *cobra.Command {
+	cmd := b.newCmd("create", b.cmdCreateRunEFn)
+	cmd.Short = "Create a notification endpoint from a JSON or YAML file with the same body as the API"
+
+	cmd.Flags().StringVarP(&b.file, "file", "f", "", "Path to a JSON or YAML notification endpoint body; use - for stdin (required)")
+	cmd.MarkFlagRequired("file")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdEndpointBuilder) cmdCreateRunEFn(cmd *cobra.Command, args []string) error {
+	endpointSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	ne, err := b.endpointFromFile()
+	if err != nil {
+		return err
+	}
+	if ne.GetStatus() == "" {
+		ne.SetStatus(influxdb.Active)
+	}
+
+	if b.org.id != "" || b.org.name != "" || !ne.GetOrgID().Valid() {
+		orgID, err := b.org.getID(orgSVC)
+		if err != nil {
+			return err
+		}
+		ne.SetOrgID(orgID)
+	}
+
+	if err := endpointSVC.CreateNotificationEndpoint(context.Background(), ne, 0); err != nil {
+		return fmt.Errorf("failed to create notification endpoint: %v", err)
+	}
+
+	return b.printEndpoints(endpointPrintOpt{endpoint: ne})
+}
+
+func (b *cmdEndpointBuilder) endpointFromFile() (influxdb.NotificationEndpoint, error) {
+	body, err := readResourceBody(b.file, b.in)
+	if err != nil {
+		return nil, err
+	}
+
+	ne, err := endpoint.UnmarshalJSON(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode notification endpoint: %v", err)
+	}
+	return ne, nil
+}
+
+func (b *cmdEndpointBuilder) cmdDelete() *cobra.Command {
+	cmd := b.newCmd("delete", b.cmdDeleteRunEFn)
+	cmd.Short = "Delete a notification endpoint"
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The notification endpoint ID (required)")
+	cmd.MarkFlagRequired("id")
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdEndpointBuilder) cmdDeleteRunEFn(cmd *cobra.Command, args []string) error {
+	endpointSVC, _, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	id, err := influxdb.IDFromString(b.id)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	ne, err := endpointSVC.FindNotificationEndpointByID(ctx, *id)
+	if err != nil {
+		return fmt.Errorf("failed to find notification endpoint with id %q: %v", b.id, err)
+	}
+
+	if _, _, err := endpointSVC.DeleteNotificationEndpoint(ctx, *id); err != nil {
+		return fmt.Errorf("failed to delete notification endpoint with id %q: %v", b.id, err)
+	}
+
+	return b.printEndpoints(endpointPrintOpt{
+		deleted:  true,
+		endpoint: ne,
+	})
+}
+
+func (b *cmdEndpointBuilder) cmdFind() *cobra.Command {
+	cmd := b.newCmd("list", b.cmdFindRunEFn)
+	cmd.Short = "List notification endpoints"
+	cmd.Aliases = []string{"find", "ls"}
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The notification endpoint ID")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdEndpointBuilder) cmdFindRunEFn(cmd *cobra.Command, args []string) error {
+	endpointSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if b.id != "" {
+		id, err := influxdb.IDFromString(b.id)
+		if err != nil {
+			return err
+		}
+		ne, err := endpointSVC.FindNotificationEndpointByID(ctx, *id)
+		if err != nil {
+			return fmt.Errorf("failed to find notification endpoint with id %q: %v", b.id, err)
+		}
+		return b.printEndpoints(endpointPrintOpt{endpoints: []influxdb.NotificationEndpoint{ne}})
+	}
+
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return err
+	}
+
+	endpoints, _, err := endpointSVC.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{OrgID: &orgID})
+	if err != nil {
+		return fmt.Errorf("failed to retrieve notification endpoints: %v", err)
+	}
+	if endpoints == nil {
+		endpoints = []influxdb.NotificationEndpoint{}
+	}
+
+	return b.printEndpoints(endpointPrintOpt{endpoints: endpoints})
+}
+
+func (b *cmdEndpointBuilder) cmdUpdate() *cobra.Command {
+	cmd := b.newCmd("update", b.cmdUpdateRunEFn)
+	cmd.Short = "Update a notification endpoint"
+	cmd.Long = `Update the name, description or status of a notification endpoint, or replace
+the whole notification endpoint with a JSON or YAML file with the same body as the API.`
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The notification endpoint ID (required)")
+	cmd.Flags().StringVarP(&b.file, "file", "f", "", "Path to a JSON or YAML notification endpoint body replacing the notification endpoint; use - for stdin")
+	cmd.Flags().StringVarP(&b.name, "name", "n", "", "The new notification endpoint name")
+	cmd.Flags().StringVarP(&b.description, "description", "d", "", "The new notification endpoint description")
+	cmd.Flags().StringVarP(&b.status, "status", "", "", "The new notification endpoint status, one of active or inactive")
+	cmd.MarkFlagRequired("id")
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdEndpointBuilder) cmdUpdateRunEFn(cmd *cobra.Command, args []string) error {
+	endpointSVC, _, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	id, err := influxdb.IDFromString(b.id)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if b.file != "" {
+		ne, err := b.endpointFromFile()
+		if err != nil {
+			return err
+		}
+		ne, err = endpointSVC.UpdateNotificationEndpoint(ctx, *id, ne, 0)
+		if err != nil {
+			return fmt.Errorf("failed to update notification endpoint with id %q: %v", b.id, err)
+		}
+		return b.printEndpoints(endpointPrintOpt{endpoint: ne})
+	}
+
+	var upd influxdb.NotificationEndpointUpdate
+	if b.name != "" {
+		upd.Name = &b.name
+	}
+	if b.description != "" {
+		upd.Description = &b.description
+	}
+	if b.status != "" {
+		status := influxdb.Status(b.status)
+		upd.Status = &status
+	}
+
+	ne, err := endpointSVC.PatchNotificationEndpoint(ctx, *id, upd)
+	if err != nil {
+		return fmt.Errorf("failed to update notification endpoint with id %q: %v", b.id, err)
+	}
+
+	return b.printEndpoints(endpointPrintOpt{endpoint: ne})
+}
+
+func (b *cmdEndpointBuilder) newCmd(use string, runE func(*cobra.Command, []string) error) *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd(use, runE, true)
+	b.globalFlags.registerFlags(cmd)
+	return cmd
+}
+
+func (b *cmdEndpointBuilder) registerPrintFlags(cmd *cobra.Command) {
+	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
+}
+
+type endpointPrintOpt struct {
+	deleted   bool
+	endpoint  influxdb.NotificationEndpoint
+	endpoints []influxdb.NotificationEndpoint
+}
+
+func (b *cmdEndpointBuilder) printEndpoints(opt endpointPrintOpt) error {
+	if b.json {
+		var v interface{} = opt.endpoints
+		if opt.endpoints == nil {
+			v = opt.endpoint
+		}
+		return b.writeJSON(v)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+
+	headers := []string{"ID", "Name", "Type", "Status", "Description", "Organization ID"}
+	if opt.deleted {
+		headers = append(headers, "Deleted")
+	}
+	w.WriteHeaders(headers...)
+
+	if opt.endpoints == nil {
+		opt.endpoints = append(opt.endpoints, opt.endpoint)
+	}
+
+	for _, e := range opt.endpoints {
+		m := map[string]interface{}{
+			"ID":              e.GetID().String(),
+			"Name":            e.GetName(),
+			"Type":            e.Type(),
+			"Status":          e.GetStatus(),
+			"Description":     e.GetDescription(),
+			"Organization ID": e.GetOrgID().String(),
+		}
+		if opt.deleted {
+			m["Deleted"] = true
+		}
+		w.Write(m)
+	}
+
+	return nil
+}
+
+func newEndpointSVCs() (influxdb.NotificationEndpointService, influxdb.OrganizationService, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	return http.NewNotificationEndpointService(httpClient), &http.OrganizationService{Client: httpClient}, nil
+}
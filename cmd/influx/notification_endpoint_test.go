@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdEndpoint(t *testing.T) {
+	orgID := influxdb.ID(9000)
+
+	cmdFn := func(svc influxdb.NotificationEndpointService) func(*globalFlags, genericCLIOpts) *cobra.Command {
+		return func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+			svcsFn := func() (influxdb.NotificationEndpointService, influxdb.OrganizationService, error) {
+				return svc, &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+						return &influxdb.Organization{ID: orgID, Name: "influxdata"}, nil
+					},
+				}, nil
+			}
+			return newCmdEndpointBuilder(svcsFn, g, opt).cmd()
+		}
+	}
+
+	t.Run("create from stdin", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		var created influxdb.NotificationEndpoint
+		svc := mock.NewNotificationEndpointService()
+		svc.CreateNotificationEndpointF = func(ctx context.Context, ne influxdb.NotificationEndpoint, userID influxdb.ID) error {
+			ne.SetID(1)
+			created = ne
+			return nil
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(strings.NewReader("type: slack\nname: ops\nurl: https://hooks.slack.com/services/x\n")),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"endpoint", "create", "--org=influxdata", "-f", "-"})
+
+		require.NoError(t, cmd.Execute())
+		require.IsType(t, &endpoint.Slack{}, created)
+		slack := created.(*endpoint.Slack)
+		assert.Equal(t, "ops", slack.Name)
+		assert.Equal(t, "https://hooks.slack.com/services/x", slack.URL)
+		assert.Equal(t, influxdb.Active, slack.Status)
+		assert.Equal(t, orgID, slack.GetOrgID())
+	})
+
+	t.Run("create on-call endpoints", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			body     string
+			expected influxdb.NotificationEndpoint
+		}{
+			{
+				name: "telegram",
+				body: "type: telegram\nname: ops\ntoken: bot-token\nchannel: \"-1001406363649\"\n",
+				expected: &endpoint.Telegram{
+					Base:    endpoint.Base{Name: "ops"},
+					Token:   influxdb.SecretField{Value: strPtr("bot-token")},
+					Channel: "-1001406363649",
+				},
+			},
+			{
+				name: "opsgenie",
+				body: "type: opsgenie\nname: ops\napiKey: genie-key\n",
+				expected: &endpoint.Opsgenie{
+					Base:   endpoint.Base{Name: "ops"},
+					APIKey: influxdb.SecretField{Value: strPtr("genie-key")},
+				},
+			},
+			{
+				name: "teams",
+				body: "type: teams\nname: ops\nurl: https://outlook.office.com/webhook/x\n",
+				expected: &endpoint.Teams{
+					Base: endpoint.Base{Name: "ops"},
+					URL:  "https://outlook.office.com/webhook/x",
+				},
+			},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				defer addEnvVars(t, envVarsZeroMap)()
+
+				var created influxdb.NotificationEndpoint
+				svc := mock.NewNotificationEndpointService()
+				svc.CreateNotificationEndpointF = func(ctx context.Context, ne influxdb.NotificationEndpoint, userID influxdb.ID) error {
+					created = ne
+					return nil
+				}
+
+				builder := newInfluxCmdBuilder(
+					in(strings.NewReader(tt.body)),
+					out(ioutil.Discard),
+				)
+				cmd := builder.cmd(cmdFn(svc))
+				cmd.SetArgs([]string{"endpoint", "create", "--org=influxdata", "-f", "-"})
+
+				require.NoError(t, cmd.Execute())
+				tt.expected.SetOrgID(orgID)
+				tt.expected.SetStatus(influxdb.Active)
+				assert.Equal(t, tt.expected, created)
+			}
+			t.Run(tt.name, fn)
+		}
+	})
+
+	t.Run("update status", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		var upd influxdb.NotificationEndpointUpdate
+		svc := mock.NewNotificationEndpointService()
+		svc.PatchNotificationEndpointF = func(ctx context.Context, id influxdb.ID, u influxdb.NotificationEndpointUpdate) (influxdb.NotificationEndpoint, error) {
+			upd = u
+			return &endpoint.Slack{Base: endpoint.Base{ID: &id, Name: "ops", Status: *u.Status}}, nil
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"endpoint", "update", "-i", influxdb.ID(1).String(), "--status=inactive"})
+
+		require.NoError(t, cmd.Execute())
+		require.NotNil(t, upd.Status)
+		assert.Equal(t, influxdb.Inactive, *upd.Status)
+	})
+	t.Run("create with org in body", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		var created influxdb.NotificationEndpoint
+		svc := mock.NewNotificationEndpointService()
+		svc.CreateNotificationEndpointF = func(ctx context.Context, ne influxdb.NotificationEndpoint, userID influxdb.ID) error {
+			created = ne
+			return nil
+		}
+
+		body := `{"type": "http", "name": "hook", "orgID": "` + influxdb.ID(1).String() + `", "status": "inactive", "url": "https://example.com/hook", "method": "POST", "authMethod": "none"}`
+		builder := newInfluxCmdBuilder(
+			in(strings.NewReader(body)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"endpoint", "create", "-f", "-"})
+
+		require.NoError(t, cmd.Execute())
+		require.IsType(t, &endpoint.HTTP{}, created)
+		assert.Equal(t, influxdb.ID(1), created.GetOrgID())
+		assert.Equal(t, influxdb.Inactive, created.GetStatus())
+	})
+
+	t.Run("create with invalid body", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		svc := mock.NewNotificationEndpointService()
+		svc.CreateNotificationEndpointF = func(ctx context.Context, ne influxdb.NotificationEndpoint, userID influxdb.ID) error {
+			t.Fatal("unexpected call to create notification endpoint")
+			return nil
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(strings.NewReader("type: carrier-pigeon\nname: ops\n")),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"endpoint", "create", "--org=influxdata", "-f", "-"})
+
+		require.Error(t, cmd.Execute())
+	})
+
+	t.Run("list", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			flags          []string
+			expectedFilter influxdb.NotificationEndpointFilter
+			expected       []string
+		}{
+			{
+				name:           "by org",
+				flags:          []string{"--org=influxdata"},
+				expectedFilter: influxdb.NotificationEndpointFilter{OrgID: &orgID},
+				expected:       []string{"ops", "slack", "pager", "pagerduty"},
+			},
+			{
+				name:           "by org id as json",
+				flags:          []string{"--org-id=" + orgID.String(), "--json"},
+				expectedFilter: influxdb.NotificationEndpointFilter{OrgID: &orgID},
+				expected:       []string{`"name": "ops"`, `"type": "slack"`, `"name": "pager"`},
+			},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				defer addEnvVars(t, envVarsZeroMap)()
+
+				var filter influxdb.NotificationEndpointFilter
+				slackID, pagerID := influxdb.ID(1), influxdb.ID(2)
+				svc := mock.NewNotificationEndpointService()
+				svc.FindNotificationEndpointsF = func(ctx context.Context, f influxdb.NotificationEndpointFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+					filter = f
+					return []influxdb.NotificationEndpoint{
+						&endpoint.Slack{Base: endpoint.Base{ID: &slackID, Name: "ops", OrgID: &orgID, Status: influxdb.Active}},
+						&endpoint.PagerDuty{Base: endpoint.Base{ID: &pagerID, Name: "pager", OrgID: &orgID, Status: influxdb.Inactive}},
+					}, 2, nil
+				}
+
+				buf := new(bytes.Buffer)
+				builder := newInfluxCmdBuilder(
+					in(new(bytes.Buffer)),
+					out(buf),
+				)
+				cmd := builder.cmd(cmdFn(svc))
+				cmd.SetArgs(append([]string{"endpoint", "list"}, tt.flags...))
+
+				require.NoError(t, cmd.Execute())
+				assert.Equal(t, tt.expectedFilter, filter)
+				for _, s := range tt.expected {
+					assert.Contains(t, buf.String(), s)
+				}
+			}
+			t.Run(tt.name, fn)
+		}
+	})
+
+	t.Run("list by id", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		var found influxdb.ID
+		svc := mock.NewNotificationEndpointService()
+		svc.FindNotificationEndpointByIDF = func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+			found = id
+			return &endpoint.Teams{Base: endpoint.Base{ID: &id, Name: "teams", OrgID: &orgID, Status: influxdb.Active}}, nil
+		}
+		svc.FindNotificationEndpointsF = func(ctx context.Context, f influxdb.NotificationEndpointFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+			t.Fatal("unexpected call to find notification endpoints")
+			return nil, 0, nil
+		}
+
+		buf := new(bytes.Buffer)
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(buf),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"endpoint", "list", "-i", influxdb.ID(3).String(), "--hide-headers"})
+
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, influxdb.ID(3), found)
+		assert.Contains(t, buf.String(), "teams")
+		assert.NotContains(t, buf.String(), "Organization ID")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		var deleted influxdb.ID
+		svc := mock.NewNotificationEndpointService()
+		svc.FindNotificationEndpointByIDF = func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+			return &endpoint.Slack{Base: endpoint.Base{ID: &id, Name: "ops", OrgID: &orgID, Status: influxdb.Active}}, nil
+		}
+		svc.DeleteNotificationEndpointF = func(ctx context.Context, id influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error) {
+			deleted = id
+			return nil, orgID, nil
+		}
+
+		buf := new(bytes.Buffer)
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(buf),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"endpoint", "delete", "-i", influxdb.ID(1).String()})
+
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, influxdb.ID(1), deleted)
+		assert.Contains(t, buf.String(), "Deleted")
+		assert.Contains(t, buf.String(), "ops")
+	})
+
+	t.Run("delete missing endpoint", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		svc := mock.NewNotificationEndpointService()
+		svc.FindNotificationEndpointByIDF = func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "notification endpoint not found"}
+		}
+		svc.DeleteNotificationEndpointF = func(ctx context.Context, id influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error) {
+			t.Fatal("unexpected call to delete notification endpoint")
+			return nil, 0, nil
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"endpoint", "delete", "-i", influxdb.ID(1).String()})
+
+		require.Error(t, cmd.Execute())
+	})
+
+	t.Run("update name and description", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		var upd influxdb.NotificationEndpointUpdate
+		svc := mock.NewNotificationEndpointService()
+		svc.PatchNotificationEndpointF = func(ctx context.Context, id influxdb.ID, u influxdb.NotificationEndpointUpdate) (influxdb.NotificationEndpoint, error) {
+			upd = u
+			return &endpoint.Slack{Base: endpoint.Base{ID: &id, Name: *u.Name, Description: *u.Description}}, nil
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"endpoint", "update", "-i", influxdb.ID(1).String(), "-n", "on-call", "-d", "paging"})
+
+		require.NoError(t, cmd.Execute())
+		require.NotNil(t, upd.Name)
+		require.NotNil(t, upd.Description)
+		assert.Equal(t, "on-call", *upd.Name)
+		assert.Equal(t, "paging", *upd.Description)
+		assert.Nil(t, upd.Status)
+	})
+
+	t.Run("update from file", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		var (
+			updatedID influxdb.ID
+			updated   influxdb.NotificationEndpoint
+		)
+		svc := mock.NewNotificationEndpointService()
+		svc.UpdateNotificationEndpointF = func(ctx context.Context, id influxdb.ID, ne influxdb.NotificationEndpoint, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
+			updatedID, updated = id, ne
+			return ne, nil
+		}
+		svc.PatchNotificationEndpointF = func(ctx context.Context, id influxdb.ID, u influxdb.NotificationEndpointUpdate) (influxdb.NotificationEndpoint, error) {
+			t.Fatal("unexpected call to patch notification endpoint")
+			return nil, nil
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(strings.NewReader("type: slack\nname: ops\nstatus: active\nurl: https://hooks.slack.com/services/y\n")),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"endpoint", "update", "-i", influxdb.ID(1).String(), "-f", "-"})
+
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, influxdb.ID(1), updatedID)
+		require.IsType(t, &endpoint.Slack{}, updated)
+		assert.Equal(t, "https://hooks.slack.com/services/y", updated.(*endpoint.Slack).URL)
+	})
+}
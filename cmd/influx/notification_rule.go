@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	"github.com/spf13/cobra"
+)
+
+type ruleSVCsFn func() (influxdb.NotificationRuleStore, influxdb.OrganizationService, error)
+
+func cmdRule(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	builder := newCmdRuleBuilder(newRuleSVCs, f, opt)
+	return builder.cmd()
+}
+
+type cmdRuleBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn ruleSVCsFn
+
+	json        bool
+	hideHeaders bool
+	id          string
+	name        string
+	description string
+	status      string
+	file        string
+	tags        []string
+	org         organization
+}
+
+func newCmdRuleBuilder(svcsFn ruleSVCsFn, f *globalFlags, opt genericCLIOpts) *cmdRuleBuilder {
+	return &cmdRuleBuilder{
+		genericCLIOpts: opt,
+		globalFlags:    f,
+		svcFn:          svcsFn,
+	}
+}
+
+func (b *cmdRuleBuilder) cmd() *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd("rule", nil, false)
+	cmd.Short = "Notification rule management commands"
+	cmd.Run = seeHelp
+	cmd.AddCommand(
+		b.cmdCreate(),
+		b.cmdDelete(),
+		b.cmdFind(),
+		b.cmdUpdate(),
+	)
+	return cmd
+}
+
+func (b *cmdRuleBuilder) cmdCreate() *cobra.Command {
+	cmd := b.newCmd("create", b.cmdCreateRunEFn)
+	cmd.Short = "Create a notification rule from a JSON or YAML file with the same body as the API"
+
+	cmd.Flags().StringVarP(&b.file, "file", "f", "", "Path to a JSON or YAML notification rule body; use - for stdin (required)")
+	cmd.MarkFlagRequired("file")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdRuleBuilder) cmdCreateRunEFn(cmd *cobra.Command, args []string) error {
+	ruleSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	nrc, err := b.ruleFromFile()
+	if err != nil {
+		return err
+	}
+
+	if b.org.id != "" || b.org.name != "" || !nrc.GetOrgID().Valid() {
+		orgID, err := b.org.getID(orgSVC)
+		if err != nil {
+			return err
+		}
+		nrc.SetOrgID(orgID)
+	}
+
+	if err := ruleSVC.CreateNotificationRule(context.Background(), nrc, 0); err != nil {
+		return fmt.Errorf("failed to create notification rule: %v", err)
+	}
+
+	return b.printRules(rulePrintOpt{rule: nrc.NotificationRule})
+}
+
+func (b *cmdRuleBuilder) ruleFromFile() (influxdb.NotificationRuleCreate, error) {
+	body, err := readResourceBody(b.file, b.in)
+	if err != nil {
+		return influxdb.NotificationRuleCreate{}, err
+	}
+
+	nr, err := rule.UnmarshalJSON(body)
+	if err != nil {
+		return influxdb.NotificationRuleCreate{}, fmt.Errorf("failed to decode notification rule: %v", err)
+	}
+
+	var status struct {
+		Status influxdb.Status `json:"status"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return influxdb.NotificationRuleCreate{}, fmt.Errorf("failed to decode notification rule: %v", err)
+	}
+	if status.Status == "" {
+		status.Status = influxdb.Active
+	}
+
+	return influxdb.NotificationRuleCreate{
+		NotificationRule: nr,
+		Status:           status.Status,
+	}, nil
+}
+
+func (b *cmdRuleBuilder) cmdDelete() *cobra.Command {
+	cmd := b.newCmd("delete", b.cmdDeleteRunEFn)
+	cmd.Short = "Delete a notification rule"
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The notification rule ID (required)")
+	cmd.MarkFlagRequired("id")
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdRuleBuilder) cmdDeleteRunEFn(cmd *cobra.Command, args []string) error {
+	ruleSVC, _, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	id, err := influxdb.IDFromString(b.id)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	nr, err := ruleSVC.FindNotificationRuleByID(ctx, *id)
+	if err != nil {
+		return fmt.Errorf("failed to find notification rule with id %q: %v", b.id, err)
+	}
+
+	if err := ruleSVC.DeleteNotificationRule(ctx, *id); err != nil {
+		return fmt.Errorf("failed to delete notification rule with id %q: %v", b.id, err)
+	}
+
+	return b.printRules(rulePrintOpt{
+		deleted: true,
+		rule:    nr,
+	})
+}
+
+func (b *cmdRuleBuilder) cmdFind() *cobra.Command {
+	cmd := b.newCmd("list", b.cmdFindRunEFn)
+	cmd.Short = "List notification rules"
+	cmd.Aliases = []string{"find", "ls"}
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The notification rule ID")
+	cmd.Flags().StringArrayVarP(&b.tags, "tag", "", nil, "Limits the list to rules matching the tag, as key:value")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdRuleBuilder) cmdFindRunEFn(cmd *cobra.Command, args []string) error {
+	ruleSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if b.id != "" {
+		id, err := influxdb.IDFromString(b.id)
+		if err != nil {
+			return err
+		}
+		nr, err := ruleSVC.FindNotificationRuleByID(ctx, *id)
+		if err != nil {
+			return fmt.Errorf("failed to find notification rule with id %q: %v", b.id, err)
+		}
+		return b.printRules(rulePrintOpt{rules: []influxdb.NotificationRule{nr}})
+	}
+
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return err
+	}
+	filter := influxdb.NotificationRuleFilter{OrgID: &orgID}
+	for _, raw := range b.tags {
+		parts := strings.SplitN(raw, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("invalid tag %q, expected key:value", raw)
+		}
+		filter.Tags = append(filter.Tags, influxdb.Tag{Key: parts[0], Value: parts[1]})
+	}
+
+	rules, _, err := ruleSVC.FindNotificationRules(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve notification rules: %v", err)
+	}
+	if rules == nil {
+		rules = []influxdb.NotificationRule{}
+	}
+
+	return b.printRules(rulePrintOpt{rules: rules})
+}
+
+func (b *cmdRuleBuilder) cmdUpdate() *cobra.Command {
+	cmd := b.newCmd("update", b.cmdUpdateRunEFn)
+	cmd.Short = "Update a notification rule"
+	cmd.Long = `Update the name, description or status of a notification rule, or replace
+the whole notification rule with a JSON or YAML file with the same body as the API.`
+	cmd.Example = `
+# mute a notification rule
+influx rule update -i 0000000000000001 --status inactive`
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The notification rule ID (required)")
+	cmd.Flags().StringVarP(&b.file, "file", "f", "", "Path to a JSON or YAML notification rule body replacing the notification rule; use - for stdin")
+	cmd.Flags().StringVarP(&b.name, "name", "n", "", "The new notification rule name")
+	cmd.Flags().StringVarP(&b.description, "description", "d", "", "The new notification rule description")
+	cmd.Flags().StringVarP(&b.status, "status", "", "", "The new notification rule status, one of active or inactive")
+	cmd.MarkFlagRequired("id")
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdRuleBuilder) cmdUpdateRunEFn(cmd *cobra.Command, args []string) error {
+	ruleSVC, _, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	id, err := influxdb.IDFromString(b.id)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if b.file != "" {
+		nrc, err := b.ruleFromFile()
+		if err != nil {
+			return err
+		}
+		nr, err := ruleSVC.UpdateNotificationRule(ctx, *id, nrc, 0)
+		if err != nil {
+			return fmt.Errorf("failed to update notification rule with id %q: %v", b.id, err)
+		}
+		return b.printRules(rulePrintOpt{rule: nr})
+	}
+
+	var upd influxdb.NotificationRuleUpdate
+	if b.name != "" {
+		upd.Name = &b.name
+	}
+	if b.description != "" {
+		upd.Description = &b.description
+	}
+	if b.status != "" {
+		status := influxdb.Status(b.status)
+		upd.Status = &status
+	}
+	if err := upd.Valid(); err != nil {
+		return err
+	}
+
+	nr, err := ruleSVC.PatchNotificationRule(ctx, *id, upd)
+	if err != nil {
+		return fmt.Errorf("failed to update notification rule with id %q: %v", b.id, err)
+	}
+
+	return b.printRules(rulePrintOpt{rule: nr})
+}
+
+func (b *cmdRuleBuilder) newCmd(use string, runE func(*cobra.Command, []string) error) *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd(use, runE, true)
+	b.globalFlags.registerFlags(cmd)
+	return cmd
+}
+
+func (b *cmdRuleBuilder) registerPrintFlags(cmd *cobra.Command) {
+	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
+}
+
+type rulePrintOpt struct {
+	deleted bool
+	rule    influxdb.NotificationRule
+	rules   []influxdb.NotificationRule
+}
+
+func (b *cmdRuleBuilder) printRules(opt rulePrintOpt) error {
+	if b.json {
+		var v interface{} = opt.rules
+		if opt.rules == nil {
+			v = opt.rule
+		}
+		return b.writeJSON(v)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+
+	headers := []string{"ID", "Name", "Type", "Description", "Endpoint ID", "Organization ID"}
+	if opt.deleted {
+		headers = append(headers, "Deleted")
+	}
+	w.WriteHeaders(headers...)
+
+	if opt.rules == nil {
+		opt.rules = append(opt.rules, opt.rule)
+	}
+
+	for _, r := range opt.rules {
+		m := map[string]interface{}{
+			"ID":              r.GetID().String(),
+			"Name":            r.GetName(),
+			"Type":            r.Type(),
+			"Description":     r.GetDescription(),
+			"Endpoint ID":     r.GetEndpointID().String(),
+			"Organization ID": r.GetOrgID().String(),
+		}
+		if opt.deleted {
+			m["Deleted"] = true
+		}
+		w.Write(m)
+	}
+
+	return nil
+}
+
+func newRuleSVCs() (influxdb.NotificationRuleStore, influxdb.OrganizationService, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	return http.NewNotificationRuleService(httpClient), &http.OrganizationService{Client: httpClient}, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdRule(t *testing.T) {
+	orgID := influxdb.ID(9000)
+
+	cmdFn := func(svc influxdb.NotificationRuleStore) func(*globalFlags, genericCLIOpts) *cobra.Command {
+		return func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+			svcsFn := func() (influxdb.NotificationRuleStore, influxdb.OrganizationService, error) {
+				return svc, &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+						return &influxdb.Organization{ID: orgID, Name: "influxdata"}, nil
+					},
+				}, nil
+			}
+			return newCmdRuleBuilder(svcsFn, g, opt).cmd()
+		}
+	}
+
+	t.Run("list by tag", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		var filter influxdb.NotificationRuleFilter
+		svc := mock.NewNotificationRuleStore()
+		svc.FindNotificationRulesF = func(ctx context.Context, f influxdb.NotificationRuleFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+			filter = f
+			return nil, 0, nil
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"rule", "list", "--org=influxdata", "--tag=team:ops"})
+
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, &orgID, filter.OrgID)
+		assert.Equal(t, []influxdb.Tag{{Key: "team", Value: "ops"}}, filter.Tags)
+	})
+
+	t.Run("create from stdin", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		var created influxdb.NotificationRuleCreate
+		svc := mock.NewNotificationRuleStore()
+		svc.CreateNotificationRuleF = func(ctx context.Context, nrc influxdb.NotificationRuleCreate, userID influxdb.ID) error {
+			nrc.SetID(1)
+			created = nrc
+			return nil
+		}
+
+		body := `{
+			"type": "slack",
+			"name": "crit alerts",
+			"endpointID": "` + influxdb.ID(2).String() + `",
+			"every": "1m",
+			"channel": "#ops",
+			"messageTemplate": "${ r._message }",
+			"statusRules": [{"currentLevel": "CRIT"}],
+			"status": "inactive"
+		}`
+		builder := newInfluxCmdBuilder(
+			in(strings.NewReader(body)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"rule", "create", "--org-id=" + orgID.String(), "-f", "-"})
+
+		require.NoError(t, cmd.Execute())
+		require.IsType(t, &rule.Slack{}, created.NotificationRule)
+		assert.Equal(t, influxdb.Inactive, created.Status)
+		assert.Equal(t, "crit alerts", created.GetName())
+		assert.Equal(t, influxdb.ID(2), created.GetEndpointID())
+		assert.Equal(t, orgID, created.GetOrgID())
+	})
+}
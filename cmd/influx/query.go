@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,33 +10,88 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/csv"
 	"github.com/influxdata/flux/values"
 	ihttp "github.com/influxdata/influxdb/v2/http"
 	"github.com/spf13/cobra"
 )
 
+const (
+	queryFormatTable        = "table"
+	queryFormatCSV          = "csv"
+	queryFormatJSON         = "json"
+	queryFormatLineProtocol = "lp"
+
+	// profilerResultName is the name of the result holding the profiler tables.
+	profilerResultName = "_profiler"
+
+	// paramsOptionName is the option dashboard variables are injected into.
+	paramsOptionName = "v"
+)
+
 var queryFlags struct {
-	org  organization
-	file string
-	raw  bool
+	org        organization
+	file       string
+	raw        bool
+	format     string
+	params     []string
+	profilers  []string
+	timeFormat string
 }
 
 func cmdQuery(f *globalFlags, opts genericCLIOpts) *cobra.Command {
+	return newCmdQuery(newRunningQuerySVCs, f, opts)
+}
+
+func newCmdQuery(svcsFn runningQuerySVCsFn, f *globalFlags, opts genericCLIOpts) *cobra.Command {
 	cmd := opts.newCmd("query [query literal or -f /path/to/query.flux]", fluxQueryF, true)
 	cmd.Short = "Execute a Flux query"
-	cmd.Long = `Execute a Flux query provided via the first argument or a file or stdin`
+	cmd.Long = `Execute a Flux query provided via the first argument or a file or stdin.
+
+The results are printed as tables by default. With --format csv the annotated CSV
+of the query API is printed, with --format json one JSON object per row, and with
+--format lp the rows holding _measurement, _field, _value and _time as line protocol.
+
+Each --param name=value is injected into the query as v.name, the same way
+dashboard variables are. Values are read as durations, times, numbers and
+booleans when they parse as one, and as strings otherwise; quote a value to
+keep it a string.
+
+The tables of --profilers are written to stderr, apart from the results.`
+	cmd.Example = `
+# query with a variable, as in a dashboard cell
+influx query 'from(bucket: "b") |> range(start: v.start) |> filter(fn: (r) => r.host == v.host)' \
+	--param start=-1h --param host=server01
+
+# print the results as JSON with Unix timestamps
+influx query -f query.flux --format json --time-format unix
+
+# profile a query
+influx query -f query.flux --profilers query,operator`
 	cmd.Args = cobra.MaximumNArgs(1)
 
 	f.registerFlags(cmd)
 	queryFlags.org.register(cmd, true)
 	cmd.Flags().StringVarP(&queryFlags.file, "file", "f", "", "Path to Flux query file")
-	cmd.Flags().BoolVarP(&queryFlags.raw, "raw", "r", false, "Display raw query results")
+	cmd.Flags().BoolVarP(&queryFlags.raw, "raw", "r", false, "Display raw query results; same as --format csv")
+	cmd.Flags().StringVar(&queryFlags.format, "format", queryFormatTable, "The format of the results: table, csv, json or lp")
+	cmd.Flags().StringArrayVar(&queryFlags.params, "param", nil, "A name=value parameter injected into the query as v.name; may be repeated")
+	cmd.Flags().StringSliceVar(&queryFlags.profilers, "profilers", nil, "Comma separated list of profilers to enable, such as query,operator")
+	cmd.Flags().StringVar(&queryFlags.timeFormat, "time-format", "", "The format of times in table and json results: rfc3339, rfc3339nano, unix, unixms, unixus or unixns; defaults to rfc3339nano")
+
+	builder := newCmdRunningQueryBuilder(svcsFn, f, opts)
+	cmd.AddCommand(
+		builder.cmdKill(),
+		builder.cmdPs(),
+	)
 
 	return cmd
 }
@@ -78,60 +134,183 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	opts := queryOutputOptions{
+		format:     queryFlags.format,
+		timeFormat: queryFlags.timeFormat,
+		profilers:  len(queryFlags.profilers) > 0,
+	}
+	if queryFlags.raw {
+		opts.format = queryFormatCSV
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
 	q, err := readFluxQuery(args, queryFlags.file)
 	if err != nil {
 		return fmt.Errorf("failed to load query: %v", err)
 	}
 
-	u, err := url.Parse(flags.config().Host)
+	extern, err := queryExtern(queryFlags.params, queryFlags.profilers)
 	if err != nil {
-		return fmt.Errorf("unable to parse host: %s", err)
+		return err
 	}
 
-	if !strings.HasSuffix(u.Path, "/") {
-		u.Path += "/"
+	ac := flags.config()
+	querier := &httpFluxCSVQuerier{
+		addr:               ac.Host,
+		token:              ac.Token,
+		insecureSkipVerify: flags.skipVerify,
+	}
+	rc, err := querier.queryCSV(context.Background(), queryFlags.org, q, extern)
+	if err != nil {
+		return err
 	}
-	u.Path += "api/v2/query"
+	defer rc.Close()
 
-	params := url.Values{}
-	if queryFlags.org.id != "" {
-		params.Set("orgID", queryFlags.org.id)
-	} else {
-		params.Set("org", queryFlags.org.name)
+	return writeQueryResults(cmd.OutOrStdout(), cmd.ErrOrStderr(), rc, opts)
+}
+
+// queryExtern returns the extern file injecting the params and enabling the
+// profilers of a query, or nil if there are none.
+func queryExtern(params, profilers []string) (*ast.File, error) {
+	extern := &ast.File{}
+
+	if len(params) > 0 {
+		obj := &ast.ObjectExpression{}
+		for _, p := range params {
+			parts := strings.SplitN(p, "=", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				return nil, fmt.Errorf("invalid param %q; expected name=value", p)
+			}
+			obj.Properties = append(obj.Properties, &ast.Property{
+				Key:   &ast.Identifier{Name: parts[0]},
+				Value: paramLiteral(parts[1]),
+			})
+		}
+		extern.Body = append(extern.Body, &ast.OptionStatement{
+			Assignment: &ast.VariableAssignment{
+				ID:   &ast.Identifier{Name: paramsOptionName},
+				Init: obj,
+			},
+		})
 	}
-	u.RawQuery = params.Encode()
 
-	body, _ := json.Marshal(map[string]interface{}{
-		"query": q,
-		"type":  "flux",
-		"dialect": map[string]interface{}{
-			"annotations": []string{"group", "datatype", "default"},
-			"delimiter":   ",",
-			"header":      true,
-		},
-	})
+	if len(profilers) > 0 {
+		arr := &ast.ArrayExpression{}
+		for _, p := range profilers {
+			arr.Elements = append(arr.Elements, &ast.StringLiteral{Value: p})
+		}
+		extern.Imports = append(extern.Imports, &ast.ImportDeclaration{
+			Path: &ast.StringLiteral{Value: "profiler"},
+		})
+		extern.Body = append(extern.Body, &ast.OptionStatement{
+			Assignment: &ast.MemberAssignment{
+				Member: &ast.MemberExpression{
+					Object:   &ast.Identifier{Name: "profiler"},
+					Property: &ast.Identifier{Name: "enabledProfilers"},
+				},
+				Init: arr,
+			},
+		})
+	}
 
-	req, _ := http.NewRequest("POST", u.String(), bytes.NewReader(body))
-	req.Header.Set("Authorization", "Token "+flags.config().Token)
-	req.Header.Set("Content-Type", "application/json")
+	if len(extern.Body) == 0 {
+		return nil, nil
+	}
+	return extern, nil
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
+var durationRegexp = regexp.MustCompile(`(\d+)(y|mo|w|d|h|ms|m|s|us|µs|ns)`)
+
+// paramLiteral returns the Flux literal a param value is read as.
+func paramLiteral(v string) ast.Expression {
+	if s, err := strconv.Unquote(v); err == nil && strings.HasPrefix(v, `"`) {
+		return &ast.StringLiteral{Value: s}
+	}
+	if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return &ast.IntegerLiteral{Value: i}
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return &ast.FloatLiteral{Value: f}
+	}
+	if b, err := strconv.ParseBool(v); err == nil && (v == "true" || v == "false") {
+		return &ast.BooleanLiteral{Value: b}
 	}
-	defer func() { _ = resp.Body.Close() }()
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return &ast.DateTimeLiteral{Value: t}
+	}
+	if d := parseDurationLiteral(strings.TrimPrefix(v, "-")); d != nil {
+		if strings.HasPrefix(v, "-") {
+			return &ast.UnaryExpression{Operator: ast.SubtractionOperator, Argument: d}
+		}
+		return d
+	}
+	return &ast.StringLiteral{Value: v}
+}
 
-	if err := ihttp.CheckError(resp); err != nil {
-		return err
+// parseDurationLiteral parses a Flux duration such as 1h30m, or returns nil.
+func parseDurationLiteral(v string) *ast.DurationLiteral {
+	matches := durationRegexp.FindAllStringSubmatchIndex(v, -1)
+	if len(matches) == 0 {
+		return nil
 	}
 
-	if queryFlags.raw {
-		io.Copy(os.Stdout, resp.Body)
+	lit := &ast.DurationLiteral{}
+	var end int
+	for _, m := range matches {
+		if m[0] != end {
+			return nil
+		}
+		mag, err := strconv.ParseInt(v[m[2]:m[3]], 10, 64)
+		if err != nil {
+			return nil
+		}
+		lit.Values = append(lit.Values, ast.Duration{Magnitude: mag, Unit: v[m[4]:m[5]]})
+		end = m[1]
+	}
+	if end != len(v) {
 		return nil
 	}
+	return lit
+}
+
+// queryOutputOptions controls how the results of a query are written.
+type queryOutputOptions struct {
+	format     string
+	timeFormat string
+	profilers  bool
+}
+
+func (o queryOutputOptions) validate() error {
+	switch o.format {
+	case queryFormatTable, queryFormatJSON:
+	case queryFormatCSV, queryFormatLineProtocol:
+		if o.timeFormat != "" {
+			return fmt.Errorf("time-format is not supported with format %s", o.format)
+		}
+	default:
+		return fmt.Errorf("unsupported format %q; use table, csv, json or lp", o.format)
+	}
+
+	switch o.timeFormat {
+	case "", "rfc3339", "rfc3339nano", "unix", "unixms", "unixus", "unixns":
+		return nil
+	default:
+		return fmt.Errorf("unsupported time-format %q", o.timeFormat)
+	}
+}
+
+// writeQueryResults writes the annotated CSV results of a query to w in the
+// format of opts. Profiler results are written to errW as tables.
+func writeQueryResults(w, errW io.Writer, r io.Reader, opts queryOutputOptions) error {
+	if opts.format == queryFormatCSV && !opts.profilers {
+		_, err := io.Copy(w, r)
+		return err
+	}
 
 	dec := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{})
-	results, err := dec.Decode(resp.Body)
+	results, err := dec.Decode(ioutil.NopCloser(r))
 	if err != nil {
 		return fmt.Errorf("query decode error: %s", err)
 	}
@@ -139,12 +318,30 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 
 	for results.More() {
 		res := results.Next()
-		fmt.Println("Result:", res.Name())
+		if res.Name() == profilerResultName {
+			fmt.Fprintln(errW, "Result:", res.Name())
+			if err := writeTableResult(errW, res, opts.timeFormat); err != nil {
+				return err
+			}
+			continue
+		}
 
-		if err := res.Tables().Do(func(tbl flux.Table) error {
-			_, err := newFormatter(tbl).WriteTo(os.Stdout)
-			return err
-		}); err != nil {
+		switch opts.format {
+		case queryFormatCSV:
+			// Results are delimited the way the query API delimits them.
+			if _, err := csv.NewResultEncoder(csv.DefaultEncoderConfig()).Encode(w, res); err != nil {
+				return err
+			}
+			_, err = io.WriteString(w, "\r\n")
+		case queryFormatJSON:
+			err = writeJSONResult(w, res, opts.timeFormat)
+		case queryFormatLineProtocol:
+			err = writeLineProtocolResult(w, res)
+		default:
+			fmt.Fprintln(w, "Result:", res.Name())
+			err = writeTableResult(w, res, opts.timeFormat)
+		}
+		if err != nil {
 			return err
 		}
 	}
@@ -154,6 +351,142 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 	return results.Err()
 }
 
+func writeTableResult(w io.Writer, res flux.Result, timeFormat string) error {
+	return res.Tables().Do(func(tbl flux.Table) error {
+		f := newFormatter(tbl)
+		f.timeFormat = timeFormat
+		_, err := f.WriteTo(w)
+		return err
+	})
+}
+
+// writeJSONResult writes each row of a result as a JSON object on its own line.
+func writeJSONResult(w io.Writer, res flux.Result, timeFormat string) error {
+	enc := json.NewEncoder(w)
+	table := 0
+	return res.Tables().Do(func(tbl flux.Table) error {
+		defer func() { table++ }()
+		return tbl.Do(func(cr flux.ColReader) error {
+			for i := 0; i < cr.Len(); i++ {
+				row := map[string]interface{}{
+					"result": res.Name(),
+					"table":  table,
+				}
+				for j, c := range cr.Cols() {
+					if c.Type == flux.TTime {
+						if cr.Times(j).IsValid(i) {
+							row[c.Label] = formatTimeValue(cr.Times(j).Value(i), timeFormat)
+						} else {
+							row[c.Label] = nil
+						}
+						continue
+					}
+					row[c.Label] = exportValue(cr, j, i)
+				}
+				if err := enc.Encode(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func writeLineProtocolResult(w io.Writer, res flux.Result) error {
+	return res.Tables().Do(func(tbl flux.Table) error {
+		return tbl.Do(func(cr flux.ColReader) error {
+			return readExportColumns(cr, func(row exportRow) error {
+				line, err := row.lineProtocol()
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(w, line)
+				return err
+			})
+		})
+	})
+}
+
+// formatTimeValue formats a time in nanoseconds as a string in the RFC3339
+// formats or as a number in the Unix formats.
+func formatTimeValue(ns int64, timeFormat string) interface{} {
+	switch timeFormat {
+	case "rfc3339":
+		return time.Unix(0, ns).UTC().Format(time.RFC3339)
+	case "unix":
+		return ns / int64(time.Second)
+	case "unixms":
+		return ns / int64(time.Millisecond)
+	case "unixus":
+		return ns / int64(time.Microsecond)
+	case "unixns":
+		return ns
+	default:
+		return values.Time(ns).String()
+	}
+}
+
+// httpFluxCSVQuerier runs queries through the query API.
+type httpFluxCSVQuerier struct {
+	addr               string
+	token              string
+	insecureSkipVerify bool
+}
+
+func (q *httpFluxCSVQuerier) QueryCSV(ctx context.Context, org organization, query string) (io.ReadCloser, error) {
+	return q.queryCSV(ctx, org, query, nil)
+}
+
+// queryCSV runs a query with an optional extern file and returns the response body.
+func (q *httpFluxCSVQuerier) queryCSV(ctx context.Context, org organization, query string, extern *ast.File) (io.ReadCloser, error) {
+	u, err := ihttp.NewURL(q.addr, "/api/v2/query")
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	if org.id != "" {
+		params.Set("orgID", org.id)
+	} else {
+		params.Set("org", org.name)
+	}
+	u.RawQuery = params.Encode()
+
+	reqBody := map[string]interface{}{
+		"query": query,
+		"type":  "flux",
+		"dialect": map[string]interface{}{
+			"annotations": []string{"group", "datatype", "default"},
+			"delimiter":   ",",
+			"header":      true,
+		},
+	}
+	if extern != nil {
+		reqBody["extern"] = extern
+	}
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	ihttp.SetToken(q.token, req)
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(ctx)
+
+	resp, err := ihttp.NewClient(u.Scheme, q.insecureSkipVerify).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := ihttp.CheckError(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
 // Below is a copy and trimmed version of the execute/format.go file from flux.
 // It is copied here to avoid requiring a dependency on the execute package which
 // may pull in the flux runtime as a dependency.
@@ -172,6 +505,8 @@ type formatter struct {
 	dash      []byte
 	// fmtBuf is used to format values
 	fmtBuf [64]byte
+	// timeFormat is the format of time values
+	timeFormat string
 
 	cols orderedCols
 }
@@ -367,7 +702,7 @@ func (f *formatter) valueBuf(i, j int, typ flux.ColType, cr flux.ColReader) []by
 		}
 	case flux.TTime:
 		if cr.Times(j).IsValid(i) {
-			buf = []byte(values.Time(cr.Times(j).Value(i)).String())
+			buf = []byte(fmt.Sprint(formatTimeValue(cr.Times(j).Value(i), f.timeFormat)))
 		}
 	}
 	return buf
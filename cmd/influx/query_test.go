@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdQuery_Params(t *testing.T) {
+	t.Run("literals", func(t *testing.T) {
+		tests := []struct {
+			value string
+			want  ast.Expression
+		}{
+			{value: "server01", want: &ast.StringLiteral{Value: "server01"}},
+			{value: `"5"`, want: &ast.StringLiteral{Value: "5"}},
+			{value: "5", want: &ast.IntegerLiteral{Value: 5}},
+			{value: "0.5", want: &ast.FloatLiteral{Value: 0.5}},
+			{value: "true", want: &ast.BooleanLiteral{Value: true}},
+			{value: "2020-10-12T00:00:00Z", want: &ast.DateTimeLiteral{Value: time.Date(2020, 10, 12, 0, 0, 0, 0, time.UTC)}},
+			{value: "1h30m", want: &ast.DurationLiteral{Values: []ast.Duration{{Magnitude: 1, Unit: "h"}, {Magnitude: 30, Unit: "m"}}}},
+			{value: "-5mo", want: &ast.UnaryExpression{
+				Operator: ast.SubtractionOperator,
+				Argument: &ast.DurationLiteral{Values: []ast.Duration{{Magnitude: 5, Unit: "mo"}}},
+			}},
+			{value: "5 m", want: &ast.StringLiteral{Value: "5 m"}},
+			{value: "1hour", want: &ast.StringLiteral{Value: "1hour"}},
+		}
+		for _, tt := range tests {
+			assert.Equal(t, tt.want, paramLiteral(tt.value), tt.value)
+		}
+	})
+
+	t.Run("extern", func(t *testing.T) {
+		extern, err := queryExtern([]string{"host=a=b", "start=-1h"}, []string{"query", "operator"})
+		require.NoError(t, err)
+
+		got, err := json.Marshal(extern)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"type": "File",
+			"package": null,
+			"imports": [{"type": "ImportDeclaration", "as": null, "path": {"type": "StringLiteral", "value": "profiler"}}],
+			"body": [
+				{"type": "OptionStatement", "assignment": {
+					"type": "VariableAssignment",
+					"id": {"type": "Identifier", "name": "v"},
+					"init": {"type": "ObjectExpression", "properties": [
+						{"type": "Property", "key": {"type": "Identifier", "name": "host"}, "value": {"type": "StringLiteral", "value": "a=b"}},
+						{"type": "Property", "key": {"type": "Identifier", "name": "start"}, "value": {
+							"type": "UnaryExpression", "operator": "-",
+							"argument": {"type": "DurationLiteral", "values": [{"magnitude": 1, "unit": "h"}]}
+						}}
+					]}
+				}},
+				{"type": "OptionStatement", "assignment": {
+					"type": "MemberAssignment",
+					"member": {"type": "MemberExpression", "object": {"type": "Identifier", "name": "profiler"}, "property": {"type": "Identifier", "name": "enabledProfilers"}},
+					"init": {"type": "ArrayExpression", "elements": [
+						{"type": "StringLiteral", "value": "query"},
+						{"type": "StringLiteral", "value": "operator"}
+					]}
+				}}
+			]
+		}`, string(got))
+
+		extern, err = queryExtern(nil, nil)
+		require.NoError(t, err)
+		assert.Nil(t, extern)
+
+		_, err = queryExtern([]string{"=a"}, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestCmdQuery_Formats(t *testing.T) {
+	const results = `#group,false,false,true,true,false,false,true,true,true
+#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,double,string,string,string
+#default,_result,,,,,,,,
+,result,table,_start,_stop,_time,_value,_field,_measurement,host
+,,0,2020-10-12T00:00:00Z,2020-10-12T01:00:00Z,2020-10-12T00:30:00Z,1.5,usage,cpu,a
+
+#group,false,false,true,false
+#datatype,string,long,string,long
+#default,_profiler,,,
+,result,table,_measurement,TotalDuration
+,,0,profiler/query,1000
+
+`
+
+	write := func(t *testing.T, opts queryOutputOptions) (string, string) {
+		t.Helper()
+		require.NoError(t, opts.validate())
+
+		var w, errW bytes.Buffer
+		require.NoError(t, writeQueryResults(&w, &errW, strings.NewReader(results), opts))
+		return w.String(), errW.String()
+	}
+
+	t.Run("json", func(t *testing.T) {
+		out, errOut := write(t, queryOutputOptions{format: queryFormatJSON, timeFormat: "unix", profilers: true})
+		assert.JSONEq(t, `{
+			"result": "_result", "table": 0,
+			"_start": 1602460800, "_stop": 1602464400, "_time": 1602462600,
+			"_value": 1.5, "_field": "usage", "_measurement": "cpu", "host": "a"
+		}`, out)
+		assert.Contains(t, errOut, "profiler/query")
+	})
+
+	t.Run("line protocol", func(t *testing.T) {
+		out, errOut := write(t, queryOutputOptions{format: queryFormatLineProtocol, profilers: true})
+		assert.Equal(t, "cpu,host=a usage=1.5 1602462600000000000\n", out)
+		assert.Contains(t, errOut, "TotalDuration")
+	})
+
+	t.Run("csv separates profilers", func(t *testing.T) {
+		out, errOut := write(t, queryOutputOptions{format: queryFormatCSV, profilers: true})
+		assert.Contains(t, out, ",,0,2020-10-12T00:00:00Z,2020-10-12T01:00:00Z")
+		assert.NotContains(t, out, "_profiler")
+		assert.Contains(t, errOut, "profiler/query")
+	})
+
+	t.Run("raw csv", func(t *testing.T) {
+		out, _ := write(t, queryOutputOptions{format: queryFormatCSV})
+		assert.Equal(t, results, out)
+	})
+
+	t.Run("table", func(t *testing.T) {
+		out, _ := write(t, queryOutputOptions{format: queryFormatTable, timeFormat: "rfc3339"})
+		assert.Contains(t, out, "Result: _result")
+		assert.Contains(t, out, "2020-10-12T00:30:00Z")
+		assert.NotContains(t, out, "2020-10-12T00:30:00.000000000Z")
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		assert.Error(t, queryOutputOptions{format: "xml"}.validate())
+		assert.Error(t, queryOutputOptions{format: queryFormatCSV, timeFormat: "unix"}.validate())
+		assert.Error(t, queryOutputOptions{format: queryFormatTable, timeFormat: "iso"}.validate())
+	})
+}
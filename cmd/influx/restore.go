@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/dbrp"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/spf13/cobra"
+)
+
+type restoreSVCsFn func() (cmdRestoreDeps, error)
+
+type cmdRestoreDeps struct {
+	restoreSVC influxdb.RestoreService
+	bucketSVC  influxdb.BucketService
+	orgSVC     influxdb.OrganizationService
+	dbrpSVC    influxdb.DBRPMappingServiceV2
+}
+
+func cmdRestore(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	builder := newCmdRestoreBuilder(newRestoreSVCs, f, opt)
+	return builder.cmd()
+}
+
+type cmdRestoreBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn restoreSVCsFn
+
+	json        bool
+	hideHeaders bool
+	paths       []string
+	bucket      string
+	bucketID    string
+	newBucket   string
+	org         organization
+}
+
+func newCmdRestoreBuilder(svcsFn restoreSVCsFn, f *globalFlags, opt genericCLIOpts) *cmdRestoreBuilder {
+	return &cmdRestoreBuilder{
+		genericCLIOpts: opt,
+		globalFlags:    f,
+		svcFn:          svcsFn,
+	}
+}
+
+func (b *cmdRestoreBuilder) cmd() *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd("restore", b.restoreRunEFn, true)
+	cmd.Short = "Restore buckets from a backup into the running InfluxDB instance"
+	cmd.Long = fmt.Sprintf(`Restores buckets from a backup fileset created by "influx backup" into the
+running InfluxDB instance. Each bucket is created with the meta data and DBRP
+mappings recorded in %s, and its shards are then uploaded to the server.
+
+The bucket must not already exist in the target organization; use --new-bucket
+to restore a single bucket under another name. To replace all data and meta data
+of an instance, stop the server and use "influxd restore" instead.
+
+An incremental backup is restored by passing --path once for the full backup it is
+based on and once for each incremental backup, in the order they were taken. The
+checksums of all files are verified before anything is restored.
+
+Incremental backups only contain the data files that changed, not the deletes made
+since the backup before them. Data deleted between the backups of a chain is
+therefore restored from the earlier backups; delete it again after the restore.`, influxdb.BackupManifestFilename)
+	cmd.Example = `
+# restore a deleted bucket into its original organization
+influx restore --path /backups/my-bucket --bucket my-bucket
+
+# restore a bucket next to the original one
+influx restore --path /backups/my-bucket --bucket my-bucket --new-bucket my-bucket-restored
+
+# restore a full backup and the incremental backups taken after it
+influx restore --path /backups/2020-10-12 --path /backups/2020-10-13 --path /backups/2020-10-14`
+
+	b.globalFlags.registerFlags(cmd)
+	cmd.Flags().StringArrayVarP(&b.paths, "path", "p", nil, "Directory path of the backup files; repeat for each incremental backup after the full one (required)")
+	cmd.Flags().StringVarP(&b.bucket, "bucket", "", "", "The name of the bucket to restore from the backup; all buckets are restored if not set")
+	cmd.Flags().StringVarP(&b.bucketID, "bucket-id", "", "", "The ID of the bucket to restore from the backup")
+	cmd.Flags().StringVarP(&b.newBucket, "new-bucket", "", "", "The name to restore the bucket under; defaults to the name in the backup")
+	cmd.MarkFlagRequired("path")
+	b.org.register(cmd, false)
+	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
+
+	return cmd
+}
+
+func (b *cmdRestoreBuilder) restoreRunEFn(cmd *cobra.Command, args []string) error {
+	if b.bucket != "" && b.bucketID != "" {
+		return errors.New("must specify bucket-id, or bucket name not both")
+	}
+
+	chain, err := readBackupChain(b.paths)
+	if err != nil {
+		return err
+	}
+	if len(chain) > 1 {
+		fmt.Fprintln(b.errW, "WARN: deletes made between the backups are not restored; data deleted after the full backup may reappear")
+	}
+
+	buckets, err := b.selectBuckets(chain)
+	if err != nil {
+		return err
+	}
+
+	deps, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	// Buckets are restored into their original organization unless one is given.
+	var orgID influxdb.ID
+	if b.org.id != "" || b.org.name != "" {
+		if orgID, err = b.org.getID(deps.orgSVC); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	restored := make([]restoredBucket, 0, len(buckets))
+	for _, bb := range buckets {
+		rb, err := b.restoreBucket(ctx, deps, chain, bb, orgID)
+		if err != nil {
+			return err
+		}
+		restored = append(restored, rb)
+	}
+
+	return b.printRestored(restored)
+}
+
+func readBackupManifest(path string) (*influxdb.BackupManifest, error) {
+	f, err := os.Open(filepath.Join(path, influxdb.BackupManifestFilename))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s not found in %s; restore backups without a manifest with influxd restore", influxdb.BackupManifestFilename, path)
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var manifest influxdb.BackupManifest
+	if err := json.NewDecoder(f).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", influxdb.BackupManifestFilename, err)
+	}
+	return &manifest, nil
+}
+
+// backupDir is a backup fileset and its manifest.
+type backupDir struct {
+	path     string
+	manifest *influxdb.BackupManifest
+}
+
+// readBackupChain reads a full backup followed by the incremental backups
+// based on it, and verifies the files of each.
+func readBackupChain(paths []string) ([]backupDir, error) {
+	chain := make([]backupDir, 0, len(paths))
+	for i, path := range paths {
+		manifest, err := readBackupManifest(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			prev := chain[i-1].manifest
+			if !manifest.Filter.Incremental() {
+				return nil, fmt.Errorf("backup in %s is not incremental; only the first path may be a full backup", path)
+			}
+			if manifest.Filter.Since.After(prev.CreatedAt) {
+				return nil, fmt.Errorf("backup in %s does not include the changes since the backup in %s", path, chain[i-1].path)
+			}
+		}
+
+		if err := verifyBackupFiles(path, manifest); err != nil {
+			return nil, err
+		}
+		chain = append(chain, backupDir{path: path, manifest: manifest})
+	}
+	return chain, nil
+}
+
+// selectBuckets returns the buckets of the backups matching the bucket flags.
+// A bucket is described by the first backup it appears in.
+func (b *cmdRestoreBuilder) selectBuckets(chain []backupDir) ([]influxdb.BucketBackup, error) {
+	var buckets []influxdb.BucketBackup
+	seen := make(map[influxdb.ID]bool)
+	for _, dir := range chain {
+		for _, bb := range dir.manifest.Buckets {
+			if bb.Bucket.Type == influxdb.BucketTypeSystem || seen[bb.Bucket.ID] {
+				continue
+			}
+			if b.bucket != "" && bb.Bucket.Name != b.bucket {
+				continue
+			}
+			if b.bucketID != "" && bb.Bucket.ID.String() != b.bucketID {
+				continue
+			}
+			seen[bb.Bucket.ID] = true
+			buckets = append(buckets, bb)
+		}
+	}
+
+	if len(buckets) == 0 {
+		return nil, errors.New("no matching buckets found in the backup")
+	}
+	if b.newBucket != "" && len(buckets) > 1 {
+		return nil, errors.New("new-bucket requires a single bucket to be restored; specify bucket or bucket-id")
+	}
+	return buckets, nil
+}
+
+type restoredBucket struct {
+	bucket     *influxdb.Bucket
+	originalID influxdb.ID
+	shards     int
+}
+
+func (b *cmdRestoreBuilder) restoreBucket(ctx context.Context, deps cmdRestoreDeps, chain []backupDir, bb influxdb.BucketBackup, orgID influxdb.ID) (restoredBucket, error) {
+	bkt := &influxdb.Bucket{
+		OrgID:           bb.Bucket.OrgID,
+		Name:            bb.Bucket.Name,
+		Description:     bb.Bucket.Description,
+		RetentionPeriod: bb.Bucket.RetentionPeriod,
+	}
+	if orgID.Valid() {
+		bkt.OrgID = orgID
+	}
+	if b.newBucket != "" {
+		bkt.Name = b.newBucket
+	}
+
+	if err := deps.bucketSVC.CreateBucket(ctx, bkt); err != nil {
+		return restoredBucket{}, fmt.Errorf("failed to create bucket %q: %v", bkt.Name, err)
+	}
+
+	shards, err := restoreShards(ctx, deps, chain, bb.Bucket.ID, bkt)
+	if err != nil {
+		// A bucket with only part of the data of the backup must not be left behind.
+		if derr := deps.bucketSVC.DeleteBucket(ctx, bkt.ID); derr != nil {
+			fmt.Fprintf(b.errW, "WARN: failed to delete partially restored bucket %q: %v\n", bkt.Name, derr)
+		}
+		return restoredBucket{}, err
+	}
+
+	// A mapping can conflict with one of the original bucket, which must not stop the restore.
+	for _, m := range bb.DBRPs {
+		mapping := &influxdb.DBRPMappingV2{
+			Database:        m.Database,
+			RetentionPolicy: m.RetentionPolicy,
+			Default:         m.Default,
+			OrganizationID:  bkt.OrgID,
+			BucketID:        bkt.ID,
+		}
+		if err := deps.dbrpSVC.Create(ctx, mapping); err != nil {
+			fmt.Fprintf(b.errW, "WARN: failed to restore DBRP mapping %s/%s of bucket %q: %v\n", m.Database, m.RetentionPolicy, bkt.Name, err)
+		}
+	}
+
+	return restoredBucket{
+		bucket:     bkt,
+		originalID: bb.Bucket.ID,
+		shards:     shards,
+	}, nil
+}
+
+// restoreShards restores the shards of the backed up bucket with the original ID
+// into bkt and returns the number of restored shards. The shards of each backup
+// are replayed in order, so later changes win.
+func restoreShards(ctx context.Context, deps cmdRestoreDeps, chain []backupDir, originalID influxdb.ID, bkt *influxdb.Bucket) (int, error) {
+	var shards int
+	for _, dir := range chain {
+		for _, backup := range dir.manifest.Buckets {
+			if backup.Bucket.ID != originalID {
+				continue
+			}
+			for _, s := range backup.Shards {
+				if err := restoreShard(ctx, deps.restoreSVC, dir.path, bkt.ID, s); err != nil {
+					return shards, fmt.Errorf("failed to restore shard %d of bucket %q from %s: %v", s.ID, bkt.Name, dir.path, err)
+				}
+				shards++
+			}
+		}
+	}
+	return shards, nil
+}
+
+func restoreShard(ctx context.Context, restoreSVC influxdb.RestoreService, path string, bucketID influxdb.ID, s influxdb.ShardBackup) error {
+	f, err := os.Open(filepath.Join(path, s.FileName))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return restoreSVC.RestoreShard(ctx, bucketID, s.StartTime, f)
+}
+
+func (b *cmdRestoreBuilder) printRestored(restored []restoredBucket) error {
+	if b.json {
+		buckets := make([]*influxdb.Bucket, 0, len(restored))
+		for _, r := range restored {
+			buckets = append(buckets, r.bucket)
+		}
+		return b.writeJSON(buckets)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+	w.WriteHeaders("ID", "Name", "Organization ID", "Original ID", "Shards")
+	for _, r := range restored {
+		w.Write(map[string]interface{}{
+			"ID":              r.bucket.ID.String(),
+			"Name":            r.bucket.Name,
+			"Organization ID": r.bucket.OrgID.String(),
+			"Original ID":     r.originalID.String(),
+			"Shards":          r.shards,
+		})
+	}
+
+	return nil
+}
+
+func newRestoreSVCs() (cmdRestoreDeps, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return cmdRestoreDeps{}, err
+	}
+
+	ac := flags.config()
+	return cmdRestoreDeps{
+		restoreSVC: &http.RestoreService{
+			Addr:               ac.Host,
+			Token:              ac.Token,
+			InsecureSkipVerify: flags.skipVerify,
+		},
+		bucketSVC: &http.BucketService{Client: httpClient},
+		orgSVC:    &http.OrganizationService{Client: httpClient},
+		dbrpSVC:   dbrp.NewClient(httpClient),
+	}, nil
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdRestore(t *testing.T) {
+	var (
+		orgID      = influxdb.ID(9000)
+		start      = time.Date(2020, 10, 12, 0, 0, 0, 0, time.UTC)
+		cpuBucket  = influxdb.ID(1)
+		diskBucket = influxdb.ID(2)
+	)
+
+	writeBackup := func(t *testing.T, manifest influxdb.BackupManifest, files map[string]string) (string, func()) {
+		t.Helper()
+
+		dir, err := ioutil.TempDir("", "influx-restore")
+		require.NoError(t, err)
+
+		for name, data := range files {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0600))
+			sum := sha256.Sum256([]byte(data))
+			manifest.Files = append(manifest.Files, influxdb.BackupFile{
+				Name:     name,
+				Size:     int64(len(data)),
+				Checksum: hex.EncodeToString(sum[:]),
+			})
+		}
+		body, err := json.Marshal(manifest)
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, influxdb.BackupManifestFilename), body, 0600))
+
+		return dir, func() { os.RemoveAll(dir) }
+	}
+
+	newBackup := func(t *testing.T) (string, func()) {
+		t.Helper()
+
+		manifest := influxdb.BackupManifest{
+			CreatedAt: start.Add(2 * time.Hour),
+			Buckets: []influxdb.BucketBackup{
+				{
+					Bucket: influxdb.Bucket{ID: cpuBucket, OrgID: orgID, Name: "cpu", RetentionPeriod: time.Hour},
+					DBRPs: []influxdb.DBRPMappingV2{
+						{ID: 20, Database: "telegraf", RetentionPolicy: "autogen", Default: true, OrganizationID: orgID, BucketID: cpuBucket},
+					},
+					Shards: []influxdb.ShardBackup{
+						{ID: 10, StartTime: start, EndTime: start.Add(time.Hour), FileName: influxdb.ShardBackupFilename(cpuBucket, 10)},
+					},
+				},
+				{
+					Bucket: influxdb.Bucket{ID: diskBucket, OrgID: orgID, Name: "disk"},
+				},
+				{
+					Bucket: influxdb.Bucket{ID: 3, OrgID: orgID, Name: "_monitoring", Type: influxdb.BucketTypeSystem},
+				},
+			},
+		}
+		return writeBackup(t, manifest, map[string]string{
+			influxdb.ShardBackupFilename(cpuBucket, 10): "shard data",
+		})
+	}
+
+	type restoreCalls struct {
+		buckets []*influxdb.Bucket
+		deleted []influxdb.ID
+		shards  map[influxdb.ID][]string
+		dbrps   []*influxdb.DBRPMappingV2
+
+		// shardErr is returned when restoring a shard.
+		shardErr error
+	}
+
+	cmdFn := func(calls *restoreCalls) func(*globalFlags, genericCLIOpts) *cobra.Command {
+		return func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+			svcsFn := func() (cmdRestoreDeps, error) {
+				bucketSVC := mock.NewBucketService()
+				bucketSVC.CreateBucketFn = func(ctx context.Context, b *influxdb.Bucket) error {
+					b.ID = influxdb.ID(100 + len(calls.buckets))
+					calls.buckets = append(calls.buckets, b)
+					return nil
+				}
+				bucketSVC.DeleteBucketFn = func(ctx context.Context, id influxdb.ID) error {
+					calls.deleted = append(calls.deleted, id)
+					return nil
+				}
+				return cmdRestoreDeps{
+					restoreSVC: &fakeRestoreService{
+						RestoreShardFn: func(ctx context.Context, bucketID influxdb.ID, s time.Time, r io.Reader) error {
+							if calls.shardErr != nil {
+								return calls.shardErr
+							}
+							assert.Equal(t, start, s)
+							data, err := ioutil.ReadAll(r)
+							require.NoError(t, err)
+							calls.shards[bucketID] = append(calls.shards[bucketID], string(data))
+							return nil
+						},
+					},
+					bucketSVC: bucketSVC,
+					orgSVC: &mock.OrganizationService{
+						FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+							return &influxdb.Organization{ID: influxdb.ID(9001), Name: "other"}, nil
+						},
+					},
+					dbrpSVC: &mock.DBRPMappingServiceV2{
+						CreateFn: func(ctx context.Context, m *influxdb.DBRPMappingV2) error {
+							calls.dbrps = append(calls.dbrps, m)
+							return nil
+						},
+					},
+				}, nil
+			}
+			return newCmdRestoreBuilder(svcsFn, g, opt).cmd()
+		}
+	}
+
+	t.Run("single bucket under a new name in another org", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+		dir, cleanup := newBackup(t)
+		defer cleanup()
+
+		calls := &restoreCalls{shards: make(map[influxdb.ID][]string)}
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(calls))
+		cmd.SetArgs([]string{"restore", "--path", dir, "--bucket", "cpu", "--new-bucket", "cpu-restored", "--org", "other"})
+
+		require.NoError(t, cmd.Execute())
+		require.Len(t, calls.buckets, 1)
+		assert.Equal(t, "cpu-restored", calls.buckets[0].Name)
+		assert.Equal(t, influxdb.ID(9001), calls.buckets[0].OrgID)
+		assert.Len(t, calls.shards[100], 1)
+		require.Len(t, calls.dbrps, 1)
+		assert.Equal(t, influxdb.ID(9001), calls.dbrps[0].OrganizationID)
+	})
+
+	t.Run("all buckets", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+		dir, cleanup := newBackup(t)
+		defer cleanup()
+
+		calls := &restoreCalls{shards: make(map[influxdb.ID][]string)}
+		buf := new(bytes.Buffer)
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(buf),
+		)
+		cmd := builder.cmd(cmdFn(calls))
+		cmd.SetArgs([]string{"restore", "--path", dir, "--hide-headers"})
+
+		require.NoError(t, cmd.Execute())
+		require.Len(t, calls.buckets, 2)
+		assert.Equal(t, "cpu", calls.buckets[0].Name)
+		assert.Equal(t, orgID, calls.buckets[0].OrgID)
+		assert.Equal(t, time.Hour, calls.buckets[0].RetentionPeriod)
+		assert.Equal(t, "disk", calls.buckets[1].Name)
+		assert.Equal(t, map[influxdb.ID][]string{100: {"shard data"}}, calls.shards)
+		require.Len(t, calls.dbrps, 1)
+		assert.Equal(t, influxdb.ID(100), calls.dbrps[0].BucketID)
+		assert.Equal(t, "telegraf", calls.dbrps[0].Database)
+		assert.True(t, calls.dbrps[0].Default)
+		assert.Contains(t, buf.String(), cpuBucket.String())
+	})
+
+	t.Run("failed shard deletes the created bucket", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+		dir, cleanup := newBackup(t)
+		defer cleanup()
+
+		calls := &restoreCalls{
+			shards:   make(map[influxdb.ID][]string),
+			shardErr: errors.New("disk full"),
+		}
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(calls))
+		cmd.SetArgs([]string{"restore", "--path", dir, "--bucket", "cpu"})
+
+		require.Error(t, cmd.Execute())
+		require.Len(t, calls.buckets, 1)
+		assert.Equal(t, []influxdb.ID{calls.buckets[0].ID}, calls.deleted)
+		assert.Empty(t, calls.dbrps)
+	})
+
+	t.Run("new bucket requires a single bucket", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+		dir, cleanup := newBackup(t)
+		defer cleanup()
+
+		b := newCmdRestoreBuilder(nil, &globalFlags{}, genericCLIOpts{})
+		b.newBucket = "renamed"
+
+		chain, err := readBackupChain([]string{dir})
+		require.NoError(t, err)
+		_, err = b.selectBuckets(chain)
+		require.Error(t, err)
+
+		b.bucket = "cpu"
+		buckets, err := b.selectBuckets(chain)
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+		assert.Equal(t, cpuBucket, buckets[0].Bucket.ID)
+	})
+
+	t.Run("full backup and incremental backups", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+		full, cleanup := newBackup(t)
+		defer cleanup()
+
+		incremental := influxdb.BackupManifest{
+			CreatedAt: start.Add(3 * time.Hour),
+			Filter:    influxdb.BackupFilter{Since: start.Add(2 * time.Hour)},
+			Buckets: []influxdb.BucketBackup{
+				{
+					Bucket: influxdb.Bucket{ID: cpuBucket, OrgID: orgID, Name: "cpu"},
+					Shards: []influxdb.ShardBackup{
+						{ID: 10, StartTime: start, EndTime: start.Add(time.Hour), FileName: influxdb.ShardBackupFilename(cpuBucket, 10)},
+					},
+				},
+			},
+		}
+		inc, cleanup := writeBackup(t, incremental, map[string]string{
+			influxdb.ShardBackupFilename(cpuBucket, 10): "more shard data",
+		})
+		defer cleanup()
+
+		calls := &restoreCalls{shards: make(map[influxdb.ID][]string)}
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(calls))
+		cmd.SetArgs([]string{"restore", "--path", full, "--path", inc, "--bucket", "cpu"})
+
+		require.NoError(t, cmd.Execute())
+		require.Len(t, calls.buckets, 1)
+		assert.Equal(t, time.Hour, calls.buckets[0].RetentionPeriod)
+		assert.Equal(t, map[influxdb.ID][]string{100: {"shard data", "more shard data"}}, calls.shards)
+		assert.Len(t, calls.dbrps, 1)
+	})
+
+	t.Run("broken chain", func(t *testing.T) {
+		full, cleanup := newBackup(t)
+		defer cleanup()
+
+		_, err := readBackupChain([]string{full, full})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not incremental")
+
+		late, cleanup := writeBackup(t, influxdb.BackupManifest{
+			CreatedAt: start.Add(5 * time.Hour),
+			Filter:    influxdb.BackupFilter{Since: start.Add(4 * time.Hour)},
+		}, nil)
+		defer cleanup()
+
+		_, err = readBackupChain([]string{full, late})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not include the changes")
+	})
+
+	t.Run("corrupted file", func(t *testing.T) {
+		dir, cleanup := newBackup(t)
+		defer cleanup()
+
+		name := filepath.Join(dir, influxdb.ShardBackupFilename(cpuBucket, 10))
+		require.NoError(t, ioutil.WriteFile(name, []byte("shard dat4"), 0600))
+
+		_, err := readBackupChain([]string{dir})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match")
+	})
+
+	t.Run("missing manifest", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "influx-restore")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		_, err = readBackupManifest(dir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "influxd restore")
+	})
+}
+
+type fakeRestoreService struct {
+	RestoreShardFn func(ctx context.Context, bucketID influxdb.ID, start time.Time, r io.Reader) error
+}
+
+func (s *fakeRestoreService) RestoreShard(ctx context.Context, bucketID influxdb.ID, start time.Time, r io.Reader) error {
+	return s.RestoreShardFn(ctx, bucketID, start, r)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/spf13/cobra"
+)
+
+type runningQuerySVCsFn func() (influxdb.RunningQueryService, influxdb.OrganizationService, error)
+
+type cmdRunningQueryBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn runningQuerySVCsFn
+
+	json        bool
+	hideHeaders bool
+	id          uint64
+}
+
+func newCmdRunningQueryBuilder(svcsFn runningQuerySVCsFn, f *globalFlags, opt genericCLIOpts) *cmdRunningQueryBuilder {
+	return &cmdRunningQueryBuilder{
+		genericCLIOpts: opt,
+		globalFlags:    f,
+		svcFn:          svcsFn,
+	}
+}
+
+func (b *cmdRunningQueryBuilder) cmdPs() *cobra.Command {
+	cmd := b.newCmd("ps", b.cmdPsRunEFn)
+	cmd.Short = "List the running queries of an organization"
+	cmd.Aliases = []string{"list", "ls"}
+
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdRunningQueryBuilder) cmdPsRunEFn(cmd *cobra.Command, args []string) error {
+	querySVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := queryFlags.org.getID(orgSVC)
+	if err != nil {
+		return err
+	}
+
+	queries, err := querySVC.FindRunningQueries(context.Background(), influxdb.RunningQueryFilter{
+		OrgID: &orgID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to retrieve running queries: %v", err)
+	}
+
+	return b.printRunningQueries(runningQueryPrintOpt{queries: queries})
+}
+
+func (b *cmdRunningQueryBuilder) cmdKill() *cobra.Command {
+	cmd := b.newCmd("kill", b.cmdKillRunEFn)
+	cmd.Short = "Kill a running query"
+
+	cmd.Flags().Uint64VarP(&b.id, "id", "i", 0, "The ID of the query (required)")
+	cmd.MarkFlagRequired("id")
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdRunningQueryBuilder) cmdKillRunEFn(cmd *cobra.Command, args []string) error {
+	querySVC, _, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	queries, err := querySVC.FindRunningQueries(ctx, influxdb.RunningQueryFilter{ID: &b.id})
+	if err != nil {
+		return fmt.Errorf("failed to find query with id %d: %v", b.id, err)
+	} else if len(queries) == 0 {
+		return fmt.Errorf("failed to find query with id %d: %v", b.id, influxdb.ErrRunningQueryNotFound)
+	}
+
+	if err := querySVC.KillQuery(ctx, b.id); err != nil {
+		return fmt.Errorf("failed to kill query with id %d: %v", b.id, err)
+	}
+
+	return b.printRunningQueries(runningQueryPrintOpt{
+		killed: true,
+		query:  queries[0],
+	})
+}
+
+func (b *cmdRunningQueryBuilder) newCmd(use string, runE func(*cobra.Command, []string) error) *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd(use, runE, true)
+	b.globalFlags.registerFlags(cmd)
+	return cmd
+}
+
+func (b *cmdRunningQueryBuilder) registerPrintFlags(cmd *cobra.Command) {
+	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
+}
+
+type runningQueryPrintOpt struct {
+	killed  bool
+	query   *influxdb.RunningQuery
+	queries []*influxdb.RunningQuery
+}
+
+func (b *cmdRunningQueryBuilder) printRunningQueries(opt runningQueryPrintOpt) error {
+	if b.json {
+		var v interface{} = opt.queries
+		if opt.queries == nil {
+			v = opt.query
+		}
+		return b.writeJSON(v)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+
+	headers := []string{"ID", "Organization ID", "User ID", "Language", "State", "Duration", "Memory", "Query"}
+	if opt.killed {
+		headers = append(headers, "Killed")
+	}
+	w.WriteHeaders(headers...)
+
+	if opt.queries == nil {
+		opt.queries = append(opt.queries, opt.query)
+	}
+
+	for _, q := range opt.queries {
+		m := map[string]interface{}{
+			"ID":              q.ID,
+			"Organization ID": q.OrgID.String(),
+			"User ID":         q.UserID.String(),
+			"Language":        q.Language,
+			"State":           q.State,
+			"Duration":        q.Duration.Round(time.Millisecond).String(),
+			"Memory":          q.Memory,
+			"Query":           q.Query,
+		}
+		if opt.killed {
+			m["Killed"] = true
+		}
+		w.Write(m)
+	}
+
+	return nil
+}
+
+func newRunningQuerySVCs() (influxdb.RunningQueryService, influxdb.OrganizationService, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &http.RunningQueryService{Client: httpClient}, &http.OrganizationService{Client: httpClient}, nil
+}
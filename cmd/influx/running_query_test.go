@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmdQuery_RunningQueries(t *testing.T) {
+	orgID := influxdb.ID(9000)
+
+	fakeSVCFn := func(svc influxdb.RunningQueryService) runningQuerySVCsFn {
+		return func() (influxdb.RunningQueryService, influxdb.OrganizationService, error) {
+			return svc, &mock.OrganizationService{
+				FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+					return &influxdb.Organization{ID: orgID, Name: "influxdata"}, nil
+				},
+			}, nil
+		}
+	}
+
+	t.Run("ps", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			command string
+			flags   []string
+			envVars map[string]string
+		}{
+			{
+				name:    "org id",
+				flags:   []string{"--org-id=" + orgID.String()},
+				envVars: envVarsZeroMap,
+			},
+			{
+				name:    "org",
+				flags:   []string{"--org=influxdata"},
+				envVars: envVarsZeroMap,
+			},
+			{
+				name: "env vars",
+				envVars: map[string]string{
+					"INFLUX_ORG": "influxdata",
+				},
+			},
+			{
+				name:    "ls alias",
+				command: "ls",
+				flags:   []string{"--org=influxdata"},
+				envVars: envVarsZeroMap,
+			},
+		}
+
+		cmdFn := func() (func(*globalFlags, genericCLIOpts) *cobra.Command, *influxdb.RunningQueryFilter) {
+			var got influxdb.RunningQueryFilter
+			svc := mock.NewRunningQueryService()
+			svc.FindRunningQueriesF = func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+				got = filter
+				return []*influxdb.RunningQuery{{ID: 1, OrgID: orgID, Language: influxdb.FluxQueryLanguage}}, nil
+			}
+
+			return func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+				return newCmdQuery(fakeSVCFn(svc), g, opt)
+			}, &got
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				defer addEnvVars(t, tt.envVars)()
+
+				builder := newInfluxCmdBuilder(
+					in(new(bytes.Buffer)),
+					out(ioutil.Discard),
+				)
+				nestedCmdFn, got := cmdFn()
+				cmd := builder.cmd(nestedCmdFn)
+
+				if tt.command == "" {
+					tt.command = "ps"
+				}
+
+				cmd.SetArgs(append([]string{"query", tt.command}, tt.flags...))
+
+				require.NoError(t, cmd.Execute())
+				require.NotNil(t, got.OrgID)
+				assert.Equal(t, orgID, *got.OrgID)
+			}
+
+			t.Run(tt.name, fn)
+		}
+	})
+
+	t.Run("kill", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			expectedID uint64
+			flags      []string
+			wantErr    bool
+		}{
+			{
+				name:       "with id",
+				expectedID: 3,
+				flags:      []string{"--id=3"},
+			},
+			{
+				name:       "shorts",
+				expectedID: 3,
+				flags:      []string{"-i=3"},
+			},
+			{
+				name:    "not running",
+				flags:   []string{"--id=4"},
+				wantErr: true,
+			},
+		}
+
+		cmdFn := func(expectedID uint64) func(*globalFlags, genericCLIOpts) *cobra.Command {
+			svc := mock.NewRunningQueryService()
+			svc.FindRunningQueriesF = func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+				if *filter.ID != 3 {
+					return nil, nil
+				}
+				return []*influxdb.RunningQuery{{ID: 3, OrgID: orgID}}, nil
+			}
+			svc.KillQueryF = func(ctx context.Context, id uint64) error {
+				if expectedID != id {
+					return fmt.Errorf("unexpected id:\n\twant= %d\n\tgot=  %d", expectedID, id)
+				}
+				return nil
+			}
+
+			return func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+				return newCmdQuery(fakeSVCFn(svc), g, opt)
+			}
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				builder := newInfluxCmdBuilder(
+					in(new(bytes.Buffer)),
+					out(ioutil.Discard),
+				)
+				cmd := builder.cmd(cmdFn(tt.expectedID))
+				cmd.SetArgs(append([]string{"query", "kill"}, tt.flags...))
+
+				err := cmd.Execute()
+				if tt.wantErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+			}
+
+			t.Run(tt.name, fn)
+		}
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/cmd/influx/internal"
 	"github.com/influxdata/influxdb/v2/http"
+	"github.com/influxdata/influxdb/v2/task/backend"
 	"github.com/spf13/cobra"
 )
 
@@ -29,6 +30,7 @@ func cmdTask(f *globalFlags, opt genericCLIOpts) *cobra.Command {
 		taskDeleteCmd(f, opt),
 		taskFindCmd(f, opt),
 		taskUpdateCmd(f, opt),
+		taskBackfillCmd(f, opt),
 	)
 
 	return cmd
@@ -40,8 +42,9 @@ var taskPrintFlags struct {
 }
 
 var taskCreateFlags struct {
-	org  organization
-	file string
+	org       organization
+	file      string
+	dependsOn []string
 }
 
 func taskCreateCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
@@ -52,6 +55,7 @@ func taskCreateCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
 
 	f.registerFlags(cmd)
 	cmd.Flags().StringVarP(&taskCreateFlags.file, "file", "f", "", "Path to Flux script file")
+	cmd.Flags().StringSliceVar(&taskCreateFlags.dependsOn, "depends-on", nil, "IDs of the upstream tasks whose runs must succeed first")
 	taskCreateFlags.org.register(cmd, false)
 	registerPrintOptions(cmd, &taskPrintFlags.hideHeaders, &taskPrintFlags.json)
 
@@ -77,9 +81,15 @@ func taskCreateF(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("error parsing flux script: %s", err)
 	}
 
+	dependsOn, err := parseTaskIDs(taskCreateFlags.dependsOn)
+	if err != nil {
+		return err
+	}
+
 	tc := influxdb.TaskCreate{
 		Flux:         flux,
 		Organization: taskCreateFlags.org.name,
+		DependsOn:    dependsOn,
 	}
 	if taskCreateFlags.org.id != "" || taskCreateFlags.org.name != "" {
 		svc, err := newOrganizationService()
@@ -203,9 +213,10 @@ func taskFindF(cmd *cobra.Command, args []string) error {
 }
 
 var taskUpdateFlags struct {
-	id     string
-	status string
-	file   string
+	id        string
+	status    string
+	file      string
+	dependsOn []string
 }
 
 func taskUpdateCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
@@ -218,6 +229,7 @@ func taskUpdateCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
 	cmd.Flags().StringVarP(&taskUpdateFlags.id, "id", "i", "", "task ID (required)")
 	cmd.Flags().StringVarP(&taskUpdateFlags.status, "status", "", "", "update task status")
 	cmd.Flags().StringVarP(&taskUpdateFlags.file, "file", "f", "", "Path to Flux script file")
+	cmd.Flags().StringSliceVar(&taskUpdateFlags.dependsOn, "depends-on", nil, "replace the IDs of the upstream tasks, empty to remove them")
 	cmd.MarkFlagRequired("id")
 
 	return cmd
@@ -242,6 +254,13 @@ func taskUpdateF(cmd *cobra.Command, args []string) error {
 	if taskUpdateFlags.status != "" {
 		update.Status = &taskUpdateFlags.status
 	}
+	if cmd.Flags().Changed("depends-on") {
+		dependsOn, err := parseTaskIDs(taskUpdateFlags.dependsOn)
+		if err != nil {
+			return err
+		}
+		update.DependsOn = &dependsOn
+	}
 
 	// update flux script only if first arg or file is supplied
 	if (len(args) > 0 && len(args[0]) > 0) || len(taskUpdateFlags.file) > 0 {
@@ -267,6 +286,21 @@ func taskUpdateF(cmd *cobra.Command, args []string) error {
 	)
 }
 
+func parseTaskIDs(ids []string) ([]influxdb.ID, error) {
+	out := make([]influxdb.ID, 0, len(ids))
+	for _, s := range ids {
+		if s == "" {
+			continue
+		}
+		id, err := influxdb.IDFromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid task ID %q: %v", s, err)
+		}
+		out = append(out, *id)
+	}
+	return out, nil
+}
+
 var taskDeleteFlags struct {
 	id string
 }
@@ -319,6 +353,70 @@ func taskDeleteF(cmd *cobra.Command, args []string) error {
 	)
 }
 
+var taskBackfillFlags struct {
+	id          string
+	start       string
+	stop        string
+	concurrency int
+}
+
+func taskBackfillCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("backfill", taskBackfillF, true)
+	cmd.Short = "Backfill task"
+	cmd.Long = `Run a task for every time it was scheduled for between start and stop, inclusive.
+
+Failed runs are retried as the retry option of the task allows.`
+
+	f.registerFlags(cmd)
+	cmd.Flags().StringVarP(&taskBackfillFlags.id, "id", "i", "", "task id (required)")
+	cmd.Flags().StringVarP(&taskBackfillFlags.start, "start", "", "", "start time of the backfill in RFC3339 format (required)")
+	cmd.Flags().StringVarP(&taskBackfillFlags.stop, "stop", "", "", "stop time of the backfill in RFC3339 format (required)")
+	cmd.Flags().IntVarP(&taskBackfillFlags.concurrency, "concurrency", "", 1, "the number of runs to queue at once")
+	cmd.MarkFlagRequired("id")
+	cmd.MarkFlagRequired("start")
+	cmd.MarkFlagRequired("stop")
+
+	return cmd
+}
+
+func taskBackfillF(cmd *cobra.Command, args []string) error {
+	client, err := newHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	s := &http.TaskService{
+		Client: client,
+	}
+
+	var id influxdb.ID
+	if err := id.DecodeFromString(taskBackfillFlags.id); err != nil {
+		return err
+	}
+	start, err := time.Parse(time.RFC3339, taskBackfillFlags.start)
+	if err != nil {
+		return fmt.Errorf("invalid start time: %v", err)
+	}
+	stop, err := time.Parse(time.RFC3339, taskBackfillFlags.stop)
+	if err != nil {
+		return fmt.Errorf("invalid stop time: %v", err)
+	}
+
+	w := cmd.OutOrStdout()
+	// the runs are printed as they complete, so that the ones that completed
+	// are known when the backfill is interrupted
+	backfill, err := s.BackfillTask(context.TODO(), id, start, stop, taskBackfillFlags.concurrency, func(p backend.BackfillProgress) {
+		if r := p.Run; r != nil {
+			fmt.Fprintf(w, "run %s scheduled for %s %s (%d/%d)\n", r.ID, r.ScheduledFor.Format(time.RFC3339), r.Status, p.Completed(), p.Total)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Backfilled task %s: %d succeeded, %d failed, %d canceled, %d skipped.\n", id, backfill.Succeeded, backfill.Failed, backfill.Canceled, backfill.Skipped)
+	return nil
+}
+
 type taskPrintOpts struct {
 	hideHeaders bool
 	json        bool
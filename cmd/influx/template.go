@@ -35,7 +35,9 @@ func cmdApply(f *globalFlags, opts genericCLIOpts) *cobra.Command {
 }
 
 func cmdExport(f *globalFlags, opts genericCLIOpts) *cobra.Command {
-	return newCmdPkgerBuilder(newPkgerSVC, f, opts).cmdExport()
+	cmd := newCmdPkgerBuilder(newPkgerSVC, f, opts).cmdExport()
+	cmd.AddCommand(newCmdExportDataBuilder(newExportDataSVCs, f, opts).cmd())
+	return cmd
 }
 
 func cmdStack(f *globalFlags, opts genericCLIOpts) *cobra.Command {
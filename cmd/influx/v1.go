@@ -0,0 +1,22 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func cmdV1(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	return newCmdV1(newV1DBRPSVCs, newV1AuthSVCs, f, opt)
+}
+
+func newCmdV1(dbrpSVCsFn v1DBRPSVCsFn, authSVCsFn v1AuthSVCsFn, f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("v1", nil, false)
+	cmd.Short = "InfluxDB v1 compatibility commands"
+	cmd.Run = seeHelp
+
+	cmd.AddCommand(
+		newCmdV1AuthBuilder(authSVCsFn, f, opt).cmd(),
+		newCmdV1DBRPBuilder(dbrpSVCsFn, f, opt).cmd(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,459 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http"
+	v1authorization "github.com/influxdata/influxdb/v2/v1/authorization"
+	"github.com/spf13/cobra"
+	input "github.com/tcnksm/go-input"
+)
+
+type v1AuthSVCsFn func() (cmdV1AuthDeps, error)
+
+type cmdV1AuthDeps struct {
+	authSVC   v1authorization.AuthorizationService
+	orgSVC    influxdb.OrganizationService
+	userSVC   influxdb.UserService
+	getPassFn func(*input.UI, bool) string
+}
+
+type cmdV1AuthBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn v1AuthSVCsFn
+
+	json        bool
+	hideHeaders bool
+	id          string
+	username    string
+	password    string
+	description string
+	user        string
+	userID      string
+	org         organization
+
+	readBuckets  []string
+	writeBuckets []string
+}
+
+func newCmdV1AuthBuilder(svcsFn v1AuthSVCsFn, f *globalFlags, opt genericCLIOpts) *cmdV1AuthBuilder {
+	return &cmdV1AuthBuilder{
+		genericCLIOpts: opt,
+		globalFlags:    f,
+		svcFn:          svcsFn,
+	}
+}
+
+func (b *cmdV1AuthBuilder) cmd() *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd("auth", nil, false)
+	cmd.Aliases = []string{"authorization"}
+	cmd.Short = "Legacy user/password authorization management commands"
+	cmd.Run = seeHelp
+	cmd.AddCommand(
+		b.cmdCreate(),
+		b.cmdDelete(),
+		b.cmdFind(),
+		b.cmdSetActive(),
+		b.cmdSetInactive(),
+		b.cmdSetPassword(),
+	)
+	return cmd
+}
+
+func (b *cmdV1AuthBuilder) cmdCreate() *cobra.Command {
+	cmd := b.newCmd("create", b.cmdCreateRunEFn)
+	cmd.Short = "Create a legacy authorization"
+
+	cmd.Flags().StringVarP(&b.username, "username", "", "", "The v1 username (required)")
+	cmd.Flags().StringVarP(&b.password, "password", "", "", "The v1 password, prompted for when not provided")
+	cmd.Flags().StringVarP(&b.description, "description", "d", "", "Token description")
+	cmd.Flags().StringArrayVarP(&b.readBuckets, "read-bucket", "", nil, "The ID of a bucket the user can read from")
+	cmd.Flags().StringArrayVarP(&b.writeBuckets, "write-bucket", "", nil, "The ID of a bucket the user can write to")
+	cmd.MarkFlagRequired("username")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdV1AuthBuilder) cmdCreateRunEFn(cmd *cobra.Command, args []string) error {
+	dep, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := b.org.getID(dep.orgSVC)
+	if err != nil {
+		return err
+	}
+
+	var permissions []influxdb.Permission
+	for _, bp := range []struct {
+		action  influxdb.Action
+		buckets []string
+	}{
+		{action: influxdb.ReadAction, buckets: b.readBuckets},
+		{action: influxdb.WriteAction, buckets: b.writeBuckets},
+	} {
+		for _, bucket := range bp.buckets {
+			bucketID, err := influxdb.IDFromString(bucket)
+			if err != nil {
+				return fmt.Errorf("failed to decode bucket id %q: %v", bucket, err)
+			}
+			p, err := influxdb.NewPermissionAtID(*bucketID, bp.action, influxdb.BucketsResourceType, orgID)
+			if err != nil {
+				return err
+			}
+			permissions = append(permissions, *p)
+		}
+	}
+	if len(permissions) == 0 {
+		return errors.New("at least one of --read-bucket or --write-bucket is required")
+	}
+
+	password := b.password
+	if password == "" {
+		ui := &input.UI{
+			Writer: b.genericCLIOpts.w,
+			Reader: b.genericCLIOpts.in,
+		}
+		password = dep.getPassFn(ui, false)
+	}
+
+	ctx := context.Background()
+	auth := &influxdb.Authorization{
+		Token:       b.username,
+		Status:      influxdb.Active,
+		Description: b.description,
+		OrgID:       orgID,
+		Permissions: permissions,
+	}
+	if err := dep.authSVC.CreateAuthorization(ctx, auth); err != nil {
+		return fmt.Errorf("failed to create legacy authorization: %v", err)
+	}
+
+	if err := dep.authSVC.SetPassword(ctx, auth.ID, password); err != nil {
+		_ = dep.authSVC.DeleteAuthorization(ctx, auth.ID)
+		return fmt.Errorf("failed to set password of %q: %v", b.username, err)
+	}
+
+	return b.printV1Tokens(dep.userSVC, v1TokenPrintOpt{auth: auth})
+}
+
+func (b *cmdV1AuthBuilder) cmdDelete() *cobra.Command {
+	cmd := b.newCmd("delete", b.cmdDeleteRunEFn)
+	cmd.Short = "Delete a legacy authorization"
+
+	b.registerLookupFlags(cmd)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdV1AuthBuilder) cmdDeleteRunEFn(cmd *cobra.Command, args []string) error {
+	dep, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	auth, err := b.findAuthorization(ctx, dep.authSVC)
+	if err != nil {
+		return err
+	}
+
+	if err := dep.authSVC.DeleteAuthorization(ctx, auth.ID); err != nil {
+		return fmt.Errorf("failed to delete legacy authorization with id %q: %v", auth.ID, err)
+	}
+
+	return b.printV1Tokens(dep.userSVC, v1TokenPrintOpt{
+		deleted: true,
+		auth:    auth,
+	})
+}
+
+func (b *cmdV1AuthBuilder) cmdFind() *cobra.Command {
+	cmd := b.newCmd("list", b.cmdFindRunEFn)
+	cmd.Short = "List legacy authorizations"
+	cmd.Aliases = []string{"find", "ls"}
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The authorization ID")
+	cmd.Flags().StringVarP(&b.username, "username", "", "", "The v1 username")
+	cmd.Flags().StringVarP(&b.user, "user", "u", "", "The name of the user that owns the authorizations")
+	cmd.Flags().StringVarP(&b.userID, "user-id", "", "", "The ID of the user that owns the authorizations")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdV1AuthBuilder) cmdFindRunEFn(cmd *cobra.Command, args []string) error {
+	dep, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	var filter influxdb.AuthorizationFilter
+	if b.id != "" {
+		filter.ID, err = influxdb.IDFromString(b.id)
+		if err != nil {
+			return err
+		}
+	}
+	if b.username != "" {
+		filter.Token = &b.username
+	}
+	if b.user != "" {
+		filter.User = &b.user
+	}
+	if b.userID != "" {
+		filter.UserID, err = influxdb.IDFromString(b.userID)
+		if err != nil {
+			return err
+		}
+	}
+	if b.org.name != "" {
+		filter.Org = &b.org.name
+	}
+	if b.org.id != "" {
+		filter.OrgID, err = influxdb.IDFromString(b.org.id)
+		if err != nil {
+			return err
+		}
+	}
+
+	auths, _, err := dep.authSVC.FindAuthorizations(context.Background(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve legacy authorizations: %v", err)
+	}
+	if auths == nil {
+		auths = []*influxdb.Authorization{}
+	}
+
+	return b.printV1Tokens(dep.userSVC, v1TokenPrintOpt{auths: auths})
+}
+
+func (b *cmdV1AuthBuilder) cmdSetActive() *cobra.Command {
+	cmd := b.newCmd("set-active", b.cmdSetStatusRunEFn(influxdb.Active))
+	cmd.Short = "Activate a legacy authorization"
+
+	b.registerLookupFlags(cmd)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdV1AuthBuilder) cmdSetInactive() *cobra.Command {
+	cmd := b.newCmd("set-inactive", b.cmdSetStatusRunEFn(influxdb.Inactive))
+	cmd.Short = "Deactivate a legacy authorization"
+
+	b.registerLookupFlags(cmd)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdV1AuthBuilder) cmdSetStatusRunEFn(status influxdb.Status) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		dep, err := b.svcFn()
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		auth, err := b.findAuthorization(ctx, dep.authSVC)
+		if err != nil {
+			return err
+		}
+
+		auth, err = dep.authSVC.UpdateAuthorization(ctx, auth.ID, &influxdb.AuthorizationUpdate{
+			Status: &status,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update legacy authorization: %v", err)
+		}
+
+		return b.printV1Tokens(dep.userSVC, v1TokenPrintOpt{auth: auth})
+	}
+}
+
+func (b *cmdV1AuthBuilder) cmdSetPassword() *cobra.Command {
+	cmd := b.newCmd("set-password", b.cmdSetPasswordRunEFn)
+	cmd.Short = "Set the password of a legacy authorization"
+
+	b.registerLookupFlags(cmd)
+	cmd.Flags().StringVarP(&b.password, "password", "", "", "The new v1 password, prompted for when not provided")
+
+	return cmd
+}
+
+func (b *cmdV1AuthBuilder) cmdSetPasswordRunEFn(cmd *cobra.Command, args []string) error {
+	dep, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	auth, err := b.findAuthorization(ctx, dep.authSVC)
+	if err != nil {
+		return err
+	}
+
+	password := b.password
+	if password == "" {
+		ui := &input.UI{
+			Writer: b.genericCLIOpts.w,
+			Reader: b.genericCLIOpts.in,
+		}
+		password = dep.getPassFn(ui, true)
+	}
+
+	if err := dep.authSVC.SetPassword(ctx, auth.ID, password); err != nil {
+		return fmt.Errorf("failed to set password of %q: %v", auth.Token, err)
+	}
+	fmt.Fprintln(b.w, "Successfully updated password.")
+	return nil
+}
+
+func (b *cmdV1AuthBuilder) registerLookupFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The authorization ID, required when --username is not provided")
+	cmd.Flags().StringVarP(&b.username, "username", "", "", "The v1 username, required when --id is not provided")
+}
+
+// findAuthorization looks up the authorization selected with the --id or --username flags.
+func (b *cmdV1AuthBuilder) findAuthorization(ctx context.Context, svc v1authorization.AuthorizationService) (*influxdb.Authorization, error) {
+	switch {
+	case b.id != "" && b.username != "":
+		return nil, errors.New("please specify one of id or username")
+	case b.id != "":
+		id, err := influxdb.IDFromString(b.id)
+		if err != nil {
+			return nil, err
+		}
+		auth, err := svc.FindAuthorizationByID(ctx, *id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find legacy authorization with id %q: %v", b.id, err)
+		}
+		return auth, nil
+	case b.username != "":
+		auth, err := svc.FindAuthorizationByToken(ctx, b.username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find legacy authorization with username %q: %v", b.username, err)
+		}
+		return auth, nil
+	default:
+		return nil, errors.New("please specify one of id or username")
+	}
+}
+
+func (b *cmdV1AuthBuilder) newCmd(use string, runE func(*cobra.Command, []string) error) *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd(use, runE, true)
+	b.globalFlags.registerFlags(cmd)
+	return cmd
+}
+
+func (b *cmdV1AuthBuilder) registerPrintFlags(cmd *cobra.Command) {
+	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
+}
+
+type (
+	v1TokenPrintOpt struct {
+		deleted bool
+		auth    *influxdb.Authorization
+		auths   []*influxdb.Authorization
+	}
+
+	v1Token struct {
+		ID          influxdb.ID `json:"id"`
+		Description string      `json:"description"`
+		Username    string      `json:"username"`
+		Status      string      `json:"status"`
+		UserName    string      `json:"userName"`
+		UserID      influxdb.ID `json:"userID"`
+		Permissions []string    `json:"permissions"`
+	}
+)
+
+func (b *cmdV1AuthBuilder) printV1Tokens(userSVC influxdb.UserService, opt v1TokenPrintOpt) error {
+	auths := opt.auths
+	if auths == nil {
+		auths = []*influxdb.Authorization{opt.auth}
+	}
+
+	tokens := make([]v1Token, 0, len(auths))
+	for _, a := range auths {
+		user, err := userSVC.FindUserByID(context.Background(), a.UserID)
+		if err != nil {
+			return err
+		}
+
+		permissions := make([]string, 0, len(a.Permissions))
+		for _, p := range a.Permissions {
+			permissions = append(permissions, p.String())
+		}
+
+		tokens = append(tokens, v1Token{
+			ID:          a.ID,
+			Description: a.Description,
+			Username:    a.Token,
+			Status:      string(a.Status),
+			UserName:    user.Name,
+			UserID:      a.UserID,
+			Permissions: permissions,
+		})
+	}
+
+	if b.json {
+		var v interface{} = tokens
+		if opt.auths == nil {
+			v = tokens[0]
+		}
+		return b.writeJSON(v)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+
+	headers := []string{"ID", "Description", "Username", "Status", "v2 User Name", "v2 User ID", "Permissions"}
+	if opt.deleted {
+		headers = append(headers, "Deleted")
+	}
+	w.WriteHeaders(headers...)
+
+	for _, t := range tokens {
+		m := map[string]interface{}{
+			"ID":           t.ID.String(),
+			"Description":  t.Description,
+			"Username":     t.Username,
+			"Status":       t.Status,
+			"v2 User Name": t.UserName,
+			"v2 User ID":   t.UserID.String(),
+			"Permissions":  t.Permissions,
+		}
+		if opt.deleted {
+			m["Deleted"] = true
+		}
+		w.Write(m)
+	}
+
+	return nil
+}
+
+func newV1AuthSVCs() (cmdV1AuthDeps, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return cmdV1AuthDeps{}, err
+	}
+	return cmdV1AuthDeps{
+		authSVC:   &v1authorization.Client{Client: httpClient},
+		orgSVC:    &http.OrganizationService{Client: httpClient},
+		userSVC:   &http.UserService{Client: httpClient},
+		getPassFn: getPassword,
+	}, nil
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/dbrp"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/spf13/cobra"
+)
+
+type v1DBRPSVCsFn func() (influxdb.DBRPMappingServiceV2, influxdb.OrganizationService, error)
+
+type cmdV1DBRPBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn v1DBRPSVCsFn
+
+	json        bool
+	hideHeaders bool
+	id          string
+	bucketID    string
+	db          string
+	rp          string
+	isDefault   bool
+	org         organization
+}
+
+func newCmdV1DBRPBuilder(svcsFn v1DBRPSVCsFn, f *globalFlags, opt genericCLIOpts) *cmdV1DBRPBuilder {
+	return &cmdV1DBRPBuilder{
+		genericCLIOpts: opt,
+		globalFlags:    f,
+		svcFn:          svcsFn,
+	}
+}
+
+func (b *cmdV1DBRPBuilder) cmd() *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd("dbrp", nil, false)
+	cmd.Short = "Database retention policy mapping management commands"
+	cmd.Run = seeHelp
+	cmd.AddCommand(
+		b.cmdCreate(),
+		b.cmdDelete(),
+		b.cmdFind(),
+		b.cmdUpdate(),
+	)
+	return cmd
+}
+
+func (b *cmdV1DBRPBuilder) cmdCreate() *cobra.Command {
+	cmd := b.newCmd("create", b.cmdCreateRunEFn)
+	cmd.Short = "Create a database retention policy mapping"
+
+	cmd.Flags().StringVarP(&b.bucketID, "bucket-id", "", "", "The ID of the bucket to be mapped (required)")
+	cmd.Flags().StringVarP(&b.db, "db", "", "", "The name of the database (required)")
+	cmd.Flags().StringVarP(&b.rp, "rp", "", "", "The name of the retention policy (required)")
+	cmd.Flags().BoolVarP(&b.isDefault, "default", "", false, "Make this mapping the default retention policy of the database")
+	cmd.MarkFlagRequired("bucket-id")
+	cmd.MarkFlagRequired("db")
+	cmd.MarkFlagRequired("rp")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdV1DBRPBuilder) cmdCreateRunEFn(cmd *cobra.Command, args []string) error {
+	dbrpSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return err
+	}
+	bucketID, err := influxdb.IDFromString(b.bucketID)
+	if err != nil {
+		return fmt.Errorf("failed to decode bucket-id: %v", err)
+	}
+
+	mapping := &influxdb.DBRPMappingV2{
+		Database:        b.db,
+		RetentionPolicy: b.rp,
+		Default:         b.isDefault,
+		OrganizationID:  orgID,
+		BucketID:        *bucketID,
+	}
+	if err := dbrpSVC.Create(context.Background(), mapping); err != nil {
+		return fmt.Errorf("failed to create mapping: %v", err)
+	}
+
+	return b.printDBRPs(dbrpPrintOpt{mapping: mapping})
+}
+
+func (b *cmdV1DBRPBuilder) cmdDelete() *cobra.Command {
+	cmd := b.newCmd("delete", b.cmdDeleteRunEFn)
+	cmd.Short = "Delete a database retention policy mapping"
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The mapping ID (required)")
+	cmd.MarkFlagRequired("id")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdV1DBRPBuilder) cmdDeleteRunEFn(cmd *cobra.Command, args []string) error {
+	dbrpSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return err
+	}
+	id, err := influxdb.IDFromString(b.id)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	mapping, err := dbrpSVC.FindByID(ctx, orgID, *id)
+	if err != nil {
+		return fmt.Errorf("failed to find mapping with id %q: %v", b.id, err)
+	}
+
+	if err := dbrpSVC.Delete(ctx, orgID, *id); err != nil {
+		return fmt.Errorf("failed to delete mapping with id %q: %v", b.id, err)
+	}
+
+	return b.printDBRPs(dbrpPrintOpt{
+		deleted: true,
+		mapping: mapping,
+	})
+}
+
+func (b *cmdV1DBRPBuilder) cmdFind() *cobra.Command {
+	cmd := b.newCmd("list", b.cmdFindRunEFn)
+	cmd.Short = "List database retention policy mappings"
+	cmd.Aliases = []string{"find", "ls"}
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "Limits the list to the mapping with this ID")
+	cmd.Flags().StringVarP(&b.bucketID, "bucket-id", "", "", "Limits the list to mappings of the bucket with this ID")
+	cmd.Flags().StringVarP(&b.db, "db", "", "", "Limits the list to mappings of this database")
+	cmd.Flags().StringVarP(&b.rp, "rp", "", "", "Limits the list to mappings of this retention policy")
+	cmd.Flags().BoolVarP(&b.isDefault, "default", "", false, "Limits the list to default mappings, or to non default mappings when set to false")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdV1DBRPBuilder) cmdFindRunEFn(cmd *cobra.Command, args []string) error {
+	dbrpSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return err
+	}
+
+	filter := influxdb.DBRPMappingFilterV2{OrgID: &orgID}
+	if b.id != "" {
+		filter.ID, err = influxdb.IDFromString(b.id)
+		if err != nil {
+			return err
+		}
+	}
+	if b.bucketID != "" {
+		filter.BucketID, err = influxdb.IDFromString(b.bucketID)
+		if err != nil {
+			return fmt.Errorf("failed to decode bucket-id: %v", err)
+		}
+	}
+	if b.db != "" {
+		filter.Database = &b.db
+	}
+	if b.rp != "" {
+		filter.RetentionPolicy = &b.rp
+	}
+	if cmd.Flags().Changed("default") {
+		filter.Default = &b.isDefault
+	}
+
+	mappings, _, err := dbrpSVC.FindMany(context.Background(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve mappings: %v", err)
+	}
+	if mappings == nil {
+		mappings = []*influxdb.DBRPMappingV2{}
+	}
+
+	return b.printDBRPs(dbrpPrintOpt{mappings: mappings})
+}
+
+func (b *cmdV1DBRPBuilder) cmdUpdate() *cobra.Command {
+	cmd := b.newCmd("update", b.cmdUpdateRunEFn)
+	cmd.Short = "Update a database retention policy mapping"
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The mapping ID (required)")
+	cmd.Flags().StringVarP(&b.rp, "rp", "", "", "The new name of the retention policy")
+	cmd.Flags().BoolVarP(&b.isDefault, "default", "", false, "Set or unset the mapping as the default retention policy of the database")
+	cmd.MarkFlagRequired("id")
+	b.org.register(cmd, false)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdV1DBRPBuilder) cmdUpdateRunEFn(cmd *cobra.Command, args []string) error {
+	dbrpSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return err
+	}
+	id, err := influxdb.IDFromString(b.id)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	mapping, err := dbrpSVC.FindByID(ctx, orgID, *id)
+	if err != nil {
+		return fmt.Errorf("failed to find mapping with id %q: %v", b.id, err)
+	}
+
+	if b.rp != "" {
+		mapping.RetentionPolicy = b.rp
+	}
+	if cmd.Flags().Changed("default") {
+		mapping.Default = b.isDefault
+	}
+
+	if err := dbrpSVC.Update(ctx, mapping); err != nil {
+		return fmt.Errorf("failed to update mapping with id %q: %v", b.id, err)
+	}
+
+	return b.printDBRPs(dbrpPrintOpt{mapping: mapping})
+}
+
+func (b *cmdV1DBRPBuilder) newCmd(use string, runE func(*cobra.Command, []string) error) *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd(use, runE, true)
+	b.globalFlags.registerFlags(cmd)
+	return cmd
+}
+
+func (b *cmdV1DBRPBuilder) registerPrintFlags(cmd *cobra.Command) {
+	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
+}
+
+type dbrpPrintOpt struct {
+	deleted  bool
+	mapping  *influxdb.DBRPMappingV2
+	mappings []*influxdb.DBRPMappingV2
+}
+
+func (b *cmdV1DBRPBuilder) printDBRPs(opt dbrpPrintOpt) error {
+	if b.json {
+		var v interface{} = opt.mappings
+		if opt.mappings == nil {
+			v = opt.mapping
+		}
+		return b.writeJSON(v)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+
+	headers := []string{"ID", "Database", "Bucket ID", "Retention Policy", "Default", "Organization ID"}
+	if opt.deleted {
+		headers = append(headers, "Deleted")
+	}
+	w.WriteHeaders(headers...)
+
+	if opt.mappings == nil {
+		opt.mappings = append(opt.mappings, opt.mapping)
+	}
+
+	for _, m := range opt.mappings {
+		row := map[string]interface{}{
+			"ID":               m.ID.String(),
+			"Database":         m.Database,
+			"Bucket ID":        m.BucketID.String(),
+			"Retention Policy": m.RetentionPolicy,
+			"Default":          m.Default,
+			"Organization ID":  m.OrganizationID.String(),
+		}
+		if opt.deleted {
+			row["Deleted"] = true
+		}
+		w.Write(row)
+	}
+
+	return nil
+}
+
+func newV1DBRPSVCs() (influxdb.DBRPMappingServiceV2, influxdb.OrganizationService, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	return dbrp.NewClient(httpClient), &http.OrganizationService{Client: httpClient}, nil
+}
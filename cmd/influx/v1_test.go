@@ -0,0 +1,420 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	input "github.com/tcnksm/go-input"
+)
+
+func TestCmdV1DBRP(t *testing.T) {
+	orgID := influxdb.ID(9000)
+
+	orgSVC := &mock.OrganizationService{
+		FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+			return &influxdb.Organization{ID: orgID, Name: "influxdata"}, nil
+		},
+	}
+
+	cmdFn := func(svc influxdb.DBRPMappingServiceV2) func(*globalFlags, genericCLIOpts) *cobra.Command {
+		return func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+			dbrpSVCsFn := func() (influxdb.DBRPMappingServiceV2, influxdb.OrganizationService, error) {
+				return svc, orgSVC, nil
+			}
+			return newCmdV1(dbrpSVCsFn, nil, g, opt)
+		}
+	}
+
+	t.Run("list", func(t *testing.T) {
+		yes, no := true, false
+		db, rp := "db", "autogen"
+
+		tests := []struct {
+			name     string
+			command  string
+			flags    []string
+			expected influxdb.DBRPMappingFilterV2
+		}{
+			{
+				name:     "org",
+				flags:    []string{"--org=influxdata"},
+				expected: influxdb.DBRPMappingFilterV2{OrgID: &orgID},
+			},
+			{
+				name: "all filters",
+				flags: []string{
+					"--org=influxdata",
+					"--id=" + influxdb.ID(1).String(),
+					"--bucket-id=" + influxdb.ID(2).String(),
+					"--db=db",
+					"--rp=autogen",
+					"--default",
+				},
+				expected: influxdb.DBRPMappingFilterV2{
+					OrgID:           &orgID,
+					ID:              idPtr(1),
+					BucketID:        idPtr(2),
+					Database:        &db,
+					RetentionPolicy: &rp,
+					Default:         &yes,
+				},
+			},
+			{
+				name:     "not default",
+				command:  "ls",
+				flags:    []string{"--org=influxdata", "--default=false"},
+				expected: influxdb.DBRPMappingFilterV2{OrgID: &orgID, Default: &no},
+			},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				defer addEnvVars(t, envVarsZeroMap)()
+
+				var filter influxdb.DBRPMappingFilterV2
+				svc := &mock.DBRPMappingServiceV2{
+					FindManyFn: func(ctx context.Context, f influxdb.DBRPMappingFilterV2, opts ...influxdb.FindOptions) ([]*influxdb.DBRPMappingV2, int, error) {
+						filter = f
+						return nil, 0, nil
+					},
+				}
+
+				builder := newInfluxCmdBuilder(
+					in(new(bytes.Buffer)),
+					out(ioutil.Discard),
+				)
+				cmd := builder.cmd(cmdFn(svc))
+
+				if tt.command == "" {
+					tt.command = "list"
+				}
+				cmd.SetArgs(append([]string{"v1", "dbrp", tt.command}, tt.flags...))
+
+				require.NoError(t, cmd.Execute())
+				assert.Equal(t, tt.expected, filter)
+			}
+
+			t.Run(tt.name, fn)
+		}
+	})
+
+	t.Run("create", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		var created *influxdb.DBRPMappingV2
+		svc := &mock.DBRPMappingServiceV2{
+			CreateFn: func(ctx context.Context, dbrp *influxdb.DBRPMappingV2) error {
+				dbrp.ID = 1
+				created = dbrp
+				return nil
+			},
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{
+			"v1", "dbrp", "create",
+			"--org-id=" + orgID.String(),
+			"--bucket-id=" + influxdb.ID(2).String(),
+			"--db=db",
+			"--rp=autogen",
+			"--default",
+		})
+
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, &influxdb.DBRPMappingV2{
+			ID:              1,
+			Database:        "db",
+			RetentionPolicy: "autogen",
+			Default:         true,
+			OrganizationID:  orgID,
+			BucketID:        2,
+		}, created)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			flags    []string
+			expected influxdb.DBRPMappingV2
+		}{
+			{
+				name:  "retention policy",
+				flags: []string{"--rp=rp2"},
+				expected: influxdb.DBRPMappingV2{
+					ID: 1, Database: "db", RetentionPolicy: "rp2", Default: true, OrganizationID: orgID, BucketID: 2,
+				},
+			},
+			{
+				name:  "default",
+				flags: []string{"--default=false"},
+				expected: influxdb.DBRPMappingV2{
+					ID: 1, Database: "db", RetentionPolicy: "autogen", Default: false, OrganizationID: orgID, BucketID: 2,
+				},
+			},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				defer addEnvVars(t, envVarsZeroMap)()
+
+				var updated influxdb.DBRPMappingV2
+				svc := &mock.DBRPMappingServiceV2{
+					FindByIDFn: func(ctx context.Context, orgID, id influxdb.ID) (*influxdb.DBRPMappingV2, error) {
+						return &influxdb.DBRPMappingV2{
+							ID: id, Database: "db", RetentionPolicy: "autogen", Default: true, OrganizationID: orgID, BucketID: 2,
+						}, nil
+					},
+					UpdateFn: func(ctx context.Context, dbrp *influxdb.DBRPMappingV2) error {
+						updated = *dbrp
+						return nil
+					},
+				}
+
+				builder := newInfluxCmdBuilder(
+					in(new(bytes.Buffer)),
+					out(ioutil.Discard),
+				)
+				cmd := builder.cmd(cmdFn(svc))
+				cmd.SetArgs(append([]string{"v1", "dbrp", "update", "--org=influxdata", "--id=" + influxdb.ID(1).String()}, tt.flags...))
+
+				require.NoError(t, cmd.Execute())
+				assert.Equal(t, tt.expected, updated)
+			}
+
+			t.Run(tt.name, fn)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		var deleted influxdb.ID
+		svc := &mock.DBRPMappingServiceV2{
+			FindByIDFn: func(ctx context.Context, orgID, id influxdb.ID) (*influxdb.DBRPMappingV2, error) {
+				return &influxdb.DBRPMappingV2{ID: id, OrganizationID: orgID}, nil
+			},
+			DeleteFn: func(ctx context.Context, orgID, id influxdb.ID) error {
+				deleted = id
+				return nil
+			},
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc))
+		cmd.SetArgs([]string{"v1", "dbrp", "delete", "--org=influxdata", "-i=" + influxdb.ID(3).String()})
+
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, influxdb.ID(3), deleted)
+	})
+}
+
+func TestCmdV1Auth(t *testing.T) {
+	orgID := influxdb.ID(9000)
+
+	cmdFn := func(svc *fakeV1AuthService, getPassFn func(*input.UI, bool) string) func(*globalFlags, genericCLIOpts) *cobra.Command {
+		return func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+			authSVCsFn := func() (cmdV1AuthDeps, error) {
+				return cmdV1AuthDeps{
+					authSVC: svc,
+					orgSVC: &mock.OrganizationService{
+						FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+							return &influxdb.Organization{ID: orgID, Name: "influxdata"}, nil
+						},
+					},
+					userSVC: &mock.UserService{
+						FindUserByIDFn: func(ctx context.Context, id influxdb.ID) (*influxdb.User, error) {
+							return &influxdb.User{ID: id, Name: "bob"}, nil
+						},
+					},
+					getPassFn: getPassFn,
+				}, nil
+			}
+			return newCmdV1(nil, authSVCsFn, g, opt)
+		}
+	}
+
+	t.Run("create", func(t *testing.T) {
+		read, err := influxdb.NewPermissionAtID(2, influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+		require.NoError(t, err)
+		write, err := influxdb.NewPermissionAtID(3, influxdb.WriteAction, influxdb.BucketsResourceType, orgID)
+		require.NoError(t, err)
+
+		tests := []struct {
+			name             string
+			flags            []string
+			expectedPerms    []influxdb.Permission
+			expectedPassword string
+		}{
+			{
+				name: "with password",
+				flags: []string{
+					"--password=secret123",
+					"--read-bucket=" + influxdb.ID(2).String(),
+					"--write-bucket=" + influxdb.ID(3).String(),
+				},
+				expectedPerms:    []influxdb.Permission{*read, *write},
+				expectedPassword: "secret123",
+			},
+			{
+				name:             "prompt for password",
+				flags:            []string{"--read-bucket=" + influxdb.ID(2).String()},
+				expectedPerms:    []influxdb.Permission{*read},
+				expectedPassword: "prompted",
+			},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				defer addEnvVars(t, envVarsZeroMap)()
+
+				var (
+					created  *influxdb.Authorization
+					password string
+				)
+				svc := &fakeV1AuthService{
+					AuthorizationService: &mock.AuthorizationService{
+						CreateAuthorizationFn: func(ctx context.Context, a *influxdb.Authorization) error {
+							a.ID = 1
+							a.UserID = 4
+							created = a
+							return nil
+						},
+					},
+					SetPasswordFn: func(ctx context.Context, id influxdb.ID, p string) error {
+						password = p
+						return nil
+					},
+				}
+
+				builder := newInfluxCmdBuilder(
+					in(new(bytes.Buffer)),
+					out(ioutil.Discard),
+				)
+				cmd := builder.cmd(cmdFn(svc, func(*input.UI, bool) string { return "prompted" }))
+				cmd.SetArgs(append([]string{"v1", "auth", "create", "--org=influxdata", "--username=tony"}, tt.flags...))
+
+				require.NoError(t, cmd.Execute())
+				require.NotNil(t, created)
+				assert.Equal(t, "tony", created.Token)
+				assert.Equal(t, orgID, created.OrgID)
+				assert.Equal(t, tt.expectedPerms, created.Permissions)
+				assert.Equal(t, tt.expectedPassword, password)
+			}
+
+			t.Run(tt.name, fn)
+		}
+	})
+
+	t.Run("create requires a bucket", func(t *testing.T) {
+		defer addEnvVars(t, envVarsZeroMap)()
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(&fakeV1AuthService{}, nil))
+		cmd.SetArgs([]string{"v1", "auth", "create", "--org=influxdata", "--username=tony", "--password=secret123"})
+
+		require.Error(t, cmd.Execute())
+	})
+
+	t.Run("set-password by username", func(t *testing.T) {
+		var (
+			id       influxdb.ID
+			password string
+		)
+		svc := &fakeV1AuthService{
+			AuthorizationService: &mock.AuthorizationService{
+				FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+					return &influxdb.Authorization{ID: 5, Token: token}, nil
+				},
+			},
+			SetPasswordFn: func(ctx context.Context, authID influxdb.ID, p string) error {
+				id, password = authID, p
+				return nil
+			},
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc, func(*input.UI, bool) string { return "prompted" }))
+		cmd.SetArgs([]string{"v1", "auth", "set-password", "--username=tony"})
+
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, influxdb.ID(5), id)
+		assert.Equal(t, "prompted", password)
+	})
+
+	t.Run("set-inactive", func(t *testing.T) {
+		var status influxdb.Status
+		svc := &fakeV1AuthService{
+			AuthorizationService: &mock.AuthorizationService{
+				FindAuthorizationByIDFn: func(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+					return &influxdb.Authorization{ID: id, Token: "tony", UserID: 4}, nil
+				},
+				UpdateAuthorizationFn: func(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
+					status = *upd.Status
+					return &influxdb.Authorization{ID: id, Token: "tony", UserID: 4, Status: *upd.Status}, nil
+				},
+			},
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(svc, nil))
+		cmd.SetArgs([]string{"v1", "auth", "set-inactive", "--id=" + influxdb.ID(5).String()})
+
+		require.NoError(t, cmd.Execute())
+		assert.Equal(t, influxdb.Inactive, status)
+	})
+
+	t.Run("delete requires id or username", func(t *testing.T) {
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(cmdFn(&fakeV1AuthService{}, nil))
+		cmd.SetArgs([]string{"v1", "auth", "delete"})
+
+		require.Error(t, cmd.Execute())
+	})
+}
+
+func idPtr(id influxdb.ID) *influxdb.ID {
+	return &id
+}
+
+type fakeV1AuthService struct {
+	*mock.AuthorizationService
+	SetPasswordFn func(context.Context, influxdb.ID, string) error
+}
+
+func (s *fakeV1AuthService) SetPermissions(ctx context.Context, id influxdb.ID, permissions []influxdb.Permission) (*influxdb.Authorization, error) {
+	return nil, nil
+}
+
+func (s *fakeV1AuthService) SetPassword(ctx context.Context, id influxdb.ID, password string) error {
+	return s.SetPasswordFn(ctx, id, password)
+}
+
+func (s *fakeV1AuthService) ComparePassword(ctx context.Context, id influxdb.ID, password string) error {
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 
 	platform "github.com/influxdata/influxdb/v2"
 	ihttp "github.com/influxdata/influxdb/v2/http"
@@ -40,6 +41,9 @@ type writeFlagsType struct {
 	IgnoreDataTypeInColumnName bool
 	Encoding                   string
 	ErrorsFile                 string
+
+	// rejected, when set, records a line that the server rejected
+	rejected func(line, reason string)
 }
 
 var writeFlags writeFlagsType
@@ -217,7 +221,12 @@ func (writeFlags *writeFlagsType) createLineReader(ctx context.Context, cmd *cob
 		}
 		closers = append(closers, writer)
 		errorsFile = csv.NewWriter(writer)
+		// rows skipped by the CSV reader and lines rejected by the server
+		// are reported from different goroutines
+		var mu sync.Mutex
 		rowSkippedListener = func(source *csv2lp.CsvToLineReader, lineError error, row []string) {
+			mu.Lock()
+			defer mu.Unlock()
 			log.Println(lineError)
 			errorsFile.Comma = source.Comma()
 			errorsFile.Write([]string{fmt.Sprintf("# error : %v", lineError)})
@@ -226,6 +235,12 @@ func (writeFlags *writeFlagsType) createLineReader(ctx context.Context, cmd *cob
 			}
 			errorsFile.Flush() // flush is required
 		}
+		writeFlags.rejected = func(line, reason string) {
+			mu.Lock()
+			defer mu.Unlock()
+			log.Printf("line rejected: %s\n", reason)
+			fmt.Fprintf(writer, "# error : %s\n%s\n", reason, line)
+		}
 	}
 
 	// concatenate readers
@@ -311,18 +326,30 @@ func fluxWriteF(cmd *cobra.Command, args []string) error {
 	}
 
 	ac := flags.config()
+	svc := &ihttp.WriteService{
+		Addr:               ac.Host,
+		Token:              ac.Token,
+		Precision:          writeFlags.Precision,
+		InsecureSkipVerify: flags.skipVerify,
+	}
+	// with an errors file, write the valid lines and record the rejected ones
+	var rejected int
+	if writeFlags.rejected != nil {
+		svc.Rejected = func(line, reason string) {
+			rejected++
+			writeFlags.rejected(line, reason)
+		}
+	}
 	// write to InfluxDB
 	s := write.Batcher{
-		Service: &ihttp.WriteService{
-			Addr:               ac.Host,
-			Token:              ac.Token,
-			Precision:          writeFlags.Precision,
-			InsecureSkipVerify: flags.skipVerify,
-		},
+		Service: svc,
 	}
 	if err := s.Write(ctx, orgID, bucketID, r); err != nil && err != context.Canceled {
 		return fmt.Errorf("failed to write data: %v", err)
 	}
+	if rejected > 0 {
+		return fmt.Errorf("failed to write %d lines, see %q", rejected, writeFlags.ErrorsFile)
+	}
 
 	return nil
 }
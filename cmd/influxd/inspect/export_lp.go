@@ -0,0 +1,335 @@
+package inspect
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/internal/fs"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/escape"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewExportLineProtocolCommand creates the command exporting the data of a
+// bucket as line protocol.
+func NewExportLineProtocolCommand() *cobra.Command {
+	var flags struct {
+		enginePath   string
+		bucketID     string
+		outputPath   string
+		start        string
+		end          string
+		measurements []string
+		compress     bool
+	}
+
+	cmd := &cobra.Command{
+		Use:   `export-lp`,
+		Short: "Exports TSM data as line protocol",
+		Long: `
+This command will export the data of a bucket from the TSM files and the
+WAL of the data engine as line protocol, which can be written again with
+"influx write". The data can be limited to a time range and to measurements.
+
+The output is gzip-compressed unless --compress=false is given.
+The command reads the files of a running server as they are; data being
+compacted or written at the same time may be missing from the export.`,
+		Args: cobra.NoArgs,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(fmt.Errorf("failed to determine influx directory: %s", err))
+	}
+
+	cmd.Flags().StringVar(&flags.enginePath, "engine-path", filepath.Join(dir, "engine"), "Path to persistent engine files")
+	cmd.Flags().StringVar(&flags.bucketID, "bucket-id", "", "ID of the bucket to export")
+	cmd.Flags().StringVar(&flags.outputPath, "output-path", "", "Path of the file to export to, or - for stdout")
+	cmd.Flags().StringVar(&flags.start, "start", "", "Only export points at or after this RFC3339 time")
+	cmd.Flags().StringVar(&flags.end, "end", "", "Only export points at or before this RFC3339 time")
+	cmd.Flags().StringArrayVar(&flags.measurements, "measurement", nil, "Only export points of this measurement; may be repeated")
+	cmd.Flags().BoolVar(&flags.compress, "compress", true, "Compress the output with gzip")
+	_ = cmd.MarkFlagRequired("bucket-id")
+	_ = cmd.MarkFlagRequired("output-path")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		bucketID, err := influxdb.IDFromString(flags.bucketID)
+		if err != nil {
+			return fmt.Errorf("invalid bucket ID: %v", err)
+		}
+
+		e := &lineProtocolExporter{
+			start: math.MinInt64,
+			end:   math.MaxInt64,
+		}
+		if flags.start != "" {
+			t, err := time.Parse(time.RFC3339Nano, flags.start)
+			if err != nil {
+				return fmt.Errorf("invalid start time: %v", err)
+			}
+			e.start = t.UnixNano()
+		}
+		if flags.end != "" {
+			t, err := time.Parse(time.RFC3339Nano, flags.end)
+			if err != nil {
+				return fmt.Errorf("invalid end time: %v", err)
+			}
+			e.end = t.UnixNano()
+		}
+		if e.end < e.start {
+			return fmt.Errorf("end must not be before start")
+		}
+		if len(flags.measurements) > 0 {
+			e.measurements = make(map[string]struct{}, len(flags.measurements))
+			for _, m := range flags.measurements {
+				e.measurements[m] = struct{}{}
+			}
+		}
+
+		var out io.Writer = os.Stdout
+		if flags.outputPath != "-" {
+			f, err := os.Create(flags.outputPath)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+
+		bw := bufio.NewWriter(out)
+		e.w = bw
+		var zw *gzip.Writer
+		if flags.compress {
+			zw = gzip.NewWriter(bw)
+			e.w = zw
+		}
+
+		if err := e.exportBucket(flags.enginePath, bucketID.String()); err != nil {
+			return err
+		}
+		if zw != nil {
+			if err := zw.Close(); err != nil {
+				return err
+			}
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d points\n", e.points)
+		return nil
+	}
+
+	return cmd
+}
+
+// lineProtocolExporter writes the values of TSM and WAL files as line protocol.
+type lineProtocolExporter struct {
+	w            io.Writer
+	start, end   int64
+	measurements map[string]struct{}
+
+	points int
+	buf    []byte
+}
+
+// exportBucket exports all shards of a bucket. The TSM files of a shard are
+// written before its WAL, so the newest value of a point is written last.
+func (e *lineProtocolExporter) exportBucket(enginePath, bucketID string) error {
+	dataDir := filepath.Join(enginePath, "data")
+	if _, err := os.Stat(filepath.Join(dataDir, bucketID)); os.IsNotExist(err) {
+		return fmt.Errorf("bucket %s not found in %s", bucketID, dataDir)
+	}
+
+	// Shards are in <bucket>/<retention policy>/<shard>.
+	shardDirs, err := filepath.Glob(filepath.Join(dataDir, bucketID, "*", "*"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(shardDirs)
+
+	for _, shardDir := range shardDirs {
+		rel, err := filepath.Rel(dataDir, shardDir)
+		if err != nil {
+			return err
+		}
+
+		tsmFiles, err := filepath.Glob(filepath.Join(shardDir, "*."+tsm1.TSMFileExtension))
+		if err != nil {
+			return err
+		}
+		sort.Strings(tsmFiles)
+		for _, f := range tsmFiles {
+			if err := e.exportTSMFile(f); err != nil {
+				return fmt.Errorf("failed to export %s: %v", f, err)
+			}
+		}
+
+		segments, err := filepath.Glob(filepath.Join(enginePath, "wal", rel, "*."+tsm1.WALFileExtension))
+		if err != nil {
+			return err
+		}
+		sort.Strings(segments)
+		if err := e.exportWALFiles(segments); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *lineProtocolExporter) exportTSMFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if min, max := r.TimeRange(); max < e.start || min > e.end {
+		return nil
+	}
+
+	for i := 0; i < r.KeyCount(); i++ {
+		key, _ := r.KeyAt(i)
+		if !e.matches(key) {
+			continue
+		}
+
+		values, err := r.ReadAll(key)
+		if err != nil {
+			return err
+		}
+		if err := e.writeValues(key, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportWALFiles loads the WAL segments of a shard into a cache, so deletes
+// apply to the values written before them, and exports the cache.
+func (e *lineProtocolExporter) exportWALFiles(segments []string) error {
+	if len(segments) == 0 {
+		return nil
+	}
+
+	cache := tsm1.NewCache(0)
+	for _, path := range segments {
+		if err := loadWALFile(cache, path); err != nil {
+			return fmt.Errorf("failed to export %s: %v", path, err)
+		}
+	}
+
+	for _, key := range cache.Keys() {
+		if !e.matches(key) {
+			continue
+		}
+		if err := e.writeValues(key, cache.Values(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadWALFile reads a WAL segment into cache. A corrupt segment is read up to
+// the corruption, as the engine does when it loads the WAL.
+func loadWALFile(cache *tsm1.Cache, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	r := tsm1.NewWALSegmentReader(f)
+	defer r.Close()
+
+	for r.Next() {
+		entry, err := r.Read()
+		if err != nil {
+			break
+		}
+
+		switch t := entry.(type) {
+		case *tsm1.WriteWALEntry:
+			if err := cache.WriteMulti(t.Values); err != nil {
+				return err
+			}
+		case *tsm1.DeleteRangeWALEntry:
+			cache.DeleteRange(t.Keys, t.Min, t.Max)
+		case *tsm1.DeleteWALEntry:
+			cache.DeleteRange(t.Keys, math.MinInt64, math.MaxInt64)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the measurement of a composite key is exported.
+func (e *lineProtocolExporter) matches(key []byte) bool {
+	if e.measurements == nil {
+		return true
+	}
+	seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+	_, ok := e.measurements[string(escape.Unescape(models.ParseName(seriesKey)))]
+	return ok
+}
+
+func (e *lineProtocolExporter) writeValues(key []byte, values []tsm1.Value) error {
+	seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+
+	e.buf = append(e.buf[:0], seriesKey...)
+	e.buf = append(e.buf, ' ')
+	e.buf = append(e.buf, escape.Bytes(field)...)
+	e.buf = append(e.buf, '=')
+	prefixLen := len(e.buf)
+
+	for _, value := range values {
+		ts := value.UnixNano()
+		if ts < e.start || ts > e.end {
+			continue
+		}
+
+		buf := e.buf[:prefixLen]
+		switch v := value.(type) {
+		case tsm1.FloatValue:
+			buf = strconv.AppendFloat(buf, v.RawValue(), 'g', -1, 64)
+		case tsm1.IntegerValue:
+			buf = strconv.AppendInt(buf, v.RawValue(), 10)
+			buf = append(buf, 'i')
+		case tsm1.UnsignedValue:
+			buf = strconv.AppendUint(buf, v.RawValue(), 10)
+			buf = append(buf, 'u')
+		case tsm1.BooleanValue:
+			buf = strconv.AppendBool(buf, v.RawValue())
+		case tsm1.StringValue:
+			buf = append(buf, '"')
+			buf = append(buf, models.EscapeStringField(v.RawValue())...)
+			buf = append(buf, '"')
+		default:
+			return fmt.Errorf("unsupported value type %T", value)
+		}
+		buf = append(buf, ' ')
+		buf = strconv.AppendInt(buf, ts, 10)
+		buf = append(buf, '\n')
+		e.buf = buf
+
+		if _, err := e.w.Write(buf); err != nil {
+			return err
+		}
+		e.points++
+	}
+	return nil
+}
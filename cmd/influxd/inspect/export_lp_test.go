@@ -0,0 +1,104 @@
+package inspect
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportLineProtocol(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export-lp")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	const bucketID = "0000000000000001"
+	shardDir := filepath.Join(dir, "data", bucketID, "autogen", "1")
+	require.NoError(t, os.MkdirAll(shardDir, 0777))
+
+	f, err := os.Create(filepath.Join(shardDir, "000000001-000000001.tsm"))
+	require.NoError(t, err)
+	w, err := tsm1.NewTSMWriter(f)
+	require.NoError(t, err)
+	require.NoError(t, w.Write([]byte("cpu,host=a#!~#value"), tsm1.Values{
+		tsm1.NewValue(10, 1.5),
+		tsm1.NewValue(20, 2.5),
+	}))
+	require.NoError(t, w.Write([]byte("disk\\ space,host=a#!~#used"), tsm1.Values{
+		tsm1.NewValue(10, int64(3)),
+	}))
+	require.NoError(t, w.WriteIndex())
+	require.NoError(t, w.Close())
+
+	wal := tsm1.NewWAL(filepath.Join(dir, "wal", bucketID, "autogen", "1"))
+	require.NoError(t, wal.Open())
+	_, err = wal.WriteMulti(map[string][]tsm1.Value{
+		"cpu,host=b#!~#status":  {tsm1.NewValue(30, `a "quoted" string`)},
+		"cpu,host=b#!~#deleted": {tsm1.NewValue(30, true)},
+		"cpu,host=b#!~#counter": {tsm1.NewValue(40, uint64(7))},
+		"mem,host=b#!~#ignored": {tsm1.NewValue(30, 1.0)},
+	})
+	require.NoError(t, err)
+	_, err = wal.DeleteRange([][]byte{[]byte("cpu,host=b#!~#deleted")}, 0, 100)
+	require.NoError(t, err)
+	require.NoError(t, wal.Close())
+
+	export := func(t *testing.T, args ...string) string {
+		t.Helper()
+
+		out := filepath.Join(dir, "export.lp.gz")
+		cmd := NewExportLineProtocolCommand()
+		cmd.SetArgs(append([]string{"--engine-path", dir, "--bucket-id", bucketID, "--output-path", out}, args...))
+		cmd.SetErr(ioutil.Discard)
+		require.NoError(t, cmd.Execute())
+
+		f, err := os.Open(out)
+		require.NoError(t, err)
+		defer f.Close()
+		r, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("all data", func(t *testing.T) {
+		got := export(t)
+		assert.Equal(t, strings.Join([]string{
+			"cpu,host=a value=1.5 10",
+			"cpu,host=a value=2.5 20",
+			"disk\\ space,host=a used=3i 10",
+			"cpu,host=b counter=7u 40",
+			`cpu,host=b status="a \"quoted\" string" 30`,
+			"mem,host=b ignored=1 30",
+		}, "\n")+"\n", got)
+	})
+
+	t.Run("time range and measurements", func(t *testing.T) {
+		got := export(t,
+			"--start", "1970-01-01T00:00:00.000000015Z",
+			"--end", "1970-01-01T00:00:00.000000030Z",
+			"--measurement", "cpu",
+			"--measurement", "disk space",
+		)
+		assert.Equal(t, strings.Join([]string{
+			"cpu,host=a value=2.5 20",
+			`cpu,host=b status="a \"quoted\" string" 30`,
+		}, "\n")+"\n", got)
+	})
+
+	t.Run("unknown bucket", func(t *testing.T) {
+		cmd := NewExportLineProtocolCommand()
+		cmd.SetArgs([]string{"--engine-path", dir, "--bucket-id", "0000000000000002", "--output-path", filepath.Join(dir, "out")})
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		assert.Error(t, cmd.Execute())
+	})
+}
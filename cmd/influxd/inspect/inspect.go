@@ -18,6 +18,7 @@ func NewCommand() *cobra.Command {
 		//NewCompactSeriesFileCommand(),
 		//NewExportBlocksCommand(),
 		NewExportIndexCommand(),
+		NewExportLineProtocolCommand(),
 		//NewReportTSMCommand(),
 		//NewVerifyTSMCommand(),
 		//NewVerifyWALCommand(),
@@ -27,6 +27,8 @@ type Engine interface {
 	storage.EngineSchema
 	prom.PrometheusCollector
 	influxdb.BackupService
+	influxdb.RestoreService
+	influxdb.ShardService
 
 	SeriesCardinality(orgID, bucketID influxdb.ID) int64
 
@@ -157,8 +159,8 @@ func (t *TemporaryEngine) Flush(ctx context.Context) {
 	}
 }
 
-func (t *TemporaryEngine) CreateBackup(ctx context.Context) (int, []string, error) {
-	return t.engine.CreateBackup(ctx)
+func (t *TemporaryEngine) CreateBackup(ctx context.Context, filter influxdb.BackupFilter) (int, []string, error) {
+	return t.engine.CreateBackup(ctx, filter)
 }
 
 func (t *TemporaryEngine) FetchBackupFile(ctx context.Context, backupID int, backupFile string, w io.Writer) error {
@@ -169,6 +171,21 @@ func (t *TemporaryEngine) InternalBackupPath(backupID int) string {
 	return t.engine.InternalBackupPath(backupID)
 }
 
+// RestoreShard imports a shard archive into a bucket.
+func (t *TemporaryEngine) RestoreShard(ctx context.Context, bucketID influxdb.ID, start time.Time, r io.Reader) error {
+	return t.engine.RestoreShard(ctx, bucketID, start, r)
+}
+
+// FindShards returns the shards of a bucket.
+func (t *TemporaryEngine) FindShards(ctx context.Context, bucketID influxdb.ID) ([]*influxdb.Shard, error) {
+	return t.engine.FindShards(ctx, bucketID)
+}
+
+// DeleteShard removes a shard and its data.
+func (t *TemporaryEngine) DeleteShard(ctx context.Context, id uint64) error {
+	return t.engine.DeleteShard(ctx, id)
+}
+
 func (t *TemporaryEngine) TSDBStore() storage.TSDBStore {
 	return &t.tsdbStore
 }
@@ -63,15 +63,18 @@ import (
 	"github.com/influxdata/influxdb/v2/task/backend/executor"
 	"github.com/influxdata/influxdb/v2/task/backend/middleware"
 	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/task/backend/trigger"
 	"github.com/influxdata/influxdb/v2/telemetry"
 	"github.com/influxdata/influxdb/v2/tenant"
 	_ "github.com/influxdata/influxdb/v2/tsdb/engine/tsm1" // needed for tsm1
 	_ "github.com/influxdata/influxdb/v2/tsdb/index/tsi1"  // needed for tsi1
+	v1authorization "github.com/influxdata/influxdb/v2/v1/authorization"
 	iqlcoordinator "github.com/influxdata/influxdb/v2/v1/coordinator"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
 	storage2 "github.com/influxdata/influxdb/v2/v1/services/storage"
 	"github.com/influxdata/influxdb/v2/vault"
 	pzap "github.com/influxdata/influxdb/v2/zap"
+	"github.com/nats-io/nats-streaming-server/stores"
 	"github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
@@ -450,6 +453,32 @@ func launcherOpts(l *Launcher) []cli.Opt {
 			Desc:  "The default period ahead of the endtime of a shard group that its successor group is created.",
 		},
 
+		// Write queue
+		{
+			DestP:   &l.writeQueueEnabled,
+			Flag:    "write-queue-enabled",
+			Default: false,
+			Desc:    "persist writes to /api/v2/write in a durable queue before the storage engine applies them",
+		},
+		{
+			DestP:   &l.writeQueuePath,
+			Flag:    "write-queue-path",
+			Default: filepath.Join(dir, "write-queue"),
+			Desc:    "path to the files of the write queue",
+		},
+		{
+			DestP:   &l.writeQueueAck,
+			Flag:    "write-queue-ack",
+			Default: nats.WriteAckApplied,
+			Desc:    fmt.Sprintf("when writes are acknowledged: once %s in the write queue, or once %s by the storage engine", nats.WriteAckEnqueued, nats.WriteAckApplied),
+		},
+		{
+			DestP:   &l.writeQueueMaxBytes,
+			Flag:    "write-queue-max-bytes",
+			Default: 1 << 30,
+			Desc:    "maximum bytes of writes the write queue holds until the storage engine applies them; the queue uses up to twice as much disk space",
+		},
+
 		// InfluxQL Coordinator Config
 		{
 			DestP: &l.CoordinatorConfig.MaxSelectPointN,
@@ -524,6 +553,13 @@ type Launcher struct {
 	natsServer *nats.Server
 	natsPort   int
 
+	writeQueueEnabled  bool
+	writeQueuePath     string
+	writeQueueAck      string
+	writeQueueMaxBytes int
+	writeQueue         *nats.WriteQueue
+	writeQueueServer   *nats.Server
+
 	noTasks            bool
 	scheduler          stoppingScheduler
 	executor           *executor.Executor
@@ -597,6 +633,15 @@ func (m *Launcher) Shutdown(ctx context.Context) {
 	m.log.Info("Stopping", zap.String("service", "task"))
 
 	m.scheduler.Stop()
+	m.executor.Stop()
+
+	if m.writeQueue != nil {
+		m.log.Info("Stopping", zap.String("service", "write-queue"))
+		m.writeQueue.Close()
+	}
+	if m.writeQueueServer != nil {
+		m.writeQueueServer.Close()
+	}
 
 	m.log.Info("Stopping", zap.String("service", "nats"))
 	m.natsServer.Close()
@@ -766,6 +811,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		bucketLogSvc              platform.BucketOperationLogService       = m.kvService
 		orgLogSvc                 platform.OrganizationOperationLogService = m.kvService
 		scraperTargetSvc          platform.ScraperTargetStoreService       = m.kvService
+		scraperTargetHealthSvc    platform.ScraperTargetHealthService      = m.kvService
 		telegrafSvc               platform.TelegrafConfigStore             = m.kvService
 		lookupSvc                 platform.LookupService                   = m.kvService
 		notificationEndpointStore platform.NotificationEndpointService     = m.kvService
@@ -841,9 +887,11 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	m.reg.MustRegister(m.engine.PrometheusCollectors()...)
 
 	var (
-		deleteService platform.DeleteService = m.engine
-		pointsWriter  storage.PointsWriter   = m.engine
-		backupService platform.BackupService = m.engine
+		deleteService  platform.DeleteService  = m.engine
+		pointsWriter   storage.PointsWriter    = m.engine
+		backupService  platform.BackupService  = m.engine
+		restoreService platform.RestoreService = m.engine
+		shardService   platform.ShardService   = m.engine
 	)
 
 	deps, err := influxdb.NewDependencies(
@@ -859,6 +907,9 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	// The registry tracks the running Flux and InfluxQL queries.
+	queryRegistry := query.NewRegistry()
+
 	m.queryController, err = control.New(control.Config{
 		ConcurrencyQuota:                m.concurrencyQuota,
 		InitialMemoryBytesQuotaPerQuery: int64(m.initialMemoryBytesQuotaPerQuery),
@@ -867,6 +918,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		QueueSize:                       m.queueSize,
 		Logger:                          m.log.With(zap.String("service", "storage-reads")),
 		ExecutorDependencies:            []flux.Dependency{deps},
+		Registry:                        queryRegistry,
 	})
 	if err != nil {
 		m.log.Error("Failed to create query controller", zap.Error(err))
@@ -877,6 +929,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
 	var taskSvc platform.TaskService
+	var taskTriggers *trigger.Dispatcher
 	{
 		// create the task stack
 		combinedTaskService := taskbackend.NewAnalyticalStorage(m.log.With(zap.String("service", "task-analytical-store")), m.kvService, m.kvService, m.kvService, pointsWriter, query.QueryServiceBridge{AsyncQueryService: m.queryController})
@@ -888,6 +941,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			combinedTaskService,
 			combinedTaskService,
 			executor.WithFlagger(m.flagger),
+			executor.WithAttemptsFunc(executor.RetryLimit(fluxlang.DefaultService)),
 		)
 		m.executor = executor
 		m.reg.MustRegister(executorMetrics.PrometheusCollectors()...)
@@ -919,10 +973,16 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		m.scheduler = sch
 
 		coordLogger := m.log.With(zap.String("service", "task-coordinator"))
+		var coordOpts []coordinator.CoordinatorOption
+		if !m.noTasks {
+			taskTriggers = trigger.NewDispatcher(m.log.With(zap.String("service", "task-trigger")), executor, ts.BucketService)
+			coordOpts = append(coordOpts, coordinator.WithTriggerDispatcherOpt(taskTriggers))
+		}
 		taskCoord := coordinator.NewCoordinator(
 			coordLogger,
 			sch,
-			executor)
+			executor,
+			coordOpts...)
 
 		taskSvc = middleware.New(combinedTaskService, taskCoord)
 		m.taskControlService = combinedTaskService
@@ -940,6 +1000,15 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		}
 	}
 
+	// Writes from here on run the tasks triggered by them. Tasks write through
+	// the engine directly, so their own output doesn't trigger runs.
+	if taskTriggers != nil {
+		pointsWriter = &storage.NotifyingPointsWriter{
+			Underlying: pointsWriter,
+			Notifier:   taskTriggers,
+		}
+	}
+
 	dbrpSvc := dbrp.NewService(ctx, authorizer.NewBucketService(ts.BucketService), m.kvStore)
 	dbrpSvc = dbrp.NewAuthorizedService(dbrpSvc)
 
@@ -958,14 +1027,20 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		zap.Int("max_select_buckets", m.CoordinatorConfig.MaxSelectBucketsN))
 
 	qe := iqlquery.NewExecutor(m.log, cm)
+	qe.Registry = queryRegistry
 	se := &iqlcoordinator.StatementExecutor{
-		MetaClient:        metaClient,
-		TSDBStore:         m.engine.TSDBStore(),
-		ShardMapper:       mapper,
-		DBRP:              dbrpSvc,
-		MaxSelectPointN:   m.CoordinatorConfig.MaxSelectPointN,
-		MaxSelectSeriesN:  m.CoordinatorConfig.MaxSelectSeriesN,
-		MaxSelectBucketsN: m.CoordinatorConfig.MaxSelectBucketsN,
+		MetaClient:          metaClient,
+		TSDBStore:           m.engine.TSDBStore(),
+		ShardMapper:         mapper,
+		DBRP:                dbrpSvc,
+		PointsWriter:        pointsWriter,
+		DeleteService:       deleteService,
+		TaskService:         taskSvc,
+		ShardService:        shardService,
+		RunningQueryService: authorizer.NewRunningQueryService(queryRegistry),
+		MaxSelectPointN:     m.CoordinatorConfig.MaxSelectPointN,
+		MaxSelectSeriesN:    m.CoordinatorConfig.MaxSelectSeriesN,
+		MaxSelectBucketsN:   m.CoordinatorConfig.MaxSelectBucketsN,
 	}
 	qe.StatementExecutor = se
 	qe.StatementNormalizer = se
@@ -994,30 +1069,8 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	// This atrocity checks if the port is free, and if it's not, moves on to the
 	// next one. This best-effort approach may still fail occasionally when, for example,
 	// two tests race on isAddressPortAvailable.
-	var total int
-	for {
-		portAvailable, err := isAddressPortAvailable(natsOpts.Host, natsOpts.Port)
-		if err != nil {
-			return err
-		}
-		if portAvailable && natsOpts.Host == "" {
-			// Double-check localhost to accommodate tests
-			time.Sleep(100 * time.Millisecond)
-			portAvailable, err = isAddressPortAvailable("localhost", natsOpts.Port)
-			if err != nil {
-				return err
-			}
-		}
-		if portAvailable {
-			break
-		}
-
-		time.Sleep(100 * time.Millisecond)
-		natsOpts.Port++
-		total++
-		if total > 50 {
-			return errors.New("unable to find free port for Nats server")
-		}
+	if natsOpts.Port, err = freeNatsPort(natsOpts.Host, natsOpts.Port); err != nil {
+		return err
 	}
 	m.natsServer = nats.NewServer(&natsOpts)
 	m.natsPort = natsOpts.Port
@@ -1040,8 +1093,38 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	var writePointsWriter storage.PointsWriter = pointsWriter
+	if m.writeQueueEnabled {
+		// The write queue has a streaming server of its own, so only its
+		// channel is stored in files and limited by the size of the queue.
+		queueOpts := nats.NewDefaultServerOptions()
+		if queueOpts.Port, err = freeNatsPort(queueOpts.Host, m.natsPort+1); err != nil {
+			return err
+		}
+		m.writeQueueServer = nats.NewServer(&queueOpts)
+		m.writeQueueServer.FilestoreDir = m.writeQueuePath
+		m.writeQueueServer.ChannelLimits = map[string]*stores.ChannelLimits{
+			nats.WriteQueueSubject: nats.WriteQueueChannelLimits(int64(m.writeQueueMaxBytes)),
+		}
+		if err := m.writeQueueServer.Open(); err != nil {
+			m.log.Error("Failed to start write queue streaming server", zap.Error(err))
+			return err
+		}
+
+		queueURL := fmt.Sprintf("http://127.0.0.1:%d", queueOpts.Port)
+		m.writeQueue = nats.NewWriteQueue(m.log.With(zap.String("service", "write-queue")), fmt.Sprintf("nats-write-queue-%d", queueOpts.Port), queueURL, nats.WriteQueueConfig{
+			AckMode:  m.writeQueueAck,
+			MaxBytes: int64(m.writeQueueMaxBytes),
+		}, pointsWriter)
+		if err := m.writeQueue.Open(ctx); err != nil {
+			m.log.Error("Failed to open write queue", zap.Error(err))
+			return err
+		}
+		writePointsWriter = m.writeQueue
+	}
+
 	subscriber.Subscribe(gather.MetricsSubject, "metrics", gather.NewRecorderHandler(m.log, gather.PointWriter{Writer: pointsWriter}))
-	scraperScheduler, err := gather.NewScheduler(m.log, 10, scraperTargetSvc, publisher, subscriber, 10*time.Second, 30*time.Second)
+	scraperScheduler, err := gather.NewScheduler(m.log, 10, scraperTargetSvc, scraperTargetHealthSvc, publisher, subscriber, secretSvc, 10*time.Second, 30*time.Second)
 	if err != nil {
 		m.log.Error("Failed to create scraper subscriber", zap.Error(err))
 		return err
@@ -1101,6 +1184,15 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 	ts.BucketService = storage.NewBucketService(ts.BucketService, m.engine)
 	ts.BucketService = dbrp.NewBucketService(m.log, ts.BucketService, dbrpSvc)
+	se.BucketService = authorizer.NewBucketService(ts.BucketService)
+
+	v1AuthStore, err := v1authorization.NewStore(m.kvStore)
+	if err != nil {
+		m.log.Error("Failed creating new legacy authorization store", zap.Error(err))
+		return err
+	}
+	v1AuthSvc := v1authorization.NewService(v1AuthStore, ts)
+	se.V1AuthorizationService = v1authorization.NewAuthedAuthorizationService(v1AuthSvc)
 
 	var onboardOpts []tenant.OnboardServiceOptionFn
 	if m.testingAlwaysAllowSetup {
@@ -1120,15 +1212,19 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		NewBucketService:     source.NewBucketService,
 		NewQueryService:      source.NewQueryService,
 		PointsWriter: &storage.LoggingPointsWriter{
-			Underlying:    pointsWriter,
+			Underlying:    writePointsWriter,
 			BucketFinder:  ts.BucketService,
 			LogBucketName: platform.MonitoringSystemBucketName,
 		},
-		DeleteService:        deleteService,
-		BackupService:        backupService,
-		KVBackupService:      m.kvService,
-		AuthorizationService: authSvc,
-		AlgoWProxy:           &http.NoopProxyHandler{},
+		DeleteService:          deleteService,
+		BackupService:          backupService,
+		KVBackupService:        m.kvService,
+		RestoreService:         restoreService,
+		ShardService:           shardService,
+		RunningQueryService:    queryRegistry,
+		AuthorizationService:   authSvc,
+		V1AuthorizationService: v1AuthSvc,
+		AlgoWProxy:             &http.NoopProxyHandler{},
 		// Wrap the BucketService in a storage backed one that will ensure deleted buckets are removed from the storage engine.
 		BucketService:                   ts.BucketService,
 		SessionService:                  sessionSvc,
@@ -1250,6 +1346,12 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		authHTTPServer = kithttp.NewFeatureHandler(feature.NewAuthPackage(), m.flagger, oldHandler, newHandler, newHandler.Prefix())
 	}
 
+	var v1AuthHTTPServer *v1authorization.AuthHandler
+	{
+		authLogger := m.log.With(zap.String("handler", "legacy_authorization"))
+		v1AuthHTTPServer = v1authorization.NewHTTPAuthHandler(authLogger, v1authorization.NewAuthedAuthorizationService(v1AuthSvc), ts)
+	}
+
 	var sessionHTTPServer *session.SessionHandler
 	{
 		sessionHTTPServer = session.NewSessionHandler(m.log.With(zap.String("handler", "session")), sessionSvc, ts.UserService, ts.PasswordsService)
@@ -1265,6 +1367,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			http.WithResourceHandler(templatesHTTPServer),
 			http.WithResourceHandler(onboardHTTPServer),
 			http.WithResourceHandler(authHTTPServer),
+			http.WithResourceHandler(v1AuthHTTPServer),
 			http.WithResourceHandler(kithttp.NewFeatureHandler(feature.NewLabelPackage(), m.flagger, oldLabelHandler, labelHandler, labelHandler.Prefix())),
 			http.WithResourceHandler(sessionHTTPServer.SignInResourceHandler()),
 			http.WithResourceHandler(sessionHTTPServer.SignOutResourceHandler()),
@@ -1422,6 +1525,34 @@ func checkForPriorVersion(ctx context.Context, log *zap.Logger, boltPath string,
 	return nil
 }
 
+// freeNatsPort returns the first port from port on that is free on host, for
+// a NATS server to listen on.
+func freeNatsPort(host string, port int) (int, error) {
+	for total := 0; ; total++ {
+		if total > 50 {
+			return 0, errors.New("unable to find free port for Nats server")
+		}
+		portAvailable, err := isAddressPortAvailable(host, port)
+		if err != nil {
+			return 0, err
+		}
+		if portAvailable && host == "" {
+			// Double-check localhost to accommodate tests
+			time.Sleep(100 * time.Millisecond)
+			portAvailable, err = isAddressPortAvailable("localhost", port)
+			if err != nil {
+				return 0, err
+			}
+		}
+		if portAvailable {
+			return port, nil
+		}
+
+		time.Sleep(100 * time.Millisecond)
+		port++
+	}
+}
+
 // isAddressPortAvailable checks whether the address:port is available to listen,
 // by using net.Listen to verify that the port opens successfully, then closes the listener.
 func isAddressPortAvailable(address string, port int) (bool, error) {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	nethttp "net/http"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -52,6 +53,25 @@ func TestStorage_WriteAndQuery(t *testing.T) {
 	}
 }
 
+func TestStorage_WriteQueue(t *testing.T) {
+	l := launcher.NewTestLauncher(nil)
+	if err := l.Run(ctx, "--write-queue-enabled", "--write-queue-path", filepath.Join(l.Path, "write-queue")); err != nil {
+		t.Fatal(err)
+	}
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	// The write is acknowledged once it is applied, so it is queryable.
+	l.WritePointsOrFail(t, `m,k=v f=100i 946684800000000000`)
+
+	qs := `from(bucket:"BUCKET") |> range(start:2000-01-01T00:00:00Z,stop:2000-01-02T00:00:00Z)`
+	exp := `,result,table,_start,_stop,_time,_value,_field,_measurement,k` + "\r\n" +
+		`,_result,0,2000-01-01T00:00:00Z,2000-01-02T00:00:00Z,2000-01-01T00:00:00Z,100,f,m,v` + "\r\n\r\n"
+	if got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, qs); !cmp.Equal(got, exp) {
+		t.Errorf("unexpected query results -got/+exp\n%s", cmp.Diff(got, exp))
+	}
+}
+
 func TestLauncher_WriteAndQuery(t *testing.T) {
 	l := launcher.RunTestLauncherOrFail(t, ctx, nil)
 	l.SetupOrFail(t)
@@ -1,6 +1,7 @@
 package restore
 
 import (
+	"archive/tar"
 	"fmt"
 	"io"
 	"os"
@@ -230,40 +231,81 @@ func restoreBolt() error {
 }
 
 func restoreEngine() error {
-	dataDir := filepath.Join(flags.enginePath, "/data")
+	dataDir := filepath.Join(flags.enginePath, "data")
 	if err := os.MkdirAll(dataDir, 0777); err != nil {
 		return err
 	}
 
 	count := 0
 	err := filepath.Walk(flags.backupPath, func(path string, info os.FileInfo, err error) error {
-		if strings.Contains(path, ".tsm") {
-			f, err := os.OpenFile(path, os.O_RDONLY, 0666)
-			if err != nil {
-				return fmt.Errorf("error opening TSM file: %v", err)
-			}
-			defer f.Close()
-
-			tsmPath := filepath.Join(dataDir, filepath.Base(path))
-			w, err := os.OpenFile(tsmPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
-			if err != nil {
-				return err
-			}
-			defer w.Close()
-
-			_, err = io.Copy(w, f)
-			if err != nil {
-				return err
-			}
-			count++
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".tar" {
 			return nil
 		}
+		if err := restoreShard(path, dataDir); err != nil {
+			return fmt.Errorf("error restoring shard archive %s: %v", path, err)
+		}
+		count++
 		return nil
 	})
-	fmt.Printf("Restored %d TSM files to %v\n", count, dataDir)
+	fmt.Printf("Restored %d shards to %v\n", count, dataDir)
 	return err
 }
 
+// restoreShard extracts a shard archive of a backup into dataDir, keeping the
+// <database>/<retention policy>/<shard id> layout of its files.
+func restoreShard(path, dataDir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dataDir, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dataDir)+string(filepath.Separator)) {
+			return fmt.Errorf("invalid archive path: %s", hdr.Name)
+		}
+
+		if hdr.Typeflag == tar.TypeDir {
+			if err := os.MkdirAll(target, 0777); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+			return err
+		}
+		if err := restoreArchiveFile(tr, hdr, target); err != nil {
+			return err
+		}
+	}
+}
+
+func restoreArchiveFile(tr *tar.Reader, hdr *tar.Header, target string) error {
+	w, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	if _, err := io.CopyN(w, tr, hdr.Size); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
 func restoreFile(backup string, target string, filetype string) error {
 	f, err := os.Open(backup)
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"strconv"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
@@ -66,6 +67,9 @@ func (c *Client) FindMany(ctx context.Context, filter influxdb.DBRPMappingFilter
 	if filter.RetentionPolicy != nil {
 		params = append(params, [2]string{"rp", *filter.RetentionPolicy})
 	}
+	if filter.Default != nil {
+		params = append(params, [2]string{"default", strconv.FormatBool(*filter.Default)})
+	}
 
 	var resp getDBRPsResponse
 	if err := c.Client.
@@ -107,15 +111,18 @@ func (c *Client) Update(ctx context.Context, dbrp *influxdb.DBRPMappingV2) error
 		return err
 	}
 
-	var newDBRP influxdb.DBRPMappingV2
+	var resp getDBRPResponse
 	if err := c.Client.
 		PatchJSON(dbrp, c.dbrpURL(dbrp.ID)).
 		QueryParams([2]string{"orgID", dbrp.OrganizationID.String()}).
-		DecodeJSON(&newDBRP).
+		DecodeJSON(&resp).
 		Do(ctx); err != nil {
 		return err
 	}
-	*dbrp = newDBRP
+	if resp.Content == nil {
+		return fmt.Errorf("no dbrp mapping in update response")
+	}
+	*dbrp = *resp.Content
 	return nil
 }
 
@@ -87,15 +87,27 @@ func TestClient(t *testing.T) {
 		client, shutdown := setup(t)
 		defer shutdown()
 
-		if err := client.Update(context.Background(), &influxdb.DBRPMappingV2{
+		dbrp := &influxdb.DBRPMappingV2{
 			ID:              1,
 			Database:        "db",
-			RetentionPolicy: "rp",
-			Default:         false,
+			RetentionPolicy: "autogen",
+			Default:         true,
 			OrganizationID:  1,
 			BucketID:        1,
-		}); err != nil {
-			t.Error(err)
+		}
+		if err := client.Update(context.Background(), dbrp); err != nil {
+			t.Fatal(err)
+		}
+		want := influxdb.DBRPMappingV2{
+			ID:              1,
+			Database:        "db",
+			RetentionPolicy: "autogen",
+			Default:         true,
+			OrganizationID:  1,
+			BucketID:        1,
+		}
+		if *dbrp != want {
+			t.Errorf("got updated mapping %+v, want %+v", *dbrp, want)
 		}
 	})
 
@@ -0,0 +1,124 @@
+package gather
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// get requests a url of a scraper target, with the auth and TLS options of
+// the target. The credentials of the target are loaded from secrets.
+func get(ctx context.Context, secrets influxdb.SecretService, target influxdb.ScraperTarget, url, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	for name, key := range target.Headers {
+		value, err := loadSecret(ctx, secrets, target, key)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(name, value)
+	}
+	if err := authenticate(ctx, req, secrets, target); err != nil {
+		return nil, err
+	}
+
+	client, err := newClient(ctx, secrets, target)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("scraper target %s returned status %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+func authenticate(ctx context.Context, req *http.Request, secrets influxdb.SecretService, target influxdb.ScraperTarget) error {
+	a := target.Auth
+	if a == nil {
+		return nil
+	}
+
+	switch {
+	case a.BearerTokenSecret != "":
+		token, err := loadSecret(ctx, secrets, target, a.BearerTokenSecret)
+		if err != nil {
+			return err
+		}
+		scheme := "Bearer"
+		if target.Type == influxdb.InfluxDBScraperType {
+			scheme = "Token"
+		}
+		req.Header.Set("Authorization", scheme+" "+token)
+	case a.Username != "":
+		var password string
+		if a.PasswordSecret != "" {
+			var err error
+			if password, err = loadSecret(ctx, secrets, target, a.PasswordSecret); err != nil {
+				return err
+			}
+		}
+		req.SetBasicAuth(a.Username, password)
+	}
+	return nil
+}
+
+// loadSecret loads a secret of the organization of a target.
+func loadSecret(ctx context.Context, secrets influxdb.SecretService, target influxdb.ScraperTarget, key string) (string, error) {
+	if secrets == nil {
+		return "", fmt.Errorf("no secret service to load scraper target secret %q", key)
+	}
+	return secrets.LoadSecret(ctx, target.OrgID, key)
+}
+
+// newClient returns the http client connecting to a target with its TLS config.
+func newClient(ctx context.Context, secrets influxdb.SecretService, target influxdb.ScraperTarget) (*http.Client, error) {
+	c := target.TLS
+	if c == nil {
+		return http.DefaultClient, nil
+	}
+
+	config := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(c.CACert)) {
+			return nil, fmt.Errorf("invalid scraper target CA certificate")
+		}
+		config.RootCAs = pool
+	}
+	if c.ClientCert != "" {
+		key, err := loadSecret(ctx, secrets, target, c.ClientKeySecret)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := tls.X509KeyPair([]byte(c.ClientCert), []byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid scraper target client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	// the transport only lives for one scrape
+	transport.DisableKeepAlives = true
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,224 @@
+package gather
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+)
+
+func TestGet_Auth(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+	}))
+	defer ts.Close()
+
+	secrets := mock.NewSecretService()
+	secrets.LoadSecretFn = func(ctx context.Context, id influxdb.ID, k string) (string, error) {
+		if id != *orgID {
+			return "", fmt.Errorf("secret %s of org %s not found", k, id)
+		}
+		return k + "-value", nil
+	}
+
+	cases := []struct {
+		name   string
+		target influxdb.ScraperTarget
+		want   string
+	}{
+		{
+			name:   "no auth",
+			target: influxdb.ScraperTarget{Type: influxdb.PrometheusScraperType},
+		},
+		{
+			name: "bearer token",
+			target: influxdb.ScraperTarget{
+				Type: influxdb.PrometheusScraperType,
+				Auth: &influxdb.ScraperAuth{BearerTokenSecret: "token"},
+			},
+			want: "Bearer token-value",
+		},
+		{
+			name: "influxdb token",
+			target: influxdb.ScraperTarget{
+				Type: influxdb.InfluxDBScraperType,
+				Auth: &influxdb.ScraperAuth{BearerTokenSecret: "token"},
+			},
+			want: "Token token-value",
+		},
+		{
+			name: "basic auth",
+			target: influxdb.ScraperTarget{
+				Type: influxdb.PrometheusScraperType,
+				Auth: &influxdb.ScraperAuth{Username: "user", PasswordSecret: "password"},
+			},
+			want: "Basic dXNlcjpwYXNzd29yZC12YWx1ZQ==",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got = ""
+			c.target.OrgID = *orgID
+			resp, err := get(context.Background(), secrets, c.target, ts.URL, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if got != c.want {
+				t.Errorf("got authorization %q, want %q", got, c.want)
+			}
+		})
+	}
+
+	t.Run("headers", func(t *testing.T) {
+		var header http.Header
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+		}))
+		defer ts.Close()
+
+		resp, err := get(context.Background(), secrets, influxdb.ScraperTarget{
+			Type:    influxdb.PrometheusScraperType,
+			OrgID:   *orgID,
+			Headers: map[string]string{"X-Api-Key": "api-key", "X-Tenant": "tenant"},
+		}, ts.URL, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got, want := header.Get("X-Api-Key"), "api-key-value"; got != want {
+			t.Errorf("got X-Api-Key %q, want %q", got, want)
+		}
+		if got, want := header.Get("X-Tenant"), "tenant-value"; got != want {
+			t.Errorf("got X-Tenant %q, want %q", got, want)
+		}
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		_, err := get(context.Background(), secrets, influxdb.ScraperTarget{
+			Type:  influxdb.PrometheusScraperType,
+			OrgID: *bucketID,
+			Auth:  &influxdb.ScraperAuth{BearerTokenSecret: "token"},
+		}, ts.URL, "")
+		if err == nil {
+			t.Fatal("expected error loading secret of another org")
+		}
+	})
+}
+
+func TestGet_TLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}))
+
+	cases := []struct {
+		name   string
+		tls    *influxdb.ScraperTLSConfig
+		hasErr bool
+	}{
+		{
+			name:   "unknown authority",
+			hasErr: true,
+		},
+		{
+			name: "ca certificate",
+			tls:  &influxdb.ScraperTLSConfig{CACert: caCert},
+		},
+		{
+			name:   "ca certificate of other server name",
+			tls:    &influxdb.ScraperTLSConfig{CACert: caCert, ServerName: "influxdata.com"},
+			hasErr: true,
+		},
+		{
+			name: "insecure skip verify",
+			tls:  &influxdb.ScraperTLSConfig{InsecureSkipVerify: true},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp, err := get(context.Background(), nil, influxdb.ScraperTarget{TLS: c.tls}, ts.URL, "")
+			if (err != nil) != c.hasErr {
+				t.Fatalf("got error %v, want error %t", err, c.hasErr)
+			}
+			if err == nil {
+				resp.Body.Close()
+			}
+		})
+	}
+}
+
+func TestGet_ClientCertificate(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+
+	cert, key := newClientCertificate(t)
+	secrets := mock.NewSecretService()
+	secrets.LoadSecretFn = func(ctx context.Context, id influxdb.ID, k string) (string, error) {
+		return key, nil
+	}
+
+	target := influxdb.ScraperTarget{
+		Type:  influxdb.PrometheusScraperType,
+		OrgID: *orgID,
+		TLS:   &influxdb.ScraperTLSConfig{InsecureSkipVerify: true},
+	}
+	if _, err := get(context.Background(), secrets, target, ts.URL, ""); err == nil {
+		t.Fatal("expected error scraping without client certificate")
+	}
+
+	target.TLS.ClientCert = cert
+	target.TLS.ClientKeySecret = "client-key"
+	resp, err := get(context.Background(), secrets, target, ts.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+// newClientCertificate returns a PEM encoded self signed certificate and its key.
+func newClientCertificate(t *testing.T) (cert, key string) {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "scraper"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
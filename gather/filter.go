@@ -0,0 +1,69 @@
+package gather
+
+import (
+	"sort"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// metricFilter selects the metrics of a scrape with the allow and deny lists
+// of the filter of a target, matching the metrics like prometheus.Matcher
+// matches the series of metric families.
+type metricFilter struct {
+	allow prometheus.Matcher
+	deny  prometheus.Matcher
+}
+
+func newMetricFilter(f *influxdb.ScraperFilter) *metricFilter {
+	return &metricFilter{
+		allow: newMatcher(f.Allow),
+		deny:  newMatcher(f.Deny),
+	}
+}
+
+func newMatcher(matches []influxdb.ScraperMetricMatch) prometheus.Matcher {
+	m := prometheus.NewMatcher()
+	for _, match := range matches {
+		lps := make([]*dto.LabelPair, 0, len(match.Labels))
+		for name, value := range match.Labels {
+			lps = append(lps, prometheus.L(name, value))
+		}
+		m.Family(match.Name, lps...)
+	}
+	return m
+}
+
+// Filter returns the metrics the filter selects.
+func (f *metricFilter) Filter(ms MetricsSlice) MetricsSlice {
+	filtered := ms[:0]
+	for _, m := range ms {
+		metric := &dto.Metric{Label: labelPairs(m.Tags)}
+		if len(f.allow) > 0 && !matches(f.allow, m.Name, metric) {
+			continue
+		}
+		if matches(f.deny, m.Name, metric) {
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+	return filtered
+}
+
+func matches(m prometheus.Matcher, name string, metric *dto.Metric) bool {
+	labels, ok := m[name]
+	return ok && labels.Match(metric)
+}
+
+// labelPairs returns the tags of a metric as label pairs sorted by name.
+func labelPairs(tags map[string]string) []*dto.LabelPair {
+	lps := make([]*dto.LabelPair, 0, len(tags))
+	for name, value := range tags {
+		lps = append(lps, prometheus.L(name, value))
+	}
+	sort.Slice(lps, func(i, j int) bool {
+		return lps[i].GetName() < lps[j].GetName()
+	})
+	return lps
+}
@@ -0,0 +1,71 @@
+package gather
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestMetricFilter(t *testing.T) {
+	ms := MetricsSlice{
+		{Name: "go_goroutines", Tags: map[string]string{}},
+		{Name: "go_info", Tags: map[string]string{"version": "go1.10.3"}},
+		{Name: "http_requests_total", Tags: map[string]string{"code": "200", "method": "get"}},
+		{Name: "http_requests_total", Tags: map[string]string{"code": "500", "method": "get"}},
+	}
+
+	cases := []struct {
+		name   string
+		filter influxdb.ScraperFilter
+		want   []int
+	}{
+		{
+			name: "no filter",
+			want: []int{0, 1, 2, 3},
+		},
+		{
+			name: "allow metrics",
+			filter: influxdb.ScraperFilter{
+				Allow: []influxdb.ScraperMetricMatch{{Name: "go_goroutines"}, {Name: "http_requests_total"}},
+			},
+			want: []int{0, 2, 3},
+		},
+		{
+			name: "allow series",
+			filter: influxdb.ScraperFilter{
+				Allow: []influxdb.ScraperMetricMatch{{Name: "http_requests_total", Labels: map[string]string{"method": "get", "code": "500"}}},
+			},
+			want: []int{3},
+		},
+		{
+			name: "deny metrics",
+			filter: influxdb.ScraperFilter{
+				Deny: []influxdb.ScraperMetricMatch{{Name: "go_info"}},
+			},
+			want: []int{0, 2, 3},
+		},
+		{
+			name: "allow metrics and deny series",
+			filter: influxdb.ScraperFilter{
+				Allow: []influxdb.ScraperMetricMatch{{Name: "http_requests_total"}},
+				Deny:  []influxdb.ScraperMetricMatch{{Name: "http_requests_total", Labels: map[string]string{"code": "200", "method": "get"}}},
+			},
+			want: []int{3},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			in := append(MetricsSlice(nil), ms...)
+			got := newMetricFilter(&c.filter).Filter(in)
+
+			want := MetricsSlice{}
+			for _, i := range c.want {
+				want = append(want, ms[i])
+			}
+			if diff := cmp.Diff(want, got, metricsCmpOption); diff != "" {
+				t.Fatalf("unexpected metrics -want/+got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/nats"
@@ -14,7 +15,11 @@ import (
 type handler struct {
 	Scraper   Scraper
 	Publisher nats.Publisher
-	log       *zap.Logger
+	// Health records the health of the scrapes, when not nil.
+	Health *healthRecorder
+	// Timeout of the scrapes of the targets without a timeout.
+	Timeout time.Duration
+	log     *zap.Logger
 }
 
 // Process consumes scraper target from scraper target queue,
@@ -29,7 +34,16 @@ func (h *handler) Process(s nats.Subscription, m nats.Message) {
 		return
 	}
 
-	ms, err := h.Scraper.Gather(context.TODO(), *req)
+	timeout := h.Timeout
+	if req.Timeout != nil {
+		timeout = req.Timeout.Duration
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	ms, err := h.Scraper.Gather(ctx, *req)
+	h.recordHealth(*req, start, err)
 	if err != nil {
 		h.log.Error("Unable to gather", zap.Error(err))
 		return
@@ -48,3 +62,22 @@ func (h *handler) Process(s nats.Subscription, m nats.Message) {
 	}
 
 }
+
+// recordHealth records the health of a scrape of a target started at start.
+func (h *handler) recordHealth(target influxdb.ScraperTarget, start time.Time, scrapeErr error) {
+	if h.Health == nil {
+		return
+	}
+
+	health := influxdb.ScraperTargetHealth{
+		LastScrape:   start,
+		LastDuration: influxdb.Duration{Duration: time.Since(start)},
+	}
+	if scrapeErr != nil {
+		health.LastError = scrapeErr.Error()
+	} else {
+		health.LastSuccess = &start
+	}
+
+	h.Health.record(target.ID, health)
+}
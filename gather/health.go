@@ -0,0 +1,104 @@
+package gather
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// healthFlushInterval is how often the health of the scrapes that did not
+// change the error of their target is persisted.
+const healthFlushInterval = time.Minute
+
+// healthRecorder keeps the health of the scrapes of the targets in memory and
+// persists it in batches, so that frequent scrapes do not each write to the
+// store. A scrape that starts or stops a target failing, or fails it
+// differently, flushes the batch right away.
+type healthRecorder struct {
+	svc influxdb.ScraperTargetHealthService
+	log *zap.Logger
+
+	// changed wakes up the flush of a batch with a changed error.
+	changed chan struct{}
+
+	mu sync.Mutex
+	// lastError is the error of the last recorded scrape of each target.
+	lastError map[influxdb.ID]string
+	// pending is the health of the targets that is not persisted yet.
+	pending map[influxdb.ID]influxdb.ScraperTargetHealth
+}
+
+func newHealthRecorder(log *zap.Logger, svc influxdb.ScraperTargetHealthService) *healthRecorder {
+	return &healthRecorder{
+		svc:       svc,
+		log:       log,
+		changed:   make(chan struct{}, 1),
+		lastError: make(map[influxdb.ID]string),
+		pending:   make(map[influxdb.ID]influxdb.ScraperTargetHealth),
+	}
+}
+
+// record keeps the health of the last scrape of a target.
+func (r *healthRecorder) record(id influxdb.ID, health influxdb.ScraperTargetHealth) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if prev, ok := r.pending[id]; ok && health.LastSuccess == nil {
+		health.LastSuccess = prev.LastSuccess
+	}
+	r.pending[id] = health
+
+	if lastError, ok := r.lastError[id]; ok && lastError == health.LastError {
+		return
+	}
+	r.lastError[id] = health.LastError
+	select {
+	case r.changed <- struct{}{}:
+	default:
+		// a flush is already due
+	}
+}
+
+// run flushes the pending health every healthFlushInterval and when the
+// error of a target changed, until ctx is done.
+func (r *healthRecorder) run(ctx context.Context) {
+	ticker := time.NewTicker(healthFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.flush()
+			return
+		case <-ticker.C:
+			r.flush()
+		case <-r.changed:
+			r.flush()
+		}
+	}
+}
+
+// flush persists the pending health of the targets in a single update.
+// Recording is not held up while the batch is written.
+func (r *healthRecorder) flush() {
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = make(map[influxdb.ID]influxdb.ScraperTargetHealth)
+	// forget the targets not scraped since the last flush, which may be
+	// removed, so that their next scrape is flushed right away
+	for id := range r.lastError {
+		if _, ok := batch[id]; !ok {
+			delete(r.lastError, id)
+		}
+	}
+	r.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+	if err := r.svc.UpdateTargetsHealth(context.Background(), batch); err != nil {
+		r.log.Error("Unable to record scraper target health", zap.Error(err))
+	}
+}
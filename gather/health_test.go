@@ -0,0 +1,105 @@
+package gather
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap/zaptest"
+)
+
+type healthStore struct {
+	batches []map[influxdb.ID]influxdb.ScraperTargetHealth
+	// block holds up the updates until it is closed, when not nil.
+	block chan struct{}
+}
+
+func (s *healthStore) UpdateTargetsHealth(ctx context.Context, healths map[influxdb.ID]influxdb.ScraperTargetHealth) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.batches = append(s.batches, healths)
+	return nil
+}
+
+func TestHealthRecorder(t *testing.T) {
+	var (
+		store    = &healthStore{}
+		recorder = newHealthRecorder(zaptest.NewLogger(t), store)
+		id       = influxdb.ID(1)
+		start    = time.Unix(1590998400, 0)
+	)
+	success := func(i int) influxdb.ScraperTargetHealth {
+		at := start.Add(time.Duration(i) * time.Second)
+		return influxdb.ScraperTargetHealth{LastScrape: at, LastSuccess: &at}
+	}
+	expectChanged := func(want bool) {
+		t.Helper()
+		select {
+		case <-recorder.changed:
+			if !want {
+				t.Fatal("unexpected flush of unchanged health")
+			}
+		default:
+			if want {
+				t.Fatal("expected changed health to be flushed")
+			}
+		}
+	}
+
+	// the first scrape of a target is flushed right away
+	recorder.record(id, success(0))
+	expectChanged(true)
+	recorder.flush()
+	if len(store.batches) != 1 || !store.batches[0][id].LastScrape.Equal(start) {
+		t.Fatalf("unexpected batches %+v", store.batches)
+	}
+
+	// scrapes that keep succeeding wait for the next flush
+	recorder.record(id, success(1))
+	recorder.record(id, success(2))
+	expectChanged(false)
+	recorder.flush()
+	if len(store.batches) != 2 || !store.batches[1][id].LastScrape.Equal(start.Add(2*time.Second)) {
+		t.Fatalf("unexpected batches %+v", store.batches)
+	}
+
+	// a failure is flushed right away, with the last success
+	recorder.record(id, success(3))
+	failure := influxdb.ScraperTargetHealth{LastScrape: start.Add(4 * time.Second), LastError: "connection refused"}
+	recorder.record(id, failure)
+	expectChanged(true)
+	recorder.flush()
+	if got := store.batches[2][id]; got.LastError != failure.LastError || got.LastSuccess == nil || !got.LastSuccess.Equal(start.Add(3*time.Second)) {
+		t.Fatalf("unexpected health %+v", got)
+	}
+
+	// a target not scraped since the last flush is forgotten
+	recorder.flush()
+	if len(store.batches) != 3 {
+		t.Fatalf("got %d batches after flushing nothing, want 3", len(store.batches))
+	}
+	recorder.record(id, failure)
+	expectChanged(true)
+
+	// scrapes are recorded while a batch is written
+	store.block = make(chan struct{})
+	flushed := make(chan struct{})
+	go func() {
+		recorder.flush()
+		close(flushed)
+	}()
+	recorded := make(chan struct{})
+	go func() {
+		recorder.record(2, success(5))
+		close(recorded)
+	}()
+	select {
+	case <-recorded:
+	case <-time.After(time.Second):
+		t.Fatal("recording health was held up by the flush")
+	}
+	close(store.block)
+	<-flushed
+}
@@ -0,0 +1,43 @@
+package gather
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// influxdbScraper handles parsing the prometheus metrics of another InfluxDB
+// instance. The metrics are tagged with the instance they come from.
+// implements Scraper interfaces.
+type influxdbScraper struct {
+	prometheusScraper
+}
+
+// Gather parse metrics from the /metrics endpoint of a scraper target url.
+func (p *influxdbScraper) Gather(ctx context.Context, target influxdb.ScraperTarget) (collected MetricsCollection, err error) {
+	u, err := url.Parse(target.URL)
+	if err != nil {
+		return collected, err
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/metrics"
+	}
+
+	resp, err := get(ctx, p.secrets, target, u.String(), "")
+	if err != nil {
+		return collected, err
+	}
+	defer resp.Body.Close()
+
+	collected, err = p.parse(resp.Body, resp.Header, target)
+	if err != nil {
+		return collected, err
+	}
+	for _, m := range collected.MetricsSlice {
+		if _, ok := m.Tags["instance"]; !ok {
+			m.Tags["instance"] = u.Host
+		}
+	}
+	return collected, nil
+}
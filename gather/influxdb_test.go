@@ -0,0 +1,36 @@
+package gather
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestInfluxDBScraper(t *testing.T) {
+	ts := httptest.NewServer(&mockHTTPHandler{
+		responseMap: map[string]string{
+			"/metrics": sampleRespSmall,
+		},
+	})
+	defer ts.Close()
+
+	scraper := new(influxdbScraper)
+	collected, err := scraper.Gather(context.Background(), influxdb.ScraperTarget{
+		Type: influxdb.InfluxDBScraperType,
+		URL:  ts.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collected.MetricsSlice) != 1 {
+		t.Fatalf("got %d metrics, want 1", len(collected.MetricsSlice))
+	}
+
+	u, _ := url.Parse(ts.URL)
+	if instance := collected.MetricsSlice[0].Tags["instance"]; instance != u.Host {
+		t.Errorf("got instance %q, want %q", instance, u.Host)
+	}
+}
@@ -0,0 +1,160 @@
+package gather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/jsonpath"
+)
+
+// jsonScraper handles mapping the JSON documents of HTTP endpoints to metrics
+// with the JSONPaths of the json config of the targets.
+// implements Scraper interfaces.
+type jsonScraper struct {
+	secrets influxdb.SecretService
+}
+
+// Gather parse metrics from a scraper target url.
+func (p *jsonScraper) Gather(ctx context.Context, target influxdb.ScraperTarget) (collected MetricsCollection, err error) {
+	if target.JSON == nil {
+		return collected, fmt.Errorf("json scraper target %s has no json config", target.ID)
+	}
+
+	resp, err := get(ctx, p.secrets, target, target.URL, "application/json")
+	if err != nil {
+		return collected, err
+	}
+	defer resp.Body.Close()
+
+	var doc interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return collected, fmt.Errorf("reading json document failed: %s", err)
+	}
+
+	ms, err := mapJSON(doc, target.JSON, time.Now())
+	if err != nil {
+		return collected, err
+	}
+
+	collected = MetricsCollection{
+		MetricsSlice: ms,
+		OrgID:        target.OrgID,
+		BucketID:     target.BucketID,
+	}
+	return collected, nil
+}
+
+// mapJSON reads a metric from each object of doc selected by the path of c.
+// Objects that have none of the fields are skipped.
+func mapJSON(doc interface{}, c *influxdb.ScraperJSONConfig, now time.Time) (MetricsSlice, error) {
+	objects := []interface{}{doc}
+	if c.Path != "" {
+		path, err := jsonpath.Compile(c.Path)
+		if err != nil {
+			return nil, err
+		}
+		objects = objects[:0]
+		for _, v := range path.Get(doc) {
+			// a path selecting an array selects its objects
+			if a, ok := v.([]interface{}); ok {
+				objects = append(objects, a...)
+				continue
+			}
+			objects = append(objects, v)
+		}
+	}
+
+	tags, err := compilePaths(c.Tags)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := compilePaths(c.Fields)
+	if err != nil {
+		return nil, err
+	}
+	var timestamp *jsonpath.Path
+	if c.Timestamp != "" {
+		if timestamp, err = jsonpath.Compile(c.Timestamp); err != nil {
+			return nil, err
+		}
+	}
+
+	ms := make(MetricsSlice, 0, len(objects))
+	for _, o := range objects {
+		m := Metrics{
+			Name:      c.Measurement,
+			Tags:      make(map[string]string),
+			Fields:    make(map[string]interface{}),
+			Timestamp: now,
+			Type:      MetricTypeUntyped,
+		}
+		for k, p := range tags {
+			v, _ := p.First(o)
+			switch v.(type) {
+			case string, float64, bool:
+				m.Tags[k] = fmt.Sprint(v)
+			}
+		}
+		for k, p := range fields {
+			v, ok := p.First(o)
+			if !ok {
+				continue
+			}
+			switch v := v.(type) {
+			case float64:
+				if !math.IsNaN(v) {
+					m.Fields[k] = v
+				}
+			case string, bool:
+				m.Fields[k] = v
+			}
+		}
+		if len(m.Fields) == 0 {
+			continue
+		}
+		if timestamp != nil {
+			if v, ok := timestamp.First(o); ok {
+				tm, err := parseJSONTime(v)
+				if err != nil {
+					return nil, err
+				}
+				m.Timestamp = tm
+			}
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
+
+func compilePaths(paths map[string]string) (map[string]*jsonpath.Path, error) {
+	compiled := make(map[string]*jsonpath.Path, len(paths))
+	for k, p := range paths {
+		path, err := jsonpath.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		compiled[k] = path
+	}
+	return compiled, nil
+}
+
+// parseJSONTime parses a time given as RFC3339 or a number of seconds since the epoch.
+func parseJSONTime(v interface{}) (time.Time, error) {
+	switch v := v.(type) {
+	case float64:
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(frac*1e9)), nil
+	case string:
+		tm, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid json timestamp %q: %s", v, err)
+		}
+		return tm, nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid json timestamp %v", v)
+	}
+}
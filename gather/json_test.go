@@ -0,0 +1,114 @@
+package gather
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+)
+
+const sampleJSON = `{
+	"service": "queue",
+	"time": "2020-06-01T10:00:00Z",
+	"queues": [
+		{"name": "jobs", "depth": 12, "paused": false, "updated": 1590998400},
+		{"name": "mail", "depth": 3, "paused": true, "updated": 1590998460.5},
+		{"name": "empty"}
+	]
+}`
+
+func TestJSONScraper(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(sampleJSON))
+	}))
+	defer ts.Close()
+
+	cases := []struct {
+		name   string
+		config *influxdb.ScraperJSONConfig
+		ms     MetricsSlice
+		times  []time.Time
+		hasErr bool
+	}{
+		{
+			name: "document",
+			config: &influxdb.ScraperJSONConfig{
+				Measurement: "service",
+				Tags:        map[string]string{"service": "$.service"},
+				Fields:      map[string]string{"queues": "$.queues[-1].name"},
+				Timestamp:   "$.time",
+			},
+			ms: MetricsSlice{
+				{
+					Name:   "service",
+					Type:   MetricTypeUntyped,
+					Tags:   map[string]string{"service": "queue"},
+					Fields: map[string]interface{}{"queues": "empty"},
+				},
+			},
+			times: []time.Time{time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "array of objects",
+			config: &influxdb.ScraperJSONConfig{
+				Path:        "$.queues",
+				Measurement: "queue",
+				Tags:        map[string]string{"name": "@.name", "missing": "@.missing"},
+				Fields:      map[string]string{"depth": "@.depth", "paused": "@.paused"},
+				Timestamp:   "@.updated",
+			},
+			ms: MetricsSlice{
+				{
+					Name:   "queue",
+					Type:   MetricTypeUntyped,
+					Tags:   map[string]string{"name": "jobs"},
+					Fields: map[string]interface{}{"depth": 12.0, "paused": false},
+				},
+				{
+					Name:   "queue",
+					Type:   MetricTypeUntyped,
+					Tags:   map[string]string{"name": "mail"},
+					Fields: map[string]interface{}{"depth": 3.0, "paused": true},
+				},
+			},
+			times: []time.Time{time.Unix(1590998400, 0), time.Unix(1590998460, 500000000)},
+		},
+		{
+			name: "invalid timestamp",
+			config: &influxdb.ScraperJSONConfig{
+				Measurement: "service",
+				Fields:      map[string]string{"service": "$.service"},
+				Timestamp:   "$.queues",
+			},
+			hasErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			scraper := new(jsonScraper)
+			collected, err := scraper.Gather(context.Background(), influxdb.ScraperTarget{
+				Type:     influxdb.JSONScraperType,
+				URL:      ts.URL,
+				OrgID:    *orgID,
+				BucketID: *bucketID,
+				JSON:     c.config,
+			})
+			if (err != nil) != c.hasErr {
+				t.Fatalf("got error %v, want error %t", err, c.hasErr)
+			}
+			if diff := cmp.Diff(c.ms, collected.MetricsSlice, metricsCmpOption); diff != "" {
+				t.Fatalf("unexpected metrics -want/+got:\n%s", diff)
+			}
+			for i, tm := range c.times {
+				if !collected.MetricsSlice[i].Timestamp.Equal(tm) {
+					t.Errorf("got metric %d at %v, want %v", i, collected.MetricsSlice[i].Timestamp, tm)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package gather
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// openMetricsAccept prefers OpenMetrics to the prometheus text format.
+const openMetricsAccept = "application/openmetrics-text; version=0.0.1,text/plain;version=0.0.4;q=0.5"
+
+// openMetricsScraper handles parsing OpenMetrics metrics and their exemplars.
+// Targets answering in a prometheus format are parsed like prometheus targets.
+// implements Scraper interfaces.
+type openMetricsScraper struct {
+	prometheusScraper
+}
+
+// Gather parse metrics from a scraper target url.
+func (p *openMetricsScraper) Gather(ctx context.Context, target influxdb.ScraperTarget) (collected MetricsCollection, err error) {
+	resp, err := get(ctx, p.secrets, target, target.URL, openMetricsAccept)
+	if err != nil {
+		return collected, err
+	}
+	defer resp.Body.Close()
+
+	if mediatype, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil && mediatype == "application/openmetrics-text" {
+		return parseOpenMetrics(resp.Body, target, time.Now())
+	}
+	return p.parse(resp.Body, resp.Header, target)
+}
+
+// openMetricsSuffixes are the suffixes of the sample names of the metric
+// families of each type.
+var openMetricsSuffixes = map[string][]string{
+	"_total":   {"counter"},
+	"_created": {"counter", "histogram", "summary"},
+	"_bucket":  {"histogram", "gaugehistogram"},
+	"_count":   {"histogram", "summary"},
+	"_sum":     {"histogram", "summary"},
+	"_gcount":  {"gaugehistogram"},
+	"_gsum":    {"gaugehistogram"},
+	"_info":    {"info"},
+}
+
+// openMetricsSample is a sample line: name{labels} value [timestamp] [# exemplar].
+type openMetricsSample struct {
+	name      string
+	labels    map[string]string
+	value     float64
+	timestamp time.Time
+	exemplar  *openMetricsExemplar
+}
+
+// openMetricsExemplar is the exemplar of a sample: # {labels} value [timestamp].
+type openMetricsExemplar struct {
+	labels    map[string]string
+	value     float64
+	timestamp time.Time
+}
+
+// parseOpenMetrics reads the metrics of the OpenMetrics text format.
+// The samples of a series, such as the buckets, count and sum of a histogram,
+// are read as the fields of one metric. Counters and infos are named after
+// their samples, like the prometheus scraper names them. Exemplars are read as
+// <family>_exemplar metrics tagged with the labels of their sample.
+func parseOpenMetrics(r io.Reader, target influxdb.ScraperTarget, now time.Time) (collected MetricsCollection, err error) {
+	families := make(map[string]string)
+	series := make(map[string]*Metrics)
+	var (
+		ms        []*Metrics
+		exemplars []Metrics
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if line == "# EOF" {
+			break
+		}
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			// HELP, UNIT and comments do not change the metrics
+			if fields := strings.Fields(line[1:]); len(fields) >= 3 && fields[0] == "TYPE" {
+				families[fields[1]] = fields[2]
+			}
+			continue
+		}
+
+		s, err := parseOpenMetricsSample(line)
+		if err != nil {
+			return collected, fmt.Errorf("reading openmetrics format failed: line %d: %s", n, err)
+		}
+
+		family, typ, suffix := lookupOpenMetricsFamily(families, s.name)
+		field, groupBy := openMetricsField(typ, suffix, s.labels)
+		tags := make(map[string]string, len(s.labels))
+		for k, v := range s.labels {
+			if k != groupBy {
+				tags[k] = v
+			}
+		}
+
+		key := seriesKey(family, tags)
+		m, ok := series[key]
+		if !ok {
+			m = &Metrics{
+				Name:   family,
+				Tags:   tags,
+				Fields: make(map[string]interface{}),
+				Type:   openMetricsType(typ),
+			}
+			series[key] = m
+			ms = append(ms, m)
+		}
+		if (typ == "counter" || typ == "info") && suffix != "_created" {
+			m.Name = s.name
+		}
+		if m.Timestamp.IsZero() {
+			m.Timestamp = s.timestamp
+		}
+		if !math.IsNaN(s.value) {
+			m.Fields[field] = s.value
+		}
+
+		if e := s.exemplar; e != nil {
+			fields := make(map[string]interface{}, len(e.labels)+1)
+			for k, v := range e.labels {
+				fields[k] = v
+			}
+			fields["exemplar"] = e.value
+			tm := e.timestamp
+			if tm.IsZero() {
+				tm = s.timestamp
+			}
+			exemplars = append(exemplars, Metrics{
+				Name:      family + "_exemplar",
+				Tags:      s.labels,
+				Fields:    fields,
+				Timestamp: tm,
+				Type:      openMetricsType(typ),
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return collected, fmt.Errorf("reading openmetrics format failed: %s", err)
+	}
+
+	result := make(MetricsSlice, 0, len(ms)+len(exemplars))
+	for _, m := range ms {
+		if len(m.Fields) > 0 {
+			result = append(result, *m)
+		}
+	}
+	result = append(result, exemplars...)
+	for i := range result {
+		if result[i].Timestamp.IsZero() {
+			result[i].Timestamp = now
+		}
+	}
+
+	return MetricsCollection{
+		MetricsSlice: result,
+		OrgID:        target.OrgID,
+		BucketID:     target.BucketID,
+	}, nil
+}
+
+// lookupOpenMetricsFamily returns the family a sample belongs to, its type and
+// the suffix of the sample name. Samples of no declared family are unknown.
+func lookupOpenMetricsFamily(families map[string]string, name string) (family, typ, suffix string) {
+	for suffix, types := range openMetricsSuffixes {
+		if !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		family := strings.TrimSuffix(name, suffix)
+		typ, ok := families[family]
+		if !ok {
+			continue
+		}
+		for _, t := range types {
+			if t == typ {
+				return family, typ, suffix
+			}
+		}
+	}
+	if typ, ok := families[name]; ok {
+		return name, typ, ""
+	}
+	return name, "unknown", ""
+}
+
+// openMetricsField returns the field of a sample in the metric of its series,
+// and the label that tells the sample apart from the others of the series.
+func openMetricsField(typ, suffix string, labels map[string]string) (field, groupBy string) {
+	switch suffix {
+	case "_created":
+		return "created", ""
+	case "_count", "_gcount":
+		return "count", ""
+	case "_sum", "_gsum":
+		return "sum", ""
+	case "_bucket":
+		return formatBound(labels["le"]), "le"
+	}
+
+	switch typ {
+	case "counter":
+		return "counter", ""
+	case "gauge", "info", "stateset":
+		return "gauge", ""
+	case "summary":
+		return formatBound(labels["quantile"]), "quantile"
+	default:
+		return "value", ""
+	}
+}
+
+// formatBound formats a bucket bound or quantile like the prometheus scraper.
+func formatBound(s string) string {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return s
+	}
+	return fmt.Sprint(f)
+}
+
+func openMetricsType(typ string) MetricType {
+	switch typ {
+	case "counter":
+		return MetricTypeCounter
+	case "gauge", "info", "stateset":
+		return MetricTypeGauge
+	case "summary":
+		return MetricTypeSummary
+	case "histogram", "gaugehistogram":
+		return MetricTypeHistogrm
+	default:
+		return MetricTypeUntyped
+	}
+}
+
+// seriesKey identifies the series of a family by its tags.
+func seriesKey(family string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(family)
+	for _, k := range keys {
+		b.WriteByte(0)
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(tags[k])
+	}
+	return b.String()
+}
+
+func parseOpenMetricsSample(line string) (s openMetricsSample, err error) {
+	i := strings.IndexAny(line, "{ ")
+	if i <= 0 {
+		return s, fmt.Errorf("invalid sample %q", line)
+	}
+	s.name, line = line[:i], line[i:]
+
+	s.labels = make(map[string]string)
+	if line[0] == '{' {
+		if s.labels, line, err = parseLabelSet(line); err != nil {
+			return s, err
+		}
+	}
+	if !strings.HasPrefix(line, " ") {
+		return s, fmt.Errorf("missing value of sample %s", s.name)
+	}
+
+	var exemplar string
+	if i := strings.Index(line, " # "); i >= 0 {
+		line, exemplar = line[:i], strings.TrimLeft(line[i+3:], " ")
+	}
+	if s.value, s.timestamp, err = parseValue(line); err != nil {
+		return s, fmt.Errorf("sample %s: %s", s.name, err)
+	}
+
+	if exemplar != "" {
+		if exemplar[0] != '{' {
+			return s, fmt.Errorf("invalid exemplar of sample %s", s.name)
+		}
+		e := &openMetricsExemplar{}
+		if e.labels, exemplar, err = parseLabelSet(exemplar); err != nil {
+			return s, err
+		}
+		if e.value, e.timestamp, err = parseValue(exemplar); err != nil {
+			return s, fmt.Errorf("exemplar of sample %s: %s", s.name, err)
+		}
+		s.exemplar = e
+	}
+	return s, nil
+}
+
+// parseValue parses a value followed by an optional timestamp in seconds.
+func parseValue(s string) (value float64, timestamp time.Time, err error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return 0, timestamp, fmt.Errorf("invalid value %q", strings.TrimSpace(s))
+	}
+	if value, err = strconv.ParseFloat(fields[0], 64); err != nil {
+		return 0, timestamp, fmt.Errorf("invalid value %q", fields[0])
+	}
+	if len(fields) == 2 {
+		ts, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || math.IsNaN(ts) || math.IsInf(ts, 0) {
+			return 0, timestamp, fmt.Errorf("invalid timestamp %q", fields[1])
+		}
+		sec, frac := math.Modf(ts)
+		timestamp = time.Unix(int64(sec), int64(frac*1e9))
+	}
+	return value, timestamp, nil
+}
+
+// parseLabelSet parses the {name="value",...} at the start of s and returns
+// the rest of s.
+func parseLabelSet(s string) (labels map[string]string, rest string, err error) {
+	labels = make(map[string]string)
+	s = s[1:]
+	for {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			return nil, "", fmt.Errorf("unterminated label set")
+		}
+		if s[0] == '}' {
+			return labels, s[1:], nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return nil, "", fmt.Errorf("invalid label set")
+		}
+		name := strings.TrimSpace(s[:eq])
+		s = s[eq+1:]
+		if s == "" || s[0] != '"' {
+			return nil, "", fmt.Errorf("value of label %s must be quoted", name)
+		}
+
+		var b strings.Builder
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				if s[i] == 'n' {
+					b.WriteByte('\n')
+				} else {
+					b.WriteByte(s[i])
+				}
+				continue
+			}
+			b.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return nil, "", fmt.Errorf("unterminated value of label %s", name)
+		}
+		labels[name] = b.String()
+
+		s = strings.TrimLeft(s[i+1:], " ")
+		if strings.HasPrefix(s, ",") {
+			s = s[1:]
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package gather
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+)
+
+const sampleOpenMetrics = `# TYPE http_requests counter
+# HELP http_requests Number of requests.
+http_requests_total{code="200"} 1027 # {trace_id="3f2a"} 1 1520879607.789
+http_requests_created{code="200"} 1520430000.123
+http_requests_total{code="500"} 3
+# TYPE request_seconds histogram
+# UNIT request_seconds seconds
+request_seconds_bucket{le="0.1"} 8 # {trace_id="KOO5S4vxi0o"} 0.067
+request_seconds_bucket{le="1.0"} 10
+request_seconds_bucket{le="+Inf"} 11
+request_seconds_count 11
+request_seconds_sum 3.2
+# TYPE rpc_seconds summary
+rpc_seconds{quantile="0.5"} 0.05
+rpc_seconds{quantile="0.9"} 0.25
+rpc_seconds_count 100
+rpc_seconds_sum 8.5
+# TYPE temperature gauge
+temperature{room="a \"b\""} 21.5 1520879607
+# TYPE build info
+build_info{version="1.2.3"} 1
+# TYPE feature stateset
+feature{feature="a"} 1
+feature{feature="b"} 0
+untyped_sample 5
+# EOF
+`
+
+func TestParseOpenMetrics(t *testing.T) {
+	now := time.Now()
+	collected, err := parseOpenMetrics(strings.NewReader(sampleOpenMetrics), influxdb.ScraperTarget{
+		OrgID:    *orgID,
+		BucketID: *bucketID,
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collected.OrgID != *orgID || collected.BucketID != *bucketID {
+		t.Fatalf("got org %s and bucket %s", collected.OrgID, collected.BucketID)
+	}
+
+	want := MetricsSlice{
+		{
+			Name:   "http_requests_total",
+			Type:   MetricTypeCounter,
+			Tags:   map[string]string{"code": "200"},
+			Fields: map[string]interface{}{"counter": 1027.0, "created": 1520430000.123},
+		},
+		{
+			Name:   "http_requests_total",
+			Type:   MetricTypeCounter,
+			Tags:   map[string]string{"code": "500"},
+			Fields: map[string]interface{}{"counter": 3.0},
+		},
+		{
+			Name: "request_seconds",
+			Type: MetricTypeHistogrm,
+			Tags: map[string]string{},
+			Fields: map[string]interface{}{
+				"0.1":   8.0,
+				"1":     10.0,
+				"+Inf":  11.0,
+				"count": 11.0,
+				"sum":   3.2,
+			},
+		},
+		{
+			Name:   "rpc_seconds",
+			Type:   MetricTypeSummary,
+			Tags:   map[string]string{},
+			Fields: map[string]interface{}{"0.5": 0.05, "0.9": 0.25, "count": 100.0, "sum": 8.5},
+		},
+		{
+			Name:   "temperature",
+			Type:   MetricTypeGauge,
+			Tags:   map[string]string{"room": `a "b"`},
+			Fields: map[string]interface{}{"gauge": 21.5},
+		},
+		{
+			Name:   "build_info",
+			Type:   MetricTypeGauge,
+			Tags:   map[string]string{"version": "1.2.3"},
+			Fields: map[string]interface{}{"gauge": 1.0},
+		},
+		{
+			Name:   "feature",
+			Type:   MetricTypeGauge,
+			Tags:   map[string]string{"feature": "a"},
+			Fields: map[string]interface{}{"gauge": 1.0},
+		},
+		{
+			Name:   "feature",
+			Type:   MetricTypeGauge,
+			Tags:   map[string]string{"feature": "b"},
+			Fields: map[string]interface{}{"gauge": 0.0},
+		},
+		{
+			Name:   "untyped_sample",
+			Type:   MetricTypeUntyped,
+			Tags:   map[string]string{},
+			Fields: map[string]interface{}{"value": 5.0},
+		},
+		{
+			Name:   "http_requests_exemplar",
+			Type:   MetricTypeCounter,
+			Tags:   map[string]string{"code": "200"},
+			Fields: map[string]interface{}{"exemplar": 1.0, "trace_id": "3f2a"},
+		},
+		{
+			Name:   "request_seconds_exemplar",
+			Type:   MetricTypeHistogrm,
+			Tags:   map[string]string{"le": "0.1"},
+			Fields: map[string]interface{}{"exemplar": 0.067, "trace_id": "KOO5S4vxi0o"},
+		},
+	}
+	if diff := cmp.Diff(want, collected.MetricsSlice, metricsCmpOption); diff != "" {
+		t.Fatalf("unexpected metrics -want/+got:\n%s", diff)
+	}
+
+	// timestamps default to the time of the scrape
+	for _, m := range collected.MetricsSlice {
+		switch m.Name {
+		case "temperature":
+			if !m.Timestamp.Equal(time.Unix(1520879607, 0)) {
+				t.Errorf("got temperature at %v", m.Timestamp)
+			}
+		case "http_requests_exemplar":
+			if !m.Timestamp.Round(time.Millisecond).Equal(time.Unix(1520879607, 789000000)) {
+				t.Errorf("got exemplar at %v", m.Timestamp)
+			}
+		default:
+			if !m.Timestamp.Equal(now) {
+				t.Errorf("got %s at %v, want %v", m.Name, m.Timestamp, now)
+			}
+		}
+	}
+}
+
+func TestParseOpenMetrics_Errors(t *testing.T) {
+	for _, s := range []string{
+		`metric`,
+		`metric{label="value"}`,
+		`metric{label=value} 1`,
+		`metric{label="value} 1`,
+		`metric one`,
+		`metric 1 yesterday`,
+		`metric 1 # trace 1`,
+		`metric 1 # {trace_id="a"}`,
+	} {
+		if _, err := parseOpenMetrics(strings.NewReader(s), influxdb.ScraperTarget{}, time.Now()); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
+
+func TestOpenMetricsScraper(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Accept"), "application/openmetrics-text") {
+			w.Header().Set("Content-Type", "application/openmetrics-text; version=0.0.1; charset=utf-8")
+			w.Write([]byte("# TYPE go_goroutines gauge\ngo_goroutines 36\n# EOF\n"))
+			return
+		}
+		w.WriteHeader(http.StatusNotAcceptable)
+	}))
+	defer ts.Close()
+
+	scraper := new(openMetricsScraper)
+	collected, err := scraper.Gather(context.Background(), influxdb.ScraperTarget{
+		Type: influxdb.OpenMetricsScraperType,
+		URL:  ts.URL + "/metrics",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := MetricsSlice{{
+		Name:   "go_goroutines",
+		Type:   MetricTypeGauge,
+		Tags:   map[string]string{},
+		Fields: map[string]interface{}{"gauge": 36.0},
+	}}
+	if diff := cmp.Diff(want, collected.MetricsSlice, metricsCmpOption); diff != "" {
+		t.Fatalf("unexpected metrics -want/+got:\n%s", diff)
+	}
+
+	// prometheus endpoints answer with the prometheus text format
+	prom := httptest.NewServer(&mockHTTPHandler{
+		responseMap: map[string]string{
+			"/metrics": sampleRespSmall,
+		},
+	})
+	defer prom.Close()
+
+	collected, err = scraper.Gather(context.Background(), influxdb.ScraperTarget{
+		Type: influxdb.OpenMetricsScraperType,
+		URL:  prom.URL + "/metrics",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, collected.MetricsSlice, metricsCmpOption); diff != "" {
+		t.Fatalf("unexpected metrics -want/+got:\n%s", diff)
+	}
+}
@@ -22,7 +22,8 @@ const (
 // Scheduler is struct to run scrape jobs.
 type Scheduler struct {
 	Targets influxdb.ScraperTargetStoreService
-	// Interval is between each metrics gathering event.
+	// Interval is between each metrics gathering event of the targets
+	// without an interval.
 	Interval time.Duration
 	// Timeout is the maxisium time duration allowed by each TCP request
 	Timeout time.Duration
@@ -33,6 +34,13 @@ type Scheduler struct {
 	log *zap.Logger
 
 	gather chan struct{}
+	// scheduled is when each target was last requested to be scraped.
+	scheduled map[influxdb.ID]time.Time
+	// next is when the next target is due, before which the targets are
+	// not listed. New targets are listed within Interval.
+	next time.Time
+
+	health *healthRecorder
 }
 
 // NewScheduler creates a new Scheduler and subscriptions for scraper jobs.
@@ -40,8 +48,10 @@ func NewScheduler(
 	log *zap.Logger,
 	numScrapers int,
 	targets influxdb.ScraperTargetStoreService,
+	health influxdb.ScraperTargetHealthService,
 	p nats.Publisher,
 	s nats.Subscriber,
+	secrets influxdb.SecretService,
 	interval time.Duration,
 	timeout time.Duration,
 ) (*Scheduler, error) {
@@ -58,12 +68,18 @@ func NewScheduler(
 		Publisher: p,
 		log:       log,
 		gather:    make(chan struct{}, 100),
+		scheduled: make(map[influxdb.ID]time.Time),
+	}
+	if health != nil {
+		scheduler.health = newHealthRecorder(log, health)
 	}
 
 	for i := 0; i < numScrapers; i++ {
 		err := s.Subscribe(promTargetSubject, "metrics", &handler{
-			Scraper:   new(prometheusScraper),
+			Scraper:   newScrapers(secrets),
 			Publisher: p,
+			Health:    scheduler.health,
+			Timeout:   timeout,
 			log:       log,
 		})
 		if err != nil {
@@ -75,14 +91,22 @@ func NewScheduler(
 }
 
 // Run will retrieve scraper targets from the target storage,
-// and publish them to nats job queue for gather.
+// and publish them to nats job queue for gather when their interval elapsed.
 func (s *Scheduler) Run(ctx context.Context) error {
+	// the targets are checked often enough to honor the shortest interval
+	tick := influxdb.MinScraperTargetInterval
+	if s.Interval < tick {
+		tick = s.Interval
+	}
+
 	go func(s *Scheduler, ctx context.Context) {
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(s.Interval): // TODO: change to ticker because of garbage collection
+			case <-ticker.C:
 				s.gather <- struct{}{}
 			}
 		}
@@ -91,6 +115,9 @@ func (s *Scheduler) Run(ctx context.Context) error {
 }
 
 func (s *Scheduler) run(ctx context.Context) error {
+	if s.health != nil {
+		go s.health.run(ctx)
+	}
 	for {
 		select {
 		case <-ctx.Done():
@@ -102,6 +129,10 @@ func (s *Scheduler) run(ctx context.Context) error {
 }
 
 func (s *Scheduler) doGather(ctx context.Context) {
+	if time.Now().Before(s.next) {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
 	defer cancel()
 	span, ctx := tracing.StartSpanFromContext(ctx)
@@ -113,12 +144,34 @@ func (s *Scheduler) doGather(ctx context.Context) {
 		tracing.LogError(span, err)
 		return
 	}
+	now := time.Now()
+	next := now.Add(s.Interval)
+	scheduled := make(map[influxdb.ID]time.Time, len(targets))
 	for _, target := range targets {
+		interval := s.Interval
+		if target.Interval != nil {
+			interval = target.Interval.Duration
+		}
+		if last, ok := s.scheduled[target.ID]; ok && now.Sub(last) < interval {
+			scheduled[target.ID] = last
+			if due := last.Add(interval); due.Before(next) {
+				next = due
+			}
+			continue
+		}
+		scheduled[target.ID] = now
+		if due := now.Add(interval); due.Before(next) {
+			next = due
+		}
+
 		if err := requestScrape(target, s.Publisher); err != nil {
 			s.log.Error("JSON encoding error", zap.Error(err))
 			tracing.LogError(span, err)
 		}
 	}
+	// forget the removed targets
+	s.scheduled = scheduled
+	s.next = next
 }
 
 func requestScrape(t influxdb.ScraperTarget, publisher nats.Publisher) error {
@@ -127,9 +180,8 @@ func requestScrape(t influxdb.ScraperTarget, publisher nats.Publisher) error {
 	if err != nil {
 		return err
 	}
-	switch t.Type {
-	case influxdb.PrometheusScraperType:
-		return publisher.Publish(promTargetSubject, buf)
+	if !influxdb.ValidScraperType(string(t.Type)) {
+		return fmt.Errorf("unsupported target scrape type: %s", t.Type)
 	}
-	return fmt.Errorf("unsupported target scrape type: %s", t.Type)
+	return publisher.Publish(promTargetSubject, buf)
 }
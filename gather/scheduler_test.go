@@ -2,6 +2,8 @@ package gather
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"net/http/httptest"
 	"os"
 	"testing"
@@ -12,6 +14,7 @@ import (
 	influxlogger "github.com/influxdata/influxdb/v2/logger"
 	"github.com/influxdata/influxdb/v2/mock"
 	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+	"go.uber.org/zap/zaptest"
 )
 
 func TestScheduler(t *testing.T) {
@@ -47,7 +50,7 @@ func TestScheduler(t *testing.T) {
 		Recorder: storage,
 	})
 
-	scheduler, err := NewScheduler(logger, 10, storage, publisher, subscriber, time.Millisecond, time.Microsecond)
+	scheduler, err := NewScheduler(logger, 10, storage, storage, publisher, subscriber, nil, time.Millisecond, time.Second)
 
 	go func() {
 		err = scheduler.run(ctx)
@@ -88,9 +91,77 @@ func TestScheduler(t *testing.T) {
 			t.Fatalf("scraper parse metrics want %v, got %v", want, v)
 		}
 	}
+
+	// the health of the first scrape of a target is flushed right away
+	var (
+		health influxdb.ScraperTargetHealth
+		ok     bool
+	)
+	for deadline := time.Now().Add(time.Second); !ok && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		storage.RLock()
+		health, ok = storage.Health[storage.Targets[0].ID]
+		storage.RUnlock()
+	}
+	if !ok || health.LastSuccess == nil || health.LastError != "" {
+		t.Fatalf("unexpected scraper target health %+v", health)
+	}
 	ts.Close()
 }
 
+type recordingPublisher struct {
+	targets []influxdb.ScraperTarget
+}
+
+func (p *recordingPublisher) Publish(subject string, r io.Reader) error {
+	var target influxdb.ScraperTarget
+	if err := json.NewDecoder(r).Decode(&target); err != nil {
+		return err
+	}
+	p.targets = append(p.targets, target)
+	return nil
+}
+
+func TestScheduler_TargetIntervals(t *testing.T) {
+	var (
+		everyTick = influxdbtesting.MustIDBase16("3a0d0a6365646120")
+		everyHour = influxdbtesting.MustIDBase16("3a0d0a6365646121")
+	)
+	storage := &mockStorage{
+		Targets: []influxdb.ScraperTarget{
+			{
+				ID:   everyTick,
+				Type: influxdb.PrometheusScraperType,
+				URL:  "http://localhost:9100/metrics",
+			},
+			{
+				ID:       everyHour,
+				Type:     influxdb.PrometheusScraperType,
+				URL:      "http://localhost:9101/metrics",
+				Interval: &influxdb.Duration{Duration: time.Hour},
+			},
+		},
+	}
+	publisher := &recordingPublisher{}
+	_, subscriber := mock.NewNats()
+
+	scheduler, err := NewScheduler(zaptest.NewLogger(t), 1, storage, storage, publisher, subscriber, nil, time.Millisecond, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		scheduler.doGather(context.Background())
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	scraped := make(map[influxdb.ID]int)
+	for _, target := range publisher.targets {
+		scraped[target.ID]++
+	}
+	if scraped[everyTick] != 3 || scraped[everyHour] != 1 {
+		t.Fatalf("got %d scrapes of the target without interval and %d of the hourly target, want 3 and 1", scraped[everyTick], scraped[everyHour])
+	}
+}
+
 const sampleRespSmall = `
 # HELP go_goroutines Number of goroutines that currently exist.
 # TYPE go_goroutines gauge
@@ -2,6 +2,7 @@ package gather
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/influxdata/influxdb/v2"
 )
@@ -10,3 +11,35 @@ import (
 type Scraper interface {
 	Gather(ctx context.Context, target influxdb.ScraperTarget) (collected MetricsCollection, err error)
 }
+
+// scrapers gathers metrics with the scraper of the type of each target.
+// implements Scraper interfaces.
+type scrapers map[string]Scraper
+
+// newScrapers returns the scrapers of the supported target types, loading
+// the credentials of the targets from secrets.
+func newScrapers(secrets influxdb.SecretService) scrapers {
+	prometheus := prometheusScraper{secrets: secrets}
+	return scrapers{
+		influxdb.PrometheusScraperType:  &prometheus,
+		influxdb.OpenMetricsScraperType: &openMetricsScraper{prometheus},
+		influxdb.JSONScraperType:        &jsonScraper{secrets: secrets},
+		influxdb.InfluxDBScraperType:    &influxdbScraper{prometheus},
+	}
+}
+
+// Gather metrics from a target with the scraper of its type, keeping those
+// selected by the filter of the target.
+func (s scrapers) Gather(ctx context.Context, target influxdb.ScraperTarget) (collected MetricsCollection, err error) {
+	scraper, ok := s[string(target.Type)]
+	if !ok {
+		return collected, fmt.Errorf("unsupported target scrape type: %s", target.Type)
+	}
+
+	collected, err = scraper.Gather(ctx, target)
+	if err != nil || target.Filter == nil {
+		return collected, err
+	}
+	collected.MetricsSlice = newMetricFilter(target.Filter).Filter(collected.MetricsSlice)
+	return collected, nil
+}
@@ -197,6 +197,7 @@ type mockStorage struct {
 	TotalGatherJobs chan struct{}
 	Metrics         map[time.Time]Metrics
 	Targets         []influxdb.ScraperTarget
+	Health          map[influxdb.ID]influxdb.ScraperTargetHealth
 }
 
 func (s *mockStorage) Record(collected MetricsCollection) error {
@@ -275,6 +276,19 @@ func (s *mockStorage) UpdateTarget(ctx context.Context, update *influxdb.Scraper
 	return update, err
 }
 
+func (s *mockStorage) UpdateTargetsHealth(ctx context.Context, healths map[influxdb.ID]influxdb.ScraperTargetHealth) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.Health == nil {
+		s.Health = make(map[influxdb.ID]influxdb.ScraperTargetHealth)
+	}
+	for id, health := range healths {
+		s.Health[id] = health
+	}
+	return nil
+}
+
 type mockHTTPHandler struct {
 	unauthorized bool
 	noContent    bool
@@ -8,6 +8,7 @@ import (
 	"github.com/influxdata/influxdb/v2/authorizer"
 	"github.com/influxdata/influxdb/v2/chronograf/server"
 	"github.com/influxdata/influxdb/v2/dbrp"
+	"github.com/influxdata/influxdb/v2/http/legacy"
 	"github.com/influxdata/influxdb/v2/http/metric"
 	"github.com/influxdata/influxdb/v2/influxql"
 	"github.com/influxdata/influxdb/v2/kit/feature"
@@ -59,7 +60,11 @@ type APIBackend struct {
 	DeleteService                   influxdb.DeleteService
 	BackupService                   influxdb.BackupService
 	KVBackupService                 influxdb.KVBackupService
+	RestoreService                  influxdb.RestoreService
+	ShardService                    influxdb.ShardService
+	RunningQueryService             influxdb.RunningQueryService
 	AuthorizationService            influxdb.AuthorizationService
+	V1AuthorizationService          legacy.V1AuthorizationService
 	OnboardingService               influxdb.OnboardingService
 	DBRPService                     influxdb.DBRPMappingServiceV2
 	BucketService                   influxdb.BucketService
@@ -152,6 +157,14 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	fluxBackend := NewFluxBackend(b.Logger.With(zap.String("handler", "query")), b)
 	h.Mount(prefixQuery, NewFluxHandler(b.Logger, fluxBackend))
 
+	prometheusBackend := NewPrometheusBackend(b.Logger.With(zap.String("handler", "prometheus")), b)
+	prometheusBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	h.Mount(prefixPrometheus, NewPrometheusHandler(b.Logger, prometheusBackend))
+
+	runningQueryBackend := NewRunningQueryBackend(b.Logger.With(zap.String("handler", "running_query")), b)
+	runningQueryBackend.RunningQueryService = authorizer.NewRunningQueryService(b.RunningQueryService)
+	h.Mount(prefixRunningQueries, NewRunningQueryHandler(b.Logger, runningQueryBackend))
+
 	notificationEndpointBackend := NewNotificationEndpointBackend(b.Logger.With(zap.String("handler", "notificationEndpoint")), b)
 	notificationEndpointBackend.NotificationEndpointService = authorizer.NewNotificationEndpointService(b.NotificationEndpointService,
 		b.UserResourceMappingService, b.OrganizationService)
@@ -196,6 +209,14 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	backupBackend.BackupService = authorizer.NewBackupService(backupBackend.BackupService)
 	h.Mount(prefixBackup, NewBackupHandler(backupBackend))
 
+	restoreBackend := NewRestoreBackend(b)
+	restoreBackend.RestoreService = authorizer.NewRestoreService(restoreBackend.RestoreService)
+	h.Mount(prefixRestore, NewRestoreHandler(restoreBackend))
+
+	shardBackend := NewShardBackend(b.Logger.With(zap.String("handler", "shard")), b)
+	shardBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	h.Mount(prefixShards, NewShardHandler(b.Logger, shardBackend))
+
 	h.Mount(dbrp.PrefixDBRP, dbrp.NewHTTPHandler(b.Logger, b.DBRPService, b.OrganizationService))
 
 	writeBackend := NewWriteBackend(b.Logger.With(zap.String("handler", "write")), b)
@@ -231,6 +252,7 @@ var apiLinks = map[string]interface{}{
 	"notificationRules":     "/api/v2/notificationRules",
 	"notificationEndpoints": "/api/v2/notificationEndpoints",
 	"orgs":                  "/api/v2/orgs",
+	"queries":               "/api/v2/queries",
 	"query": map[string]string{
 		"self":        "/api/v2/query",
 		"ast":         "/api/v2/query/ast",
@@ -238,6 +260,7 @@ var apiLinks = map[string]interface{}{
 		"suggestions": "/api/v2/query/suggestions",
 	},
 	"setup":    "/api/v2/setup",
+	"shards":   "/api/v2/shards",
 	"signin":   "/api/v2/signin",
 	"signout":  "/api/v2/signout",
 	"sources":  "/api/v2/sources",
@@ -1,7 +1,10 @@
 package http
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -29,6 +32,9 @@ type BackupBackend struct {
 
 	BackupService   influxdb.BackupService
 	KVBackupService influxdb.KVBackupService
+	BucketService   influxdb.BucketService
+	DBRPService     influxdb.DBRPMappingServiceV2
+	ShardService    influxdb.ShardService
 }
 
 // NewBackupBackend returns a new instance of BackupBackend.
@@ -39,6 +45,9 @@ func NewBackupBackend(b *APIBackend) *BackupBackend {
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		BackupService:    b.BackupService,
 		KVBackupService:  b.KVBackupService,
+		BucketService:    b.BucketService,
+		DBRPService:      b.DBRPService,
+		ShardService:     b.ShardService,
 	}
 }
 
@@ -50,6 +59,9 @@ type BackupHandler struct {
 
 	BackupService   influxdb.BackupService
 	KVBackupService influxdb.KVBackupService
+	BucketService   influxdb.BucketService
+	DBRPService     influxdb.DBRPMappingServiceV2
+	ShardService    influxdb.ShardService
 }
 
 const (
@@ -73,6 +85,9 @@ func NewBackupHandler(b *BackupBackend) *BackupHandler {
 		Logger:           b.Logger,
 		BackupService:    b.BackupService,
 		KVBackupService:  b.KVBackupService,
+		BucketService:    b.BucketService,
+		DBRPService:      b.DBRPService,
+		ShardService:     b.ShardService,
 	}
 
 	h.HandlerFunc(http.MethodPost, prefixBackup, h.handleCreate)
@@ -92,7 +107,21 @@ func (h *BackupHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	id, files, err := h.BackupService.CreateBackup(ctx)
+	// The backup contains all changes made before it started, which is what
+	// an incremental backup following it is based on.
+	createdAt := time.Now().UTC()
+
+	var filter influxdb.BackupFilter
+	if err := json.NewDecoder(r.Body).Decode(&filter); err != nil && err != io.EOF {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid backup request body",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	id, files, err := h.BackupService.CreateBackup(ctx, filter)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
@@ -100,32 +129,54 @@ func (h *BackupHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 
 	internalBackupPath := h.BackupService.InternalBackupPath(id)
 
-	boltPath := filepath.Join(internalBackupPath, bolt.DefaultFilename)
-	boltFile, err := os.OpenFile(boltPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0660)
+	buckets, err := h.findBuckets(ctx, filter)
 	if err != nil {
 		err = multierr.Append(err, os.RemoveAll(internalBackupPath))
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
 
-	if err = h.KVBackupService.Backup(ctx, boltFile); err != nil {
-		err = multierr.Append(err, os.RemoveAll(internalBackupPath))
-		h.HandleHTTPError(ctx, err, w)
-		return
+	// A selective backup only contains the metadata of its buckets, which is in the manifest.
+	if !filter.Selective() {
+		boltPath := filepath.Join(internalBackupPath, bolt.DefaultFilename)
+		boltFile, err := os.OpenFile(boltPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0660)
+		if err != nil {
+			err = multierr.Append(err, os.RemoveAll(internalBackupPath))
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+
+		err = h.KVBackupService.Backup(ctx, boltFile)
+		if err = multierr.Append(err, boltFile.Close()); err != nil {
+			err = multierr.Append(err, os.RemoveAll(internalBackupPath))
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+
+		files = append(files, bolt.DefaultFilename)
+
+		credsExist, err := h.backupCredentials(internalBackupPath)
+
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+
+		if credsExist {
+			files = append(files, fs.DefaultConfigsFile)
+		}
 	}
 
-	files = append(files, bolt.DefaultFilename)
-
-	credsExist, err := h.backupCredentials(internalBackupPath)
-
-	if err != nil {
+	manifest := influxdb.BackupManifest{
+		CreatedAt: createdAt,
+		Filter:    filter,
+	}
+	if err := h.backupManifest(ctx, internalBackupPath, &manifest, buckets, files); err != nil {
+		err = multierr.Append(err, os.RemoveAll(internalBackupPath))
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
-
-	if credsExist {
-		files = append(files, fs.DefaultConfigsFile)
-	}
+	files = append(files, influxdb.BackupManifestFilename)
 
 	b := backup{
 		ID:    id,
@@ -138,6 +189,111 @@ func (h *BackupHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// findBuckets returns the buckets matching the backup filter.
+func (h *BackupHandler) findBuckets(ctx context.Context, filter influxdb.BackupFilter) ([]*influxdb.Bucket, error) {
+	if !filter.Selective() {
+		var buckets []*influxdb.Bucket
+		opt := influxdb.FindOptions{Limit: influxdb.MaxPageSize}
+		for {
+			page, _, err := h.BucketService.FindBuckets(ctx, influxdb.BucketFilter{}, opt)
+			if err != nil {
+				return nil, err
+			}
+			buckets = append(buckets, page...)
+			if len(page) < opt.Limit {
+				return buckets, nil
+			}
+			opt.Offset += len(page)
+		}
+	}
+
+	buckets := make([]*influxdb.Bucket, 0, len(filter.BucketIDs))
+	for _, id := range filter.BucketIDs {
+		b, err := h.BucketService.FindBucketByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, nil
+}
+
+// backupManifest writes the manifest describing the files, buckets and shards of the backup.
+func (h *BackupHandler) backupManifest(ctx context.Context, internalBackupPath string, manifest *influxdb.BackupManifest, buckets []*influxdb.Bucket, files []string) error {
+	manifest.Files = make([]influxdb.BackupFile, 0, len(files))
+	for _, name := range files {
+		f, err := newBackupFile(internalBackupPath, name)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, f)
+	}
+
+	manifest.Buckets = make([]influxdb.BucketBackup, 0, len(buckets))
+	for _, b := range buckets {
+		shards, err := h.ShardService.FindShards(ctx, b.ID)
+		if err != nil {
+			return err
+		}
+		dbrps, _, err := h.DBRPService.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+			OrgID:    &b.OrgID,
+			BucketID: &b.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		bb := influxdb.BucketBackup{
+			Bucket: *b,
+			DBRPs:  make([]influxdb.DBRPMappingV2, 0, len(dbrps)),
+			Shards: make([]influxdb.ShardBackup, 0, len(shards)),
+		}
+		for _, m := range dbrps {
+			bb.DBRPs = append(bb.DBRPs, *m)
+		}
+		for _, s := range shards {
+			name := influxdb.ShardBackupFilename(b.ID, s.ID)
+			if _, ok := manifest.File(name); !ok {
+				continue
+			}
+			bb.Shards = append(bb.Shards, influxdb.ShardBackup{
+				ID:        s.ID,
+				StartTime: s.StartTime,
+				EndTime:   s.EndTime,
+				FileName:  name,
+			})
+		}
+		manifest.Buckets = append(manifest.Buckets, bb)
+	}
+
+	f, err := os.OpenFile(filepath.Join(internalBackupPath, influxdb.BackupManifestFilename), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0660)
+	if err != nil {
+		return err
+	}
+	return multierr.Append(json.NewEncoder(f).Encode(manifest), f.Close())
+}
+
+// newBackupFile returns the size and checksum of a file of a backup.
+func newBackupFile(internalBackupPath, name string) (influxdb.BackupFile, error) {
+	f, err := os.Open(filepath.Join(internalBackupPath, name))
+	if err != nil {
+		return influxdb.BackupFile{}, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return influxdb.BackupFile{}, err
+	}
+
+	return influxdb.BackupFile{
+		Name:     name,
+		Size:     n,
+		Checksum: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
 func (h *BackupHandler) backupCredentials(internalBackupPath string) (bool, error) {
 	credBackupPath := filepath.Join(internalBackupPath, fs.DefaultConfigsFile)
 
@@ -185,7 +341,7 @@ type BackupService struct {
 	InsecureSkipVerify bool
 }
 
-func (s *BackupService) CreateBackup(ctx context.Context) (int, []string, error) {
+func (s *BackupService) CreateBackup(ctx context.Context, filter influxdb.BackupFilter) (int, []string, error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
@@ -194,11 +350,17 @@ func (s *BackupService) CreateBackup(ctx context.Context) (int, []string, error)
 		return 0, nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, u.String(), nil)
+	body, err := json.Marshal(filter)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(body))
 	if err != nil {
 		return 0, nil, err
 	}
 	SetToken(s.Token, req)
+	req.Header.Set("Content-Type", "application/json")
 	req = req.WithContext(ctx)
 
 	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
@@ -18,6 +18,7 @@ func newLegacyBackend(b *APIBackend) *legacy.Backend {
 		DBRPMappingServiceV2:  b.DBRPService,
 		ProxyQueryService:     b.InfluxQLService,
 		InfluxqldQueryService: b.InfluxqldService,
+		FluxQueryService:      b.FluxService,
 		WriteEventRecorder:    b.WriteEventRecorder,
 	}
 }
@@ -34,6 +35,9 @@ func newLegacyHandler(b *legacy.Backend, config legacy.HandlerConfig) *legacy.Ha
 	influxqlBackend := legacy.NewInfluxQLBackend(b)
 	h.InfluxQLHandler = legacy.NewInfluxQLHandler(influxqlBackend, config)
 
+	promReadBackend := legacy.NewPromReadBackend(b)
+	h.PromReadHandler = legacy.NewPromReadHandler(promReadBackend, b.MaxBatchSizeBytes)
+
 	h.PingHandler = legacy.NewPingHandler(config.Version)
 	return h
 }
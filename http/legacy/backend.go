@@ -19,6 +19,7 @@ type Handler struct {
 	PointsWriterHandler *WriteHandler
 	PingHandler         *PingHandler
 	InfluxQLHandler     *InfluxqlHandler
+	PromReadHandler     *PromReadHandler
 }
 
 type Backend struct {
@@ -34,6 +35,7 @@ type Backend struct {
 	DBRPMappingServiceV2  influxdb.DBRPMappingServiceV2
 	ProxyQueryService     query.ProxyQueryService
 	InfluxqldQueryService influxql.ProxyQueryService
+	FluxQueryService      query.ProxyQueryService
 }
 
 // HandlerConfig provides configuration for the legacy handler.
@@ -60,7 +62,7 @@ func (c *HandlerConfig) Opts() []cli.Opt {
 }
 
 func (h *Handler) ServeHTTP(w http2.ResponseWriter, r *http2.Request) {
-	if r.URL.Path == "/write" {
+	if r.URL.Path == "/write" || r.URL.Path == "/api/v1/prom/write" {
 		h.PointsWriterHandler.ServeHTTP(w, r)
 		return
 	}
@@ -75,6 +77,11 @@ func (h *Handler) ServeHTTP(w http2.ResponseWriter, r *http2.Request) {
 		return
 	}
 
+	if r.URL.Path == "/api/v1/prom/read" {
+		h.PromReadHandler.ServeHTTP(w, r)
+		return
+	}
+
 	w.WriteHeader(http2.StatusNotFound)
 }
 
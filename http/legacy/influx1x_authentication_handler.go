@@ -11,20 +11,34 @@ import (
 	"github.com/opentracing/opentracing-go"
 )
 
+// V1AuthorizationService finds the authorizations of v1 users and
+// verifies their passwords.
+type V1AuthorizationService interface {
+	// FindAuthorizationByToken returns the authorization of the v1 user named token.
+	FindAuthorizationByToken(ctx context.Context, token string) (*influxdb.Authorization, error)
+
+	// ComparePassword checks if the password matches the password of an authorization.
+	ComparePassword(ctx context.Context, id influxdb.ID, password string) error
+}
+
 type Influx1xAuthenticationHandler struct {
 	influxdb.HTTPErrorHandler
-	next http.Handler
-	auth influxdb.AuthorizationService
-	user influxdb.UserService
+	next   http.Handler
+	auth   influxdb.AuthorizationService
+	v1Auth V1AuthorizationService
+	user   influxdb.UserService
 }
 
 // NewInflux1xAuthenticationHandler creates an authentication handler to process
-// InfluxDB 1.x authentication requests.
-func NewInflux1xAuthenticationHandler(next http.Handler, auth influxdb.AuthorizationService, user influxdb.UserService, h influxdb.HTTPErrorHandler) *Influx1xAuthenticationHandler {
+// InfluxDB 1.x authentication requests. Credentials with a username are
+// first checked against the v1 users of v1Auth, when it is not nil, and
+// then treated as a username and token.
+func NewInflux1xAuthenticationHandler(next http.Handler, auth influxdb.AuthorizationService, v1Auth V1AuthorizationService, user influxdb.UserService, h influxdb.HTTPErrorHandler) *Influx1xAuthenticationHandler {
 	return &Influx1xAuthenticationHandler{
 		HTTPErrorHandler: h,
 		next:             next,
 		auth:             auth,
+		v1Auth:           v1Auth,
 		user:             user,
 	}
 }
@@ -44,14 +58,21 @@ func (h *Influx1xAuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http
 		return
 	}
 
-	auth, err := h.auth.FindAuthorizationByToken(ctx, creds.Token)
+	auth, v1User, err := h.findV1Authorization(ctx, creds)
 	if err != nil {
 		unauthorizedError(ctx, h, w)
 		return
 	}
+	if !v1User {
+		auth, err = h.auth.FindAuthorizationByToken(ctx, creds.Token)
+		if err != nil {
+			unauthorizedError(ctx, h, w)
+			return
+		}
+	}
 
 	var user *influxdb.User
-	if creds.Username != "" {
+	if creds.Username != "" && !v1User {
 		user, err = h.user.FindUser(ctx, influxdb.UserFilter{Name: &creds.Username})
 		if err != nil {
 			unauthorizedError(ctx, h, w)
@@ -87,6 +108,29 @@ func (h *Influx1xAuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http
 	h.next.ServeHTTP(w, r.WithContext(ctx))
 }
 
+// findV1Authorization returns the authorization of the v1 user named in
+// the credentials, if there is one, after verifying its password.
+func (h *Influx1xAuthenticationHandler) findV1Authorization(ctx context.Context, creds *credentials) (*influxdb.Authorization, bool, error) {
+	if h.v1Auth == nil || creds.Username == "" {
+		return nil, false, nil
+	}
+
+	auth, err := h.v1Auth.FindAuthorizationByToken(ctx, creds.Username)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	if err := h.v1Auth.ComparePassword(ctx, auth.ID, creds.Token); err != nil {
+		return nil, false, err
+	}
+	if !auth.IsActive() {
+		return nil, false, &influxdb.Error{Code: influxdb.EUnauthorized, Msg: "authorization is inactive"}
+	}
+	return auth, true, nil
+}
+
 func (h *Influx1xAuthenticationHandler) isUserActive(u *influxdb.User) error {
 	if u.Status != "inactive" {
 		return nil
@@ -25,6 +25,7 @@ func TestInflux1xAuthenticationHandler(t *testing.T) {
 		FindAuthorizationByTokenFn func(context.Context, string) (*influxdb.Authorization, error)
 		FindUserFn                 func(context.Context, influxdb.UserFilter) (*influxdb.User, error)
 		FindUserByIDFn             func(context.Context, influxdb.ID) (*influxdb.User, error)
+		V1AuthService              *v1AuthService
 	}
 
 	type exp struct {
@@ -141,6 +142,74 @@ func TestInflux1xAuthenticationHandler(t *testing.T) {
 				code: http.StatusForbidden,
 			},
 		},
+		{
+			name: "v1 user",
+			fields: fields{
+				FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+					return nil, fmt.Errorf("authorization not found")
+				},
+				V1AuthService: &v1AuthService{
+					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+						return &influxdb.Authorization{ID: 2, Token: token, UserID: one, Status: influxdb.Active}, nil
+					},
+					ComparePasswordFn: func(ctx context.Context, id influxdb.ID, password string) error {
+						return nil
+					},
+				},
+			},
+			auth: basic(User, Token),
+			exp: exp{
+				code: http.StatusOK,
+			},
+		},
+		{
+			name: "v1 user with incorrect password",
+			fields: fields{
+				V1AuthService: &v1AuthService{
+					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+						return &influxdb.Authorization{ID: 2, Token: token, UserID: one, Status: influxdb.Active}, nil
+					},
+					ComparePasswordFn: func(ctx context.Context, id influxdb.ID, password string) error {
+						return &influxdb.Error{Code: influxdb.EForbidden, Msg: "your username or password is incorrect"}
+					},
+				},
+			},
+			auth: query(User, Token),
+			exp: exp{
+				code: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "inactive v1 user",
+			fields: fields{
+				V1AuthService: &v1AuthService{
+					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+						return &influxdb.Authorization{ID: 2, Token: token, UserID: one, Status: influxdb.Inactive}, nil
+					},
+					ComparePasswordFn: func(ctx context.Context, id influxdb.ID, password string) error {
+						return nil
+					},
+				},
+			},
+			auth: basic(User, Token),
+			exp: exp{
+				code: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "not a v1 user",
+			fields: fields{
+				V1AuthService: &v1AuthService{
+					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+						return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "authorization not found"}
+					},
+				},
+			},
+			auth: token(User, Token),
+			exp: exp{
+				code: http.StatusOK,
+			},
+		},
 		{
 			name: "no auth provided",
 			fields: fields{
@@ -180,7 +249,12 @@ func TestInflux1xAuthenticationHandler(t *testing.T) {
 					w.WriteHeader(http.StatusOK)
 				})
 
-				h = NewInflux1xAuthenticationHandler(next, auth, user, kithttp.ErrorHandler(0))
+				var v1Auth V1AuthorizationService
+				if tt.fields.V1AuthService != nil {
+					v1Auth = tt.fields.V1AuthService
+				}
+
+				h = NewInflux1xAuthenticationHandler(next, auth, v1Auth, user, kithttp.ErrorHandler(0))
 			}
 
 			w := httptest.NewRecorder()
@@ -196,3 +270,16 @@ func TestInflux1xAuthenticationHandler(t *testing.T) {
 		})
 	}
 }
+
+type v1AuthService struct {
+	FindAuthorizationByTokenFn func(context.Context, string) (*influxdb.Authorization, error)
+	ComparePasswordFn          func(context.Context, influxdb.ID, string) error
+}
+
+func (s *v1AuthService) FindAuthorizationByToken(ctx context.Context, token string) (*influxdb.Authorization, error) {
+	return s.FindAuthorizationByTokenFn(ctx, token)
+}
+
+func (s *v1AuthService) ComparePassword(ctx context.Context, id influxdb.ID, password string) error {
+	return s.ComparePasswordFn(ctx, id, password)
+}
@@ -0,0 +1,226 @@
+package legacy
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http/points"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/prometheus"
+	"github.com/influxdata/influxdb/v2/prometheus/prompb"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/promql"
+	"go.uber.org/zap"
+)
+
+var _ http.Handler = (*PromReadHandler)(nil)
+
+const opPromReadHandler = "http/v1PromReadHandler"
+
+// PromReadBackend contains all the services needed to run a PromReadHandler.
+type PromReadBackend struct {
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	DBRPMappingService influxdb.DBRPMappingServiceV2
+	FluxQueryService   query.ProxyQueryService
+}
+
+// NewPromReadBackend creates a new backend for Prometheus remote reads.
+func NewPromReadBackend(b *Backend) *PromReadBackend {
+	return &PromReadBackend{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		Logger:             b.Logger.With(zap.String("handler", "prom_read")),
+		DBRPMappingService: b.DBRPMappingServiceV2,
+		FluxQueryService:   b.FluxQueryService,
+	}
+}
+
+// PromReadHandler mimics the /api/v1/prom/read handler of influxdb 1.x: it
+// serves the Prometheus remote read requests of the bucket mapped to the db
+// and rp parameters, written with the /api/v1/prom/write handler.
+type PromReadHandler struct {
+	influxdb.HTTPErrorHandler
+	DBRPMappingService influxdb.DBRPMappingServiceV2
+	FluxQueryService   query.ProxyQueryService
+
+	router            *httprouter.Router
+	logger            *zap.Logger
+	maxBatchSizeBytes int64
+}
+
+// NewPromReadHandler returns a new instance of PromReadHandler.
+func NewPromReadHandler(b *PromReadBackend, maxBatchSizeBytes int64) *PromReadHandler {
+	h := &PromReadHandler{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		DBRPMappingService: b.DBRPMappingService,
+		FluxQueryService:   b.FluxQueryService,
+
+		router:            NewRouter(b.HTTPErrorHandler),
+		logger:            b.Logger,
+		maxBatchSizeBytes: maxBatchSizeBytes,
+	}
+
+	h.router.HandlerFunc(http.MethodPost, "/api/v1/prom/read", h.handleRead)
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *PromReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.router.ServeHTTP(w, r)
+}
+
+// handleRead handles Prometheus remote read requests.
+func (h *PromReadHandler) handleRead(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PromReadHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	auth, err := getAuthorization(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	qp := r.URL.Query()
+	db := qp.Get("db")
+	if db == "" {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "missing db",
+		}, w)
+		return
+	}
+	mapping, err := findMapping(ctx, h.DBRPMappingService, auth.OrgID, db, qp.Get("rp"))
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	span.LogKV("bucket_id", mapping.BucketID)
+
+	body, err := points.BatchReadCloser(r.Body, "", h.maxBatchSizeBytes)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   opPromReadHandler,
+			Msg:  "unable to read request body",
+			Err:  err,
+		}, w)
+		return
+	}
+	req, err := prometheus.DecodeReadRequest(data)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   opPromReadHandler,
+			Msg:  err.Error(),
+		}, w)
+		return
+	}
+
+	resp := &prompb.ReadResponse{Results: make([]*prompb.QueryResult, len(req.Queries))}
+	for i, q := range req.Queries {
+		result, err := h.read(ctx, auth, mapping.BucketID, q)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		resp.Results[i] = result
+	}
+
+	data, err = prometheus.EncodeReadResponse(resp)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Op:   opPromReadHandler,
+			Msg:  "unable to encode remote read response",
+			Err:  err,
+		}, w)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	if _, err := w.Write(data); err != nil {
+		h.logger.Info("Error writing response to client", zap.Error(err))
+	}
+}
+
+// read returns the series of a bucket matching a remote read query.
+func (h *PromReadHandler) read(ctx context.Context, auth *influxdb.Authorization, bucketID influxdb.ID, q *prompb.Query) (*prompb.QueryResult, error) {
+	matchers := make([]*promql.LabelMatcher, len(q.Matchers))
+	for i, m := range q.Matchers {
+		kind, err := matchKind(m.Type)
+		if err != nil {
+			return nil, err
+		}
+		matchers[i] = &promql.LabelMatcher{
+			Name:  m.Name,
+			Kind:  kind,
+			Value: &promql.StringLiteral{String: m.Value},
+		}
+	}
+
+	start := time.Unix(0, q.StartTimestampMs*int64(time.Millisecond))
+	end := time.Unix(0, q.EndTimestampMs*int64(time.Millisecond))
+	dialect := &promql.SeriesDialect{}
+	if _, err := h.FluxQueryService.Query(ctx, ioutil.Discard, &query.ProxyRequest{
+		Request: query.Request{
+			Authorization:  auth,
+			OrganizationID: auth.OrgID,
+			Compiler:       lang.FluxCompiler{Query: promql.SeriesFlux(matchers, start, end, promql.FluxOptions{BucketID: bucketID})},
+		},
+		Dialect: dialect,
+	}); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Op:   opPromReadHandler,
+			Msg:  "unable to read series",
+			Err:  err,
+		}
+	}
+
+	result := &prompb.QueryResult{Timeseries: make([]*prompb.TimeSeries, len(dialect.Series))}
+	for i, s := range dialect.Series {
+		ts := &prompb.TimeSeries{
+			Labels:  make([]prompb.Label, len(s.Labels)),
+			Samples: make([]prompb.Sample, len(s.Samples)),
+		}
+		for j, l := range s.Labels {
+			ts.Labels[j] = prompb.Label{Name: l.Name, Value: l.Value}
+		}
+		for j, sample := range s.Samples {
+			ts.Samples[j] = prompb.Sample{Value: sample.Value, Timestamp: sample.Time / int64(time.Millisecond)}
+		}
+		result.Timeseries[i] = ts
+	}
+	return result, nil
+}
+
+func matchKind(t prompb.LabelMatcher_Type) (promql.MatchKind, error) {
+	switch t {
+	case prompb.LabelMatcher_EQ:
+		return promql.Equal, nil
+	case prompb.LabelMatcher_NEQ:
+		return promql.NotEqual, nil
+	case prompb.LabelMatcher_RE:
+		return promql.RegexMatch, nil
+	case prompb.LabelMatcher_NRE:
+		return promql.RegexNoMatch, nil
+	default:
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unknown label matcher type %d", t),
+		}
+	}
+}
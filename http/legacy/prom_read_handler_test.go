@@ -0,0 +1,104 @@
+package legacy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/snappy"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/dbrp"
+	"github.com/influxdata/influxdb/v2/http/mocks"
+	"github.com/influxdata/influxdb/v2/prometheus/prompb"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/mock"
+	"github.com/influxdata/influxdb/v2/query/promql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPromReadHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		dbrpMappingSvc = mocks.NewMockDBRPMappingServiceV2(ctrl)
+
+		orgID   = generator.ID()
+		mapping = &influxdb.DBRPMappingV2{
+			OrganizationID:  orgID,
+			BucketID:        generator.ID(),
+			Database:        "prometheus",
+			RetentionPolicy: "autogen",
+		}
+	)
+
+	dbrpMappingSvc.
+		EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{
+			OrgID:    &mapping.OrganizationID,
+			Database: &mapping.Database,
+		}).Return([]*influxdb.DBRPMappingV2{mapping}, 1, nil)
+
+	var script string
+	queryService := &mock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			script = req.Request.Compiler.(lang.FluxCompiler).Query
+			req.Dialect.(*promql.SeriesDialect).Series = []*promql.Series{{
+				Labels:  []promql.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "node"}},
+				Samples: []promql.Sample{{Time: 1602462600000000000, Value: 1}},
+			}}
+			return flux.Statistics{}, nil
+		},
+	}
+
+	data, err := (&prompb.ReadRequest{
+		Queries: []*prompb.Query{{
+			StartTimestampMs: 1602462600000,
+			EndTimestampMs:   1602462630000,
+			Matchers: []*prompb.LabelMatcher{
+				{Type: prompb.LabelMatcher_EQ, Name: "__name__", Value: "up"},
+				{Type: prompb.LabelMatcher_RE, Name: "job", Value: "no.*"},
+			},
+		}},
+	}).Marshal()
+	require.NoError(t, err)
+
+	perms := newPermissions(influxdb.BucketsResourceType, &orgID, nil)
+	auth := newAuthorization(orgID, perms...)
+	ctx := pcontext.SetAuthorizer(context.Background(), auth)
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/prom/read?db=prometheus", bytes.NewReader(snappy.Encode(nil, data))).WithContext(ctx)
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	r.Header.Set("Content-Encoding", "snappy")
+
+	handler := NewPromReadHandler(&PromReadBackend{
+		HTTPErrorHandler:   DefaultErrorHandler,
+		Logger:             zaptest.NewLogger(t),
+		DBRPMappingService: dbrp.NewAuthorizedService(dbrpMappingSvc),
+		FluxQueryService:   queryService,
+	}, 0)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "snappy", w.Header().Get("Content-Encoding"))
+	assert.True(t, strings.Contains(script, `r["job"] =~ /^(?:no.*)$/`), script)
+
+	body, err := snappy.Decode(nil, w.Body.Bytes())
+	require.NoError(t, err)
+	var resp prompb.ReadResponse
+	require.NoError(t, resp.Unmarshal(body))
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, []*prompb.TimeSeries{{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "node"}},
+		Samples: []prompb.Sample{{Value: 1, Timestamp: 1602462600000}},
+	}}, resp.Results[0].Timeseries)
+}
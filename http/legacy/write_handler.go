@@ -83,6 +83,7 @@ func NewWriterHandler(b *PointsWriterBackend, opts ...WriteHandlerOption) *Write
 	}
 
 	h.router.HandlerFunc(http.MethodPost, "/write", h.handleWrite)
+	h.router.HandlerFunc(http.MethodPost, "/api/v1/prom/write", h.handleWrite)
 
 	return h
 }
@@ -143,7 +144,7 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	}
 	span.LogKV("bucket_id", bucket.ID)
 
-	parsed, err := points.NewParser(req.Precision).Parse(ctx, auth.OrgID, bucket.ID, req.Body)
+	parsed, err := (&points.Parser{Precision: req.Precision, Format: req.Format}).Parse(ctx, auth.OrgID, bucket.ID, req.Body)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, sw)
 		return
@@ -186,6 +187,12 @@ func (h *WriteHandler) findOrCreateMappedBucket(ctx context.Context, orgID influ
 // findMapping finds a DBRPMappingV2 for the database and retention policy
 // combination.
 func (h *WriteHandler) findMapping(ctx context.Context, orgID influxdb.ID, db, rp string) (*influxdb.DBRPMappingV2, error) {
+	return findMapping(ctx, h.DBRPMappingService, orgID, db, rp)
+}
+
+// findMapping finds a DBRPMappingV2 of a mapping service for the database and
+// retention policy combination.
+func findMapping(ctx context.Context, svc influxdb.DBRPMappingServiceV2, orgID influxdb.ID, db, rp string) (*influxdb.DBRPMappingV2, error) {
 	filter := influxdb.DBRPMappingFilterV2{
 		OrgID:    &orgID,
 		Database: &db,
@@ -194,7 +201,7 @@ func (h *WriteHandler) findMapping(ctx context.Context, orgID influxdb.ID, db, r
 		filter.RetentionPolicy = &rp
 	}
 
-	mappings, count, err := h.DBRPMappingService.FindMany(ctx, filter)
+	mappings, count, err := svc.FindMany(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -271,6 +278,8 @@ type writeRequest struct {
 	RetentionPolicy  string
 	Precision        string
 	Body             io.ReadCloser
+	// Format is the format of Body, as given by its Content-Type.
+	Format string
 }
 
 // decodeWriteRequest extracts write request information from an inbound
@@ -301,6 +310,7 @@ func decodeWriteRequest(ctx context.Context, r *http.Request, maxBatchSizeBytes
 		RetentionPolicy:  qp.Get("rp"),
 		Precision:        precision,
 		Body:             body,
+		Format:           points.ContentFormat(r.Header.Get("Content-Type")),
 	}, nil
 }
 
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
@@ -708,17 +709,24 @@ func (n *notificationEndpointEncoder) MarshalJSON() ([]byte, error) {
 
 	// this makes me queezy and altogether sad
 	fieldMap := map[string]string{
+		"-api-key":     "apiKey",
 		"-password":    "password",
 		"-routing-key": "routingKey",
 		"-token":       "token",
 		"-username":    "username",
 	}
 	for _, sec := range n.ne.SecretFields() {
-		var v string
-		if sec.Value != nil {
-			v = *sec.Value
+		// a field referencing an existing secret is sent as its key
+		if sec.Value == nil {
+			continue
+		}
+		// the backfilled keys are prefixed with the endpoint id
+		for suffix, field := range fieldMap {
+			if strings.HasSuffix(sec.Key, suffix) {
+				ughhh[field] = *sec.Value
+				break
+			}
 		}
-		ughhh[fieldMap[sec.Key]] = v
 	}
 	return json.Marshal(ughhh)
 }
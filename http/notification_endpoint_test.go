@@ -14,6 +14,7 @@ import (
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
 	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/endpoints"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/kv"
 	"github.com/influxdata/influxdb/v2/mock"
@@ -1126,6 +1127,108 @@ func TestNotificationEndpointService(t *testing.T) {
 	}
 }
 
+func TestNotificationEndpointService_InlineSecrets(t *testing.T) {
+	orgID := influxTesting.MustIDBase16("020f755c3c082000")
+	base := func(name string) endpoint.Base {
+		return endpoint.Base{Name: name, OrgID: &orgID, Status: influxdb.Active}
+	}
+	value := func(v string) influxdb.SecretField {
+		return influxdb.SecretField{Value: &v}
+	}
+
+	tests := []struct {
+		name string
+		// endpoint returns the endpoint with its secret values prefixed
+		endpoint func(prefix string) influxdb.NotificationEndpoint
+		secrets  map[string]string
+	}{
+		{
+			name: "telegram token",
+			endpoint: func(prefix string) influxdb.NotificationEndpoint {
+				return &endpoint.Telegram{Base: base("telegram"), Token: value(prefix + "bot-token"), Channel: "-1001406363649"}
+			},
+			secrets: map[string]string{"-token": "bot-token"},
+		},
+		{
+			name: "pagerduty routing key",
+			endpoint: func(prefix string) influxdb.NotificationEndpoint {
+				return &endpoint.PagerDuty{Base: base("pagerduty"), ClientURL: "http://localhost:8086", RoutingKey: value(prefix + "routing-key")}
+			},
+			secrets: map[string]string{"-routing-key": "routing-key"},
+		},
+		{
+			name: "opsgenie api key",
+			endpoint: func(prefix string) influxdb.NotificationEndpoint {
+				return &endpoint.Opsgenie{Base: base("opsgenie"), APIKey: value(prefix + "api-key")}
+			},
+			secrets: map[string]string{"-api-key": "api-key"},
+		},
+		{
+			name: "http basic auth",
+			endpoint: func(prefix string) influxdb.NotificationEndpoint {
+				return &endpoint.HTTP{
+					Base:       base("http"),
+					URL:        "http://localhost:9999",
+					Method:     "POST",
+					AuthMethod: "basic",
+					Username:   value(prefix + "user"),
+					Password:   value(prefix + "password"),
+				}
+			},
+			secrets: map[string]string{"-username": "user", "-password": "password"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := kv.NewService(zaptest.NewLogger(t), NewTestInmemStore(t))
+			if err := svc.PutOrganization(ctx, &influxdb.Organization{ID: orgID, Name: "org"}); err != nil {
+				t.Fatal(err)
+			}
+
+			backend := NewMockNotificationEndpointBackend(t)
+			backend.NotificationEndpointService = endpoints.NewService(svc, svc, svc, svc)
+			backend.UserResourceMappingService = svc
+			backend.OrganizationService = svc
+			handler := NewNotificationEndpointHandler(zaptest.NewLogger(t), backend)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handler.ServeHTTP(w, r.WithContext(pcontext.SetAuthorizer(r.Context(), &influxdb.Session{UserID: user1ID})))
+			}))
+			defer server.Close()
+
+			client := NewNotificationEndpointService(mustNewHTTPClient(t, server.URL, ""))
+			checkSecrets := func(id influxdb.ID, prefix string) {
+				t.Helper()
+				for suffix, want := range tt.secrets {
+					got, err := svc.LoadSecret(ctx, orgID, id.String()+suffix)
+					if err != nil {
+						t.Fatalf("failed to load secret %q: %v", suffix, err)
+					}
+					if got != prefix+want {
+						t.Errorf("got secret %q = %q, want %q", suffix, got, prefix+want)
+					}
+				}
+			}
+
+			edp := tt.endpoint("")
+			if err := client.CreateNotificationEndpoint(ctx, edp, user1ID); err != nil {
+				t.Fatal(err)
+			}
+			id := edp.GetID()
+			checkSecrets(id, "")
+
+			// the secret keys of an endpoint with an id are prefixed with it
+			upd := tt.endpoint("new-")
+			upd.SetID(id)
+			if _, err := client.UpdateNotificationEndpoint(ctx, id, upd, user1ID); err != nil {
+				t.Fatal(err)
+			}
+			checkSecrets(id, "new-")
+		})
+	}
+}
+
 func authCtxFn(userID influxdb.ID) func(context.Context) context.Context {
 	return func(ctx context.Context) context.Context {
 		return pcontext.SetAuthorizer(ctx, &influxdb.Session{UserID: userID})
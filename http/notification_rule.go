@@ -780,13 +780,16 @@ func (s *NotificationRuleService) CreateNotificationRule(ctx context.Context, nr
 
 // FindNotificationRuleByID finds and returns one Notification Rule with a matching ID
 func (s *NotificationRuleService) FindNotificationRuleByID(ctx context.Context, id influxdb.ID) (influxdb.NotificationRule, error) {
-	var resp notificationRuleResponse
+	var resp notificationRuleDecoder
 	err := s.Client.
 		Get(getNotificationRulesIDPath(id)).
 		DecodeJSON(&resp).
 		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	return resp.NotificationRule, err
+	return resp.rule, nil
 }
 
 // FindNotificationRules returns a list of notification rules that match filter and the total count of matching notification rules.
@@ -46,7 +46,7 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 		AssetHandler:  assetHandler,
 		DocsHandler:   Redoc("/api/v2/swagger.json"),
 		APIHandler:    wrappedHandler,
-		LegacyHandler: legacy.NewInflux1xAuthenticationHandler(lh, b.AuthorizationService, b.UserService, b.HTTPErrorHandler),
+		LegacyHandler: legacy.NewInflux1xAuthenticationHandler(lh, b.AuthorizationService, b.V1AuthorizationService, b.UserService, b.HTTPErrorHandler),
 	}
 }
 
@@ -55,7 +55,9 @@ func (h *PlatformHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// TODO(affo): change this to be mounted prefixes: https://github.com/influxdata/idpe/issues/6689.
 	if r.URL.Path == "/write" ||
 		r.URL.Path == "/query" ||
-		r.URL.Path == "/ping" {
+		r.URL.Path == "/ping" ||
+		r.URL.Path == "/api/v1/prom/write" ||
+		r.URL.Path == "/api/v1/prom/read" {
 		h.LegacyHandler.ServeHTTP(w, r)
 		return
 	}
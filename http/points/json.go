@@ -0,0 +1,132 @@
+package points
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+)
+
+// jsonPoint is a point of a JSON write body. A body holds a point or an array
+// of points:
+//
+//	[{
+//		"measurement": "cpu",
+//		"tags": {"host": "a"},
+//		"fields": {"usage": 1.5, "cores": {"integer": 4}, "up": true, "mode": "eco"},
+//		"time": 1602462600
+//	}]
+//
+// Numbers are float fields; integer and unsigned fields are objects with an
+// "integer" or "unsigned" key. The time is a number in the write precision,
+// or an RFC3339 string, and defaults to the time of the write.
+type jsonPoint struct {
+	Measurement string                     `json:"measurement"`
+	Tags        map[string]string          `json:"tags"`
+	Fields      map[string]json.RawMessage `json:"fields"`
+	Time        json.RawMessage            `json:"time"`
+}
+
+// parseJSONPoints parses a JSON write body. As with lines of line protocol,
+// it returns the position of each point in the body, starting at 1, and the
+// errors of the points that failed to parse.
+func parseJSONPoints(data []byte, defaultTime time.Time, precision string) (points []models.Point, positions []int, failed []*models.LineError, err error) {
+	var raw []json.RawMessage
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &raw)
+	} else {
+		raw = []json.RawMessage{data}
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for i, r := range raw {
+		pt, err := parseJSONPoint(r, defaultTime, precision)
+		if err != nil {
+			failed = append(failed, &models.LineError{Line: i + 1, Text: string(r), Err: err})
+			continue
+		}
+		points = append(points, pt)
+		positions = append(positions, i+1)
+	}
+	return points, positions, failed, nil
+}
+
+func parseJSONPoint(data []byte, defaultTime time.Time, precision string) (models.Point, error) {
+	var p jsonPoint
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	if p.Measurement == "" {
+		return nil, errors.New("missing measurement")
+	}
+
+	fields := make(models.Fields, len(p.Fields))
+	for k, v := range p.Fields {
+		fv, err := jsonFieldValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for field %q: %v", k, err)
+		}
+		fields[k] = fv
+	}
+
+	t, err := jsonTime(p.Time, defaultTime, precision)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewPoint(p.Measurement, models.NewTags(p.Tags), fields, t)
+}
+
+// jsonFieldValue returns the value of a field of a JSON point.
+func jsonFieldValue(data json.RawMessage) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case json.Number:
+		return v.Float64()
+	case string, bool:
+		return v, nil
+	case map[string]interface{}:
+		if len(v) == 1 {
+			if n, ok := v["integer"].(json.Number); ok {
+				return strconv.ParseInt(n.String(), 10, 64)
+			}
+			if n, ok := v["unsigned"].(json.Number); ok {
+				return strconv.ParseUint(n.String(), 10, 64)
+			}
+		}
+	}
+	return nil, fmt.Errorf("unsupported value %s", data)
+}
+
+// jsonTime returns the time of a JSON point.
+func jsonTime(data json.RawMessage, defaultTime time.Time, precision string) (time.Time, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return defaultTime, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time: %v", err)
+		}
+		return t.UTC(), models.CheckTime(t)
+	}
+
+	ts, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %s", data)
+	}
+	return models.SafeCalcTime(ts, precision)
+}
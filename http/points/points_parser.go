@@ -6,12 +6,14 @@ import (
 	"errors"
 	"io"
 	"io/ioutil"
+	"mime"
 	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	io2 "github.com/influxdata/influxdb/v2/kit/io"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/prometheus"
 	"github.com/opentracing/opentracing-go"
 	"go.uber.org/zap"
 	"istio.io/pkg/log"
@@ -30,16 +32,48 @@ const (
 	msgWritingRequiresPoints = "writing requires points"
 )
 
+// The formats of points batches.
+const (
+	// FormatLineProtocol is line protocol, the default format.
+	FormatLineProtocol = "lp"
+	// FormatJSON is a JSON point or array of points.
+	FormatJSON = "json"
+	// FormatPrometheus is a snappy compressed Prometheus remote write request.
+	FormatPrometheus = "prometheus"
+)
+
+// ContentFormat returns the format of a points batch with a Content-Type.
+// Content types other than JSON and protocol buffers are line protocol.
+func ContentFormat(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/json":
+		return FormatJSON
+	case "application/x-protobuf":
+		return FormatPrometheus
+	default:
+		return FormatLineProtocol
+	}
+}
+
 // ParsedPoints contains the points parsed as well as the total number of bytes
 // after decompression.
 type ParsedPoints struct {
 	Points  models.Points
 	RawSize int
+
+	// Lines holds the line number of each point, and Invalid the lines that
+	// failed to parse, when parsed with ParsePartial. Prometheus batches have
+	// no lines.
+	Lines   []int
+	Invalid []*models.LineError
 }
 
 // Parser parses batches of Points.
 type Parser struct {
 	Precision string
+	// Format is the format of the batches, line protocol by default.
+	Format string
 	//ParserOptions []models.ParserOption
 }
 
@@ -47,10 +81,18 @@ type Parser struct {
 func (pw *Parser) Parse(ctx context.Context, orgID, bucketID influxdb.ID, rc io.ReadCloser) (*ParsedPoints, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "write points")
 	defer span.Finish()
-	return pw.parsePoints(ctx, orgID, bucketID, rc)
+	return pw.parsePoints(ctx, orgID, bucketID, rc, false)
 }
 
-func (pw *Parser) parsePoints(ctx context.Context, orgID, bucketID influxdb.ID, rc io.ReadCloser) (*ParsedPoints, error) {
+// ParsePartial parses the points from an io.ReadCloser like Parse, but keeps
+// the points of the valid lines when some lines fail to parse.
+func (pw *Parser) ParsePartial(ctx context.Context, orgID, bucketID influxdb.ID, rc io.ReadCloser) (*ParsedPoints, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "write points")
+	defer span.Finish()
+	return pw.parsePoints(ctx, orgID, bucketID, rc, true)
+}
+
+func (pw *Parser) parsePoints(ctx context.Context, orgID, bucketID influxdb.ID, rc io.ReadCloser, partial bool) (*ParsedPoints, error) {
 	data, err := readAll(ctx, rc)
 	if err != nil {
 		code := influxdb.EInternal
@@ -78,6 +120,27 @@ func (pw *Parser) parsePoints(ctx context.Context, orgID, bucketID influxdb.ID,
 
 	span, _ := tracing.StartSpanFromContextWithOperationName(ctx, "encoding and parsing")
 
+	switch pw.Format {
+	case FormatJSON:
+		defer span.Finish()
+		return pw.parseJSON(span, data, partial)
+	case FormatPrometheus:
+		defer span.Finish()
+		return parsePrometheus(span, data)
+	}
+
+	if partial {
+		points, lines, invalid := models.ParsePointsWithLines(data, time.Now().UTC(), pw.Precision)
+		span.LogKV("values_total", len(points), "lines_invalid", len(invalid))
+		span.Finish()
+		return &ParsedPoints{
+			Points:  points,
+			RawSize: requestBytes,
+			Lines:   lines,
+			Invalid: invalid,
+		}, nil
+	}
+
 	points, err := models.ParsePointsWithPrecision(data, time.Now().UTC(), pw.Precision)
 	span.LogKV("values_total", len(points))
 	span.Finish()
@@ -106,6 +169,60 @@ func (pw *Parser) parsePoints(ctx context.Context, orgID, bucketID influxdb.ID,
 	}, nil
 }
 
+// parseJSON parses a JSON batch. The line of a point is its position in the
+// batch.
+func (pw *Parser) parseJSON(span opentracing.Span, data []byte, partial bool) (*ParsedPoints, error) {
+	points, positions, invalid, err := parseJSONPoints(data, time.Now().UTC(), pw.Precision)
+	span.LogKV("values_total", len(points), "lines_invalid", len(invalid))
+	if err == nil && !partial && len(invalid) > 0 {
+		err = invalid[0]
+	}
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   opPointsWriter,
+			Err:  err,
+		}
+	}
+
+	parsed := &ParsedPoints{
+		Points:  points,
+		RawSize: len(data),
+	}
+	if partial {
+		parsed.Lines, parsed.Invalid = positions, invalid
+	}
+	return parsed, nil
+}
+
+// parsePrometheus parses a Prometheus remote write request. Partial writes
+// of requests are not supported: a request is written whole or not at all.
+func parsePrometheus(span opentracing.Span, data []byte) (*ParsedPoints, error) {
+	req, err := prometheus.DecodeWriteRequest(data)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   opPointsWriter,
+			Err:  err,
+		}
+	}
+
+	points, err := prometheus.WriteRequestPoints(req)
+	span.LogKV("values_total", len(points))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   opPointsWriter,
+			Err:  err,
+		}
+	}
+
+	return &ParsedPoints{
+		Points:  points,
+		RawSize: len(data),
+	}, nil
+}
+
 func readAll(ctx context.Context, rc io.ReadCloser) (data []byte, err error) {
 	defer func() {
 		if cerr := rc.Close(); cerr != nil && err == nil {
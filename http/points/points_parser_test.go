@@ -0,0 +1,76 @@
+package points
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentFormat(t *testing.T) {
+	assert.Equal(t, FormatLineProtocol, ContentFormat(""))
+	assert.Equal(t, FormatLineProtocol, ContentFormat("text/plain; charset=utf-8"))
+	assert.Equal(t, FormatLineProtocol, ContentFormat("application/x-www-form-urlencoded"))
+	assert.Equal(t, FormatJSON, ContentFormat("application/json; charset=utf-8"))
+	assert.Equal(t, FormatPrometheus, ContentFormat("application/x-protobuf"))
+}
+
+func TestParser_JSON(t *testing.T) {
+	parse := func(body string, partial bool) (*ParsedPoints, error) {
+		p := &Parser{Precision: "s", Format: FormatJSON}
+		rc := ioutil.NopCloser(strings.NewReader(body))
+		if partial {
+			return p.ParsePartial(context.Background(), 1, 2, rc)
+		}
+		return p.Parse(context.Background(), 1, 2, rc)
+	}
+
+	t.Run("fields and times", func(t *testing.T) {
+		parsed, err := parse(`[
+			{"measurement": "cpu", "tags": {"host": "a"}, "fields": {"usage": 1.5, "cores": {"integer": 4}, "up": true, "mode": "eco"}, "time": 1602462600},
+			{"measurement": "mem", "fields": {"free": {"unsigned": 10}}, "time": "2020-10-12T00:30:00Z"}
+		]`, false)
+		require.NoError(t, err)
+		require.Len(t, parsed.Points, 2)
+		assert.Equal(t, `cpu,host=a cores=4i,mode="eco",up=true,usage=1.5 1602462600000000000`, parsed.Points[0].String())
+		assert.Equal(t, `mem free=10u 1602462600000000000`, parsed.Points[1].String())
+	})
+
+	t.Run("single point", func(t *testing.T) {
+		parsed, err := parse(`{"measurement": "cpu", "fields": {"usage": 2}}`, false)
+		require.NoError(t, err)
+		require.Len(t, parsed.Points, 1)
+		assert.Equal(t, "cpu", string(parsed.Points[0].Name()))
+		fields, err := parsed.Points[0].Fields()
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, fields["usage"])
+	})
+
+	t.Run("invalid points", func(t *testing.T) {
+		body := `[
+			{"measurement": "cpu", "fields": {"usage": 1}, "time": 1},
+			{"measurement": "cpu", "fields": {"usage": [1]}, "time": 2},
+			{"fields": {"usage": 3}, "time": 3}
+		]`
+		_, err := parse(body, false)
+		assert.Error(t, err)
+
+		parsed, err := parse(body, true)
+		require.NoError(t, err)
+		require.Len(t, parsed.Points, 1)
+		assert.Equal(t, []int{1}, parsed.Lines)
+		require.Len(t, parsed.Invalid, 2)
+		assert.Equal(t, 2, parsed.Invalid[0].Line)
+		assert.Contains(t, parsed.Invalid[0].Err.Error(), `field "usage"`)
+		assert.Equal(t, 3, parsed.Invalid[1].Line)
+		assert.EqualError(t, parsed.Invalid[1].Err, "missing measurement")
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		_, err := parse(`[{"measurement": "cpu"`, true)
+		assert.Error(t, err)
+	})
+}
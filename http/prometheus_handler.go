@@ -0,0 +1,600 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/jsonweb"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/promql"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixPrometheus = "/api/v2/prometheus"
+
+	// maxPrometheusPoints is the maximum number of evaluation times of a
+	// range query, as in Prometheus.
+	maxPrometheusPoints = 11000
+)
+
+// PrometheusBackend is all services and associated parameters required to
+// construct the PrometheusHandler.
+type PrometheusBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	OrganizationService influxdb.OrganizationService
+	BucketService       influxdb.BucketService
+	ProxyQueryService   query.ProxyQueryService
+}
+
+// NewPrometheusBackend returns a new instance of PrometheusBackend.
+func NewPrometheusBackend(log *zap.Logger, b *APIBackend) *PrometheusBackend {
+	return &PrometheusBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		OrganizationService: b.OrganizationService,
+		BucketService:       b.BucketService,
+		ProxyQueryService:   b.FluxService,
+	}
+}
+
+// PrometheusHandler serves the query API of Prometheus on the series of a
+// bucket, as written by Prometheus remote write, for clients such as the
+// Prometheus data source of Grafana. PromQL queries are translated to Flux.
+//
+// The bucket is given by the bucket or bucketID parameter, and its
+// organization by the org or orgID parameter, of every request.
+type PrometheusHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	Now                 func() time.Time
+	OrganizationService influxdb.OrganizationService
+	BucketService       influxdb.BucketService
+	ProxyQueryService   query.ProxyQueryService
+}
+
+// Prefix provides the route prefix.
+func (*PrometheusHandler) Prefix() string {
+	return prefixPrometheus
+}
+
+// NewPrometheusHandler returns a new handler at /api/v2/prometheus for
+// PromQL queries.
+func NewPrometheusHandler(log *zap.Logger, b *PrometheusBackend) *PrometheusHandler {
+	h := &PrometheusHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+		Now:              time.Now,
+
+		OrganizationService: b.OrganizationService,
+		BucketService:       b.BucketService,
+		ProxyQueryService:   b.ProxyQueryService,
+	}
+
+	for _, method := range []string{"GET", "POST"} {
+		h.HandlerFunc(method, prefixPrometheus+"/api/v1/query", h.handleQuery)
+		h.HandlerFunc(method, prefixPrometheus+"/api/v1/query_range", h.handleQueryRange)
+		h.HandlerFunc(method, prefixPrometheus+"/api/v1/series", h.handleSeries)
+		h.HandlerFunc(method, prefixPrometheus+"/api/v1/labels", h.handleLabels)
+	}
+	h.HandlerFunc("GET", prefixPrometheus+"/api/v1/label/:name/values", h.handleLabelValues)
+	return h
+}
+
+// The error types of the Prometheus query API.
+const (
+	promErrorBadData   = "bad_data"
+	promErrorExecution = "execution"
+	promErrorInternal  = "internal"
+)
+
+// promResponse is a response of the Prometheus query API.
+type promResponse struct {
+	Status    string      `json:"status"`
+	Data      interface{} `json:"data,omitempty"`
+	ErrorType string      `json:"errorType,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// promQueryData is the result of a PromQL query.
+type promQueryData struct {
+	ResultType string        `json:"resultType"`
+	Result     []*promSeries `json:"result"`
+}
+
+// promSeries is a series of the result of a PromQL query. Series of vectors
+// have a value, and series of matrices values.
+type promSeries struct {
+	Metric map[string]string `json:"metric"`
+	Value  *promSample       `json:"value,omitempty"`
+	Values []promSample      `json:"values,omitempty"`
+}
+
+// promSample is a sample of a series, encoded as its time in seconds and its
+// value as a string.
+type promSample promql.Sample
+
+func (s promSample) MarshalJSON() ([]byte, error) {
+	t := strconv.FormatFloat(float64(s.Time/int64(time.Millisecond))/1e3, 'f', -1, 64)
+	return []byte(fmt.Sprintf(`[%s,"%s"]`, t, strconv.FormatFloat(s.Value, 'f', -1, 64))), nil
+}
+
+// handleQuery evaluates a PromQL query at a single time.
+func (h *PrometheusHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	bucket, auth, err := h.decodeBucket(ctx, r)
+	if err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+
+	ts := h.Now()
+	if v := r.FormValue("time"); v != "" {
+		if ts, err = parsePromTime(v); err != nil {
+			h.encodeError(w, r, err)
+			return
+		}
+	}
+
+	q := r.FormValue("query")
+	opts := promql.FluxOptions{BucketID: bucket.ID, Start: ts, End: ts}
+	series, err := h.evaluate(ctx, auth, q, opts)
+	if err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+
+	data := &promQueryData{ResultType: "vector", Result: make([]*promSeries, 0, len(series))}
+	if promql.IsRangeSelector(q) {
+		data.ResultType = "matrix"
+	}
+	for _, s := range series {
+		ps := &promSeries{Metric: promMetric(s.Labels)}
+		switch {
+		case data.ResultType == "matrix":
+			ps.Values = promSamples(s.Samples)
+		case len(s.Samples) > 0:
+			sample := promSample(s.Samples[len(s.Samples)-1])
+			ps.Value = &sample
+		default:
+			continue
+		}
+		data.Result = append(data.Result, ps)
+	}
+	h.encodeData(w, r, data)
+}
+
+// handleQueryRange evaluates a PromQL query at the times between a start and
+// an end time, every step.
+func (h *PrometheusHandler) handleQueryRange(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	bucket, auth, err := h.decodeBucket(ctx, r)
+	if err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+
+	start, err := parsePromTime(r.FormValue("start"))
+	if err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+	end, err := parsePromTime(r.FormValue("end"))
+	if err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+	if end.Before(start) {
+		h.encodeError(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "end timestamp must not be before start time",
+		})
+		return
+	}
+	step, err := parsePromDuration(r.FormValue("step"))
+	if err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+	if step <= 0 {
+		h.encodeError(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "zero or negative query resolution step widths are not accepted",
+		})
+		return
+	}
+	if end.Sub(start)/step > maxPrometheusPoints {
+		h.encodeError(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("exceeded maximum resolution of %d points per timeseries", maxPrometheusPoints),
+		})
+		return
+	}
+
+	opts := promql.FluxOptions{BucketID: bucket.ID, Start: start, End: end, Step: step}
+	series, err := h.evaluate(ctx, auth, r.FormValue("query"), opts)
+	if err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+
+	data := &promQueryData{ResultType: "matrix", Result: make([]*promSeries, 0, len(series))}
+	for _, s := range series {
+		if len(s.Samples) > 0 {
+			data.Result = append(data.Result, &promSeries{Metric: promMetric(s.Labels), Values: promSamples(s.Samples)})
+		}
+	}
+	h.encodeData(w, r, data)
+}
+
+// handleSeries returns the label sets of the series matching selectors.
+func (h *PrometheusHandler) handleSeries(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	bucket, auth, err := h.decodeBucket(ctx, r)
+	if err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+	start, end, err := h.decodeTimeRange(r)
+	if err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+	selectors := r.Form["match[]"]
+	if len(selectors) == 0 {
+		h.encodeError(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "no match[] parameter provided",
+		})
+		return
+	}
+
+	data := []map[string]string{}
+	seen := make(map[string]bool)
+	for _, selector := range selectors {
+		matchers, err := parsePromSelector(selector)
+		if err != nil {
+			h.encodeError(w, r, err)
+			return
+		}
+
+		dialect := &promql.SeriesDialect{}
+		script := promql.SeriesFlux(matchers, start, end, promql.FluxOptions{BucketID: bucket.ID}) + "  |> last()\n"
+		if err := h.query(ctx, auth, script, dialect); err != nil {
+			h.encodeError(w, r, err)
+			return
+		}
+		for _, s := range dialect.Series {
+			metric := promMetric(s.Labels)
+			key := fmt.Sprint(s.Labels)
+			if !seen[key] {
+				seen[key] = true
+				data = append(data, metric)
+			}
+		}
+	}
+	h.encodeData(w, r, data)
+}
+
+// handleLabels returns the label names of the series of the bucket.
+func (h *PrometheusHandler) handleLabels(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	bucket, auth, err := h.decodeBucket(ctx, r)
+	if err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+	start, end, err := h.decodeTimeRange(r)
+	if err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+
+	dialect := &promql.ValuesDialect{Values: []string{}}
+	if err := h.query(ctx, auth, promql.LabelNamesFlux(start, end, promql.FluxOptions{BucketID: bucket.ID}), dialect); err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+	h.encodeData(w, r, dialect.Values)
+}
+
+// handleLabelValues returns the values of a label of the series of the
+// bucket.
+func (h *PrometheusHandler) handleLabelValues(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	bucket, auth, err := h.decodeBucket(ctx, r)
+	if err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+	start, end, err := h.decodeTimeRange(r)
+	if err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+
+	label := httprouter.ParamsFromContext(ctx).ByName("name")
+	dialect := &promql.ValuesDialect{Values: []string{}}
+	if err := h.query(ctx, auth, promql.LabelValuesFlux(label, start, end, promql.FluxOptions{BucketID: bucket.ID}), dialect); err != nil {
+		h.encodeError(w, r, err)
+		return
+	}
+	h.encodeData(w, r, dialect.Values)
+}
+
+// decodeBucket returns the bucket of a request and the authorization to
+// query it with.
+func (h *PrometheusHandler) decodeBucket(ctx context.Context, r *http.Request) (*influxdb.Bucket, *influxdb.Authorization, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unable to parse request parameters",
+			Err:  err,
+		}
+	}
+
+	orgFilter := influxdb.OrganizationFilter{}
+	if org := r.FormValue(Org); org != "" {
+		if id, err := influxdb.IDFromString(org); err == nil {
+			orgFilter.ID = id
+		} else {
+			orgFilter.Name = &org
+		}
+	}
+	if reqID := r.FormValue(OrgID); reqID != "" {
+		id, err := influxdb.IDFromString(reqID)
+		if err != nil {
+			return nil, nil, &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid orgID", Err: err}
+		}
+		orgFilter.ID = id
+	}
+	org, err := h.OrganizationService.FindOrganization(ctx, orgFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bucketFilter := influxdb.BucketFilter{OrganizationID: &org.ID}
+	if bucket := r.FormValue(Bucket); bucket != "" {
+		if id, err := influxdb.IDFromString(bucket); err == nil {
+			bucketFilter.ID = id
+		} else {
+			bucketFilter.Name = &bucket
+		}
+	}
+	if reqID := r.FormValue(BucketID); reqID != "" {
+		id, err := influxdb.IDFromString(reqID)
+		if err != nil {
+			return nil, nil, &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid bucketID", Err: err}
+		}
+		bucketFilter.ID = id
+	}
+	if bucketFilter.ID == nil && bucketFilter.Name == nil {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Please provide either bucketID or bucket",
+		}
+	}
+	bucket, err := h.BucketService.FindBucket(ctx, bucketFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "authorization is invalid or missing in the query request",
+			Err:  err,
+		}
+	}
+	var auth *influxdb.Authorization
+	switch a := a.(type) {
+	case *influxdb.Authorization:
+		auth = a
+	case *influxdb.Session:
+		auth = a.EphemeralAuth(org.ID)
+	case *jsonweb.Token:
+		auth = a.EphemeralAuth(org.ID)
+	default:
+		return nil, nil, influxdb.ErrAuthorizerNotSupported
+	}
+	return bucket, auth, nil
+}
+
+// decodeTimeRange returns the optional start and end times of a request,
+// which default to the epoch and to now.
+func (h *PrometheusHandler) decodeTimeRange(r *http.Request) (start, end time.Time, err error) {
+	start, end = time.Unix(0, 0), h.Now()
+	if v := r.FormValue("start"); v != "" {
+		if start, err = parsePromTime(v); err != nil {
+			return start, end, err
+		}
+	}
+	if v := r.FormValue("end"); v != "" {
+		if end, err = parsePromTime(v); err != nil {
+			return start, end, err
+		}
+	}
+	return start, end, nil
+}
+
+// evaluate returns the series of the result of a PromQL query.
+func (h *PrometheusHandler) evaluate(ctx context.Context, auth *influxdb.Authorization, q string, opts promql.FluxOptions) ([]*promql.Series, error) {
+	if q == "" {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "missing query",
+		}
+	}
+	script, err := promql.Flux(q, opts)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid query %q", q),
+			Err:  err,
+		}
+	}
+
+	dialect := &promql.SeriesDialect{}
+	if err := h.query(ctx, auth, script, dialect); err != nil {
+		return nil, err
+	}
+	return dialect.Series, nil
+}
+
+// query runs a Flux query whose results are collected by a dialect.
+func (h *PrometheusHandler) query(ctx context.Context, auth *influxdb.Authorization, script string, dialect flux.Dialect) error {
+	ctx = pcontext.SetAuthorizer(ctx, auth)
+	if _, err := h.ProxyQueryService.Query(ctx, ioutil.Discard, &query.ProxyRequest{
+		Request: query.Request{
+			Authorization:  auth,
+			OrganizationID: auth.OrgID,
+			Compiler:       lang.FluxCompiler{Query: script},
+		},
+		Dialect: dialect,
+	}); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EUnprocessableEntity,
+			Msg:  "unable to execute query",
+			Err:  err,
+		}
+	}
+	return nil
+}
+
+func (h *PrometheusHandler) encodeData(w http.ResponseWriter, r *http.Request, data interface{}) {
+	if err := encodeResponse(r.Context(), w, http.StatusOK, &promResponse{Status: "success", Data: data}); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// encodeError writes an error of the Prometheus query API: invalid requests
+// are bad data, failed queries execution errors.
+func (h *PrometheusHandler) encodeError(w http.ResponseWriter, r *http.Request, err error) {
+	code, errorType := http.StatusInternalServerError, promErrorInternal
+	switch influxdb.ErrorCode(err) {
+	case influxdb.EInvalid:
+		code, errorType = http.StatusBadRequest, promErrorBadData
+	case influxdb.EUnprocessableEntity:
+		code, errorType = http.StatusUnprocessableEntity, promErrorExecution
+	case influxdb.ENotFound:
+		code, errorType = http.StatusNotFound, promErrorBadData
+	case influxdb.EUnauthorized:
+		code = http.StatusUnauthorized
+	case influxdb.EForbidden:
+		code = http.StatusForbidden
+	}
+	if err := encodeResponse(r.Context(), w, code, &promResponse{Status: "error", ErrorType: errorType, Error: err.Error()}); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// parsePromTime parses a time of the Prometheus query API, a Unix time in
+// seconds or an RFC3339 time.
+func parsePromTime(s string) (time.Time, error) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(math.Round(frac*1e3))*int64(time.Millisecond)).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("cannot parse %q to a valid timestamp", s),
+		}
+	}
+	return t, nil
+}
+
+// parsePromDuration parses a duration of the Prometheus query API, a number
+// of seconds or a duration.
+func parsePromDuration(s string) (time.Duration, error) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(math.Round(f*1e3)) * time.Millisecond, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("cannot parse %q to a valid duration", s),
+		}
+	}
+	return d, nil
+}
+
+// parsePromSelector returns the label matchers of a series selector.
+func parsePromSelector(s string) ([]*promql.LabelMatcher, error) {
+	parsed, err := promql.ParsePromQL(s)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid series selector %q", s),
+			Err:  err,
+		}
+	}
+	selector, ok := parsed.(*promql.Selector)
+	if !ok || selector.Range > 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid series selector %q", s),
+		}
+	}
+
+	matchers := selector.LabelMatchers
+	if selector.Name != "" {
+		matchers = append([]*promql.LabelMatcher{{
+			Name:  "__name__",
+			Kind:  promql.Equal,
+			Value: &promql.StringLiteral{String: selector.Name},
+		}}, matchers...)
+	}
+	return matchers, nil
+}
+
+func promMetric(labels []promql.Label) map[string]string {
+	metric := make(map[string]string, len(labels))
+	for _, l := range labels {
+		metric[l.Name] = l.Value
+	}
+	return metric
+}
+
+func promSamples(samples []promql.Sample) []promSample {
+	ps := make([]promSample, len(samples))
+	for i, s := range samples {
+		ps[i] = promSample(s)
+	}
+	return ps
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query"
+	qmock "github.com/influxdata/influxdb/v2/query/mock"
+	"github.com/influxdata/influxdb/v2/query/promql"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPrometheusHandler(t *testing.T) {
+	var (
+		orgID    = influxdb.ID(1)
+		bucketID = influxdb.ID(2)
+		now      = time.Unix(1602462600, 0)
+	)
+
+	series := []*promql.Series{{
+		Labels: []promql.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "node"}},
+		Samples: []promql.Sample{
+			{Time: now.Add(-time.Minute).UnixNano(), Value: 0},
+			{Time: now.UnixNano(), Value: 1},
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		path     string
+		params   url.Values
+		wantCode int
+		wantBody string
+		wantFlux string
+	}{
+		{
+			name:     "instant query",
+			path:     "/api/v1/query",
+			params:   url.Values{"bucket": {"prometheus"}, "query": {`up{job="node"}`}},
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up","job":"node"},"value":[1602462600,"1"]}]}}`,
+			wantFlux: `r["job"] == "node"`,
+		},
+		{
+			name:     "range query",
+			path:     "/api/v1/query_range",
+			params:   url.Values{"bucketID": {bucketID.String()}, "query": {`sum(up)`}, "start": {"1602462540"}, "end": {"2020-10-12T00:30:00Z"}, "step": {"60"}},
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up","job":"node"},"values":[[1602462540,"0"],[1602462600,"1"]]}]}}`,
+			wantFlux: `window(every: 1m, period: 5m`,
+		},
+		{
+			name:     "label values",
+			path:     "/api/v1/label/job/values",
+			params:   url.Values{"bucket": {"prometheus"}},
+			wantCode: http.StatusOK,
+			wantBody: `{"status":"success","data":["node"]}`,
+			wantFlux: `distinct(column: "job")`,
+		},
+		{
+			name:     "missing bucket",
+			path:     "/api/v1/query",
+			params:   url.Values{"query": {"up"}},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"error","errorType":"bad_data","error":"Please provide either bucketID or bucket"}`,
+		},
+		{
+			name:     "invalid step",
+			path:     "/api/v1/query_range",
+			params:   url.Values{"bucket": {"prometheus"}, "query": {"up"}, "start": {"1602462540"}, "end": {"1602462600"}, "step": {"0"}},
+			wantCode: http.StatusBadRequest,
+			wantBody: `{"status":"error","errorType":"bad_data","error":"zero or negative query resolution step widths are not accepted"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var script string
+			h := NewPrometheusHandler(zaptest.NewLogger(t), &PrometheusBackend{
+				HTTPErrorHandler: kithttp.ErrorHandler(0),
+				log:              zaptest.NewLogger(t),
+				OrganizationService: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, f influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+						return &influxdb.Organization{ID: orgID}, nil
+					},
+				},
+				BucketService: &mock.BucketService{
+					FindBucketFn: func(ctx context.Context, f influxdb.BucketFilter) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{ID: bucketID, OrgID: orgID}, nil
+					},
+				},
+				ProxyQueryService: &qmock.ProxyQueryService{
+					QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+						script = req.Request.Compiler.(lang.FluxCompiler).Query
+						switch d := req.Dialect.(type) {
+						case *promql.SeriesDialect:
+							d.Series = series
+						case *promql.ValuesDialect:
+							d.Values = []string{"node"}
+						}
+						return flux.Statistics{}, nil
+					},
+				},
+			})
+			h.Now = func() time.Time { return now }
+
+			r := httptest.NewRequest("GET", prefixPrometheus+tt.path+"?"+tt.params.Encode(), nil)
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &influxdb.Authorization{OrgID: orgID, Status: influxdb.Active}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", res.StatusCode, tt.wantCode, body)
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.wantBody); err != nil || !eq {
+				t.Errorf("body = %s, want %s: %s %v", body, tt.wantBody, diff, err)
+			}
+			if !strings.Contains(script, tt.wantFlux) {
+				t.Errorf("Flux query\n%s\ndoes not contain %s", script, tt.wantFlux)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"go.uber.org/zap"
+)
+
+// RestoreBackend is all services and associated parameters required to construct the RestoreHandler.
+type RestoreBackend struct {
+	Logger *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	RestoreService influxdb.RestoreService
+}
+
+// NewRestoreBackend returns a new instance of RestoreBackend.
+func NewRestoreBackend(b *APIBackend) *RestoreBackend {
+	return &RestoreBackend{
+		Logger: b.Logger.With(zap.String("handler", "restore")),
+
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		RestoreService:   b.RestoreService,
+	}
+}
+
+// RestoreHandler is http handler for restore service.
+type RestoreHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	Logger *zap.Logger
+
+	RestoreService influxdb.RestoreService
+}
+
+const (
+	prefixRestore     = "/api/v2/restore"
+	restoreShardsPath = prefixRestore + "/buckets/:id/shards"
+)
+
+func composeRestoreShardsPath(bucketID influxdb.ID) string {
+	return path.Join(prefixRestore, "buckets", bucketID.String(), "shards")
+}
+
+// NewRestoreHandler creates a new handler at /api/v2/restore to receive restore requests.
+func NewRestoreHandler(b *RestoreBackend) *RestoreHandler {
+	h := &RestoreHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		Logger:           b.Logger,
+		RestoreService:   b.RestoreService,
+	}
+
+	h.HandlerFunc(http.MethodPost, restoreShardsPath, h.handleRestoreShard)
+
+	return h
+}
+
+// handleRestoreShard is the HTTP handler for the POST /api/v2/restore/buckets/:id/shards route.
+// The body is a shard archive from a backup fileset.
+func (h *RestoreHandler) handleRestoreShard(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "RestoreHandler.handleRestoreShard")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	bucketID, start, err := decodeRestoreShardRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.RestoreService.RestoreShard(ctx, bucketID, start, r.Body); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.Logger.Debug("Shard restored", zap.String("bucketID", bucketID.String()), zap.Time("start", start))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeRestoreShardRequest(ctx context.Context, r *http.Request) (influxdb.ID, time.Time, error) {
+	params := httprouter.ParamsFromContext(ctx)
+
+	var bucketID influxdb.ID
+	if err := bucketID.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, time.Time{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid bucket id",
+			Err:  err,
+		}
+	}
+
+	start, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("start"))
+	if err != nil {
+		return 0, time.Time{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "start must be an RFC3339 timestamp",
+			Err:  err,
+		}
+	}
+
+	return bucketID, start, nil
+}
+
+// RestoreService is the client implementation of influxdb.RestoreService.
+type RestoreService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ influxdb.RestoreService = (*RestoreService)(nil)
+
+// RestoreShard uploads a shard archive to be restored into a bucket.
+func (s *RestoreService) RestoreShard(ctx context.Context, bucketID influxdb.ID, start time.Time, r io.Reader) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	u, err := NewURL(s.Addr, composeRestoreShardsPath(bucketID))
+	if err != nil {
+		return err
+	}
+	params := u.Query()
+	params.Set("start", start.Format(time.RFC3339Nano))
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), r)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req = req.WithContext(ctx)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	hc.Timeout = httpClientTimeout
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
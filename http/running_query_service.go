@@ -0,0 +1,182 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixRunningQueries = "/api/v2/queries"
+	runningQueryIDPath   = "/api/v2/queries/:id"
+)
+
+// RunningQueryBackend is all services and associated parameters required to construct the RunningQueryHandler.
+type RunningQueryBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	RunningQueryService influxdb.RunningQueryService
+	OrganizationService influxdb.OrganizationService
+}
+
+// NewRunningQueryBackend returns a new instance of RunningQueryBackend.
+func NewRunningQueryBackend(log *zap.Logger, b *APIBackend) *RunningQueryBackend {
+	return &RunningQueryBackend{
+		log:                 log,
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		RunningQueryService: b.RunningQueryService,
+		OrganizationService: b.OrganizationService,
+	}
+}
+
+// RunningQueryHandler is the http handler for listing and killing running queries.
+type RunningQueryHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	RunningQueryService influxdb.RunningQueryService
+	OrganizationService influxdb.OrganizationService
+}
+
+// NewRunningQueryHandler creates a new handler at /api/v2/queries to list and kill running queries.
+func NewRunningQueryHandler(log *zap.Logger, b *RunningQueryBackend) *RunningQueryHandler {
+	h := &RunningQueryHandler{
+		Router:              NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		log:                 log,
+		RunningQueryService: b.RunningQueryService,
+		OrganizationService: b.OrganizationService,
+	}
+
+	h.HandlerFunc("GET", prefixRunningQueries, h.handleGetRunningQueries)
+	h.HandlerFunc("DELETE", runningQueryIDPath, h.handleDeleteRunningQuery)
+
+	return h
+}
+
+type runningQueriesResponse struct {
+	Links   map[string]string        `json:"links"`
+	Queries []*influxdb.RunningQuery `json:"queries"`
+}
+
+func newRunningQueriesResponse(queries []*influxdb.RunningQuery) *runningQueriesResponse {
+	if queries == nil {
+		queries = []*influxdb.RunningQuery{}
+	}
+	return &runningQueriesResponse{
+		Links: map[string]string{
+			"self": prefixRunningQueries,
+		},
+		Queries: queries,
+	}
+}
+
+// handleGetRunningQueries is the HTTP handler for the GET /api/v2/queries route.
+func (h *RunningQueryHandler) handleGetRunningQueries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var filter influxdb.RunningQueryFilter
+	qp := r.URL.Query()
+	if qp.Get(Org) != "" || qp.Get(OrgID) != "" {
+		o, err := queryOrganization(ctx, r, h.OrganizationService)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		filter.OrgID = &o.ID
+	}
+
+	queries, err := h.RunningQueryService.FindRunningQueries(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Running queries retrieved", zap.String("queries", fmt.Sprint(queries)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRunningQueriesResponse(queries)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteRunningQuery is the HTTP handler for the DELETE /api/v2/queries/:id route.
+func (h *RunningQueryHandler) handleDeleteRunningQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeRunningQueryID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.RunningQueryService.KillQuery(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Running query killed", zap.Uint64("queryID", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeRunningQueryID(ctx context.Context) (uint64, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id, err := strconv.ParseUint(params.ByName("id"), 10, 64)
+	if err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid query id",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
+
+// RunningQueryService lists and kills running queries over HTTP.
+type RunningQueryService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.RunningQueryService = (*RunningQueryService)(nil)
+
+// FindRunningQueries returns the running queries that match the filter.
+// The server filters by organization and the query ID is matched here.
+func (s *RunningQueryService) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	var params [][2]string
+	if filter.OrgID != nil {
+		params = append(params, [2]string{OrgID, filter.OrgID.String()})
+	}
+
+	var resp runningQueriesResponse
+	err := s.Client.
+		Get(prefixRunningQueries).
+		QueryParams(params...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.ID == nil {
+		return resp.Queries, nil
+	}
+	var queries []*influxdb.RunningQuery
+	for _, q := range resp.Queries {
+		if q.ID == *filter.ID {
+			queries = append(queries, q)
+		}
+	}
+	return queries, nil
+}
+
+// KillQuery cancels a running query.
+func (s *RunningQueryService) KillQuery(ctx context.Context, id uint64) error {
+	return s.Client.
+		Delete(prefixRunningQueries, strconv.FormatUint(id, 10)).
+		Do(ctx)
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+const prefixShards = "/api/v2/shards"
+
+// ShardBackend is all services and associated parameters required to construct the ShardHandler.
+type ShardBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	ShardService  influxdb.ShardService
+	BucketService influxdb.BucketService
+}
+
+// NewShardBackend returns a new instance of ShardBackend.
+func NewShardBackend(log *zap.Logger, b *APIBackend) *ShardBackend {
+	return &ShardBackend{
+		log:              log,
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		ShardService:     b.ShardService,
+		BucketService:    b.BucketService,
+	}
+}
+
+// ShardHandler is the http handler for inspecting the shards of buckets.
+type ShardHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	ShardService  influxdb.ShardService
+	BucketService influxdb.BucketService
+}
+
+// NewShardHandler creates a new handler at /api/v2/shards to list the shards of a bucket.
+func NewShardHandler(log *zap.Logger, b *ShardBackend) *ShardHandler {
+	h := &ShardHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+		ShardService:     b.ShardService,
+		BucketService:    b.BucketService,
+	}
+
+	h.HandlerFunc("GET", prefixShards, h.handleGetShards)
+
+	return h
+}
+
+type shardsResponse struct {
+	Links  map[string]string `json:"links"`
+	Shards []*influxdb.Shard `json:"shards"`
+}
+
+func newShardsResponse(bucketID influxdb.ID, shards []*influxdb.Shard) *shardsResponse {
+	if shards == nil {
+		shards = []*influxdb.Shard{}
+	}
+	return &shardsResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("%s?bucketID=%s", prefixShards, bucketID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", bucketID),
+		},
+		Shards: shards,
+	}
+}
+
+// handleGetShards is the HTTP handler for the GET /api/v2/shards route.
+func (h *ShardHandler) handleGetShards(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	bucketID, err := decodeGetShardsRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	// Finding the bucket ensures it exists and that the caller may read it.
+	if _, err := h.BucketService.FindBucketByID(ctx, bucketID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	shards, err := h.ShardService.FindShards(ctx, bucketID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Shards retrieved", zap.String("shards", fmt.Sprint(shards)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newShardsResponse(bucketID, shards)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeGetShardsRequest(r *http.Request) (influxdb.ID, error) {
+	var id influxdb.ID
+	bucketID := r.URL.Query().Get("bucketID")
+	if bucketID == "" {
+		return id, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bucketID is required",
+		}
+	}
+	if err := id.DecodeFromString(bucketID); err != nil {
+		return id, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid bucketID",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
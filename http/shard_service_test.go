@@ -0,0 +1,145 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	platformtesting "github.com/influxdata/influxdb/v2/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_handleGetShards(t *testing.T) {
+	type fields struct {
+		BucketService platform.BucketService
+		ShardService  platform.ShardService
+	}
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	bucketID := platformtesting.MustIDBase16("020f755c3c082000")
+	start := time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	bucketService := mock.NewBucketService()
+	bucketService.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		if id != bucketID {
+			return nil, &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}
+		}
+		return &platform.Bucket{ID: id}, nil
+	}
+	shardService := mock.NewShardService()
+	shardService.FindShardsF = func(ctx context.Context, id platform.ID) ([]*platform.Shard, error) {
+		return []*platform.Shard{
+			{
+				ID:           1,
+				BucketID:     id,
+				ShardGroupID: 1,
+				StartTime:    start,
+				EndTime:      start.Add(7 * 24 * time.Hour),
+				ExpiryTime:   start.Add(8 * 24 * time.Hour),
+				Size:         1024,
+			},
+		}, nil
+	}
+
+	tests := []struct {
+		name     string
+		fields   fields
+		bucketID string
+		wants    wants
+	}{
+		{
+			name:     "get the shards of a bucket",
+			fields:   fields{BucketService: bucketService, ShardService: shardService},
+			bucketID: "020f755c3c082000",
+			wants: wants{
+				statusCode: http.StatusOK,
+				body: `
+{
+  "links": {
+    "self": "/api/v2/shards?bucketID=020f755c3c082000",
+    "bucket": "/api/v2/buckets/020f755c3c082000"
+  },
+  "shards": [
+    {
+      "id": 1,
+      "bucketID": "020f755c3c082000",
+      "shardGroupID": 1,
+      "startTime": "2020-01-06T00:00:00Z",
+      "endTime": "2020-01-13T00:00:00Z",
+      "expiryTime": "2020-01-14T00:00:00Z",
+      "size": 1024
+    }
+  ]
+}`,
+			},
+		},
+		{
+			name:     "get the shards of a bucket without shards",
+			fields:   fields{BucketService: bucketService, ShardService: mock.NewShardService()},
+			bucketID: "020f755c3c082000",
+			wants: wants{
+				statusCode: http.StatusOK,
+				body: `
+{
+  "links": {
+    "self": "/api/v2/shards?bucketID=020f755c3c082000",
+    "bucket": "/api/v2/buckets/020f755c3c082000"
+  },
+  "shards": []
+}`,
+			},
+		},
+		{
+			name:   "missing bucket id",
+			fields: fields{BucketService: bucketService, ShardService: shardService},
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:     "bucket not found",
+			fields:   fields{BucketService: bucketService, ShardService: shardService},
+			bucketID: "020f755c3c082001",
+			wants: wants{
+				statusCode: http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewShardHandler(zaptest.NewLogger(t), &ShardBackend{
+				HTTPErrorHandler: kithttp.ErrorHandler(0),
+				BucketService:    tt.fields.BucketService,
+				ShardService:     tt.fields.ShardService,
+			})
+
+			r := httptest.NewRequest("GET", "http://any.url/api/v2/shards?bucketID="+tt.bucketID, nil)
+			w := httptest.NewRecorder()
+
+			h.handleGetShards(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("%q. handleGetShards() = %v, want %v", tt.name, res.StatusCode, tt.wants.statusCode)
+			}
+			if tt.wants.body == "" {
+				return
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil || !eq {
+				t.Errorf("%q. handleGetShards() = ***%v***", tt.name, diff)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
@@ -17,6 +18,8 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/kv"
 	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"github.com/influxdata/influxdb/v2/task/backend"
+	"github.com/influxdata/influxdb/v2/task/options"
 	"go.uber.org/zap"
 )
 
@@ -71,6 +74,7 @@ const (
 	prefixTasks            = "/api/v2/tasks"
 	tasksIDPath            = "/api/v2/tasks/:id"
 	tasksIDLogsPath        = "/api/v2/tasks/:id/logs"
+	tasksIDBackfillPath    = "/api/v2/tasks/:id/backfill"
 	tasksIDMembersPath     = "/api/v2/tasks/:id/members"
 	tasksIDMembersIDPath   = "/api/v2/tasks/:id/members/:userID"
 	tasksIDOwnersPath      = "/api/v2/tasks/:id/owners"
@@ -135,6 +139,7 @@ func NewTaskHandler(log *zap.Logger, b *TaskBackend) *TaskHandler {
 
 	h.HandlerFunc("GET", tasksIDRunsPath, h.handleGetRuns)
 	h.HandlerFunc("POST", tasksIDRunsPath, h.handleForceRun)
+	h.HandlerFunc("POST", tasksIDBackfillPath, h.handleBackfillTask)
 	h.HandlerFunc("GET", tasksIDRunsIDPath, h.handleGetRun)
 	h.HandlerFunc("POST", tasksIDRunsIDRetryPath, h.handleRetryRun)
 	h.HandlerFunc("DELETE", tasksIDRunsIDPath, h.handleCancelRun)
@@ -172,6 +177,8 @@ type Task struct {
 	CreatedAt       string                 `json:"createdAt,omitempty"`
 	UpdatedAt       string                 `json:"updatedAt,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	DependsOn       []influxdb.ID          `json:"dependsOn,omitempty"`
+	Trigger         *options.Trigger       `json:"trigger,omitempty"`
 }
 
 type taskResponse struct {
@@ -217,6 +224,8 @@ func NewFrontEndTask(t influxdb.Task) Task {
 		CreatedAt:       createdAt,
 		UpdatedAt:       updatedAt,
 		Metadata:        t.Metadata,
+		DependsOn:       t.DependsOn,
+		Trigger:         t.Trigger,
 	}
 }
 
@@ -1110,6 +1119,171 @@ func decodeForceRunRequest(ctx context.Context, r *http.Request) (forceRunReques
 	}, nil
 }
 
+// handleBackfillTask forces the runs of a task scheduled in a range, and
+// streams the progress of the backfill as a line of JSON per completed run,
+// followed by a last line once the backfill is done.
+func (h *TaskHandler) handleBackfillTask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeBackfillTaskRequest(ctx, r)
+	if err != nil {
+		err = &influxdb.Error{
+			Err:  err,
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	task, err := h.TaskService.FindTaskByID(ctx, req.TaskID)
+	if err != nil {
+		err := &influxdb.Error{
+			Err: err,
+			Msg: "failed to find task",
+		}
+		if err.Err == influxdb.ErrTaskNotFound {
+			err.Code = influxdb.ENotFound
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	// the range is checked before the progress is streamed, so that an
+	// invalid one is still responded to with an error status
+	if _, err := backend.BackfillTimes(task, req.Start, req.Stop); err != nil {
+		err := &influxdb.Error{
+			Err: err,
+			Msg: "failed to backfill task",
+		}
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	var (
+		enc        = json.NewEncoder(w)
+		flusher, _ = w.(http.Flusher)
+		encodeErr  error
+	)
+	encode := func(p backfillProgressResponse) {
+		if encodeErr != nil {
+			return
+		}
+		if encodeErr = enc.Encode(p); encodeErr != nil {
+			logEncodingError(h.log, r, encodeErr)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	// the backfill stops when the client goes away, the runs that completed
+	// until then were already streamed to it
+	progress, err := backend.Backfill(ctx, h.TaskService, task, req.Start, req.Stop, backend.BackfillOptions{
+		Concurrency: req.Concurrency,
+		Progress: func(p backend.BackfillProgress) {
+			encode(newBackfillProgressResponse(p))
+		},
+	})
+
+	progress.Run = nil
+	done := newBackfillProgressResponse(progress)
+	done.Done = true
+	if err != nil {
+		done.Error = &influxdb.Error{
+			Err: err,
+			Msg: "failed to backfill task",
+		}
+	}
+	encode(done)
+}
+
+type backfillTaskRequest struct {
+	TaskID      influxdb.ID
+	Start       time.Time
+	Stop        time.Time
+	Concurrency int
+}
+
+func decodeBackfillTaskRequest(ctx context.Context, r *http.Request) (backfillTaskRequest, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	tid := params.ByName("id")
+	if tid == "" {
+		return backfillTaskRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "you must provide a task ID",
+		}
+	}
+
+	var ti influxdb.ID
+	if err := ti.DecodeFromString(tid); err != nil {
+		return backfillTaskRequest{}, err
+	}
+
+	var req struct {
+		Start       time.Time `json:"start"`
+		Stop        time.Time `json:"stop"`
+		Concurrency int       `json:"concurrency"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return backfillTaskRequest{}, err
+	}
+	if req.Start.IsZero() || req.Stop.IsZero() {
+		return backfillTaskRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "start and stop are required",
+		}
+	}
+	if req.Concurrency < 0 || req.Concurrency > backend.MaxBackfillConcurrency {
+		return backfillTaskRequest{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("concurrency must be between 1 and %d", backend.MaxBackfillConcurrency),
+		}
+	}
+
+	return backfillTaskRequest{
+		TaskID:      ti,
+		Start:       req.Start,
+		Stop:        req.Stop,
+		Concurrency: req.Concurrency,
+	}, nil
+}
+
+// backfillProgressResponse is a line of the streamed progress of a backfill.
+type backfillProgressResponse struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Canceled  int `json:"canceled"`
+	Skipped   int `json:"skipped"`
+
+	// Run is the run that completed, nil if it was skipped and on the last line.
+	Run *runResponse `json:"run,omitempty"`
+
+	// Done is set on the last line, with the error the backfill stopped on if any.
+	Done  bool            `json:"done,omitempty"`
+	Error *influxdb.Error `json:"error,omitempty"`
+}
+
+func newBackfillProgressResponse(p backend.BackfillProgress) backfillProgressResponse {
+	r := backfillProgressResponse{
+		Total:     p.Total,
+		Succeeded: p.Succeeded,
+		Failed:    p.Failed,
+		Canceled:  p.Canceled,
+		Skipped:   p.Skipped,
+	}
+	if p.Run != nil {
+		rs := newRunResponse(*p.Run)
+		r.Run = &rs
+	}
+	return r
+}
+
 func (h *TaskHandler) handleGetRun(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -1676,6 +1850,67 @@ func (t TaskService) ForceRun(ctx context.Context, taskID influxdb.ID, scheduled
 	return convertRun(rs.httpRun), nil
 }
 
+// BackfillTask runs a task for every time it was scheduled for between start
+// and stop, inclusive, queueing concurrency runs at once. The progress of the
+// backfill is passed to fn, when not nil, each time a run completes. It
+// returns once the backfill is done, with the runs that completed counted in
+// the progress when it stopped on an error.
+func (t TaskService) BackfillTask(ctx context.Context, taskID influxdb.ID, start, stop time.Time, concurrency int, fn func(backend.BackfillProgress)) (backend.BackfillProgress, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b := struct {
+		Start       time.Time `json:"start"`
+		Stop        time.Time `json:"stop"`
+		Concurrency int       `json:"concurrency,omitempty"`
+	}{Start: start, Stop: stop, Concurrency: concurrency}
+
+	var (
+		progress    backend.BackfillProgress
+		backfillErr error
+	)
+	err := t.Client.
+		PostJSON(b, taskIDBackfillPath(taskID)).
+		Decode(func(resp *http.Response) error {
+			dec := json.NewDecoder(resp.Body)
+			for {
+				var p backfillProgressResponse
+				if err := dec.Decode(&p); err != nil {
+					if err == io.EOF {
+						err = io.ErrUnexpectedEOF
+					}
+					return err
+				}
+
+				progress = backend.BackfillProgress{
+					Total:     p.Total,
+					Succeeded: p.Succeeded,
+					Failed:    p.Failed,
+					Canceled:  p.Canceled,
+					Skipped:   p.Skipped,
+				}
+				if p.Done {
+					if p.Error != nil {
+						backfillErr = p.Error
+					}
+					return nil
+				}
+				if p.Run != nil {
+					progress.Run = convertRun(p.Run.httpRun)
+				}
+				if fn != nil {
+					fn(progress)
+				}
+			}
+		}).
+		Do(ctx)
+	if err == nil {
+		err = backfillErr
+	}
+	progress.Run = nil
+	return progress, err
+}
+
 func cancelPath(taskID, runID influxdb.ID) string {
 	return path.Join(taskID.String(), runID.String())
 }
@@ -1704,6 +1939,10 @@ func taskIDRunsPath(id influxdb.ID) string {
 	return path.Join(prefixTasks, id.String(), "runs")
 }
 
+func taskIDBackfillPath(id influxdb.ID) string {
+	return path.Join(prefixTasks, id.String(), "backfill")
+}
+
 func taskIDRunIDPath(taskID, runID influxdb.ID) string {
 	return path.Join(prefixTasks, taskID.String(), "runs", runID.String())
 }
@@ -9,7 +9,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -22,6 +24,7 @@ import (
 	"github.com/influxdata/influxdb/v2/label"
 	"github.com/influxdata/influxdb/v2/mock"
 	_ "github.com/influxdata/influxdb/v2/query/builtin"
+	"github.com/influxdata/influxdb/v2/task/backend"
 	"github.com/influxdata/influxdb/v2/tenant"
 	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
 	"go.uber.org/zap"
@@ -698,6 +701,194 @@ func TestTaskHandler_handleGetRun(t *testing.T) {
 	}
 }
 
+// newBackfillTaskService returns a task service with a task of ID 1 that runs
+// every hour. The run scheduled an hour after start fails, and the one
+// scheduled two hours after start is not found with findErr when it is set.
+func newBackfillTaskService(start time.Time, findErr error) *mock.TaskService {
+	var mu sync.Mutex
+	runs := make(map[influxdb.ID]*influxdb.Run)
+	return &mock.TaskService{
+		FindTaskByIDFn: func(ctx context.Context, id influxdb.ID) (*influxdb.Task, error) {
+			if id != 1 {
+				return nil, influxdb.ErrTaskNotFound
+			}
+			return &influxdb.Task{ID: id, Every: "1h"}, nil
+		},
+		ForceRunFn: func(ctx context.Context, taskID influxdb.ID, scheduledFor int64) (*influxdb.Run, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			r := &influxdb.Run{ID: influxdb.ID(len(runs) + 1), TaskID: taskID, ScheduledFor: time.Unix(scheduledFor, 0).UTC(), Status: "success"}
+			runs[r.ID] = r
+			if r.ScheduledFor.Equal(start.Add(time.Hour)) {
+				r.Status = "failed"
+				return r, errors.New("run failed")
+			}
+			return r, nil
+		},
+		FindRunByIDFn: func(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			r := runs[runID]
+			if findErr != nil && r.ScheduledFor.Equal(start.Add(2*time.Hour)) {
+				return nil, findErr
+			}
+			return r, nil
+		},
+	}
+}
+
+func TestTaskHandler_handleBackfillTask(t *testing.T) {
+	start := time.Date(2020, 10, 12, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		taskID     influxdb.ID
+		body       string
+		statusCode int
+		backfill   *backfillProgressResponse
+	}{
+		{
+			name:       "backfill a task",
+			taskID:     1,
+			body:       `{"start": "2020-10-12T10:00:00Z", "stop": "2020-10-12T12:00:00Z"}`,
+			statusCode: http.StatusOK,
+			backfill:   &backfillProgressResponse{Total: 3, Succeeded: 2, Failed: 1, Done: true},
+		},
+		{
+			name:       "missing stop",
+			taskID:     1,
+			body:       `{"start": "2020-10-12T10:00:00Z"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "stop before start",
+			taskID:     1,
+			body:       `{"start": "2020-10-12T10:00:00Z", "stop": "2020-10-12T09:00:00Z"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "concurrency too high",
+			taskID:     1,
+			body:       `{"start": "2020-10-12T10:00:00Z", "stop": "2020-10-12T12:00:00Z", "concurrency": 1000}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "missing task",
+			taskID:     2,
+			body:       `{"start": "2020-10-12T10:00:00Z", "stop": "2020-10-12T12:00:00Z"}`,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "http://any.url", strings.NewReader(tt.body))
+			r = r.WithContext(context.WithValue(
+				context.Background(),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: tt.taskID.String(),
+					},
+				}))
+			w := httptest.NewRecorder()
+			taskBackend := NewMockTaskBackend(t)
+			taskBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			taskBackend.TaskService = newBackfillTaskService(start, nil)
+			h := NewTaskHandler(zaptest.NewLogger(t), taskBackend)
+			h.handleBackfillTask(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.statusCode {
+				t.Fatalf("handleBackfillTask() = %v, want %v: %s", res.StatusCode, tt.statusCode, body)
+			}
+			if tt.backfill == nil {
+				return
+			}
+			if ct := res.Header.Get("Content-Type"); ct != "application/x-ndjson; charset=utf-8" {
+				t.Errorf("got content type %q", ct)
+			}
+
+			// a line per run, followed by the last one
+			var lines []backfillProgressResponse
+			dec := json.NewDecoder(bytes.NewReader(body))
+			for dec.More() {
+				var line backfillProgressResponse
+				if err := dec.Decode(&line); err != nil {
+					t.Fatal(err)
+				}
+				lines = append(lines, line)
+			}
+			if len(lines) != tt.backfill.Total+1 {
+				t.Fatalf("got %d lines, want %d: %s", len(lines), tt.backfill.Total+1, body)
+			}
+			for i, line := range lines[:len(lines)-1] {
+				if line.Run == nil || line.Done {
+					t.Errorf("line %d is not a run: %+v", i, line)
+				}
+				if n := line.Succeeded + line.Failed + line.Canceled + line.Skipped; n != i+1 {
+					t.Errorf("line %d counts %d completed runs, want %d", i, n, i+1)
+				}
+			}
+			if got := lines[len(lines)-1]; !reflect.DeepEqual(got, *tt.backfill) {
+				t.Errorf("got last line %+v, want %+v", got, *tt.backfill)
+			}
+		})
+	}
+}
+
+func TestTaskService_BackfillTask(t *testing.T) {
+	start := time.Date(2020, 10, 12, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		findErr  error
+		runs     []string
+		backfill backend.BackfillProgress
+		errCode  string
+	}{
+		{
+			name:     "backfill a task",
+			runs:     []string{"success", "failed", "success"},
+			backfill: backend.BackfillProgress{Total: 3, Succeeded: 2, Failed: 1},
+		},
+		{
+			name:     "stops on an error",
+			findErr:  &influxdb.Error{Code: influxdb.EUnavailable, Msg: "unavailable"},
+			runs:     []string{"success", "failed"},
+			backfill: backend.BackfillProgress{Total: 3, Succeeded: 1, Failed: 1},
+			errCode:  influxdb.EUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taskBackend := NewMockTaskBackend(t)
+			taskBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			taskBackend.TaskService = newBackfillTaskService(start, tt.findErr)
+			server := httptest.NewServer(NewTaskHandler(zaptest.NewLogger(t), taskBackend))
+			defer server.Close()
+
+			svc := TaskService{Client: mustNewHTTPClient(t, server.URL, "")}
+			var runs []string
+			got, err := svc.BackfillTask(context.Background(), 1, start, start.Add(2*time.Hour), 1, func(p backend.BackfillProgress) {
+				runs = append(runs, p.Run.Status)
+			})
+			if code := influxdb.ErrorCode(err); code != tt.errCode {
+				t.Fatalf("got error %v, want code %q", err, tt.errCode)
+			}
+			if !reflect.DeepEqual(runs, tt.runs) {
+				t.Errorf("got runs %v, want %v", runs, tt.runs)
+			}
+			if !reflect.DeepEqual(got, tt.backfill) {
+				t.Errorf("got backfill %+v, want %+v", got, tt.backfill)
+			}
+		})
+	}
+}
+
 func TestTaskHandler_handleGetRuns(t *testing.T) {
 	type fields struct {
 		taskService influxdb.TaskService
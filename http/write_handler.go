@@ -1,11 +1,17 @@
 package http
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"sort"
+	"strconv"
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
@@ -16,6 +22,7 @@ import (
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
 	"go.uber.org/zap"
 )
 
@@ -176,10 +183,15 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Partial {
+		requestBytes = h.writePartial(ctx, sw, r, org.ID, bucket.ID, req)
+		return
+	}
+
 	// TODO: Backport?
 	//opts := append([]models.ParserOption{}, h.parserOptions...)
 	//opts = append(opts, models.WithParserPrecision(req.Precision))
-	parsed, err := points.NewParser(req.Precision).Parse(ctx, org.ID, bucket.ID, req.Body)
+	parsed, err := (&points.Parser{Precision: req.Precision, Format: req.Format}).Parse(ctx, org.ID, bucket.ID, req.Body)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, sw)
 		return
@@ -199,6 +211,89 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	sw.WriteHeader(http.StatusNoContent)
 }
 
+// writeReport is the response to a partial write, reporting the lines that
+// were rejected.
+type writeReport struct {
+	// Accepted is the number of points written.
+	Accepted int `json:"accepted"`
+	// Dropped is the number of points the storage engine dropped.
+	Dropped int `json:"dropped"`
+	// Invalid is the number of lines that failed to parse.
+	Invalid int `json:"invalid"`
+	// Rejected holds the lines that failed to parse or were dropped, in
+	// order. The line is 0 for points dropped without a known line.
+	Rejected []rejectedLine `json:"rejected"`
+}
+
+type rejectedLine struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// writePartial writes the valid lines of a request and responds with a
+// writeReport. It returns the size of the request body.
+func (h *WriteHandler) writePartial(ctx context.Context, w http.ResponseWriter, r *http.Request, orgID, bucketID influxdb.ID, req *writeRequest) int {
+	parsed, err := (&points.Parser{Precision: req.Precision, Format: req.Format}).ParsePartial(ctx, orgID, bucketID, req.Body)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return 0
+	}
+
+	report := writeReport{
+		Accepted: len(parsed.Points),
+		Invalid:  len(parsed.Invalid),
+		Rejected: make([]rejectedLine, 0, len(parsed.Invalid)),
+	}
+	for _, lerr := range parsed.Invalid {
+		report.Rejected = append(report.Rejected, rejectedLine{Line: lerr.Line, Reason: lerr.Err.Error()})
+	}
+
+	if len(parsed.Points) > 0 {
+		err := h.PointsWriter.WritePoints(ctx, orgID, bucketID, parsed.Points)
+		var partial tsdb.PartialWriteError
+		if err != nil && !errors.As(err, &partial) {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInternal,
+				Op:   opWriteHandler,
+				Msg:  "unexpected error writing points to database",
+				Err:  err,
+			}, w)
+			return parsed.RawSize
+		}
+		if err != nil {
+			report.Accepted -= partial.Dropped
+			report.Dropped = partial.Dropped
+			report.Rejected = append(report.Rejected, droppedLines(parsed, partial)...)
+		}
+	}
+
+	sort.SliceStable(report.Rejected, func(i, j int) bool {
+		return report.Rejected[i].Line < report.Rejected[j].Line
+	})
+	if err := encodeResponse(ctx, w, http.StatusOK, report); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+	return parsed.RawSize
+}
+
+// droppedLines returns the lines of the points a partial write dropped.
+func droppedLines(parsed *points.ParsedPoints, partial tsdb.PartialWriteError) []rejectedLine {
+	lines := make(map[models.Point]int, len(parsed.Points))
+	for i, l := range parsed.Lines {
+		lines[parsed.Points[i]] = l
+	}
+
+	rejected := make([]rejectedLine, 0, partial.Dropped)
+	for _, d := range partial.DroppedPoints {
+		rejected = append(rejected, rejectedLine{Line: lines[d.Point], Reason: d.Reason})
+	}
+	// Points may be dropped without being reported one by one.
+	if n := partial.Dropped - len(partial.DroppedPoints); n > 0 {
+		rejected = append(rejected, rejectedLine{Reason: fmt.Sprintf("%s; %d points dropped", partial.Reason, n)})
+	}
+	return rejected
+}
+
 // checkBucketWritePermissions checks an Authorizer for write permissions to a
 // specific Bucket.
 func checkBucketWritePermissions(auth influxdb.Authorizer, orgID, bucketID influxdb.ID) error {
@@ -229,6 +324,12 @@ type writeRequest struct {
 	Bucket    string
 	Precision string
 	Body      io.ReadCloser
+	// Format is the format of Body, as given by its Content-Type.
+	Format string
+
+	// Partial writes the valid lines of a batch with invalid lines, and
+	// reports the rejected lines.
+	Partial bool
 }
 
 // decodeWriteRequest extracts information from an http.Request object to
@@ -263,11 +364,24 @@ func decodeWriteRequest(ctx context.Context, r *http.Request, maxBatchSizeBytes
 		return nil, err
 	}
 
+	var partial bool
+	if v := qp.Get("partial"); v != "" {
+		if partial, err = strconv.ParseBool(v); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Op:   "http/newWriteRequest",
+				Msg:  "invalid partial; expected true or false",
+			}
+		}
+	}
+
 	return &writeRequest{
 		Bucket:    qp.Get("bucket"),
 		Org:       qp.Get("org"),
 		Precision: precision,
 		Body:      body,
+		Format:    points.ContentFormat(r.Header.Get("Content-Type")),
+		Partial:   partial,
 	}, nil
 }
 
@@ -277,6 +391,10 @@ type WriteService struct {
 	Token              string
 	Precision          string
 	InsecureSkipVerify bool
+
+	// Rejected, if set, makes writes partial: the valid lines of a batch are
+	// written, and Rejected is called with each line the server rejects.
+	Rejected func(line, reason string)
 }
 
 var _ influxdb.WriteService = (*WriteService)(nil)
@@ -300,6 +418,15 @@ func (s *WriteService) Write(ctx context.Context, orgID, bucketID influxdb.ID, r
 		return err
 	}
 
+	// The batch is kept to look up the lines the server rejects.
+	var batch []byte
+	if s.Rejected != nil {
+		if batch, err = ioutil.ReadAll(r); err != nil {
+			return err
+		}
+		r = bytes.NewReader(batch)
+	}
+
 	r, err = compressWithGzip(r)
 	if err != nil {
 		return err
@@ -328,6 +455,9 @@ func (s *WriteService) Write(ctx context.Context, orgID, bucketID influxdb.ID, r
 	params.Set("org", string(org))
 	params.Set("bucket", string(bucket))
 	params.Set("precision", string(precision))
+	if s.Rejected != nil {
+		params.Set("partial", "true")
+	}
 	req.URL.RawQuery = params.Encode()
 
 	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
@@ -338,7 +468,23 @@ func (s *WriteService) Write(ctx context.Context, orgID, bucketID influxdb.ID, r
 	}
 	defer resp.Body.Close()
 
-	return CheckError(resp)
+	if err := CheckError(resp); err != nil || s.Rejected == nil {
+		return err
+	}
+
+	var report writeReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return err
+	}
+	lines := bytes.Split(batch, []byte{'\n'})
+	for _, rejected := range report.Rejected {
+		var line string
+		if rejected.Line > 0 && rejected.Line <= len(lines) {
+			line = string(bytes.TrimSuffix(lines[rejected.Line-1], []byte{'\r'}))
+		}
+		s.Rejected(line, rejected.Reason)
+	}
+	return nil
 }
 
 func compressWithGzip(data io.Reader) (io.Reader, error) {
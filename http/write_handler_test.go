@@ -8,15 +8,20 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/golang/snappy"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/http/metric"
 	httpmock "github.com/influxdata/influxdb/v2/http/mock"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/prometheus/prompb"
 	influxtesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/influxdata/influxdb/v2/tsdb"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -77,6 +82,34 @@ func TestWriteService_Write(t *testing.T) {
 	}
 }
 
+func TestWriteService_Write_Rejected(t *testing.T) {
+	var partial string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		partial = r.URL.Query().Get("partial")
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte(`{"accepted":1,"dropped":1,"invalid":1,"rejected":[{"line":2,"reason":"invalid field format"},{"line":3,"reason":"points beyond retention policy"}]}`))
+	}))
+	defer ts.Close()
+
+	var rejected []string
+	s := &WriteService{
+		Addr: ts.URL,
+		Rejected: func(line, reason string) {
+			rejected = append(rejected, reason+": "+line)
+		},
+	}
+	if err := s.Write(context.Background(), 1, 2, strings.NewReader("m f=1\r\nm f=\r\nm f=3 0")); err != nil {
+		t.Fatalf("WriteService.Write() error = %v", err)
+	}
+	if partial != "true" {
+		t.Errorf("WriteService.Write() partial = %q, want true", partial)
+	}
+	want := []string{"invalid field format: m f=", "points beyond retention policy: m f=3 0"}
+	if !reflect.DeepEqual(rejected, want) {
+		t.Errorf("WriteService.Write() rejected = %v, want %v", rejected, want)
+	}
+}
+
 func TestWriteHandler_handleWrite(t *testing.T) {
 	// state is the internal state of org and bucket services
 	type state struct {
@@ -85,7 +118,8 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 		bucket    *influxdb.Bucket       // bucket to return in bucket service
 		bucketErr error                  // err to return in bucket service
 		writeErr  error                  // err to return from the points writer
-		opts      []WriteHandlerOption   // write handle configured options
+		writeFn   func(context.Context, influxdb.ID, influxdb.ID, []models.Point) error
+		opts      []WriteHandlerOption // write handle configured options
 	}
 
 	// want is the expected output of the HTTP endpoint
@@ -96,10 +130,37 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 
 	// request is sent to the HTTP endpoint
 	type request struct {
-		auth   influxdb.Authorizer
-		org    string
-		bucket string
-		body   string
+		auth        influxdb.Authorizer
+		org         string
+		bucket      string
+		body        string
+		contentType string
+		partial     bool
+	}
+
+	// expectPoints returns a points writer func expecting points in line
+	// protocol.
+	expectPoints := func(want ...string) func(context.Context, influxdb.ID, influxdb.ID, []models.Point) error {
+		return func(_ context.Context, _, _ influxdb.ID, pts []models.Point) error {
+			got := make([]string, len(pts))
+			for i, p := range pts {
+				got[i] = p.String()
+			}
+			if !reflect.DeepEqual(got, want) {
+				return fmt.Errorf("unexpected points %v", got)
+			}
+			return nil
+		}
+	}
+
+	promWrite, err := (&prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "node"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1602462600000}},
+		}},
+	}).Marshal()
+	if err != nil {
+		t.Fatal(err)
 	}
 
 	tests := []struct {
@@ -292,6 +353,126 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				body: `{"code":"request too large","message":"unable to read data: points batch is too large"}`,
 			},
 		},
+		{
+			name: "partial write reports invalid lines",
+			request: request{
+				org:     "043e0780ee2b1000",
+				bucket:  "04504b356e23b000",
+				body:    "m1,t1=v1 f1=1\ninvalid\nm1,t1=v1 f1=2 1",
+				auth:    bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+				partial: true,
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 200,
+				body: `{"accepted":2,"dropped":0,"invalid":1,"rejected":[{"line":2,"reason":"missing fields"}]}` + "\n",
+			},
+		},
+		{
+			name: "partial write reports dropped points",
+			request: request{
+				org:     "043e0780ee2b1000",
+				bucket:  "04504b356e23b000",
+				body:    "m1 f1=1 1\nm1 f1=\"a\" 2\nm1 f1 3\nm1 f1=1 4",
+				auth:    bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+				partial: true,
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+				writeFn: func(ctx context.Context, orgID, bucketID influxdb.ID, points []models.Point) error {
+					reason := `field type conflict: input field "f1" on measurement "m1" is type string, already exists as type float`
+					return tsdb.PartialWriteError{
+						Reason:        reason,
+						Dropped:       2,
+						DroppedPoints: []tsdb.DroppedPoint{{Point: points[1], Reason: reason}},
+					}
+				},
+			},
+			wants: wants{
+				code: 200,
+				body: `{"accepted":1,"dropped":2,"invalid":1,"rejected":[` +
+					`{"line":0,"reason":"field type conflict: input field \"f1\" on measurement \"m1\" is type string, already exists as type float; 1 points dropped"},` +
+					`{"line":2,"reason":"field type conflict: input field \"f1\" on measurement \"m1\" is type string, already exists as type float"},` +
+					`{"line":3,"reason":"invalid field format"}]}` + "\n",
+			},
+		},
+		{
+			name: "partial write of invalid lines only writes nothing",
+			request: request{
+				org:     "043e0780ee2b1000",
+				bucket:  "04504b356e23b000",
+				body:    "invalid",
+				auth:    bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+				partial: true,
+			},
+			state: state{
+				org:      testOrg("043e0780ee2b1000"),
+				bucket:   testBucket("043e0780ee2b1000", "04504b356e23b000"),
+				writeErr: fmt.Errorf("unexpected write"),
+			},
+			wants: wants{
+				code: 200,
+				body: `{"accepted":0,"dropped":0,"invalid":1,"rejected":[{"line":1,"reason":"missing fields"}]}` + "\n",
+			},
+		},
+		{
+			name: "json body is accepted",
+			request: request{
+				org:         "043e0780ee2b1000",
+				bucket:      "04504b356e23b000",
+				body:        `[{"measurement": "m1", "tags": {"t1": "v1"}, "fields": {"f1": 1, "f2": {"integer": 2}}, "time": 10}]`,
+				contentType: "application/json",
+				auth:        bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			state: state{
+				org:     testOrg("043e0780ee2b1000"),
+				bucket:  testBucket("043e0780ee2b1000", "04504b356e23b000"),
+				writeFn: expectPoints("m1,t1=v1 f1=1,f2=2i 10"),
+			},
+			wants: wants{
+				code: 204,
+			},
+		},
+		{
+			name: "invalid json point returns 400 error",
+			request: request{
+				org:         "043e0780ee2b1000",
+				bucket:      "04504b356e23b000",
+				body:        `{"fields": {"f1": 1}}`,
+				contentType: "application/json",
+				auth:        bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"unable to parse '{\"fields\": {\"f1\": 1}}': missing measurement"}`,
+			},
+		},
+		{
+			name: "prometheus remote write is accepted",
+			request: request{
+				org:         "043e0780ee2b1000",
+				bucket:      "04504b356e23b000",
+				body:        string(snappy.Encode(nil, promWrite)),
+				contentType: "application/x-protobuf",
+				auth:        bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			state: state{
+				org:     testOrg("043e0780ee2b1000"),
+				bucket:  testBucket("043e0780ee2b1000", "04504b356e23b000"),
+				writeFn: expectPoints("up,job=node value=1 1602462600000000000"),
+			},
+			wants: wants{
+				code: 204,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -309,7 +490,7 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				Logger:              zaptest.NewLogger(t),
 				OrganizationService: orgs,
 				BucketService:       buckets,
-				PointsWriter:        &mock.PointsWriter{Err: tt.state.writeErr},
+				PointsWriter:        &mock.PointsWriter{Err: tt.state.writeErr, WritePointsFn: tt.state.writeFn},
 				WriteEventRecorder:  &metric.NopEventRecorder{},
 			}
 			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b), tt.state.opts...)
@@ -320,10 +501,16 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				"http://localhost:8086/api/v2/write",
 				strings.NewReader(tt.request.body),
 			)
+			if tt.request.contentType != "" {
+				r.Header.Set("Content-Type", tt.request.contentType)
+			}
 
 			params := r.URL.Query()
 			params.Set("org", tt.request.org)
 			params.Set("bucket", tt.request.bucket)
+			if tt.request.partial {
+				params.Set("partial", "true")
+			}
 			r.URL.RawQuery = params.Encode()
 
 			w := httptest.NewRecorder()
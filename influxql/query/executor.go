@@ -10,10 +10,12 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	iql "github.com/influxdata/influxdb/v2/influxql"
 	"github.com/influxdata/influxdb/v2/influxql/control"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/models"
+	v2query "github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxql"
 	"github.com/opentracing/opentracing-go/log"
 	"go.uber.org/zap"
@@ -172,6 +174,9 @@ type Executor struct {
 
 	Metrics *control.ControllerMetrics
 
+	// Registry, if set, tracks the running queries so that they can be killed.
+	Registry *v2query.Registry
+
 	log *zap.Logger
 }
 
@@ -206,6 +211,24 @@ func (e *Executor) executeQuery(ctx context.Context, query *influxql.Query, opt
 
 	defer e.recover(query, results)
 
+	if e.Registry != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+
+		rq := influxdb.RunningQuery{
+			OrgID:    opt.OrgID,
+			Language: influxdb.InfluxQLQueryLanguage,
+			Query:    query.String(),
+			Database: opt.Database,
+		}
+		if a, err := icontext.GetAuthorizer(ctx); err == nil {
+			rq.UserID = a.GetUserID()
+		}
+		_, unregister := e.Registry.Register(rq, queryTracker{cancel: cancel})
+		defer unregister()
+	}
+
 	gatherer := new(iql.StatisticsGatherer)
 
 	statusLabel := control.LabelSuccess
@@ -329,6 +352,17 @@ LOOP:
 	}
 }
 
+// queryTracker reports the progress of an InfluxQL query to the registry.
+type queryTracker struct {
+	cancel context.CancelFunc
+}
+
+func (t queryTracker) Cancel() { t.cancel() }
+
+// Progress reports the query as running. The memory used by
+// InfluxQL queries is not accounted for.
+func (t queryTracker) Progress() (string, int64) { return "running", 0 }
+
 // Determines if the Executor will recover any panics or let them crash
 // the server.
 var willCrash bool
@@ -6,6 +6,7 @@ import (
 
 	"github.com/influxdata/influxdb/v2/influxql/query"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"github.com/influxdata/influxql"
 	"go.uber.org/zap"
@@ -30,6 +31,7 @@ type TSDBStoreMock struct {
 	ImportShardFn             func(id uint64, r io.Reader) error
 	MeasurementSeriesCountsFn func(database string) (measuments int, series int)
 	MeasurementsCardinalityFn func(database string) (int64, error)
+	MeasurementsSketchesFn    func(database string) (estimator.Sketch, estimator.Sketch, error)
 	MeasurementNamesFn        func(auth query.Authorizer, database string, cond influxql.Expr) ([][]byte, error)
 	OpenFn                    func() error
 	PathFn                    func() string
@@ -101,6 +103,9 @@ func (s *TSDBStoreMock) MeasurementSeriesCounts(database string) (measuments int
 func (s *TSDBStoreMock) MeasurementsCardinality(database string) (int64, error) {
 	return s.MeasurementsCardinalityFn(database)
 }
+func (s *TSDBStoreMock) MeasurementsSketches(database string) (estimator.Sketch, estimator.Sketch, error) {
+	return s.MeasurementsSketchesFn(database)
+}
 func (s *TSDBStoreMock) Open() error {
 	return s.OpenFn()
 }
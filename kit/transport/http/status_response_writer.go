@@ -26,6 +26,14 @@ func (w *StatusResponseWriter) WriteHeader(statusCode int) {
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Flush sends the buffered response to the client, when the underlying
+// ResponseWriter supports it, so that streamed responses are not held up.
+func (w *StatusResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func (w *StatusResponseWriter) Code() int {
 	code := w.statusCode
 	if code == 0 {
@@ -0,0 +1,17 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var (
+	legacyAuthBucket         = []byte("legacy/authorizationsv1")
+	legacyAuthIndexBucket    = []byte("legacy/authorizationindexv1")
+	legacyAuthPasswordBucket = []byte("legacy/authorizationpasswordv1")
+)
+
+// Migration0008_AddLegacyAuthBuckets creates the buckets necessary for the v1 users to operate.
+var Migration0008_AddLegacyAuthBuckets = migration.CreateBuckets(
+	"create legacy authorization buckets",
+	legacyAuthBucket,
+	legacyAuthIndexBucket,
+	legacyAuthPasswordBucket,
+)
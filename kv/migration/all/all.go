@@ -21,5 +21,7 @@ var Migrations = [...]migration.Spec{
 	Migration0006_DeleteBucketSessionsv1,
 	// CreateMetaDataBucket
 	Migration0007_CreateMetaDataBucket,
+	// add legacy authorization buckets
+	Migration0008_AddLegacyAuthBuckets,
 	// {{ do_not_edit . }}
 }
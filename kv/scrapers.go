@@ -167,7 +167,12 @@ func (s *Service) addTarget(ctx context.Context, tx Tx, target *influxdb.Scraper
 		return ErrInvalidScrapersBucketID
 	}
 
+	if err := target.Validate(); err != nil {
+		return err
+	}
+
 	target.ID = s.IDGenerator.ID()
+	target.Health = nil
 	if err := s.putTarget(ctx, tx, target); err != nil {
 		return err
 	}
@@ -241,10 +246,44 @@ func (s *Service) updateTarget(ctx context.Context, tx Tx, update *influxdb.Scra
 	if !update.OrgID.Valid() {
 		update.OrgID = target.OrgID
 	}
+	if update.Type == "" {
+		update.Type = target.Type
+	}
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	// the health is only recorded by the scrapes
+	update.Health = target.Health
 	target = update
 	return target, s.putTarget(ctx, tx, target)
 }
 
+// UpdateTargetsHealth records the health of the last scrapes of targets in a
+// single transaction. The targets that were removed are skipped.
+func (s *Service) UpdateTargetsHealth(ctx context.Context, healths map[influxdb.ID]influxdb.ScraperTargetHealth) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		for id, health := range healths {
+			target, err := s.findTargetByID(ctx, tx, id)
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			health := health
+			if health.LastSuccess == nil && target.Health != nil {
+				health.LastSuccess = target.Health.LastSuccess
+			}
+			target.Health = &health
+			if err := s.putTarget(ctx, tx, target); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // GetTargetByID retrieves a scraper target by id.
 func (s *Service) GetTargetByID(ctx context.Context, id influxdb.ID) (*influxdb.ScraperTarget, error) {
 	var target *influxdb.ScraperTarget
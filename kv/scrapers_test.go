@@ -3,7 +3,9 @@ package kv_test
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kv"
 	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
@@ -57,3 +59,72 @@ func initScraperTargetStoreService(s kv.SchemaStore, f influxdbtesting.TargetFie
 		}
 	}
 }
+
+func TestScraperTargetHealth(t *testing.T) {
+	s, closeFn, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeFn()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	target := &influxdb.ScraperTarget{
+		ID:       1,
+		Name:     "target",
+		Type:     influxdb.PrometheusScraperType,
+		URL:      "http://localhost:9100/metrics",
+		OrgID:    2,
+		BucketID: 3,
+	}
+	if err := svc.PutTarget(ctx, target); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	success := influxdb.ScraperTargetHealth{
+		LastScrape:   start,
+		LastSuccess:  &start,
+		LastDuration: influxdb.Duration{Duration: time.Second},
+	}
+	if err := svc.UpdateTargetsHealth(ctx, map[influxdb.ID]influxdb.ScraperTargetHealth{target.ID: success}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a failed scrape keeps the last success
+	failure := influxdb.ScraperTargetHealth{
+		LastScrape:   start.Add(time.Minute),
+		LastError:    "connection refused",
+		LastDuration: influxdb.Duration{Duration: 2 * time.Second},
+	}
+	// a removed target does not fail the others
+	if err := svc.UpdateTargetsHealth(ctx, map[influxdb.ID]influxdb.ScraperTargetHealth{target.ID: failure, 4: success}); err != nil {
+		t.Fatal(err)
+	}
+	want := failure
+	want.LastSuccess = &start
+
+	got, err := svc.GetTargetByID(ctx, target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&want, got.Health); diff != "" {
+		t.Fatalf("unexpected health -want/+got:\n%s", diff)
+	}
+
+	// updates of the target keep its health
+	update := *target
+	update.Name = "renamed"
+	update.Health = nil
+	got, err = svc.UpdateTarget(ctx, &update, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&want, got.Health); diff != "" {
+		t.Fatalf("unexpected health after update -want/+got:\n%s", diff)
+	}
+
+	if _, err := svc.GetTargetByID(ctx, 4); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("got error %v finding missing target", err)
+	}
+}
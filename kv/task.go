@@ -56,6 +56,8 @@ type kvTask struct {
 	CreatedAt       time.Time              `json:"createdAt,omitempty"`
 	UpdatedAt       time.Time              `json:"updatedAt,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	DependsOn       []influxdb.ID          `json:"dependsOn,omitempty"`
+	Trigger         *options.Trigger       `json:"trigger,omitempty"`
 }
 
 func kvToInfluxTask(k *kvTask) *influxdb.Task {
@@ -81,6 +83,8 @@ func kvToInfluxTask(k *kvTask) *influxdb.Task {
 		CreatedAt:       k.CreatedAt,
 		UpdatedAt:       k.UpdatedAt,
 		Metadata:        k.Metadata,
+		DependsOn:       k.DependsOn,
+		Trigger:         k.Trigger,
 	}
 }
 
@@ -555,6 +559,7 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 		Flux:            tc.Flux,
 		Every:           opts.Every.String(),
 		Cron:            opts.Cron,
+		Trigger:         opts.Trigger,
 		CreatedAt:       createdAt,
 		LatestCompleted: createdAt,
 		LatestScheduled: createdAt,
@@ -569,6 +574,13 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 
 	}
 
+	if len(tc.DependsOn) > 0 {
+		if err := s.validateTaskDependencies(ctx, tx, task, tc.DependsOn); err != nil {
+			return nil, err
+		}
+		task.DependsOn = tc.DependsOn
+	}
+
 	taskBucket, err := tx.Bucket(taskBucket)
 	if err != nil {
 		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
@@ -663,6 +675,7 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		task.Name = opts.Name
 		task.Every = opts.Every.String()
 		task.Cron = opts.Cron
+		task.Trigger = opts.Trigger
 
 		var off time.Duration
 		if opts.Offset != nil {
@@ -675,6 +688,14 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		task.UpdatedAt = updatedAt
 	}
 
+	if upd.DependsOn != nil {
+		if err := s.validateTaskDependencies(ctx, tx, task, *upd.DependsOn); err != nil {
+			return nil, err
+		}
+		task.DependsOn = *upd.DependsOn
+		task.UpdatedAt = updatedAt
+	}
+
 	if upd.Description != nil {
 		task.Description = *upd.Description
 		task.UpdatedAt = updatedAt
@@ -779,6 +800,52 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 	return task, nil
 }
 
+// validateTaskDependencies checks that the upstream tasks of a task belong to its
+// organization and that none of them depends, directly or not, on the task.
+func (s *Service) validateTaskDependencies(ctx context.Context, tx Tx, task *influxdb.Task, dependsOn []influxdb.ID) error {
+	var upstream []influxdb.ID
+	for _, id := range dependsOn {
+		if id == task.ID {
+			return influxdb.ErrTaskDependencyCycle
+		}
+		t, err := s.findTaskByID(ctx, tx, id)
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				return influxdb.ErrInvalidTaskDependency(id)
+			}
+			return err
+		}
+		if t.OrganizationID != task.OrganizationID {
+			return influxdb.ErrInvalidTaskDependency(id)
+		}
+		upstream = append(upstream, t.DependsOn...)
+	}
+
+	visited := make(map[influxdb.ID]bool)
+	for len(upstream) > 0 {
+		id := upstream[0]
+		upstream = upstream[1:]
+		if id == task.ID {
+			return influxdb.ErrTaskDependencyCycle
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		t, err := s.findTaskByID(ctx, tx, id)
+		if err != nil {
+			// a deleted task cannot be part of a cycle
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				continue
+			}
+			return err
+		}
+		upstream = append(upstream, t.DependsOn...)
+	}
+	return nil
+}
+
 // DeleteTask removes a task by ID and purges all associated data and scheduled runs.
 func (s *Service) DeleteTask(ctx context.Context, id influxdb.ID) error {
 	err := s.kv.Update(ctx, func(tx Tx) error {
@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.RunningQueryService = &RunningQueryService{}
+
+// RunningQueryService is a mock running query service.
+type RunningQueryService struct {
+	FindRunningQueriesF func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error)
+	KillQueryF          func(ctx context.Context, id uint64) error
+}
+
+// NewRunningQueryService returns a mock RunningQueryService where its methods
+// will return zero values.
+func NewRunningQueryService() *RunningQueryService {
+	return &RunningQueryService{
+		FindRunningQueriesF: func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+			return nil, nil
+		},
+		KillQueryF: func(ctx context.Context, id uint64) error {
+			return nil
+		},
+	}
+}
+
+// FindRunningQueries calls FindRunningQueriesF.
+func (s *RunningQueryService) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	return s.FindRunningQueriesF(ctx, filter)
+}
+
+// KillQuery calls KillQueryF.
+func (s *RunningQueryService) KillQuery(ctx context.Context, id uint64) error {
+	return s.KillQueryF(ctx, id)
+}
@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.ShardService = &ShardService{}
+
+// ShardService is a mock shard service.
+type ShardService struct {
+	FindShardsF  func(ctx context.Context, bucketID influxdb.ID) ([]*influxdb.Shard, error)
+	DeleteShardF func(ctx context.Context, id uint64) error
+}
+
+// NewShardService returns a mock ShardService where its methods will return
+// zero values.
+func NewShardService() *ShardService {
+	return &ShardService{
+		FindShardsF: func(ctx context.Context, bucketID influxdb.ID) ([]*influxdb.Shard, error) {
+			return nil, nil
+		},
+		DeleteShardF: func(ctx context.Context, id uint64) error {
+			return nil
+		},
+	}
+}
+
+// FindShards calls FindShardsF.
+func (s *ShardService) FindShards(ctx context.Context, bucketID influxdb.ID) ([]*influxdb.Shard, error) {
+	return s.FindShardsF(ctx, bucketID)
+}
+
+// DeleteShard calls DeleteShardF.
+func (s *ShardService) DeleteShard(ctx context.Context, id uint64) error {
+	return s.DeleteShardF(ctx, id)
+}
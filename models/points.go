@@ -357,15 +357,71 @@ func ValidPrecision(precision string) bool {
 // This can have the unintended effect preventing buf from being garbage collected.
 func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision string) ([]Point, error) {
 	points := make([]Point, 0, bytes.Count(buf, []byte{'\n'})+1)
+	var failed []string
+	parseLines(buf, defaultTime, precision, func(_ int, pt Point, err *LineError) {
+		if err != nil {
+			failed = append(failed, err.Error())
+		} else {
+			points = append(points, pt)
+		}
+	})
+	if len(failed) > 0 {
+		return points, fmt.Errorf("%s", strings.Join(failed, "\n"))
+	}
+	return points, nil
+
+}
+
+// LineError is the error of a line that failed to parse.
+type LineError struct {
+	// Line is the number of the line, starting at 1.
+	Line int
+	// Text is the text of the line.
+	Text string
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("unable to parse '%s': %v", e.Text, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// ParsePointsWithLines is similar to ParsePointsWithPrecision, but returns
+// the line number of each point and the errors of the lines that failed to
+// parse, rather than a single error.
+func ParsePointsWithLines(buf []byte, defaultTime time.Time, precision string) (points []Point, lines []int, failed []*LineError) {
+	n := bytes.Count(buf, []byte{'\n'}) + 1
+	points, lines = make([]Point, 0, n), make([]int, 0, n)
+	parseLines(buf, defaultTime, precision, func(line int, pt Point, err *LineError) {
+		if err != nil {
+			failed = append(failed, err)
+		} else {
+			points = append(points, pt)
+			lines = append(lines, line)
+		}
+	})
+	return points, lines, failed
+}
+
+// parseLines calls fn with the point, or the error, of each line of buf
+// holding a point. A line starts at a newline outside of a string field.
+func parseLines(buf []byte, defaultTime time.Time, precision string, fn func(line int, pt Point, err *LineError)) {
 	var (
-		pos    int
-		block  []byte
-		failed []string
+		pos   int
+		block []byte
+		line  = 1
 	)
 	for pos < len(buf) {
 		pos, block = scanLine(buf, pos)
 		pos++
 
+		// a block ends at a newline, and may hold newlines of string fields
+		blockLine := line
+		line += bytes.Count(block, []byte{'\n'}) + 1
+
 		if len(block) == 0 {
 			continue
 		}
@@ -389,17 +445,11 @@ func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision strin
 
 		pt, err := parsePoint(block[start:], defaultTime, precision)
 		if err != nil {
-			failed = append(failed, fmt.Sprintf("unable to parse '%s': %v", string(block[start:]), err))
+			fn(blockLine, nil, &LineError{Line: blockLine, Text: string(block[start:]), Err: err})
 		} else {
-			points = append(points, pt)
+			fn(blockLine, pt, nil)
 		}
-
-	}
-	if len(failed) > 0 {
-		return points, fmt.Errorf("%s", strings.Join(failed, "\n"))
 	}
-	return points, nil
-
 }
 
 func parsePoint(buf []byte, defaultTime time.Time, precision string) (Point, error) {
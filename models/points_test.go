@@ -1830,6 +1830,36 @@ func TestParsePointsWithPrecision(t *testing.T) {
 	}
 }
 
+func TestParsePointsWithLines(t *testing.T) {
+	buf := "cpu value=1 1\n" +
+		"\n" +
+		"# comment\n" +
+		"cpu value= 2\n" +
+		"cpu value=\"multi\nline\" 3\n" +
+		"cpu 4\n" +
+		"cpu value=5 5"
+
+	pts, lines, failed := models.ParsePointsWithLines([]byte(buf), time.Unix(0, 0), "n")
+	if got, exp := len(pts), 3; got != exp {
+		t.Fatalf("ParsePointsWithLines() points len mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := lines, []int{1, 5, 8}; !reflect.DeepEqual(got, exp) {
+		t.Errorf("ParsePointsWithLines() lines mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := len(failed), 2; got != exp {
+		t.Fatalf("ParsePointsWithLines() failed len mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := failed[0].Line, 4; got != exp {
+		t.Errorf("ParsePointsWithLines() failed line mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := failed[1].Line, 7; got != exp {
+		t.Errorf("ParsePointsWithLines() failed line mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := failed[1].Error(), "unable to parse 'cpu 4': invalid field format"; got != exp {
+		t.Errorf("ParsePointsWithLines() error mismatch: got %q, exp %q", got, exp)
+	}
+}
+
 func TestParsePointsWithPrecisionNoTime(t *testing.T) {
 	line := `cpu,host=serverA,region=us-east value=1.0`
 	tm, _ := time.Parse(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z")
@@ -16,6 +16,13 @@ var ErrNoNatsConnection = errors.New("nats connection has not been established.
 type Server struct {
 	serverOpts *server.Options
 	Server     *sserver.StanServer
+
+	// FilestoreDir is the directory messages are persisted in. Messages are
+	// kept in memory if it is empty.
+	FilestoreDir string
+
+	// ChannelLimits overrides the default limits of specific channels.
+	ChannelLimits map[string]*stores.ChannelLimits
 }
 
 // Open starts a NATS streaming server
@@ -24,6 +31,13 @@ func (s *Server) Open() error {
 	opts := sserver.GetDefaultOptions()
 	opts.StoreType = stores.TypeMemory
 	opts.ID = ServerName
+	if s.FilestoreDir != "" {
+		opts.StoreType = stores.TypeFile
+		opts.FilestoreDir = s.FilestoreDir
+	}
+	for name, limits := range s.ChannelLimits {
+		opts.StoreLimits.AddPerChannel(name, limits)
+	}
 
 	server, err := sserver.RunServerWithOpts(opts, s.serverOpts)
 	if err != nil {
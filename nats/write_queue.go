@@ -0,0 +1,436 @@
+package nats
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/nats-io/gnatsd/server"
+	stan "github.com/nats-io/go-nats-streaming"
+	"github.com/nats-io/nats-streaming-server/stores"
+	"go.uber.org/zap"
+)
+
+// Acknowledgment modes of a WriteQueue.
+const (
+	// WriteAckEnqueued acknowledges a write once it is persisted in the queue.
+	WriteAckEnqueued = "enqueued"
+	// WriteAckApplied acknowledges a write once the engine has applied it.
+	WriteAckApplied = "applied"
+)
+
+const (
+	// WriteQueueSubject is the channel queued writes are published to.
+	WriteQueueSubject = "writes"
+
+	writeQueueDurableName = "write-queue"
+
+	// writeMessageHeaderLen is the length of the kind, ID, org ID and bucket
+	// ID preceding the line protocol of a message.
+	writeMessageHeaderLen = 1 + 3*8
+
+	// writeMessageOverhead bounds the bytes the server stores for a message
+	// in addition to its data: the envelope of the message and the header of
+	// its record in the file store.
+	writeMessageOverhead = 64
+
+	writeMessageMarker byte = 0
+	writeMessagePoints byte = 1
+)
+
+// ErrWriteQueueFull is returned when a write would exceed the bytes of the
+// writes a WriteQueue may hold.
+var ErrWriteQueueFull = &influxdb.Error{
+	Code: influxdb.EUnavailable,
+	Msg:  "write queue is full; retry later",
+}
+
+// WriteQueueChannelLimits returns the limits of the channel of a WriteQueue
+// holding up to maxBytes of writes not yet applied. The server only discards
+// messages to reclaim space beyond twice maxBytes. Since the queue rejects the
+// writes that exceed maxBytes, the messages discarded are always ones the
+// queue has applied.
+func WriteQueueChannelLimits(maxBytes int64) *stores.ChannelLimits {
+	return &stores.ChannelLimits{
+		MsgStoreLimits: stores.MsgStoreLimits{
+			MaxMsgs:  -1,
+			MaxBytes: 2 * maxBytes,
+			MaxAge:   -1,
+		},
+	}
+}
+
+// WriteQueueConfig configures a WriteQueue.
+type WriteQueueConfig struct {
+	// AckMode is WriteAckEnqueued or WriteAckApplied.
+	AckMode string
+	// MaxBytes bounds the bytes of the writes not yet applied by the engine,
+	// including the bytes the server stores for each message.
+	MaxBytes int64
+	// MaxMessageBytes bounds the size of a message; writes are split into
+	// messages of at most this size. It defaults to the default maximum
+	// payload of the NATS server, less room for the streaming envelope.
+	MaxMessageBytes int
+}
+
+// WriteQueue is a storage.PointsWriter persisting writes in a NATS streaming
+// channel before they are applied to the underlying PointsWriter, so writes
+// are accepted while the engine stalls. The writes left in the channel when
+// the queue closes are replayed when it opens again. The server of the queue
+// should store messages in files, and limit the channel with
+// WriteQueueChannelLimits.
+//
+// Messages are applied in order, one at a time. A message may be applied
+// twice if the server redelivers it, which rewrites the same points.
+type WriteQueue struct {
+	ClientID string
+	Addr     string
+
+	config WriteQueueConfig
+	writer storage.PointsWriter
+	log    *zap.Logger
+
+	conn   stan.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending int64
+	writes  map[uint64]*queuedWrite
+	marker  uint64
+	ready   chan struct{}
+}
+
+type queuedWrite struct {
+	size   int64
+	points []models.Point
+	done   chan error
+}
+
+// NewWriteQueue returns a WriteQueue applying writes to writer.
+func NewWriteQueue(log *zap.Logger, clientID, addr string, config WriteQueueConfig, writer storage.PointsWriter) *WriteQueue {
+	if config.MaxMessageBytes == 0 {
+		config.MaxMessageBytes = server.MAX_PAYLOAD_SIZE - 1024
+	}
+	return &WriteQueue{
+		ClientID: clientID,
+		Addr:     addr,
+		config:   config,
+		writer:   writer,
+		log:      log,
+		writes:   make(map[uint64]*queuedWrite),
+	}
+}
+
+// Open connects to the NATS server and replays the writes left in the queue.
+// It returns once they have been applied.
+func (q *WriteQueue) Open(ctx context.Context) error {
+	switch q.config.AckMode {
+	case WriteAckEnqueued, WriteAckApplied:
+	default:
+		return fmt.Errorf("invalid write queue acknowledgment mode %q", q.config.AckMode)
+	}
+	if q.config.MaxMessageBytes <= writeMessageHeaderLen {
+		return fmt.Errorf("write queue message size must be greater than %d", writeMessageHeaderLen)
+	}
+
+	sc, err := stan.Connect(ServerName, q.ClientID, stan.NatsURL(q.Addr))
+	if err != nil {
+		return err
+	}
+	q.conn = sc
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+
+	// IDs start at the current time so they differ from those of the
+	// messages replayed from an earlier run.
+	q.nextID = uint64(time.Now().UnixNano())
+	q.marker = q.nextID
+	q.nextID++
+	q.ready = make(chan struct{})
+
+	_, err = sc.Subscribe(WriteQueueSubject, q.handle,
+		stan.DurableName(writeQueueDurableName),
+		stan.SetManualAckMode(),
+		stan.MaxInflight(1),
+		stan.AckWait(time.Minute),
+	)
+	if err != nil {
+		_ = sc.Close()
+		return err
+	}
+
+	// The marker is delivered after the messages left in the channel, so
+	// the replay is complete once it is handled.
+	if err := sc.Publish(WriteQueueSubject, encodeWriteMessage(writeMessageMarker, q.marker, 0, 0, nil)); err != nil {
+		q.Close()
+		return err
+	}
+	select {
+	case <-q.ready:
+		return nil
+	case <-ctx.Done():
+		q.Close()
+		return ctx.Err()
+	}
+}
+
+// Close stops applying writes and closes the connection. Writes not yet
+// applied stay in the queue.
+func (q *WriteQueue) Close() {
+	if q.conn == nil {
+		return
+	}
+	q.cancel()
+	if err := q.conn.Close(); err != nil {
+		q.log.Info("Failed to close write queue connection", zap.Error(err))
+	}
+	q.conn = nil
+}
+
+// WritePoints publishes points to the queue. Depending on the acknowledgment
+// mode it returns once they are persisted, or once they are applied.
+func (q *WriteQueue) WritePoints(ctx context.Context, orgID influxdb.ID, bucketID influxdb.ID, points []models.Point) error {
+	if len(points) == 0 {
+		return nil
+	}
+	if q.conn == nil {
+		return ErrNoNatsConnection
+	}
+
+	messages, err := q.encodePoints(orgID, bucketID, points)
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	for _, m := range messages {
+		size += int64(len(m.data)) + writeMessageOverhead
+	}
+
+	q.mu.Lock()
+	if q.pending+size > q.config.MaxBytes {
+		q.mu.Unlock()
+		return ErrWriteQueueFull
+	}
+	q.pending += size
+	ids := make([]uint64, len(messages))
+	for i, m := range messages {
+		ids[i] = q.nextID
+		q.nextID++
+		binary.BigEndian.PutUint64(m.data[1:9], ids[i])
+		q.writes[ids[i]] = &queuedWrite{size: int64(len(m.data)) + writeMessageOverhead, points: m.points, done: make(chan error, 1)}
+	}
+	waits := make([]*queuedWrite, len(ids))
+	for i, id := range ids {
+		waits[i] = q.writes[id]
+	}
+	q.mu.Unlock()
+
+	for i, m := range messages {
+		if err := q.conn.Publish(WriteQueueSubject, m.data); err != nil {
+			// Messages not published will never be applied.
+			for _, id := range ids[i:] {
+				q.finish(id, nil, nil)
+			}
+			return &influxdb.Error{
+				Code: influxdb.EUnavailable,
+				Msg:  "failed to enqueue write",
+				Err:  err,
+			}
+		}
+	}
+
+	if q.config.AckMode == WriteAckEnqueued {
+		return nil
+	}
+	var partial error
+	for _, w := range waits {
+		select {
+		case err := <-w.done:
+			if perr, ok := err.(tsdb.PartialWriteError); ok {
+				partial = perr.Merge(partial)
+			} else if err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return partial
+}
+
+// writeMessage is an encoded message and the points it holds.
+type writeMessage struct {
+	data   []byte
+	points []models.Point
+}
+
+// encodePoints encodes points as line protocol in messages of at most
+// MaxMessageBytes. The ID of a message is set when it is published.
+func (q *WriteQueue) encodePoints(orgID, bucketID influxdb.ID, points []models.Point) ([]writeMessage, error) {
+	var (
+		messages []writeMessage
+		buf      = encodeWriteMessage(writeMessagePoints, 0, orgID, bucketID, nil)
+		first    int
+	)
+	for i, p := range points {
+		line := p.String()
+		if writeMessageHeaderLen+len(line)+1 > q.config.MaxMessageBytes {
+			return nil, &influxdb.Error{
+				Code: influxdb.ETooLarge,
+				Msg:  fmt.Sprintf("point of %d bytes exceeds the write queue message size of %d bytes", len(line), q.config.MaxMessageBytes),
+			}
+		}
+		if len(buf)+len(line)+1 > q.config.MaxMessageBytes {
+			messages = append(messages, writeMessage{data: buf, points: points[first:i]})
+			buf = encodeWriteMessage(writeMessagePoints, 0, orgID, bucketID, nil)
+			first = i
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	return append(messages, writeMessage{data: buf, points: points[first:]}), nil
+}
+
+// handle applies a message to the underlying PointsWriter, retrying until it
+// succeeds, fails with an error retrying will not fix, or the queue closes.
+func (q *WriteQueue) handle(m *stan.Msg) {
+	kind, id, orgID, bucketID, data, err := decodeWriteMessage(m.Data)
+	if err != nil {
+		q.log.Error("Dropping invalid write queue message", zap.Uint64("sequence", m.Sequence), zap.Error(err))
+		q.ack(m)
+		return
+	}
+
+	if kind == writeMessageMarker {
+		q.ack(m)
+		if id == q.marker {
+			select {
+			case <-q.ready:
+			default:
+				close(q.ready)
+			}
+		}
+		return
+	}
+
+	points, err := models.ParsePoints(data)
+	if err == nil {
+		err = q.apply(orgID, bucketID, points)
+	} else {
+		err = &influxdb.Error{Code: influxdb.EInvalid, Err: err}
+	}
+	if q.ctx.Err() != nil {
+		// Closing; the message is redelivered when the queue opens again.
+		return
+	}
+	if err != nil {
+		q.log.Error("Dropping write that failed to apply",
+			zap.String("org_id", orgID.String()),
+			zap.String("bucket_id", bucketID.String()),
+			zap.Error(err))
+	}
+	q.ack(m)
+	q.finish(id, points, err)
+}
+
+func (q *WriteQueue) apply(orgID, bucketID influxdb.ID, points []models.Point) error {
+	backoff := 100 * time.Millisecond
+	for {
+		err := q.writer.WritePoints(q.ctx, orgID, bucketID, points)
+		if err == nil || !retryableWriteError(err) {
+			return err
+		}
+
+		q.log.Warn("Failed to apply queued write; retrying", zap.Duration("backoff", backoff), zap.Error(err))
+		select {
+		case <-time.After(backoff):
+		case <-q.ctx.Done():
+			return q.ctx.Err()
+		}
+		if backoff *= 2; backoff > 10*time.Second {
+			backoff = 10 * time.Second
+		}
+	}
+}
+
+// retryableWriteError reports whether applying a write again may succeed.
+func retryableWriteError(err error) bool {
+	switch influxdb.ErrorCode(err) {
+	case influxdb.EInvalid, influxdb.ENotFound, influxdb.EUnprocessableEntity, influxdb.EForbidden, influxdb.ETooLarge:
+		return false
+	}
+	// Writes that dropped points drop them again when retried.
+	var partial tsdb.PartialWriteError
+	return !errors.As(err, &partial)
+}
+
+func (q *WriteQueue) ack(m *stan.Msg) {
+	if err := m.Ack(); err != nil {
+		q.log.Info("Failed to acknowledge write queue message", zap.Uint64("sequence", m.Sequence), zap.Error(err))
+	}
+}
+
+// finish releases the bytes of a write published by this queue and reports
+// its result to WritePoints. The points dropped by a partial write are
+// replaced by the points given to WritePoints, which applied decodes.
+func (q *WriteQueue) finish(id uint64, applied []models.Point, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	w, ok := q.writes[id]
+	if !ok {
+		return
+	}
+	delete(q.writes, id)
+	q.pending -= w.size
+
+	if perr, ok := err.(tsdb.PartialWriteError); ok && len(applied) == len(w.points) {
+		index := make(map[models.Point]int, len(applied))
+		for i, p := range applied {
+			index[p] = i
+		}
+		dropped := make([]tsdb.DroppedPoint, len(perr.DroppedPoints))
+		for i, d := range perr.DroppedPoints {
+			dropped[i] = d
+			if j, ok := index[d.Point]; ok {
+				dropped[i].Point = w.points[j]
+			}
+		}
+		perr.DroppedPoints = dropped
+		err = perr
+	}
+	w.done <- err
+}
+
+func encodeWriteMessage(kind byte, id uint64, orgID, bucketID influxdb.ID, data []byte) []byte {
+	buf := make([]byte, writeMessageHeaderLen, writeMessageHeaderLen+len(data))
+	buf[0] = kind
+	binary.BigEndian.PutUint64(buf[1:9], id)
+	binary.BigEndian.PutUint64(buf[9:17], uint64(orgID))
+	binary.BigEndian.PutUint64(buf[17:25], uint64(bucketID))
+	return append(buf, data...)
+}
+
+func decodeWriteMessage(buf []byte) (kind byte, id uint64, orgID, bucketID influxdb.ID, data []byte, err error) {
+	if len(buf) < writeMessageHeaderLen {
+		return 0, 0, 0, 0, nil, fmt.Errorf("message of %d bytes is too short", len(buf))
+	}
+	kind = buf[0]
+	if kind != writeMessageMarker && kind != writeMessagePoints {
+		return 0, 0, 0, 0, nil, fmt.Errorf("unknown message kind %d", kind)
+	}
+	id = binary.BigEndian.Uint64(buf[1:9])
+	orgID = influxdb.ID(binary.BigEndian.Uint64(buf[9:17]))
+	bucketID = influxdb.ID(binary.BigEndian.Uint64(buf[17:25]))
+	return kind, id, orgID, bucketID, bytes.TrimSpace(buf[writeMessageHeaderLen:]), nil
+}
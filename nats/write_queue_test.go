@@ -0,0 +1,274 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/nats-io/nats-streaming-server/stores"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestWriteQueue(t *testing.T) {
+	const (
+		orgID    = influxdb.ID(1)
+		bucketID = influxdb.ID(2)
+	)
+
+	points := func(t *testing.T, lines string) []models.Point {
+		t.Helper()
+		pts, err := models.ParsePointsString(lines)
+		require.NoError(t, err)
+		return pts
+	}
+
+	t.Run("applied acknowledgment", func(t *testing.T) {
+		s, addr := newTestServer(t, "", nil)
+		defer s.Close()
+
+		w := newRecordingWriter()
+		q := NewWriteQueue(zaptest.NewLogger(t), "applied", addr, WriteQueueConfig{
+			AckMode:         WriteAckApplied,
+			MaxBytes:        1 << 20,
+			MaxMessageBytes: 64,
+		}, w)
+		require.NoError(t, q.Open(context.Background()))
+		defer q.Close()
+
+		// The points are split in a message per point.
+		require.NoError(t, q.WritePoints(context.Background(), orgID, bucketID, points(t, "cpu,host=a value=1 10\ncpu,host=b value=2 20")))
+		assert.Equal(t, []string{"cpu,host=a value=1 10", "cpu,host=b value=2 20"}, w.lines())
+
+		w.fail(&influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"})
+		err := q.WritePoints(context.Background(), orgID, bucketID, points(t, "cpu value=3 30"))
+		assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+	})
+
+	t.Run("partial writes report the points written", func(t *testing.T) {
+		s, addr := newTestServer(t, "", nil)
+		defer s.Close()
+
+		q := NewWriteQueue(zaptest.NewLogger(t), "partial", addr, WriteQueueConfig{
+			AckMode:         WriteAckApplied,
+			MaxBytes:        1 << 20,
+			MaxMessageBytes: 64,
+		}, droppingWriter{})
+		require.NoError(t, q.Open(context.Background()))
+		defer q.Close()
+
+		pts := points(t, "cpu,host=a value=1 10\ncpu,host=b value=2 20")
+		err := q.WritePoints(context.Background(), orgID, bucketID, pts)
+		perr, ok := err.(tsdb.PartialWriteError)
+		require.True(t, ok, "unexpected error %v", err)
+		assert.Equal(t, 2, perr.Dropped)
+		require.Len(t, perr.DroppedPoints, 2)
+		assert.True(t, perr.DroppedPoints[0].Point == pts[0])
+		assert.True(t, perr.DroppedPoints[1].Point == pts[1])
+	})
+
+	t.Run("enqueued acknowledgment is bounded", func(t *testing.T) {
+		s, addr := newTestServer(t, "", nil)
+		defer s.Close()
+
+		w := newRecordingWriter()
+		w.stall()
+		q := NewWriteQueue(zaptest.NewLogger(t), "enqueued", addr, WriteQueueConfig{
+			AckMode:         WriteAckEnqueued,
+			MaxBytes:        250,
+			MaxMessageBytes: 1024,
+		}, w)
+		require.NoError(t, q.Open(context.Background()))
+		defer q.Close()
+
+		require.NoError(t, q.WritePoints(context.Background(), orgID, bucketID, points(t, "cpu value=1 10")))
+		require.NoError(t, q.WritePoints(context.Background(), orgID, bucketID, points(t, "cpu value=2 20")))
+		err := q.WritePoints(context.Background(), orgID, bucketID, points(t, "cpu,host=a,region=west value=3 30"))
+		assert.Equal(t, ErrWriteQueueFull, err)
+
+		w.resume()
+		require.Eventually(t, func() bool { return len(w.lines()) == 2 }, 5*time.Second, 10*time.Millisecond)
+		require.NoError(t, q.WritePoints(context.Background(), orgID, bucketID, points(t, "cpu,host=a,region=west value=3 30")))
+	})
+
+	t.Run("limited channel keeps writes not applied", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "write-queue")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		const maxBytes = 1000
+		s, addr := newTestServer(t, dir, WriteQueueChannelLimits(maxBytes))
+		defer s.Close()
+
+		w := newRecordingWriter()
+		q := NewWriteQueue(zaptest.NewLogger(t), "limited", addr, WriteQueueConfig{
+			AckMode:         WriteAckEnqueued,
+			MaxBytes:        maxBytes,
+			MaxMessageBytes: 1024,
+		}, w)
+		require.NoError(t, q.Open(context.Background()))
+		defer q.Close()
+
+		// Fill the channel past its limits with writes that are applied.
+		var want []string
+		for i := 0; i < 50; i++ {
+			line := fmt.Sprintf("cpu value=%d %d", i, i)
+			require.NoError(t, q.WritePoints(context.Background(), orgID, bucketID, points(t, line)))
+			want = append(want, line)
+			require.Eventually(t, func() bool { return len(w.lines()) == len(want) }, 5*time.Second, time.Millisecond)
+		}
+
+		// Queue writes until the queue is full; none may be discarded.
+		w.stall()
+		for i := 50; ; i++ {
+			line := fmt.Sprintf("cpu value=%d %d", i, i)
+			err := q.WritePoints(context.Background(), orgID, bucketID, points(t, line))
+			if err == ErrWriteQueueFull {
+				break
+			}
+			require.NoError(t, err)
+			want = append(want, line)
+		}
+		w.resume()
+		require.Eventually(t, func() bool { return len(w.lines()) == len(want) }, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, want, w.lines())
+	})
+
+	t.Run("replays writes on restart", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "write-queue")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		s, addr := newTestServer(t, dir, nil)
+
+		stalled := newRecordingWriter()
+		stalled.stall()
+		q := NewWriteQueue(zaptest.NewLogger(t), "replay", addr, WriteQueueConfig{
+			AckMode:         WriteAckEnqueued,
+			MaxBytes:        1 << 20,
+			MaxMessageBytes: 1024,
+		}, stalled)
+		require.NoError(t, q.Open(context.Background()))
+		require.NoError(t, q.WritePoints(context.Background(), orgID, bucketID, points(t, "cpu value=1 10")))
+		require.NoError(t, q.WritePoints(context.Background(), orgID, bucketID, points(t, "cpu value=2 20")))
+		q.Close()
+		s.Close()
+
+		s, addr = newTestServer(t, dir, nil)
+		defer s.Close()
+
+		w := newRecordingWriter()
+		q = NewWriteQueue(zaptest.NewLogger(t), "replay", addr, WriteQueueConfig{
+			AckMode:         WriteAckEnqueued,
+			MaxBytes:        1 << 20,
+			MaxMessageBytes: 1024,
+		}, w)
+		require.NoError(t, q.Open(context.Background()))
+		defer q.Close()
+
+		assert.Equal(t, []string{"cpu value=1 10", "cpu value=2 20"}, w.lines())
+	})
+}
+
+// newTestServer starts a streaming server on a free port, storing messages in
+// dir if it is not empty. The write queue channel is limited by limits if it
+// is not nil.
+func newTestServer(t *testing.T, dir string, limits *stores.ChannelLimits) (*Server, string) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	opts := NewDefaultServerOptions()
+	opts.Host = "127.0.0.1"
+	opts.Port = port
+	s := NewServer(&opts)
+	s.FilestoreDir = dir
+	if limits != nil {
+		s.ChannelLimits = map[string]*stores.ChannelLimits{WriteQueueSubject: limits}
+	}
+	require.NoError(t, s.Open())
+	return s, fmt.Sprintf("nats://127.0.0.1:%d", port)
+}
+
+// droppingWriter drops every point written to it.
+type droppingWriter struct{}
+
+func (droppingWriter) WritePoints(ctx context.Context, orgID, bucketID influxdb.ID, points []models.Point) error {
+	dropped := make([]tsdb.DroppedPoint, len(points))
+	for i, p := range points {
+		dropped[i] = tsdb.DroppedPoint{Point: p, Reason: "points beyond retention policy"}
+	}
+	return tsdb.PartialWriteError{Reason: "points beyond retention policy", Dropped: len(points), DroppedPoints: dropped}
+}
+
+// recordingWriter records the points written to it, and blocks writes while
+// stalled.
+type recordingWriter struct {
+	mu      sync.Mutex
+	written []string
+	err     error
+	stalled chan struct{}
+}
+
+func newRecordingWriter() *recordingWriter {
+	return &recordingWriter{}
+}
+
+func (w *recordingWriter) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.err = err
+}
+
+func (w *recordingWriter) stall() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stalled = make(chan struct{})
+}
+
+func (w *recordingWriter) resume() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	close(w.stalled)
+}
+
+func (w *recordingWriter) WritePoints(ctx context.Context, orgID, bucketID influxdb.ID, points []models.Point) error {
+	w.mu.Lock()
+	stalled := w.stalled
+	w.mu.Unlock()
+	if stalled != nil {
+		select {
+		case <-stalled:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	for _, p := range points {
+		w.written = append(w.written, p.String())
+	}
+	return nil
+}
+
+func (w *recordingWriter) lines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.written...)
+}
@@ -13,6 +13,8 @@ const (
 	PagerDutyType = "pagerduty"
 	HTTPType      = "http"
 	TelegramType  = "telegram"
+	OpsgenieType  = "opsgenie"
+	TeamsType     = "teams"
 )
 
 var typeToEndpoint = map[string]func() influxdb.NotificationEndpoint{
@@ -20,6 +22,8 @@ var typeToEndpoint = map[string]func() influxdb.NotificationEndpoint{
 	PagerDutyType: func() influxdb.NotificationEndpoint { return &PagerDuty{} },
 	HTTPType:      func() influxdb.NotificationEndpoint { return &HTTP{} },
 	TelegramType:  func() influxdb.NotificationEndpoint { return &Telegram{} },
+	OpsgenieType:  func() influxdb.NotificationEndpoint { return &Opsgenie{} },
+	TeamsType:     func() influxdb.NotificationEndpoint { return &Teams{} },
 }
 
 // UnmarshalJSON will convert the bytes to notification endpoint.
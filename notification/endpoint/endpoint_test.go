@@ -193,6 +193,42 @@ func TestValidEndpoint(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name: "empty opsgenie API key",
+			src: &endpoint.Opsgenie{
+				Base: goodBase,
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "empty opsgenie API key",
+			},
+		},
+		{
+			name: "valid opsgenie API key",
+			src: &endpoint.Opsgenie{
+				Base:   goodBase,
+				APIKey: influxdb.SecretField{Key: id1 + "-api-key"},
+			},
+			err: nil,
+		},
+		{
+			name: "empty teams url",
+			src: &endpoint.Teams{
+				Base: goodBase,
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "teams endpoint URL is empty",
+			},
+		},
+		{
+			name: "valid teams url",
+			src: &endpoint.Teams{
+				Base: goodBase,
+				URL:  "https://outlook.office.com/webhook/x/IncomingWebhook/y/z",
+			},
+			err: nil,
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -305,6 +341,39 @@ func TestJSON(t *testing.T) {
 				Token: influxdb.SecretField{Key: "token-key-1"},
 			},
 		},
+		{
+			name: "simple opsgenie",
+			src: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL:    "https://api.eu.opsgenie.com/v2/alerts",
+				APIKey: influxdb.SecretField{Key: "opsgenie-api-key"},
+			},
+		},
+		{
+			name: "simple teams",
+			src: &endpoint.Teams{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL: "https://outlook.office.com/webhook/x/IncomingWebhook/y/z",
+			},
+		},
 	}
 	for _, c := range cases {
 		b, err := json.Marshal(c.src)
@@ -478,6 +547,40 @@ func TestBackFill(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "simple opsgenie",
+			src: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				APIKey: influxdb.SecretField{
+					Value: strPtr("api-key-value"),
+				},
+			},
+			target: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				APIKey: influxdb.SecretField{
+					Key:   id1 + "-api-key",
+					Value: strPtr("api-key-value"),
+				},
+			},
+		},
 	}
 	for _, c := range cases {
 		c.src.BackfillSecretKeys()
@@ -605,6 +708,48 @@ func TestSecretFields(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "simple opsgenie",
+			src: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				APIKey: influxdb.SecretField{
+					Key:   id1 + "-api-key",
+					Value: strPtr("api-key-value"),
+				},
+			},
+			secrets: []influxdb.SecretField{
+				{
+					Key:   id1 + "-api-key",
+					Value: strPtr("api-key-value"),
+				},
+			},
+		},
+		{
+			name: "simple teams",
+			src: &endpoint.Teams{
+				Base: endpoint.Base{
+					ID:     influxTesting.MustIDBase16Ptr(id1),
+					Name:   "name1",
+					OrgID:  influxTesting.MustIDBase16Ptr(id3),
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL: "https://outlook.office.com/webhook/x/IncomingWebhook/y/z",
+			},
+			secrets: []influxdb.SecretField{},
+		},
 	}
 	for _, c := range cases {
 		secretFields := c.src.SecretFields()
@@ -0,0 +1,89 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.NotificationEndpoint = &Opsgenie{}
+
+const (
+	opsgenieAPIKeySuffix = "-api-key"
+
+	// OpsgenieDefaultURL is the alert API of the opsgenie US instance.
+	OpsgenieDefaultURL = "https://api.opsgenie.com/v2/alerts"
+)
+
+// Opsgenie is the notification endpoint config of opsgenie.
+type Opsgenie struct {
+	Base
+	// URL is the alert API url, it defaults to OpsgenieDefaultURL,
+	// EU accounts have to use https://api.eu.opsgenie.com/v2/alerts
+	URL string `json:"url,omitempty"`
+	// APIKey is the key of an API integration, see https://docs.opsgenie.com/docs/api-integration
+	APIKey influxdb.SecretField `json:"apiKey"`
+}
+
+// BackfillSecretKeys fill back the secret field key during the unmarshalling
+// if value of that secret field is not nil.
+func (s *Opsgenie) BackfillSecretKeys() {
+	if s.APIKey.Key == "" && s.APIKey.Value != nil {
+		s.APIKey.Key = s.idStr() + opsgenieAPIKeySuffix
+	}
+}
+
+// SecretFields return available secret fields.
+func (s Opsgenie) SecretFields() []influxdb.SecretField {
+	return []influxdb.SecretField{
+		s.APIKey,
+	}
+}
+
+// AlertURL returns the url alerts are posted to.
+func (s Opsgenie) AlertURL() string {
+	if s.URL == "" {
+		return OpsgenieDefaultURL
+	}
+	return s.URL
+}
+
+// Valid returns error if some configuration is invalid
+func (s Opsgenie) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if _, err := url.Parse(s.URL); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("opsgenie endpoint URL is invalid: %s", err.Error()),
+		}
+	}
+	if s.APIKey.Key == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "empty opsgenie API key",
+		}
+	}
+	return nil
+}
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Opsgenie) MarshalJSON() ([]byte, error) {
+	type opsgenieAlias Opsgenie
+	return json.Marshal(
+		struct {
+			opsgenieAlias
+			Type string `json:"type"`
+		}{
+			opsgenieAlias: opsgenieAlias(s),
+			Type:          s.Type(),
+		})
+}
+
+// Type returns the type.
+func (s Opsgenie) Type() string {
+	return OpsgenieType
+}
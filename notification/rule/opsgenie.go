@@ -0,0 +1,173 @@
+package rule
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+)
+
+// Opsgenie is the notification rule config of opsgenie.
+type Opsgenie struct {
+	Base
+	MessageTemplate string   `json:"messageTemplate"`
+	Tags            []string `json:"tags,omitempty"`
+}
+
+// GenerateFlux generates a flux script for the opsgenie notification rule.
+func (s *Opsgenie) GenerateFlux(e influxdb.NotificationEndpoint) (string, error) {
+	opsgenieEndpoint, ok := e.(*endpoint.Opsgenie)
+	if !ok {
+		return "", fmt.Errorf("endpoint provided is a %s, not an Opsgenie endpoint", e.Type())
+	}
+	p, err := s.GenerateFluxAST(opsgenieEndpoint)
+	if err != nil {
+		return "", err
+	}
+	return ast.Format(p), nil
+}
+
+// GenerateFluxAST generates a flux AST for the opsgenie notification rule.
+func (s *Opsgenie) GenerateFluxAST(e *endpoint.Opsgenie) (*ast.Package, error) {
+	f := flux.File(
+		s.Name,
+		flux.Imports("influxdata/influxdb/monitor", "http", "json", "influxdata/influxdb/secrets", "experimental"),
+		s.generateFluxASTBody(e),
+	)
+	return &ast.Package{Package: "main", Files: []*ast.File{f}}, nil
+}
+
+func (s *Opsgenie) generateFluxASTBody(e *endpoint.Opsgenie) []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, s.generateTaskOption())
+	statements = append(statements, s.generateFluxASTSecrets(e))
+	statements = append(statements, s.generateFluxASTEndpoint(e))
+	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
+	statements = append(statements, s.generateFluxASTStatuses())
+	statements = append(statements, s.generateLevelChecks()...)
+	statements = append(statements, s.generateFluxASTNotifyPipe())
+
+	return statements
+}
+
+func (s *Opsgenie) generateFluxASTSecrets(e *endpoint.Opsgenie) ast.Statement {
+	call := flux.Call(flux.Member("secrets", "get"), flux.Object(flux.Property("key", flux.String(e.APIKey.Key))))
+
+	return flux.DefineVariable("opsgenie_secret", call)
+}
+
+// generateFluxASTEndpoint defines the opsgenie endpoint function, http.endpoint
+// cannot be used because the alert API answers 202 Accepted:
+//
+//	opsgenie_endpoint = (mapFn) => (tables=<-) => tables
+//		|> map(fn: (r) => {
+//			obj = mapFn(r: r)
+//			return {r with _sent: string(v: 2 == http.post(url: url, headers: headers, data: json.encode(v: obj)) / 100)}
+//		})
+func (s *Opsgenie) generateFluxASTEndpoint(e *endpoint.Opsgenie) ast.Statement {
+	headers := flux.Object(
+		flux.Dictionary("Authorization", flux.Add(flux.String("GenieKey "), flux.Identifier("opsgenie_secret"))),
+		flux.Dictionary("Content-Type", flux.String("application/json")),
+	)
+	post := flux.Call(flux.Member("http", "post"), flux.Object(
+		flux.Property("url", flux.String(e.AlertURL())),
+		flux.Property("headers", headers),
+		flux.Property("data", flux.Call(flux.Member("json", "encode"), flux.Object(flux.Property("v", flux.Identifier("obj"))))),
+	))
+	sent := flux.Call(flux.Identifier("string"), flux.Object(flux.Property("v",
+		flux.Equal(flux.Integer(2), &ast.BinaryExpression{
+			Operator: ast.DivisionOperator,
+			Left:     post,
+			Right:    flux.Integer(100),
+		}),
+	)))
+	mapFn := flux.FuncBlock(flux.FunctionParams("r"),
+		flux.DefineVariable("obj", flux.Call(flux.Identifier("mapFn"), flux.Object(flux.Property("r", flux.Identifier("r"))))),
+		&ast.ReturnStatement{
+			Argument: flux.ObjectWith("r", flux.Property("_sent", sent)),
+		},
+	)
+	tablesFn := flux.Function(
+		[]*ast.Property{{Key: &ast.Identifier{Name: "tables"}, Value: &ast.PipeLiteral{}}},
+		flux.Pipe(flux.Identifier("tables"), flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", mapFn)))),
+	)
+
+	return flux.DefineVariable("opsgenie_endpoint", flux.Function(flux.FunctionParams("mapFn"), tablesFn))
+}
+
+func (s *Opsgenie) generateFluxASTNotifyPipe() ast.Statement {
+	endpointProps := []*ast.Property{}
+	endpointProps = append(endpointProps, flux.Property("message", flux.String(s.MessageTemplate)))
+	endpointProps = append(endpointProps, flux.Property("alias",
+		flux.Add(flux.Add(flux.Member("notification", "_notification_rule_id"), flux.String("-")), flux.Member("r", "_check_id"))))
+	endpointProps = append(endpointProps, flux.Property("description", flux.Member("r", "_message")))
+	endpointProps = append(endpointProps, flux.Property("priority", s.generatePriority()))
+	endpointProps = append(endpointProps, flux.Property("source", flux.Member("notification", "_notification_rule_name")))
+	if len(s.Tags) > 0 {
+		tags := make([]ast.Expression, 0, len(s.Tags))
+		for _, t := range s.Tags {
+			tags = append(tags, flux.String(t))
+		}
+		endpointProps = append(endpointProps, flux.Property("tags", flux.Array(tags...)))
+	}
+	endpointFn := flux.Function(flux.FunctionParams("r"), flux.Object(endpointProps...))
+
+	props := []*ast.Property{}
+	props = append(props, flux.Property("data", flux.Identifier("notification")))
+	props = append(props, flux.Property("endpoint",
+		flux.Call(flux.Identifier("opsgenie_endpoint"), flux.Object(flux.Property("mapFn", endpointFn)))))
+
+	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
+
+	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call))
+}
+
+// generatePriority maps the level to an opsgenie priority, P1 being the highest.
+func (s *Opsgenie) generatePriority() ast.Expression {
+	level := flux.Member("r", "_level")
+	return flux.If(
+		flux.Equal(level, flux.String("crit")),
+		flux.String("P1"),
+		flux.If(
+			flux.Equal(level, flux.String("warn")),
+			flux.String("P3"),
+			flux.String("P5"),
+		),
+	)
+}
+
+type opsgenieAlias Opsgenie
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Opsgenie) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			opsgenieAlias
+			Type string `json:"type"`
+		}{
+			opsgenieAlias: opsgenieAlias(s),
+			Type:          s.Type(),
+		})
+}
+
+// Valid returns where the config is valid.
+func (s Opsgenie) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.MessageTemplate == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Opsgenie MessageTemplate is invalid",
+		}
+	}
+	return nil
+}
+
+// Type returns the type of the rule config.
+func (s Opsgenie) Type() string {
+	return "opsgenie"
+}
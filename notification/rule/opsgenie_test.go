@@ -0,0 +1,269 @@
+package rule_test
+
+import (
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	influxTesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+var _ influxdb.NotificationRule = &rule.Opsgenie{}
+
+func TestOpsgenie_GenerateFlux(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     *rule.Opsgenie
+		endpoint influxdb.NotificationEndpoint
+		script   string
+	}{
+		{
+			name: "incompatible with endpoint",
+			endpoint: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:   idPtr(3),
+					Name: "foo",
+				},
+				URL: "http://whatever",
+			},
+			rule: &rule.Opsgenie{
+				MessageTemplate: "blah",
+				Base: rule.Base{
+					ID:         1,
+					EndpointID: 3,
+					Name:       "foo",
+					Every:      mustDuration("1h"),
+					StatusRules: []notification.StatusRule{
+						{
+							CurrentLevel: notification.Critical,
+						},
+					},
+				},
+			},
+			script: "", //no script generater, because of incompatible endpoint
+		},
+		{
+			name: "notify on crit",
+			endpoint: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:   idPtr(3),
+					Name: "foo",
+				},
+				APIKey: influxdb.SecretField{Key: "3-api-key"},
+			},
+			rule: &rule.Opsgenie{
+				MessageTemplate: "blah",
+				Base: rule.Base{
+					ID:         1,
+					EndpointID: 3,
+					Name:       "foo",
+					Every:      mustDuration("1h"),
+					StatusRules: []notification.StatusRule{
+						{
+							CurrentLevel: notification.Critical,
+						},
+					},
+					TagRules: []notification.TagRule{
+						{
+							Tag: influxdb.Tag{
+								Key:   "foo",
+								Value: "bar",
+							},
+							Operator: influxdb.Equal,
+						},
+					},
+				},
+			},
+			script: `package main
+// foo
+import "influxdata/influxdb/monitor"
+import "http"
+import "json"
+import "influxdata/influxdb/secrets"
+import "experimental"
+
+option task = {name: "foo", every: 1h}
+
+opsgenie_secret = secrets["get"](key: "3-api-key")
+opsgenie_endpoint = (mapFn) =>
+	((tables=<-) =>
+		(tables
+			|> map(fn: (r) => {
+				obj = mapFn(r: r)
+
+				return {r with _sent: string(v: 2 == http["post"](url: "https://api.opsgenie.com/v2/alerts", headers: {"Authorization": "GenieKey " + opsgenie_secret, "Content-Type": "application/json"}, data: json["encode"](v: obj)) / 100)}
+			})))
+notification = {
+	_notification_rule_id: "0000000000000001",
+	_notification_rule_name: "foo",
+	_notification_endpoint_id: "0000000000000003",
+	_notification_endpoint_name: "foo",
+}
+statuses = monitor["from"](start: -2h, fn: (r) =>
+	(r["foo"] == "bar"))
+crit = statuses
+	|> filter(fn: (r) =>
+		(r["_level"] == "crit"))
+all_statuses = crit
+	|> filter(fn: (r) =>
+		(r["_time"] >= experimental["subDuration"](from: now(), d: 1h)))
+
+all_statuses
+	|> monitor["notify"](data: notification, endpoint: opsgenie_endpoint(mapFn: (r) =>
+		({
+			message: "blah",
+			alias: notification["_notification_rule_id"] + "-" + r["_check_id"],
+			description: r["_message"],
+			priority: if r["_level"] == "crit" then "P1" else if r["_level"] == "warn" then "P3" else "P5",
+			source: notification["_notification_rule_name"],
+		})))`,
+		},
+		{
+			name: "with EU url and tags",
+			endpoint: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:   idPtr(3),
+					Name: "foo",
+				},
+				URL:    "https://api.eu.opsgenie.com/v2/alerts",
+				APIKey: influxdb.SecretField{Key: "3-api-key"},
+			},
+			rule: &rule.Opsgenie{
+				MessageTemplate: "blah",
+				Tags:            []string{"influxdb", "cpu"},
+				Base: rule.Base{
+					ID:         1,
+					EndpointID: 3,
+					Name:       "foo",
+					Every:      mustDuration("1h"),
+					StatusRules: []notification.StatusRule{
+						{
+							CurrentLevel: notification.Any,
+						},
+					},
+				},
+			},
+			script: `package main
+// foo
+import "influxdata/influxdb/monitor"
+import "http"
+import "json"
+import "influxdata/influxdb/secrets"
+import "experimental"
+
+option task = {name: "foo", every: 1h}
+
+opsgenie_secret = secrets["get"](key: "3-api-key")
+opsgenie_endpoint = (mapFn) =>
+	((tables=<-) =>
+		(tables
+			|> map(fn: (r) => {
+				obj = mapFn(r: r)
+
+				return {r with _sent: string(v: 2 == http["post"](url: "https://api.eu.opsgenie.com/v2/alerts", headers: {"Authorization": "GenieKey " + opsgenie_secret, "Content-Type": "application/json"}, data: json["encode"](v: obj)) / 100)}
+			})))
+notification = {
+	_notification_rule_id: "0000000000000001",
+	_notification_rule_name: "foo",
+	_notification_endpoint_id: "0000000000000003",
+	_notification_endpoint_name: "foo",
+}
+statuses = monitor["from"](start: -2h)
+any = statuses
+	|> filter(fn: (r) =>
+		(true))
+all_statuses = any
+	|> filter(fn: (r) =>
+		(r["_time"] >= experimental["subDuration"](from: now(), d: 1h)))
+
+all_statuses
+	|> monitor["notify"](data: notification, endpoint: opsgenie_endpoint(mapFn: (r) =>
+		({
+			message: "blah",
+			alias: notification["_notification_rule_id"] + "-" + r["_check_id"],
+			description: r["_message"],
+			priority: if r["_level"] == "crit" then "P1" else if r["_level"] == "warn" then "P3" else "P5",
+			source: notification["_notification_rule_name"],
+			tags: ["influxdb", "cpu"],
+		})))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := tt.rule.GenerateFlux(tt.endpoint)
+			if err != nil {
+				if script != "" {
+					t.Errorf("Failed to generate flux: %v", err)
+				}
+				return
+			}
+
+			if got, want := script, tt.script; got != want {
+				t.Errorf("\n\nStrings do not match:\n\n%s", diff.LineDiff(got, want))
+			}
+		})
+	}
+}
+
+func TestOpsgenie_Valid(t *testing.T) {
+	cases := []struct {
+		name string
+		rule *rule.Opsgenie
+		err  error
+	}{
+		{
+			name: "valid template",
+			rule: &rule.Opsgenie{
+				MessageTemplate: "blah",
+				Base: rule.Base{
+					ID:         1,
+					EndpointID: 3,
+					OwnerID:    4,
+					OrgID:      5,
+					Name:       "foo",
+					Every:      mustDuration("1h"),
+					StatusRules: []notification.StatusRule{
+						{
+							CurrentLevel: notification.Critical,
+						},
+					},
+					TagRules: []notification.TagRule{},
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "missing MessageTemplate",
+			rule: &rule.Opsgenie{
+				Base: rule.Base{
+					ID:         1,
+					EndpointID: 3,
+					OwnerID:    4,
+					OrgID:      5,
+					Name:       "foo",
+					Every:      mustDuration("1h"),
+					StatusRules: []notification.StatusRule{
+						{
+							CurrentLevel: notification.Critical,
+						},
+					},
+					TagRules: []notification.TagRule{},
+				},
+			},
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "Opsgenie MessageTemplate is invalid",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.rule.Valid()
+			influxTesting.ErrorsEqual(t, got, c.err)
+		})
+	}
+}
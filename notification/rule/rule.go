@@ -17,6 +17,8 @@ var typeToRule = map[string](func() influxdb.NotificationRule){
 	"pagerduty": func() influxdb.NotificationRule { return &PagerDuty{} },
 	"http":      func() influxdb.NotificationRule { return &HTTP{} },
 	"telegram":  func() influxdb.NotificationRule { return &Telegram{} },
+	"opsgenie":  func() influxdb.NotificationRule { return &Opsgenie{} },
+	"teams":     func() influxdb.NotificationRule { return &Teams{} },
 }
 
 // UnmarshalJSON will convert
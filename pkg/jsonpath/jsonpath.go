@@ -0,0 +1,164 @@
+// Package jsonpath implements the subset of JSONPath needed to select values
+// from decoded JSON documents: the root ($ or @), child names (.name,
+// ['name'] or ["name"]), array indexes ([0], [-1]) and wildcards (.* or [*]).
+package jsonpath
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// step selects the children of a value by name, index or wildcard.
+type step struct {
+	name     string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Path is a compiled JSONPath.
+type Path struct {
+	src   string
+	steps []step
+}
+
+// Compile parses a JSONPath.
+func Compile(path string) (*Path, error) {
+	p := &Path{src: path}
+	s := strings.TrimSpace(path)
+	if s == "" {
+		return nil, fmt.Errorf("empty JSONPath")
+	}
+	if s[0] != '$' && s[0] != '@' {
+		return nil, fmt.Errorf("JSONPath %q must start with $ or @", path)
+	}
+	s = s[1:]
+
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			if s == "" {
+				return nil, fmt.Errorf("JSONPath %q ends with a dot", path)
+			}
+			if s[0] == '*' {
+				p.steps = append(p.steps, step{wildcard: true})
+				s = s[1:]
+				continue
+			}
+			n := strings.IndexAny(s, ".[")
+			if n < 0 {
+				n = len(s)
+			}
+			if n == 0 {
+				return nil, fmt.Errorf("JSONPath %q has an empty name", path)
+			}
+			p.steps = append(p.steps, step{name: s[:n]})
+			s = s[n:]
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("JSONPath %q has an unclosed bracket", path)
+			}
+			sel := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+
+			switch {
+			case sel == "*":
+				p.steps = append(p.steps, step{wildcard: true})
+			case len(sel) >= 2 && (sel[0] == '\'' || sel[0] == '"') && sel[len(sel)-1] == sel[0]:
+				p.steps = append(p.steps, step{name: sel[1 : len(sel)-1]})
+			default:
+				i, err := strconv.Atoi(sel)
+				if err != nil {
+					return nil, fmt.Errorf("JSONPath %q has an invalid selector [%s]", path, sel)
+				}
+				p.steps = append(p.steps, step{index: i, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("JSONPath %q has an unexpected %q", path, s[0])
+		}
+	}
+	return p, nil
+}
+
+// MustCompile parses a JSONPath and panics if it is invalid.
+func MustCompile(path string) *Path {
+	p, err := Compile(path)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source of the path.
+func (p *Path) String() string {
+	return p.src
+}
+
+// Get returns the values the path selects in a document decoded by
+// encoding/json, in array order or key order for objects.
+func (p *Path) Get(doc interface{}) []interface{} {
+	values := []interface{}{doc}
+	for _, st := range p.steps {
+		var next []interface{}
+		for _, v := range values {
+			next = st.apply(v, next)
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		values = next
+	}
+	return values
+}
+
+// First returns the first value the path selects in a document.
+func (p *Path) First(doc interface{}) (interface{}, bool) {
+	values := p.Get(doc)
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+func (st step) apply(v interface{}, out []interface{}) []interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if st.wildcard {
+			// objects have no order, select their children by key
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				out = append(out, v[k])
+			}
+			return out
+		}
+		if st.isIndex {
+			return out
+		}
+		if c, ok := v[st.name]; ok {
+			out = append(out, c)
+		}
+	case []interface{}:
+		if st.wildcard {
+			return append(out, v...)
+		}
+		if !st.isIndex {
+			return out
+		}
+		i := st.index
+		if i < 0 {
+			i += len(v)
+		}
+		if i >= 0 && i < len(v) {
+			out = append(out, v[i])
+		}
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2/pkg/jsonpath"
+)
+
+const doc = `{
+	"host": "a",
+	"stats": {"cpu": 0.5, "mem": 1024},
+	"disks": [
+		{"name": "sda", "used": 10},
+		{"name": "sdb", "used": 20}
+	],
+	"odd key": true
+}`
+
+func TestPath_Get(t *testing.T) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		path string
+		want []interface{}
+	}{
+		{path: "$.host", want: []interface{}{"a"}},
+		{path: "@.host", want: []interface{}{"a"}},
+		{path: "$.stats.cpu", want: []interface{}{0.5}},
+		{path: "$['odd key']", want: []interface{}{true}},
+		{path: `$["stats"]["mem"]`, want: []interface{}{1024.0}},
+		{path: "$.disks[1].name", want: []interface{}{"sdb"}},
+		{path: "$.disks[-1].used", want: []interface{}{20.0}},
+		{path: "$.disks[*].name", want: []interface{}{"sda", "sdb"}},
+		{path: "$.stats.*", want: []interface{}{0.5, 1024.0}},
+		{path: "$.missing"},
+		{path: "$.disks[2]"},
+		{path: "$.host.name"},
+	} {
+		t.Run(tt.path, func(t *testing.T) {
+			got := jsonpath.MustCompile(tt.path).Get(v)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected values -want/+got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	for _, path := range []string{
+		"",
+		"host",
+		"$.",
+		"$..host",
+		"$[0",
+		"$[one]",
+		"$host",
+	} {
+		if _, err := jsonpath.Compile(path); err == nil {
+			t.Errorf("expected error compiling %q", path)
+		}
+	}
+}
@@ -163,3 +163,34 @@ func extractFile(tr *tar.Reader, dir string) error {
 
 	return file.RenameFile(tmp, destPath)
 }
+
+// Rebase copies the shard archive in r to w, replacing the shard relative path of
+// each file with relativePath. This allows the data of a shard to be restored into a
+// shard with a different ID or of a different database.
+func Rebase(w io.Writer, r io.Reader, relativePath string) error {
+	tr := tar.NewReader(r)
+	tw := tar.NewWriter(w)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return tw.Close()
+		} else if err != nil {
+			return err
+		}
+
+		// The hdr.Name is the relative path of the file from the root data dir.
+		// e.g (db/rp/1/xxxxx.tsm or db/rp/1/index/xxxxxx.tsi)
+		sections := strings.Split(filepath.FromSlash(hdr.Name), string(filepath.Separator))
+		if len(sections) < 4 {
+			return fmt.Errorf("invalid archive path: %s", hdr.Name)
+		}
+		hdr.Name = filepath.ToSlash(filepath.Join(relativePath, filepath.Join(sections[3:]...)))
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
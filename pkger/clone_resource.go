@@ -54,13 +54,16 @@ var kindPriorities = map[Kind]int{
 	KindCheckThreshold:                5,
 	KindNotificationEndpoint:          6,
 	KindNotificationEndpointHTTP:      7,
-	KindNotificationEndpointPagerDuty: 8,
-	KindNotificationEndpointSlack:     9,
-	KindNotificationRule:              10,
-	KindTask:                          11,
-	KindVariable:                      12,
-	KindDashboard:                     13,
-	KindTelegraf:                      14,
+	KindNotificationEndpointOpsgenie:  8,
+	KindNotificationEndpointPagerDuty: 9,
+	KindNotificationEndpointSlack:     10,
+	KindNotificationEndpointTeams:     11,
+	KindNotificationEndpointTelegram:  12,
+	KindNotificationRule:              13,
+	KindTask:                          14,
+	KindVariable:                      15,
+	KindDashboard:                     16,
+	KindTelegraf:                      17,
 }
 
 type exportKey struct {
@@ -314,8 +317,11 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		}
 	case r.Kind.is(KindNotificationEndpoint),
 		r.Kind.is(KindNotificationEndpointHTTP),
+		r.Kind.is(KindNotificationEndpointOpsgenie),
 		r.Kind.is(KindNotificationEndpointPagerDuty),
-		r.Kind.is(KindNotificationEndpointSlack):
+		r.Kind.is(KindNotificationEndpointSlack),
+		r.Kind.is(KindNotificationEndpointTeams),
+		r.Kind.is(KindNotificationEndpointTelegram):
 		var endpoints []influxdb.NotificationEndpoint
 
 		switch {
@@ -1107,6 +1113,21 @@ func NotificationEndpointToObject(name string, e influxdb.NotificationEndpoint)
 		assignNonZeroSecrets(o.Spec, map[string]influxdb.SecretField{
 			fieldNotificationEndpointToken: actual.Token,
 		})
+	case *endpoint.Telegram:
+		o.Kind = KindNotificationEndpointTelegram
+		o.Spec[fieldNotificationEndpointChannel] = actual.Channel
+		assignNonZeroSecrets(o.Spec, map[string]influxdb.SecretField{
+			fieldNotificationEndpointToken: actual.Token,
+		})
+	case *endpoint.Opsgenie:
+		o.Kind = KindNotificationEndpointOpsgenie
+		assignNonZeroStrings(o.Spec, map[string]string{fieldNotificationEndpointURL: actual.URL})
+		assignNonZeroSecrets(o.Spec, map[string]influxdb.SecretField{
+			fieldNotificationEndpointAPIKey: actual.APIKey,
+		})
+	case *endpoint.Teams:
+		o.Kind = KindNotificationEndpointTeams
+		o.Spec[fieldNotificationEndpointURL] = actual.URL
 	}
 
 	return o
@@ -1167,6 +1188,26 @@ func NotificationRuleToObject(name, endpointPkgName string, iRule influxdb.Notif
 		assignBase(t.Base)
 		o.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
 		assignNonZeroStrings(o.Spec, map[string]string{fieldNotificationRuleChannel: t.Channel})
+	case *rule.Telegram:
+		assignBase(t.Base)
+		o.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
+		assignNonZeroStrings(o.Spec, map[string]string{fieldNotificationRuleParseMode: t.ParseMode})
+		if t.DisableWebPagePreview {
+			o.Spec[fieldNotificationRuleDisableWebPagePreview] = true
+		}
+	case *rule.Opsgenie:
+		assignBase(t.Base)
+		o.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
+		if len(t.Tags) > 0 {
+			o.Spec[fieldNotificationRuleTags] = t.Tags
+		}
+	case *rule.Teams:
+		assignBase(t.Base)
+		o.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
+		assignNonZeroStrings(o.Spec, map[string]string{
+			fieldNotificationRuleTitle:   t.Title,
+			fieldNotificationRuleSummary: t.Summary,
+		})
 	}
 
 	return o
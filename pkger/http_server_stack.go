@@ -424,8 +424,11 @@ func stackResLinks(r StackResource) RespStackResourceLinks {
 		linkResource = "labels"
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams,
+		KindNotificationEndpointTelegram:
 		linkResource = "notificationEndpoints"
 	case KindNotificationRule:
 		linkResource = "notificationRules"
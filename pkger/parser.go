@@ -498,8 +498,11 @@ func (p *Template) Contains(k Kind, pkgName string) bool {
 		return ok
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams,
+		KindNotificationEndpointTelegram:
 		_, ok := p.mNotificationEndpoints[pkgName]
 		return ok
 	case KindNotificationRule:
@@ -993,6 +996,18 @@ func (p *Template) graphNotificationEndpoints() *parseErr {
 			kind:             KindNotificationEndpointSlack,
 			notificationKind: notificationKindSlack,
 		},
+		{
+			kind:             KindNotificationEndpointTelegram,
+			notificationKind: notificationKindTelegram,
+		},
+		{
+			kind:             KindNotificationEndpointOpsgenie,
+			notificationKind: notificationKindOpsgenie,
+		},
+		{
+			kind:             KindNotificationEndpointTeams,
+			notificationKind: notificationKindTeams,
+		},
 	}
 
 	var pErr parseErr
@@ -1006,6 +1021,8 @@ func (p *Template) graphNotificationEndpoints() *parseErr {
 			endpoint := &notificationEndpoint{
 				kind:        nk.notificationKind,
 				identity:    ident,
+				apiKey:      o.Spec.references(fieldNotificationEndpointAPIKey),
+				channel:     o.Spec.stringShort(fieldNotificationEndpointChannel),
 				description: o.Spec.stringShort(fieldDescription),
 				method:      strings.TrimSpace(strings.ToUpper(o.Spec.stringShort(fieldNotificationEndpointHTTPMethod))),
 				httpType:    normStr(o.Spec.stringShort(fieldType)),
@@ -1026,6 +1043,7 @@ func (p *Template) graphNotificationEndpoints() *parseErr {
 			p.setRefs(
 				endpoint.name,
 				endpoint.displayName,
+				endpoint.apiKey,
 				endpoint.password,
 				endpoint.routingKey,
 				endpoint.token,
@@ -1055,14 +1073,19 @@ func (p *Template) graphNotificationRules() *parseErr {
 		}
 
 		rule := &notificationRule{
-			identity:     ident,
-			endpointName: p.getRefWithKnownEnvs(o.Spec, fieldNotificationRuleEndpointName),
-			description:  o.Spec.stringShort(fieldDescription),
-			channel:      o.Spec.stringShort(fieldNotificationRuleChannel),
-			every:        o.Spec.durationShort(fieldEvery),
-			msgTemplate:  o.Spec.stringShort(fieldNotificationRuleMessageTemplate),
-			offset:       o.Spec.durationShort(fieldOffset),
-			status:       normStr(o.Spec.stringShort(fieldStatus)),
+			identity:              ident,
+			endpointName:          p.getRefWithKnownEnvs(o.Spec, fieldNotificationRuleEndpointName),
+			description:           o.Spec.stringShort(fieldDescription),
+			channel:               o.Spec.stringShort(fieldNotificationRuleChannel),
+			every:                 o.Spec.durationShort(fieldEvery),
+			msgTemplate:           o.Spec.stringShort(fieldNotificationRuleMessageTemplate),
+			offset:                o.Spec.durationShort(fieldOffset),
+			status:                normStr(o.Spec.stringShort(fieldStatus)),
+			parseMode:             o.Spec.stringShort(fieldNotificationRuleParseMode),
+			disableWebPagePreview: o.Spec.boolShort(fieldNotificationRuleDisableWebPagePreview),
+			tags:                  o.Spec.slcStr(fieldNotificationRuleTags),
+			title:                 o.Spec.stringShort(fieldNotificationRuleTitle),
+			summary:               o.Spec.stringShort(fieldNotificationRuleSummary),
 		}
 
 		for _, sRule := range o.Spec.slcResource(fieldNotificationRuleStatusRules) {
@@ -1375,14 +1375,20 @@ const (
 	notificationKindHTTP notificationEndpointKind = iota + 1
 	notificationKindPagerDuty
 	notificationKindSlack
+	notificationKindTelegram
+	notificationKindOpsgenie
+	notificationKindTeams
 )
 
 func (n notificationEndpointKind) String() string {
-	if n > 0 && n < 4 {
+	if n > 0 && n < 7 {
 		return [...]string{
 			endpoint.HTTPType,
 			endpoint.PagerDutyType,
 			endpoint.SlackType,
+			endpoint.TelegramType,
+			endpoint.OpsgenieType,
+			endpoint.TeamsType,
 		}[n-1]
 	}
 	return ""
@@ -1395,6 +1401,8 @@ const (
 )
 
 const (
+	fieldNotificationEndpointAPIKey     = "apiKey"
+	fieldNotificationEndpointChannel    = "channel"
 	fieldNotificationEndpointHTTPMethod = "method"
 	fieldNotificationEndpointPassword   = "password"
 	fieldNotificationEndpointRoutingKey = "routingKey"
@@ -1407,6 +1415,8 @@ type notificationEndpoint struct {
 	identity
 
 	kind        notificationEndpointKind
+	apiKey      *references
+	channel     string
 	description string
 	method      string
 	password    *references
@@ -1480,6 +1490,26 @@ func (n *notificationEndpoint) summarize() SummaryNotificationEndpoint {
 			URL:   n.url,
 			Token: n.token.SecretField(),
 		}
+	case notificationKindTelegram:
+		sum.Kind = KindNotificationEndpointTelegram
+		sum.NotificationEndpoint = &endpoint.Telegram{
+			Base:    base,
+			Token:   n.token.SecretField(),
+			Channel: n.channel,
+		}
+	case notificationKindOpsgenie:
+		sum.Kind = KindNotificationEndpointOpsgenie
+		sum.NotificationEndpoint = &endpoint.Opsgenie{
+			Base:   base,
+			URL:    n.url,
+			APIKey: n.apiKey.SecretField(),
+		}
+	case notificationKindTeams:
+		sum.Kind = KindNotificationEndpointTeams
+		sum.NotificationEndpoint = &endpoint.Teams{
+			Base: base,
+			URL:  n.url,
+		}
 	}
 	return sum
 }
@@ -1508,7 +1538,9 @@ func (n *notificationEndpoint) valid() []validationErr {
 		failures = append(failures, err)
 	}
 
-	if _, err := url.Parse(n.url); err != nil || n.url == "" {
+	// telegram endpoints have no url and the opsgenie url defaults to the US instance
+	urlRequired := n.kind != notificationKindTelegram && n.kind != notificationKindOpsgenie
+	if _, err := url.Parse(n.url); err != nil || (urlRequired && n.url == "") {
 		failures = append(failures, validationErr{
 			Field: fieldNotificationEndpointURL,
 			Msg:   "must be valid url",
@@ -1531,6 +1563,26 @@ func (n *notificationEndpoint) valid() []validationErr {
 				Msg:   "must be provide",
 			})
 		}
+	case notificationKindTelegram:
+		if !n.token.hasValue() {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointToken,
+				Msg:   "must provide non empty string",
+			})
+		}
+		if n.channel == "" {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointChannel,
+				Msg:   "must provide non empty string",
+			})
+		}
+	case notificationKindOpsgenie:
+		if !n.apiKey.hasValue() {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointAPIKey,
+				Msg:   "must provide non empty string",
+			})
+		}
 	case notificationKindHTTP:
 		if !validEndpointHTTPMethods[n.method] {
 			failures = append(failures, validationErr{
@@ -1585,13 +1637,18 @@ func (n *notificationEndpoint) valid() []validationErr {
 }
 
 const (
-	fieldNotificationRuleChannel         = "channel"
-	fieldNotificationRuleCurrentLevel    = "currentLevel"
-	fieldNotificationRuleEndpointName    = "endpointName"
-	fieldNotificationRuleMessageTemplate = "messageTemplate"
-	fieldNotificationRulePreviousLevel   = "previousLevel"
-	fieldNotificationRuleStatusRules     = "statusRules"
-	fieldNotificationRuleTagRules        = "tagRules"
+	fieldNotificationRuleChannel               = "channel"
+	fieldNotificationRuleCurrentLevel          = "currentLevel"
+	fieldNotificationRuleDisableWebPagePreview = "disableWebPagePreview"
+	fieldNotificationRuleEndpointName          = "endpointName"
+	fieldNotificationRuleMessageTemplate       = "messageTemplate"
+	fieldNotificationRuleParseMode             = "parseMode"
+	fieldNotificationRulePreviousLevel         = "previousLevel"
+	fieldNotificationRuleStatusRules           = "statusRules"
+	fieldNotificationRuleSummary               = "summary"
+	fieldNotificationRuleTagRules              = "tagRules"
+	fieldNotificationRuleTags                  = "tags"
+	fieldNotificationRuleTitle                 = "title"
 )
 
 type notificationRule struct {
@@ -1606,6 +1663,15 @@ type notificationRule struct {
 	statusRules []struct{ curLvl, prevLvl string }
 	tagRules    []struct{ k, v, op string }
 
+	// telegram
+	parseMode             string
+	disableWebPagePreview bool
+	// opsgenie
+	tags []string
+	// teams
+	title   string
+	summary string
+
 	associatedEndpoint *notificationEndpoint
 	endpointName       *references
 
@@ -1708,6 +1774,26 @@ func (r *notificationRule) toInfluxRule() influxdb.NotificationRule {
 			Channel:         r.channel,
 			MessageTemplate: r.msgTemplate,
 		}
+	case notificationKindTelegram:
+		return &rule.Telegram{
+			Base:                  base,
+			MessageTemplate:       r.msgTemplate,
+			ParseMode:             r.parseMode,
+			DisableWebPagePreview: r.disableWebPagePreview,
+		}
+	case notificationKindOpsgenie:
+		return &rule.Opsgenie{
+			Base:            base,
+			MessageTemplate: r.msgTemplate,
+			Tags:            r.tags,
+		}
+	case notificationKindTeams:
+		return &rule.Teams{
+			Base:            base,
+			Title:           r.title,
+			MessageTemplate: r.msgTemplate,
+			Summary:         r.summary,
+		}
 	}
 	return nil
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/influxdata/influxdb/v2/notification"
 	icheck "github.com/influxdata/influxdb/v2/notification/check"
 	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -2748,6 +2749,92 @@ spec:
 			})
 		})
 
+		t.Run("with telegram, opsgenie and teams endpoints should be successful", func(t *testing.T) {
+			testfileRunner(t, "testdata/notification_endpoint_oncall.yml", func(t *testing.T, template *Template) {
+				expectedEndpoints := []SummaryNotificationEndpoint{
+					{
+						SummaryIdentifier: SummaryIdentifier{
+							Kind:     KindNotificationEndpointTelegram,
+							MetaName: "telegram-notification-endpoint",
+						},
+						NotificationEndpoint: &endpoint.Telegram{
+							Base: endpoint.Base{
+								Name:        "telegram-notification-endpoint",
+								Description: "telegram desc",
+								Status:      influxdb.TaskStatusInactive,
+							},
+							Token:   influxdb.SecretField{Value: strPtr("secret token")},
+							Channel: "-1001406363649",
+						},
+					},
+					{
+						SummaryIdentifier: SummaryIdentifier{
+							Kind:     KindNotificationEndpointOpsgenie,
+							MetaName: "opsgenie-notification-endpoint",
+						},
+						NotificationEndpoint: &endpoint.Opsgenie{
+							Base: endpoint.Base{
+								Name:        "opsgenie-notification-endpoint",
+								Description: "opsgenie desc",
+								Status:      influxdb.TaskStatusActive,
+							},
+							URL:    "https://api.eu.opsgenie.com/v2/alerts",
+							APIKey: influxdb.SecretField{Value: strPtr("secret api-key")},
+						},
+					},
+					{
+						SummaryIdentifier: SummaryIdentifier{
+							Kind:     KindNotificationEndpointTeams,
+							MetaName: "teams-notification-endpoint",
+						},
+						NotificationEndpoint: &endpoint.Teams{
+							Base: endpoint.Base{
+								Name:        "teams-notification-endpoint",
+								Description: "teams desc",
+								Status:      influxdb.TaskStatusActive,
+							},
+							URL: "https://outlook.office.com/webhook/bip/IncomingWebhook/piddy/boppidy",
+						},
+					},
+				}
+
+				sum := template.Summary()
+				endpoints := sum.NotificationEndpoints
+				require.Len(t, endpoints, len(expectedEndpoints))
+				for i := range expectedEndpoints {
+					expected, actual := expectedEndpoints[i], endpoints[i]
+					assert.Equalf(t, expected.Kind, actual.Kind, "index=%d", i)
+					assert.Equalf(t, expected.NotificationEndpoint, actual.NotificationEndpoint, "index=%d", i)
+				}
+
+				rules := sum.NotificationRules
+				require.Len(t, rules, 3)
+				assert.Equal(t, "opsgenie", rules[0].EndpointType)
+				assert.Equal(t, "teams", rules[1].EndpointType)
+				assert.Equal(t, "telegram", rules[2].EndpointType)
+
+				influxRules := make(map[string]influxdb.NotificationRule)
+				for _, r := range template.notificationRules() {
+					influxRules[r.MetaName()] = r.toInfluxRule()
+				}
+
+				opsgenieRule, ok := influxRules["opsgenie-rule"].(*rule.Opsgenie)
+				require.True(t, ok)
+				assert.Equal(t, []string{"influxdb", "cpu"}, opsgenieRule.Tags)
+
+				teamsRule, ok := influxRules["teams-rule"].(*rule.Teams)
+				require.True(t, ok)
+				assert.Equal(t, "${ r._check_name } is ${ r._level }", teamsRule.Title)
+				assert.Equal(t, "${ r._message }", teamsRule.MessageTemplate)
+				assert.Equal(t, "${ r._check_name }", teamsRule.Summary)
+
+				telegramRule, ok := influxRules["telegram-rule"].(*rule.Telegram)
+				require.True(t, ok)
+				assert.Equal(t, "HTML", telegramRule.ParseMode)
+				assert.True(t, telegramRule.DisableWebPagePreview)
+			})
+		})
+
 		t.Run("with env refs should be valid", func(t *testing.T) {
 			testfileRunner(t, "testdata/notification_endpoint_ref.yml", func(t *testing.T, template *Template) {
 				actual := template.Summary().NotificationEndpoints
@@ -3024,6 +3111,48 @@ spec:
   name: slack
   description: slack desc
   url: https://hooks.slack.com/services/bip/piddy/boppidy
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointTelegram,
+					resErr: testTemplateResourceError{
+						name:           "missing telegram token and channel",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldNotificationEndpointToken, fieldNotificationEndpointChannel},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointTelegram
+metadata:
+  name: telegram-notification-endpoint
+spec:
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointOpsgenie,
+					resErr: testTemplateResourceError{
+						name:           "missing opsgenie api key",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldNotificationEndpointAPIKey},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointOpsgenie
+metadata:
+  name: opsgenie-notification-endpoint
+spec:
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointTeams,
+					resErr: testTemplateResourceError{
+						name:           "missing teams url",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldNotificationEndpointURL},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointTeams
+metadata:
+  name: teams-notification-endpoint
+spec:
 `,
 					},
 				},
@@ -1438,8 +1438,11 @@ func ApplyWithResourceSkip(action ActionSkipResource) ApplyOptFn {
 		case KindCheckDeadman, KindCheckThreshold:
 			action.Kind = KindCheck
 		case KindNotificationEndpointHTTP,
+			KindNotificationEndpointOpsgenie,
 			KindNotificationEndpointPagerDuty,
-			KindNotificationEndpointSlack:
+			KindNotificationEndpointSlack,
+			KindNotificationEndpointTeams,
+			KindNotificationEndpointTelegram:
 			action.Kind = KindNotificationEndpoint
 		}
 		opt.ResourcesToSkip[action] = true
@@ -1456,8 +1459,11 @@ func ApplyWithKindSkip(action ActionSkipKind) ApplyOptFn {
 		case KindCheckDeadman, KindCheckThreshold:
 			action.Kind = KindCheck
 		case KindNotificationEndpointHTTP,
+			KindNotificationEndpointOpsgenie,
 			KindNotificationEndpointPagerDuty,
-			KindNotificationEndpointSlack:
+			KindNotificationEndpointSlack,
+			KindNotificationEndpointTeams,
+			KindNotificationEndpointTelegram:
 			action.Kind = KindNotificationEndpoint
 		}
 		opt.KindsToSkip[action.Kind] = true
@@ -2129,6 +2135,11 @@ func (s *Service) applyNotificationEndpoints(ctx context.Context, userID influxd
 							endpoints[i].parserEndpoint.routingKey = new(references)
 						}
 						endpoints[i].parserEndpoint.routingKey.Secret = secret.Key
+					case strings.HasSuffix(secret.Key, "-api-key"):
+						if endpoints[i].parserEndpoint.apiKey == nil {
+							endpoints[i].parserEndpoint.apiKey = new(references)
+						}
+						endpoints[i].parserEndpoint.apiKey.Secret = secret.Key
 					case strings.HasSuffix(secret.Key, "-token"):
 						if endpoints[i].parserEndpoint.token == nil {
 							endpoints[i].parserEndpoint.token = new(references)
@@ -2370,6 +2381,12 @@ func (s *Service) rollbackNotificationRules(ctx context.Context, userID influxdb
 				rr.EndpointID = endpointID
 			case *rule.Slack:
 				rr.EndpointID = endpointID
+			case *rule.Telegram:
+				rr.EndpointID = endpointID
+			case *rule.Opsgenie:
+				rr.EndpointID = endpointID
+			case *rule.Teams:
+				rr.EndpointID = endpointID
 			}
 			return r.existing
 		}
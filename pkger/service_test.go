@@ -3250,6 +3250,40 @@ func TestService(t *testing.T) {
 							URL:        "http://example.com",
 						},
 					},
+					{
+						name: "telegram",
+						expected: &endpoint.Telegram{
+							Base: endpoint.Base{
+								Name:        "pd-endpoint",
+								Description: "desc",
+								Status:      influxdb.TaskStatusActive,
+							},
+							Token:   influxdb.SecretField{Key: "token"},
+							Channel: "-1001406363649",
+						},
+					},
+					{
+						name: "opsgenie",
+						expected: &endpoint.Opsgenie{
+							Base: endpoint.Base{
+								Name:        "pd-endpoint",
+								Description: "desc",
+								Status:      influxdb.TaskStatusActive,
+							},
+							APIKey: influxdb.SecretField{Key: "api-key"},
+						},
+					},
+					{
+						name: "teams",
+						expected: &endpoint.Teams{
+							Base: endpoint.Base{
+								Name:        "pd-endpoint",
+								Description: "desc",
+								Status:      influxdb.TaskStatusInactive,
+							},
+							URL: "http://example.com",
+						},
+					},
 				}
 
 				for _, tt := range tests {
@@ -3506,6 +3540,60 @@ func TestService(t *testing.T) {
 								Base: newRuleBase(13),
 							},
 						},
+						{
+							name: "telegram",
+							endpoint: &endpoint.Telegram{
+								Base: endpoint.Base{
+									ID:          newTestIDPtr(13),
+									Name:        "endpoint_0",
+									Description: "desc",
+									Status:      influxdb.TaskStatusActive,
+								},
+								Token:   influxdb.SecretField{Key: "token"},
+								Channel: "-1001406363649",
+							},
+							rule: &rule.Telegram{
+								Base:                  newRuleBase(13),
+								MessageTemplate:       "TELEGRAM TEMPlate",
+								ParseMode:             "MarkdownV2",
+								DisableWebPagePreview: true,
+							},
+						},
+						{
+							name: "opsgenie",
+							endpoint: &endpoint.Opsgenie{
+								Base: endpoint.Base{
+									ID:          newTestIDPtr(13),
+									Name:        "endpoint_0",
+									Description: "desc",
+									Status:      influxdb.TaskStatusActive,
+								},
+								APIKey: influxdb.SecretField{Key: "api-key"},
+							},
+							rule: &rule.Opsgenie{
+								Base:            newRuleBase(13),
+								MessageTemplate: "OPSGENIE TEMPlate",
+								Tags:            []string{"influxdb", "cpu"},
+							},
+						},
+						{
+							name: "teams",
+							endpoint: &endpoint.Teams{
+								Base: endpoint.Base{
+									ID:          newTestIDPtr(13),
+									Name:        "endpoint_0",
+									Description: "desc",
+									Status:      influxdb.TaskStatusInactive,
+								},
+								URL: "http://example.com",
+							},
+							rule: &rule.Teams{
+								Base:            newRuleBase(13),
+								Title:           "TEAMS title",
+								MessageTemplate: "TEAMS TEMPlate",
+								Summary:         "TEAMS summary",
+							},
+						},
 					}
 
 					for _, tt := range tests {
@@ -3586,6 +3674,26 @@ func TestService(t *testing.T) {
 							case *rule.Slack:
 								baseEqual(t, p.Base)
 								assert.Equal(t, p.MessageTemplate, actualRule.MessageTemplate)
+							case *rule.Telegram:
+								baseEqual(t, p.Base)
+								actual, ok := newTemplate.notificationRules()[0].toInfluxRule().(*rule.Telegram)
+								require.True(t, ok)
+								assert.Equal(t, p.MessageTemplate, actual.MessageTemplate)
+								assert.Equal(t, p.ParseMode, actual.ParseMode)
+								assert.Equal(t, p.DisableWebPagePreview, actual.DisableWebPagePreview)
+							case *rule.Opsgenie:
+								baseEqual(t, p.Base)
+								actual, ok := newTemplate.notificationRules()[0].toInfluxRule().(*rule.Opsgenie)
+								require.True(t, ok)
+								assert.Equal(t, p.MessageTemplate, actual.MessageTemplate)
+								assert.Equal(t, p.Tags, actual.Tags)
+							case *rule.Teams:
+								baseEqual(t, p.Base)
+								actual, ok := newTemplate.notificationRules()[0].toInfluxRule().(*rule.Teams)
+								require.True(t, ok)
+								assert.Equal(t, p.Title, actual.Title)
+								assert.Equal(t, p.MessageTemplate, actual.MessageTemplate)
+								assert.Equal(t, p.Summary, actual.Summary)
 							}
 
 							require.Len(t, template.Summary().NotificationEndpoints, 1)
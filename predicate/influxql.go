@@ -0,0 +1,112 @@
+package predicate
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxql"
+)
+
+// measurementKeys are the InfluxQL references to the measurement name of a series.
+var measurementKeys = map[string]struct{}{
+	"_name":        {},
+	"_measurement": {},
+}
+
+// FromInfluxQL converts an InfluxQL condition into a predicate node.
+// Only tag comparisons with = and != joined by AND are supported, so any
+// time conditions must be removed from expr beforehand. Conditions with OR
+// or regular expressions return an error with the ENotImplemented code, so
+// callers can evaluate them another way. A nil node is returned if expr
+// matches every series.
+func FromInfluxQL(expr influxql.Expr) (Node, error) {
+	switch expr := expr.(type) {
+	case nil:
+		return nil, nil
+	case *influxql.ParenExpr:
+		return FromInfluxQL(expr.Expr)
+	case *influxql.BooleanLiteral:
+		if expr.Val {
+			return nil, nil
+		}
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "a condition that never matches is not supported",
+		}
+	case *influxql.BinaryExpr:
+		switch expr.Op {
+		case influxql.AND:
+			lhs, err := FromInfluxQL(expr.LHS)
+			if err != nil {
+				return nil, err
+			}
+			rhs, err := FromInfluxQL(expr.RHS)
+			if err != nil {
+				return nil, err
+			}
+			if lhs == nil {
+				return rhs, nil
+			} else if rhs == nil {
+				return lhs, nil
+			}
+			return LogicalNode{Operator: LogicalAnd, Children: [2]Node{lhs, rhs}}, nil
+		case influxql.EQ, influxql.NEQ:
+			return tagRuleFromInfluxQL(expr)
+		case influxql.OR:
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotImplemented,
+				Msg:  "the logical operator OR is not supported yet",
+			}
+		case influxql.EQREGEX, influxql.NEQREGEX:
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotImplemented,
+				Msg:  fmt.Sprintf("operator: %q is not supported yet", expr.Op.String()),
+			}
+		default:
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid operator %q", expr.Op.String()),
+			}
+		}
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unsupported expression %q", expr.String()),
+		}
+	}
+}
+
+// tagRuleFromInfluxQL converts a comparison between a tag key and
+// a string literal into a TagRuleNode.
+func tagRuleFromInfluxQL(expr *influxql.BinaryExpr) (Node, error) {
+	ref, lit := expr.LHS, expr.RHS
+	if _, ok := ref.(*influxql.VarRef); !ok {
+		ref, lit = lit, ref
+	}
+
+	key, ok := ref.(*influxql.VarRef)
+	if !ok {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("bad tag key in %q", expr.String()),
+		}
+	}
+	value, ok := lit.(*influxql.StringLiteral)
+	if !ok {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("fields not supported in WHERE clause during deletion: %q", expr.String()),
+		}
+	}
+
+	n := TagRuleNode{Tag: influxdb.Tag{Key: key.Val, Value: value.Val}}
+	if _, ok := measurementKeys[key.Val]; ok {
+		n.Key = "_measurement"
+	}
+	if expr.Op == influxql.EQ {
+		n.Operator = influxdb.Equal
+	} else {
+		n.Operator = influxdb.NotEqual
+	}
+	return n, nil
+}
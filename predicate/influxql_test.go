@@ -0,0 +1,90 @@
+package predicate
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxql"
+)
+
+func TestFromInfluxQL(t *testing.T) {
+	cases := []struct {
+		expr string
+		node Node
+		err  error
+	}{
+		{
+			expr: `true`,
+		},
+		{
+			expr: `host = 'a'`,
+			node: TagRuleNode{Tag: influxdb.Tag{Key: "host", Value: "a"}, Operator: influxdb.Equal},
+		},
+		{
+			expr: `'a' != host`,
+			node: TagRuleNode{Tag: influxdb.Tag{Key: "host", Value: "a"}, Operator: influxdb.NotEqual},
+		},
+		{
+			expr: `_name = 'cpu'`,
+			node: TagRuleNode{Tag: influxdb.Tag{Key: "_measurement", Value: "cpu"}, Operator: influxdb.Equal},
+		},
+		{
+			expr: `host = 'a' AND (region = 'west' AND dc != 'b')`,
+			node: LogicalNode{Operator: LogicalAnd, Children: [2]Node{
+				TagRuleNode{Tag: influxdb.Tag{Key: "host", Value: "a"}, Operator: influxdb.Equal},
+				LogicalNode{Operator: LogicalAnd, Children: [2]Node{
+					TagRuleNode{Tag: influxdb.Tag{Key: "region", Value: "west"}, Operator: influxdb.Equal},
+					TagRuleNode{Tag: influxdb.Tag{Key: "dc", Value: "b"}, Operator: influxdb.NotEqual},
+				}},
+			}},
+		},
+		{
+			expr: `host = 'a' AND true`,
+			node: TagRuleNode{Tag: influxdb.Tag{Key: "host", Value: "a"}, Operator: influxdb.Equal},
+		},
+		{
+			expr: `host = 'a' OR host = 'b'`,
+			err: &influxdb.Error{
+				Code: influxdb.ENotImplemented,
+				Msg:  "the logical operator OR is not supported yet",
+			},
+		},
+		{
+			expr: `host =~ /a/`,
+			err: &influxdb.Error{
+				Code: influxdb.ENotImplemented,
+				Msg:  `operator: "=~" is not supported yet`,
+			},
+		},
+		{
+			expr: `value = 1`,
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `fields not supported in WHERE clause during deletion: "value = 1"`,
+			},
+		},
+		{
+			expr: `value > 1`,
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  `invalid operator ">"`,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.expr, func(t *testing.T) {
+			expr, err := influxql.ParseExpr(c.expr)
+			if err != nil {
+				t.Fatalf("unexpected error parsing expression: %v", err)
+			}
+			node, err := FromInfluxQL(expr)
+			errorsEqual(t, err, c.err)
+			if c.err == nil {
+				if diff := cmp.Diff(node, c.node); diff != "" {
+					t.Errorf("node mismatch:\n  %s", diff)
+				}
+			}
+		})
+	}
+}
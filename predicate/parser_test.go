@@ -6,7 +6,6 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb/v2"
-	influxtesting "github.com/influxdata/influxdb/v2/testing"
 	"github.com/influxdata/influxql"
 )
 
@@ -80,7 +79,7 @@ func TestParseNode(t *testing.T) {
 	}
 	for _, c := range cases {
 		node, err := Parse(c.str)
-		influxtesting.ErrorsEqual(t, err, c.err)
+		errorsEqual(t, err, c.err)
 		if c.err == nil {
 			if diff := cmp.Diff(node, c.node); diff != "" {
 				t.Errorf("tag rule mismatch:\n  %s", diff)
@@ -157,7 +156,7 @@ func TestParseTagRule(t *testing.T) {
 		p := new(parser)
 		p.sc = influxql.NewScanner(strings.NewReader(c.str))
 		tr, err := p.parseTagRuleNode()
-		influxtesting.ErrorsEqual(t, err, c.err)
+		errorsEqual(t, err, c.err)
 		if c.err == nil {
 			if diff := cmp.Diff(tr, c.node); diff != "" {
 				t.Errorf("tag rule mismatch:\n  %s", diff)
@@ -165,3 +164,29 @@ func TestParseTagRule(t *testing.T) {
 		}
 	}
 }
+
+// errorsEqual checks to see if the provided errors are equivalent.
+func errorsEqual(t *testing.T, actual, expected error) {
+	t.Helper()
+	if expected == nil && actual == nil {
+		return
+	}
+
+	if expected == nil && actual != nil {
+		t.Errorf("unexpected error %s", actual.Error())
+	}
+
+	if expected != nil && actual == nil {
+		t.Errorf("expected error %s but received nil", expected.Error())
+	}
+
+	if influxdb.ErrorCode(expected) != influxdb.ErrorCode(actual) {
+		t.Logf("\nexpected: %v\nactual: %v\n\n", expected, actual)
+		t.Errorf("expected error code %q but received %q", influxdb.ErrorCode(expected), influxdb.ErrorCode(actual))
+	}
+
+	if influxdb.ErrorMessage(expected) != influxdb.ErrorMessage(actual) {
+		t.Logf("\nexpected: %v\nactual: %v\n\n", expected, actual)
+		t.Errorf("expected error message %q but received %q", influxdb.ErrorMessage(expected), influxdb.ErrorMessage(actual))
+	}
+}
@@ -0,0 +1,5 @@
+// Package prompb holds the protocol buffer messages of the Prometheus remote
+// storage protocol.
+package prompb
+
+//go:generate protoc -I ../../internal -I . --plugin ../../scripts/protoc-gen-gogofaster --gogofaster_out=. remote.proto
@@ -0,0 +1,105 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/prometheus/prompb"
+)
+
+// Samples of the Prometheus remote storage protocol map to points as follows:
+//
+//   - the metric name, the __name__ label, is the measurement,
+//   - the other labels are tags; labels with an empty value are left out,
+//   - the sample value is the float field "value",
+//   - the sample timestamp, in milliseconds, is the point time.
+//
+// NaN samples, such as the staleness markers of Prometheus, are not written.
+const (
+	// MetricNameLabel is the label holding the name of a metric.
+	MetricNameLabel = "__name__"
+	// ValueField is the field holding the value of a sample.
+	ValueField = "value"
+)
+
+// DecodeWriteRequest decodes a snappy compressed remote write request.
+func DecodeWriteRequest(data []byte) (*prompb.WriteRequest, error) {
+	b, err := snappy.Decode(nil, data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress remote write request: %v", err)
+	}
+
+	var req prompb.WriteRequest
+	if err := req.Unmarshal(b); err != nil {
+		return nil, fmt.Errorf("unable to decode remote write request: %v", err)
+	}
+	return &req, nil
+}
+
+// DecodeReadRequest decodes a snappy compressed remote read request.
+func DecodeReadRequest(data []byte) (*prompb.ReadRequest, error) {
+	b, err := snappy.Decode(nil, data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress remote read request: %v", err)
+	}
+
+	var req prompb.ReadRequest
+	if err := req.Unmarshal(b); err != nil {
+		return nil, fmt.Errorf("unable to decode remote read request: %v", err)
+	}
+	return &req, nil
+}
+
+// EncodeReadResponse encodes and snappy compresses a remote read response.
+func EncodeReadResponse(resp *prompb.ReadResponse) ([]byte, error) {
+	b, err := resp.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return snappy.Encode(nil, b), nil
+}
+
+// WriteRequestPoints returns the points of the samples of a remote write
+// request.
+func WriteRequestPoints(req *prompb.WriteRequest) (models.Points, error) {
+	var pts models.Points
+	for _, ts := range req.Timeseries {
+		name, tags := seriesTags(ts.Labels)
+		if name == "" {
+			return nil, fmt.Errorf("time series %v has no %s label", ts.Labels, MetricNameLabel)
+		}
+
+		for _, s := range ts.Samples {
+			if math.IsNaN(s.Value) {
+				continue
+			}
+			pt, err := models.NewPoint(name, tags, models.Fields{ValueField: s.Value}, time.Unix(0, s.Timestamp*nsPerMilliseconds))
+			if err != nil {
+				return nil, fmt.Errorf("unable to write sample of %s: %v", name, err)
+			}
+			pts = append(pts, pt)
+		}
+	}
+	return pts, nil
+}
+
+// seriesTags returns the metric name and the tags of the labels of a time
+// series.
+func seriesTags(labels []prompb.Label) (string, models.Tags) {
+	var name string
+	tags := make(models.Tags, 0, len(labels))
+	for _, l := range labels {
+		switch {
+		case l.Name == MetricNameLabel:
+			name = l.Value
+		case l.Value != "":
+			tags = append(tags, models.NewTag([]byte(l.Name), []byte(l.Value)))
+		}
+	}
+	sort.Sort(tags)
+	return name, tags
+}
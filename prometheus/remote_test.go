@@ -0,0 +1,117 @@
+package prometheus_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/snappy"
+	pr "github.com/influxdata/influxdb/v2/prometheus"
+	"github.com/influxdata/influxdb/v2/prometheus/prompb"
+)
+
+func TestWriteRequestPoints(t *testing.T) {
+	req := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{
+			{
+				Labels: []prompb.Label{
+					{Name: "job", Value: "node"},
+					{Name: "__name__", Value: "node_load1"},
+					{Name: "instance", Value: "a:9100"},
+					{Name: "empty", Value: ""},
+				},
+				Samples: []prompb.Sample{
+					{Value: 0.5, Timestamp: 1602462600000},
+					{Value: math.NaN(), Timestamp: 1602462615000},
+					{Value: 0.75, Timestamp: 1602462630000},
+				},
+			},
+		},
+	}
+	data, err := req.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := pr.DecodeWriteRequest(snappy.Encode(nil, data))
+	if err != nil {
+		t.Fatalf("DecodeWriteRequest() error = %v", err)
+	}
+	pts, err := pr.WriteRequestPoints(got)
+	if err != nil {
+		t.Fatalf("WriteRequestPoints() error = %v", err)
+	}
+
+	want := []string{
+		"node_load1,instance=a:9100,job=node value=0.5 1602462600000000000",
+		"node_load1,instance=a:9100,job=node value=0.75 1602462630000000000",
+	}
+	if len(pts) != len(want) {
+		t.Fatalf("WriteRequestPoints() = %v, want %v", pts, want)
+	}
+	for i := range want {
+		if got := pts[i].String(); got != want[i] {
+			t.Errorf("WriteRequestPoints()[%d] = %s, want %s", i, got, want[i])
+		}
+	}
+
+	if _, err := pr.DecodeWriteRequest(data); err == nil {
+		t.Error("DecodeWriteRequest() of an uncompressed request should fail")
+	}
+
+	req.Timeseries[0].Labels = req.Timeseries[0].Labels[:1]
+	if _, err := pr.WriteRequestPoints(req); err == nil {
+		t.Error("WriteRequestPoints() of a series without a name should fail")
+	}
+}
+
+func TestReadRequestRoundTrip(t *testing.T) {
+	req := &prompb.ReadRequest{
+		Queries: []*prompb.Query{{
+			StartTimestampMs: 1602462600000,
+			EndTimestampMs:   1602462630000,
+			Matchers: []*prompb.LabelMatcher{
+				{Type: prompb.LabelMatcher_EQ, Name: "__name__", Value: "node_load1"},
+				{Type: prompb.LabelMatcher_NRE, Name: "job", Value: "test.*"},
+			},
+		}},
+	}
+	data, err := req.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := pr.DecodeReadRequest(snappy.Encode(nil, data))
+	if err != nil {
+		t.Fatalf("DecodeReadRequest() error = %v", err)
+	}
+	if len(got.Queries) != 1 || len(got.Queries[0].Matchers) != 2 {
+		t.Fatalf("DecodeReadRequest() = %v, want %v", got, req)
+	}
+	if m := got.Queries[0].Matchers[1]; m.Type != prompb.LabelMatcher_NRE || m.Name != "job" || m.Value != "test.*" {
+		t.Errorf("DecodeReadRequest() matcher = %v, want %v", m, req.Queries[0].Matchers[1])
+	}
+
+	resp := &prompb.ReadResponse{
+		Results: []*prompb.QueryResult{{
+			Timeseries: []*prompb.TimeSeries{{
+				Labels:  []prompb.Label{{Name: "__name__", Value: "node_load1"}},
+				Samples: []prompb.Sample{{Value: 0.5, Timestamp: 1602462600000}},
+			}},
+		}},
+	}
+	encoded, err := pr.EncodeReadResponse(resp)
+	if err != nil {
+		t.Fatalf("EncodeReadResponse() error = %v", err)
+	}
+	decoded, err := snappy.Decode(nil, encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotResp prompb.ReadResponse
+	if err := gotResp.Unmarshal(decoded); err != nil {
+		t.Fatal(err)
+	}
+	if ts := gotResp.Results[0].Timeseries[0]; ts.Samples[0] != resp.Results[0].Timeseries[0].Samples[0] {
+		t.Errorf("EncodeReadResponse() samples = %v, want %v", ts.Samples, resp.Results[0].Timeseries[0].Samples)
+	}
+}
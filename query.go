@@ -2,6 +2,7 @@ package influxdb
 
 import (
 	"context"
+	"time"
 
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/complete"
@@ -33,3 +34,46 @@ type FluxLanguageService interface {
 	// Completer will return a flux completer.
 	Completer() complete.Completer
 }
+
+// Query languages of running queries.
+const (
+	FluxQueryLanguage     = "flux"
+	InfluxQLQueryLanguage = "influxql"
+)
+
+// ErrRunningQueryNotFound is returned when killing a query that is not running.
+var ErrRunningQueryNotFound = &Error{
+	Code: ENotFound,
+	Msg:  "query not found",
+}
+
+// RunningQuery describes a Flux or InfluxQL query that is being executed.
+type RunningQuery struct {
+	ID       uint64 `json:"id"`
+	OrgID    ID     `json:"orgID"`
+	UserID   ID     `json:"userID,omitempty"`
+	Language string `json:"language"`
+	Query    string `json:"query"`
+	// Database is the default database of an InfluxQL query.
+	Database  string        `json:"database,omitempty"`
+	State     string        `json:"state"`
+	StartTime time.Time     `json:"startTime"`
+	Duration  time.Duration `json:"duration"`
+	// Memory is the number of bytes currently allocated by the query.
+	Memory int64 `json:"memory"`
+}
+
+// RunningQueryFilter represents a set of filters that restrict the returned running queries.
+type RunningQueryFilter struct {
+	ID    *uint64
+	OrgID *ID
+}
+
+// RunningQueryService represents a service for listing and cancelling running queries.
+type RunningQueryService interface {
+	// FindRunningQueries returns the running queries that match the filter, ordered by ID.
+	FindRunningQueries(ctx context.Context, filter RunningQueryFilter) ([]*RunningQuery, error)
+
+	// KillQuery cancels a running query.
+	KillQuery(ctx context.Context, id uint64) error
+}
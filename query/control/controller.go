@@ -101,6 +101,10 @@ type Config struct {
 	MetricLabelKeys []string
 
 	ExecutorDependencies []flux.Dependency
+
+	// Registry, if set, tracks the queries run by the controller
+	// alongside the queries run by other query engines.
+	Registry *query.Registry
 }
 
 // complete will fill in the defaults, validate the configuration, and
@@ -304,9 +308,33 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 		return nil, err
 	}
 	c.queries[id] = q
+	if c.config.Registry != nil {
+		_, q.unregister = c.config.Registry.Register(runningQuery(ctx), q)
+	}
 	return q, nil
 }
 
+// runningQuery describes the request of a query for the registry.
+func runningQuery(ctx context.Context) influxdb.RunningQuery {
+	rq := influxdb.RunningQuery{Language: influxdb.FluxQueryLanguage}
+	req := query.RequestFromContext(ctx)
+	if req == nil {
+		return rq
+	}
+
+	rq.OrgID = req.OrganizationID
+	if req.Authorization != nil {
+		rq.UserID = req.Authorization.GetUserID()
+	}
+	switch c := req.Compiler.(type) {
+	case lang.FluxCompiler:
+		rq.Query = c.Query
+	case *lang.FluxCompiler:
+		rq.Query = c.Query
+	}
+	return rq
+}
+
 func (c *Controller) nextID() QueryID {
 	nextID := atomic.AddUint64(&c.lastID, 1)
 	return QueryID(nextID)
@@ -426,7 +454,10 @@ func (c *Controller) executeQuery(q *Query) {
 		return
 	}
 
+	// The allocator is guarded by the state mutex as it is read by Progress.
+	q.stateMu.Lock()
 	q.c.createAllocator(q)
+	q.stateMu.Unlock()
 	// Record unused memory before start.
 	q.recordUnusedMemory()
 	exec, err := q.program.Start(ctx, q.alloc)
@@ -447,6 +478,10 @@ func (c *Controller) waitForQuery(q *Query) {
 }
 
 func (c *Controller) finish(q *Query) {
+	if q.unregister != nil {
+		q.unregister()
+	}
+
 	c.queriesMu.Lock()
 	delete(c.queries, q.id)
 	if len(c.queries) == 0 && c.shutdown {
@@ -552,6 +587,9 @@ type Query struct {
 
 	memoryManager *queryMemoryManager
 	alloc         *memory.Allocator
+
+	// unregister removes the query from the registry of the controller.
+	unregister func()
 }
 
 func (q *Query) ProfilerResults() (flux.ResultIterator, error) {
@@ -680,6 +718,19 @@ func (q *Query) Statistics() flux.Statistics {
 	return stats
 }
 
+// Progress reports the current state of the query and the number
+// of bytes of memory it has allocated. It implements query.Tracker.
+func (q *Query) Progress() (string, int64) {
+	state := q.State()
+
+	q.stateMu.RLock()
+	defer q.stateMu.RUnlock()
+	if q.alloc == nil {
+		return state.String(), 0
+	}
+	return state.String(), q.alloc.Allocated()
+}
+
 // State reports the current state of the query.
 func (q *Query) State() State {
 	q.stateMu.RLock()
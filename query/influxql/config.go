@@ -2,6 +2,8 @@ package influxql
 
 import (
 	"time"
+
+	"github.com/influxdata/influxdb/v2"
 )
 
 // Config modifies the behavior of the Transpiler.
@@ -16,4 +18,7 @@ type Config struct {
 	// FallbackToDBRP if true will use the naming convention of `db/rp`
 	// for a bucket name when an mapping is not found
 	FallbackToDBRP bool
+	// OrgID restricts the dbrp mappings used to resolve buckets to a single
+	// organization. It is ignored if it is not valid.
+	OrgID influxdb.ID
 }
@@ -0,0 +1,179 @@
+package influxql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxql"
+)
+
+// TranspileContinuousQuery converts a continuous query into the Flux script of a task.
+// The task runs the SELECT statement of the continuous query on every RESAMPLE EVERY
+// interval over the last RESAMPLE FOR duration and writes the results to the bucket
+// identified by orgID and bucketID. Both durations default to the GROUP BY time() interval.
+// Like continuous queries in 1.x, the range of each run is aligned to the GROUP BY
+// time() interval, so every window it writes is computed over all of its data.
+func (t *Transpiler) TranspileContinuousQuery(ctx context.Context, stmt *influxql.CreateContinuousQueryStatement, orgID, bucketID influxdb.ID) (*ast.File, error) {
+	interval, err := stmt.Source.GroupByInterval()
+	if err != nil {
+		return nil, err
+	} else if interval == 0 {
+		return nil, errors.New("unable to transpile: continuous query must have a GROUP BY time() interval")
+	}
+
+	every, resampleFor := interval, interval
+	if stmt.ResampleEvery > 0 {
+		every = stmt.ResampleEvery
+		if every > resampleFor {
+			resampleFor = every
+		}
+	}
+	if stmt.ResampleFor > 0 {
+		resampleFor = stmt.ResampleFor
+	}
+
+	// The range starts at the first interval that lies within RESAMPLE FOR and
+	// stops at the end of the last interval that has started RESAMPLE EVERY ago.
+	start := interval - resampleFor - time.Nanosecond
+	stop := interval - every
+	if stop < 0 {
+		stop = 0
+	}
+
+	transpiler := newTranspilerState(t.dbrpMappingSvc, t.Config)
+	cur, err := transpiler.transpileSelect(ctx, stmt.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	expr := cur.Expr()
+	if name := stmt.Source.Target.Measurement.Name; name != "" {
+		expr = pipe(expr, "set",
+			property("key", &ast.StringLiteral{Value: "_measurement"}),
+			property("value", &ast.StringLiteral{Value: name}),
+		)
+	}
+
+	// Every column produced by the select statement is written as a field.
+	// The remaining string columns, other than the field name, are written as tags.
+	fields := make([]*ast.Property, 0, len(transpiler.stmt.Fields))
+	for _, column := range transpiler.stmt.ColumnNames() {
+		fields = append(fields, &ast.Property{
+			Key: &ast.StringLiteral{Value: column},
+			Value: &ast.MemberExpression{
+				Object:   &ast.Identifier{Name: "r"},
+				Property: &ast.StringLiteral{Value: column},
+			},
+		})
+	}
+	expr = pipe(expr, "drop",
+		property("fn", &ast.FunctionExpression{
+			Params: []*ast.Property{{Key: &ast.Identifier{Name: "column"}}},
+			Body: &ast.BinaryExpression{
+				Operator: ast.EqualOperator,
+				Left:     &ast.Identifier{Name: "column"},
+				Right:    &ast.StringLiteral{Value: "_field"},
+			},
+		}),
+	)
+	expr = pipe(expr, "to",
+		property("bucketID", &ast.StringLiteral{Value: bucketID.String()}),
+		property("orgID", &ast.StringLiteral{Value: orgID.String()}),
+		property("fieldFn", &ast.FunctionExpression{
+			Params: []*ast.Property{{Key: &ast.Identifier{Name: "r"}}},
+			Body: &ast.ParenExpression{
+				Expression: &ast.ObjectExpression{Properties: fields},
+			},
+		}),
+	)
+
+	// Any assignments made by the transpiler, such as the inputs of a join,
+	// follow the task options.
+	file := transpiler.file
+	file.Body = append([]ast.Statement{
+		&ast.OptionStatement{
+			Assignment: &ast.VariableAssignment{
+				ID: &ast.Identifier{Name: "task"},
+				Init: &ast.ObjectExpression{
+					Properties: []*ast.Property{
+						property("name", &ast.StringLiteral{Value: stmt.Name}),
+						property("every", &ast.DurationLiteral{Values: durationLiteral(every)}),
+					},
+				},
+			},
+		},
+	}, file.Body...)
+	file.Body = append(file.Body, &ast.ExpressionStatement{Expression: expr})
+
+	// The transpiler stamps the time range using the current time, but a task
+	// needs a range relative to the time it is run.
+	date := transpiler.requireImport("date")
+	ast.Visit(file, func(n ast.Node) {
+		call, ok := n.(*ast.CallExpression)
+		if !ok {
+			return
+		}
+		if ident, ok := call.Callee.(*ast.Identifier); !ok || ident.Name != "range" {
+			return
+		}
+		call.Arguments = []ast.Expression{
+			&ast.ObjectExpression{
+				Properties: []*ast.Property{
+					property("start", truncate(date, start, interval)),
+					property("stop", truncate(date, stop, interval)),
+				},
+			},
+		}
+	})
+
+	return file, nil
+}
+
+// truncate returns a call to date.truncate that truncates the time d from now
+// to the unit.
+func truncate(date *ast.Identifier, d, unit time.Duration) ast.Expression {
+	var t ast.Expression = &ast.DurationLiteral{Values: durationLiteral(d)}
+	if d < 0 {
+		t = &ast.UnaryExpression{
+			Operator: ast.SubtractionOperator,
+			Argument: &ast.DurationLiteral{Values: durationLiteral(-d)},
+		}
+	}
+	return &ast.CallExpression{
+		Callee: &ast.MemberExpression{
+			Object:   date,
+			Property: &ast.Identifier{Name: "truncate"},
+		},
+		Arguments: []ast.Expression{
+			&ast.ObjectExpression{
+				Properties: []*ast.Property{
+					property("t", t),
+					property("unit", &ast.DurationLiteral{Values: durationLiteral(unit)}),
+				},
+			},
+		},
+	}
+}
+
+// pipe pipes the argument into a call to the named function with the given properties.
+func pipe(arg ast.Expression, name string, properties ...*ast.Property) ast.Expression {
+	return &ast.PipeExpression{
+		Argument: arg,
+		Call: &ast.CallExpression{
+			Callee: &ast.Identifier{Name: name},
+			Arguments: []ast.Expression{
+				&ast.ObjectExpression{Properties: properties},
+			},
+		},
+	}
+}
+
+func property(key string, value ast.Expression) *ast.Property {
+	return &ast.Property{
+		Key:   &ast.Identifier{Name: key},
+		Value: value,
+	}
+}
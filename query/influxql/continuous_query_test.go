@@ -0,0 +1,138 @@
+package influxql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2/query/influxql"
+	platformtesting "github.com/influxdata/influxdb/v2/testing"
+	iql "github.com/influxdata/influxql"
+)
+
+func TestTranspiler_TranspileContinuousQuery(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		s    string
+		want string
+	}{
+		{
+			name: "group by interval",
+			s:    `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.autogen.cpu_mean FROM db0.autogen.cpu GROUP BY time(10m), host END`,
+			want: `package main
+
+
+import date "date"
+
+option task = {name: "cq0", every: 10m}
+
+from(bucketID: "bbbbbbbbbbbbbbbb")
+	|> range(start: date.truncate(t: -1ns, unit: 10m), stop: date.truncate(t: 0s, unit: 10m))
+	|> filter(fn: (r) =>
+		(r._measurement == "cpu" and r._field == "value"))
+	|> group(columns: ["_measurement", "_start", "_stop", "_field", "host"], mode: "by")
+	|> keep(columns: ["_measurement", "_start", "_stop", "_field", "host", "_time", "_value"])
+	|> window(every: 10m)
+	|> mean()
+	|> map(fn: (r) =>
+		({r with _time: r._start}))
+	|> window(every: inf)
+	|> rename(columns: {_value: "mean"})
+	|> set(key: "_measurement", value: "cpu_mean")
+	|> drop(fn: (column) =>
+		(column == "_field"))
+	|> to(bucketID: "cccccccccccccccc", orgID: "aaaaaaaaaaaaaaaa", fieldFn: (r) =>
+		({"mean": r["mean"]}))`,
+		},
+		{
+			name: "resample every",
+			s:    `CREATE CONTINUOUS QUERY cq0 ON db0 RESAMPLE EVERY 1h BEGIN SELECT max(value) INTO db0.autogen.:MEASUREMENT FROM db0.autogen.cpu GROUP BY time(30m) END`,
+			want: `package main
+
+
+import date "date"
+
+option task = {name: "cq0", every: 1h}
+
+from(bucketID: "bbbbbbbbbbbbbbbb")
+	|> range(start: date.truncate(t: -30m1ns, unit: 30m), stop: date.truncate(t: 0s, unit: 30m))
+	|> filter(fn: (r) =>
+		(r._measurement == "cpu" and r._field == "value"))
+	|> group(columns: ["_measurement", "_start", "_stop", "_field"], mode: "by")
+	|> keep(columns: ["_measurement", "_start", "_stop", "_field", "_time", "_value"])
+	|> window(every: 30m)
+	|> max()
+	|> drop(columns: ["_time"])
+	|> map(fn: (r) =>
+		({r with _time: r._start}))
+	|> window(every: inf)
+	|> rename(columns: {_value: "max"})
+	|> drop(fn: (column) =>
+		(column == "_field"))
+	|> to(bucketID: "cccccccccccccccc", orgID: "aaaaaaaaaaaaaaaa", fieldFn: (r) =>
+		({"max": r["max"]}))`,
+		},
+		{
+			name: "resample every and for",
+			s:    `CREATE CONTINUOUS QUERY cq0 ON db0 RESAMPLE EVERY 1m FOR 1h BEGIN SELECT max(value), min(value) INTO db0.autogen.cpu_range FROM db0.autogen.cpu GROUP BY time(30m) END`,
+			want: `package main
+
+
+import date "date"
+
+option task = {name: "cq0", every: 1m}
+
+t0 = from(bucketID: "bbbbbbbbbbbbbbbb")
+	|> range(start: date.truncate(t: -30m1ns, unit: 30m), stop: date.truncate(t: 29m, unit: 30m))
+	|> filter(fn: (r) =>
+		(r._measurement == "cpu" and r._field == "value"))
+	|> group(columns: ["_measurement", "_start", "_stop", "_field"], mode: "by")
+	|> keep(columns: ["_measurement", "_start", "_stop", "_field", "_time", "_value"])
+	|> window(every: 30m)
+	|> max()
+	|> drop(columns: ["_time"])
+	|> map(fn: (r) =>
+		({r with _time: r._start}))
+	|> window(every: inf)
+t1 = from(bucketID: "bbbbbbbbbbbbbbbb")
+	|> range(start: date.truncate(t: -30m1ns, unit: 30m), stop: date.truncate(t: 29m, unit: 30m))
+	|> filter(fn: (r) =>
+		(r._measurement == "cpu" and r._field == "value"))
+	|> group(columns: ["_measurement", "_start", "_stop", "_field"], mode: "by")
+	|> keep(columns: ["_measurement", "_start", "_stop", "_field", "_time", "_value"])
+	|> window(every: 30m)
+	|> min()
+	|> drop(columns: ["_time"])
+	|> map(fn: (r) =>
+		({r with _time: r._start}))
+	|> window(every: inf)
+
+join(tables: {t0: t0, t1: t1}, on: ["_time", "_measurement"])
+	|> rename(columns: {"t0__value": "max", "t1__value": "min"})
+	|> set(key: "_measurement", value: "cpu_range")
+	|> drop(fn: (column) =>
+		(column == "_field"))
+	|> to(bucketID: "cccccccccccccccc", orgID: "aaaaaaaaaaaaaaaa", fieldFn: (r) =>
+		({"max": r["max"], "min": r["min"]}))`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := iql.ParseStatement(tt.s)
+			if err != nil {
+				t.Fatalf("unexpected error parsing statement: %v", err)
+			}
+
+			transpiler := influxql.NewTranspiler(dbrpMappingSvc)
+			file, err := transpiler.TranspileContinuousQuery(context.Background(), stmt.(*iql.CreateContinuousQueryStatement),
+				platformtesting.MustIDBase16("aaaaaaaaaaaaaaaa"),
+				platformtesting.MustIDBase16("cccccccccccccccc"),
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := ast.Format(file); got != tt.want {
+				t.Errorf("unexpected flux script:\n-- got --\n%s\n-- want --\n%s", got, tt.want)
+			}
+		})
+	}
+}
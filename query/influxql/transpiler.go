@@ -696,6 +696,9 @@ func (t *transpilerState) from(m *influxql.Measurement) (ast.Expression, error)
 		}
 
 		var filter influxdb.DBRPMappingFilterV2
+		if t.config.OrgID.Valid() {
+			filter.OrgID = &t.config.OrgID
+		}
 		if db != "" {
 			filter.Database = &db
 		}
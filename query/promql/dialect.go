@@ -0,0 +1,203 @@
+package promql
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+)
+
+const (
+	// SeriesDialectType is the type of the dialect collecting the series of
+	// the results of a query.
+	SeriesDialectType = "promql-series"
+	// ValuesDialectType is the type of the dialect collecting the string
+	// values of the results of a query.
+	ValuesDialectType = "promql-values"
+)
+
+// Label is a label of a series.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a sample of a series, at a time in nanoseconds.
+type Sample struct {
+	Time  int64
+	Value float64
+}
+
+// Series is a series of the results of a query, with labels sorted by name
+// and samples sorted by time.
+type Series struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+// SeriesDialect is a dialect that collects the series of the results of the
+// Flux queries of PromQL queries rather than writing them. The labels of a
+// series are the string columns of the group key of its tables, and its
+// samples the _time and _value columns.
+type SeriesDialect struct {
+	Series []*Series
+}
+
+func (d *SeriesDialect) Encoder() flux.MultiResultEncoder {
+	return &seriesEncoder{d: d}
+}
+
+func (d *SeriesDialect) DialectType() flux.DialectType {
+	return SeriesDialectType
+}
+
+type seriesEncoder struct {
+	d *SeriesDialect
+}
+
+func (e *seriesEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	defer results.Release()
+
+	series := make(map[string]*Series)
+	for results.More() {
+		if err := results.Next().Tables().Do(func(tbl flux.Table) error {
+			labels := seriesLabels(tbl.Key())
+			key := labelsKey(labels)
+			s, ok := series[key]
+			if !ok {
+				s = &Series{Labels: labels}
+				series[key] = s
+				e.d.Series = append(e.d.Series, s)
+			}
+
+			timeIdx := execute.ColIdx(execute.DefaultTimeColLabel, tbl.Cols())
+			valueIdx := execute.ColIdx(execute.DefaultValueColLabel, tbl.Cols())
+			if timeIdx < 0 || valueIdx < 0 {
+				return tbl.Do(func(flux.ColReader) error { return nil })
+			}
+			return tbl.Do(func(cr flux.ColReader) error {
+				times := cr.Times(timeIdx)
+				for i := 0; i < cr.Len(); i++ {
+					if !times.IsValid(i) {
+						continue
+					}
+					if v, ok := floatValue(cr, valueIdx, i); ok {
+						s.Samples = append(s.Samples, Sample{Time: times.Value(i), Value: v})
+					}
+				}
+				return nil
+			})
+		}); err != nil {
+			return 0, err
+		}
+	}
+	if err := results.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, s := range e.d.Series {
+		sort.SliceStable(s.Samples, func(i, j int) bool { return s.Samples[i].Time < s.Samples[j].Time })
+	}
+	return 0, nil
+}
+
+// seriesLabels returns the labels of the series of a group key.
+func seriesLabels(key flux.GroupKey) []Label {
+	var labels []Label
+	for j, c := range key.Cols() {
+		if c.Type != flux.TString || key.IsNull(j) {
+			continue
+		}
+		switch c.Label {
+		case "_field", "_value", "result", "table":
+			continue
+		case "_measurement":
+			labels = append(labels, Label{Name: metricNameLabel, Value: key.ValueString(j)})
+		default:
+			labels = append(labels, Label{Name: c.Label, Value: key.ValueString(j)})
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return labels
+}
+
+func labelsKey(labels []Label) string {
+	var b strings.Builder
+	for _, l := range labels {
+		b.WriteString(l.Name)
+		b.WriteByte(0)
+		b.WriteString(l.Value)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// floatValue returns the value of a row of a numeric column as a float.
+func floatValue(cr flux.ColReader, j, i int) (float64, bool) {
+	switch cr.Cols()[j].Type {
+	case flux.TFloat:
+		vs := cr.Floats(j)
+		return vs.Value(i), vs.IsValid(i)
+	case flux.TInt:
+		vs := cr.Ints(j)
+		return float64(vs.Value(i)), vs.IsValid(i)
+	case flux.TUInt:
+		vs := cr.UInts(j)
+		return float64(vs.Value(i)), vs.IsValid(i)
+	default:
+		return 0, false
+	}
+}
+
+// ValuesDialect is a dialect that collects the distinct strings of the
+// _value column of the results of a query, such as the results of
+// LabelNamesFlux and LabelValuesFlux.
+type ValuesDialect struct {
+	Values []string
+}
+
+func (d *ValuesDialect) Encoder() flux.MultiResultEncoder {
+	return &valuesEncoder{d: d}
+}
+
+func (d *ValuesDialect) DialectType() flux.DialectType {
+	return ValuesDialectType
+}
+
+type valuesEncoder struct {
+	d *ValuesDialect
+}
+
+func (e *valuesEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	defer results.Release()
+
+	seen := make(map[string]bool)
+	for results.More() {
+		if err := results.Next().Tables().Do(func(tbl flux.Table) error {
+			j := execute.ColIdx(execute.DefaultValueColLabel, tbl.Cols())
+			if j < 0 || tbl.Cols()[j].Type != flux.TString {
+				return tbl.Do(func(flux.ColReader) error { return nil })
+			}
+			return tbl.Do(func(cr flux.ColReader) error {
+				vs := cr.Strings(j)
+				for i := 0; i < cr.Len(); i++ {
+					if v := vs.ValueString(i); vs.IsValid(i) && v != "" && !seen[v] {
+						seen[v] = true
+						e.d.Values = append(e.d.Values, v)
+					}
+				}
+				return nil
+			})
+		}); err != nil {
+			return 0, err
+		}
+	}
+	if err := results.Err(); err != nil {
+		return 0, err
+	}
+
+	sort.Strings(e.d.Values)
+	return 0, nil
+}
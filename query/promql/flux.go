@@ -0,0 +1,372 @@
+package promql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// Series of PromQL queries are the series of the "value" field of a bucket,
+// as written by Prometheus remote write: the measurement is the metric name,
+// the __name__ label, and the tags are the other labels.
+const (
+	metricNameLabel = "__name__"
+	valueField      = "value"
+)
+
+// DefaultLookbackDelta is how far back instant vector selectors look for the
+// latest sample of a series, as in Prometheus.
+const DefaultLookbackDelta = 5 * time.Minute
+
+// FluxOptions are the options of the Flux translation of a PromQL query.
+type FluxOptions struct {
+	// Bucket, or BucketID when it is valid, is the bucket to query.
+	Bucket   string
+	BucketID influxdb.ID
+
+	// Start and End are the first and last evaluation times of the query,
+	// and Step the time between evaluations. Instant queries are evaluated
+	// once, at End.
+	Start, End time.Time
+	Step       time.Duration
+
+	// LookbackDelta, if not zero, replaces DefaultLookbackDelta.
+	LookbackDelta time.Duration
+}
+
+func (o FluxOptions) lookbackDelta() time.Duration {
+	if o.LookbackDelta > 0 {
+		return o.LookbackDelta
+	}
+	return DefaultLookbackDelta
+}
+
+func (o FluxOptions) from() string {
+	if o.BucketID.Valid() {
+		return fmt.Sprintf("from(bucketID: %s)", fluxString(o.BucketID.String()))
+	}
+	return fmt.Sprintf("from(bucket: %s)", fluxString(o.Bucket))
+}
+
+// IsRangeSelector reports whether a PromQL query is a range vector selector,
+// whose result is a matrix rather than a vector when evaluated once.
+func IsRangeSelector(query string) bool {
+	parsed, err := ParsePromQL(query)
+	if err != nil {
+		return false
+	}
+	s, ok := parsed.(*Selector)
+	return ok && s.Range > 0
+}
+
+// Flux translates a PromQL query to Flux. Vector selectors, with label
+// matchers, ranges and offsets, and the count, sum, min, max, avg, stddev,
+// stdvar, topk and bottomk aggregations are supported. Range vector
+// selectors are only supported in instant queries.
+//
+// The results of the query are a table per series, with the evaluation
+// times of the query in the _time column.
+func Flux(query string, opts FluxOptions) (string, error) {
+	parsed, err := ParsePromQL(query)
+	if err != nil {
+		return "", err
+	}
+	if opts.Step <= 0 {
+		opts.Start = opts.End
+	}
+
+	var b strings.Builder
+	switch expr := parsed.(type) {
+	case *Selector:
+		if expr.Range > 0 {
+			if !opts.Start.Equal(opts.End) {
+				return "", fmt.Errorf("range vector selector %s is only supported by instant queries", expr.Name)
+			}
+			rangeSelectorFlux(&b, expr, opts)
+			break
+		}
+		vectorSelectorFlux(&b, expr, opts)
+	case *AggregateExpr:
+		if expr.Selector.Range > 0 {
+			return "", fmt.Errorf("unable to aggregate range vector selector %s", expr.Selector.Name)
+		}
+		vectorSelectorFlux(&b, expr.Selector, opts)
+		if err := aggregateFlux(&b, expr); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported query %s", query)
+	}
+	return b.String(), nil
+}
+
+// SeriesFlux returns a Flux query of the samples of the series matching
+// label matchers, between start and end inclusive.
+func SeriesFlux(matchers []*LabelMatcher, start, end time.Time, opts FluxOptions) string {
+	var b strings.Builder
+	selectFlux(&b, "", matchers, start, end, opts)
+	return b.String()
+}
+
+// LabelNamesFlux returns a Flux query of the label names of the series
+// between start and end, as strings in the _value column.
+func LabelNamesFlux(start, end time.Time, opts FluxOptions) string {
+	var b strings.Builder
+	selectFlux(&b, "", nil, start, end, opts)
+	b.WriteString(`  |> keys()
+  |> keep(columns: ["_value"])
+  |> distinct()
+  |> filter(fn: (r) => r._value != "_start" and r._value != "_stop" and r._value != "_field")
+  |> map(fn: (r) => ({_value: if r._value == "_measurement" then "__name__" else r._value}))
+  |> group()
+  |> sort()
+`)
+	return b.String()
+}
+
+// LabelValuesFlux returns a Flux query of the values of a label of the
+// series between start and end, as strings in the _value column.
+func LabelValuesFlux(label string, start, end time.Time, opts FluxOptions) string {
+	var b strings.Builder
+	selectFlux(&b, "", nil, start, end, opts)
+	fmt.Fprintf(&b, `  |> keep(columns: [%[1]s])
+  |> group()
+  |> distinct(column: %[1]s)
+  |> sort()
+`, fluxString(columnName(label)))
+	return b.String()
+}
+
+// selectFlux writes the selection of the series of a metric, or of all
+// metrics when name is empty, matching label matchers between start and end
+// inclusive.
+func selectFlux(b *strings.Builder, name string, matchers []*LabelMatcher, start, end time.Time, opts FluxOptions) {
+	predicate := []string{fmt.Sprintf("r._field == %s", fluxString(valueField))}
+	if name != "" {
+		predicate = append(predicate, fmt.Sprintf("r._measurement == %s", fluxString(name)))
+	}
+	for _, m := range matchers {
+		predicate = append(predicate, matcherPredicate(m))
+	}
+
+	fmt.Fprintf(b, "%s\n", opts.from())
+	fmt.Fprintf(b, "  |> range(start: %s, stop: %s)\n", fluxTime(start), fluxTime(end.Add(time.Nanosecond)))
+	fmt.Fprintf(b, "  |> filter(fn: (r) => %s)\n", strings.Join(predicate, " and "))
+}
+
+// rangeSelectorFlux writes the samples of a range vector selector evaluated
+// at opts.End.
+func rangeSelectorFlux(b *strings.Builder, s *Selector, opts FluxOptions) {
+	end := opts.End.Add(-s.Offset)
+	selectFlux(b, s.Name, s.LabelMatchers, end.Add(-s.Range+time.Nanosecond), end, opts)
+}
+
+// vectorSelectorFlux writes the samples of an instant vector selector at
+// each evaluation time: the latest sample of each series within the lookback
+// delta. Every evaluation time is the stop of a window of the lookback
+// delta, and windows truncated by the range are left out.
+func vectorSelectorFlux(b *strings.Builder, s *Selector, opts FluxOptions) {
+	lookback := opts.lookbackDelta()
+	step := opts.Step
+	if step <= 0 {
+		step = lookback
+	}
+	start, end := opts.Start.Add(-s.Offset), opts.End.Add(-s.Offset)
+	// Windows stop right after evaluation times.
+	offset := time.Duration(start.Add(time.Nanosecond).UnixNano() % int64(step))
+	if offset < 0 {
+		offset += step
+	}
+
+	selectFlux(b, s.Name, s.LabelMatchers, start.Add(-lookback+time.Nanosecond), end, opts)
+	fmt.Fprintf(b, `  |> window(every: %s, period: %s, offset: %s)
+  |> last()
+  |> filter(fn: (r) => int(v: r._stop) - int(v: r._start) == %d)
+  |> map(fn: (r) => ({r with _time: time(v: int(v: r._stop) %s)}))
+  |> group(mode: "except", columns: ["_start", "_stop", "_time", "_value"])
+`, fluxDuration(step), fluxDuration(lookback), fluxDuration(offset), int64(lookback), fluxAddend(int64(s.Offset-time.Nanosecond)))
+}
+
+// aggregateFlux writes the aggregation of the series of a vector selector
+// at each evaluation time.
+func aggregateFlux(b *strings.Builder, a *AggregateExpr) error {
+	var labels []string
+	if a.Aggregate != nil {
+		for _, l := range a.Aggregate.Labels {
+			labels = append(labels, fluxString(columnName(l.Name)))
+		}
+	}
+	without := a.Aggregate != nil && a.Aggregate.Without
+	// The columns of a series that are not labels.
+	series := []string{`"_start"`, `"_stop"`, `"_time"`, `"_value"`}
+
+	// Group the samples of each evaluation time.
+	if without {
+		fmt.Fprintf(b, "  |> group(mode: \"except\", columns: [%s])\n", strings.Join(append(labels, `"_measurement"`, `"_field"`, `"_start"`, `"_stop"`, `"_value"`), ", "))
+	} else {
+		fmt.Fprintf(b, "  |> group(columns: [%s])\n", strings.Join(append(labels, `"_time"`), ", "))
+	}
+
+	switch a.Op.Kind {
+	case SumKind:
+		b.WriteString("  |> sum()\n")
+	case CountKind:
+		b.WriteString("  |> count()\n")
+	case MinKind:
+		b.WriteString("  |> min()\n")
+	case MaxKind:
+		b.WriteString("  |> max()\n")
+	case AvgKind:
+		b.WriteString("  |> mean()\n")
+	case StdevKind:
+		b.WriteString("  |> stddev(mode: \"population\")\n")
+	case StdVarKind:
+		b.WriteString("  |> stddev(mode: \"population\")\n")
+		b.WriteString("  |> map(fn: (r) => ({r with _value: r._value * r._value}))\n")
+	case TopKind, BottomKind:
+		n, ok := a.Op.Arg.(*Number)
+		if !ok || n.Val < 1 {
+			return fmt.Errorf("invalid number of series %v", a.Op.Arg.Value())
+		}
+		fn := "top"
+		if a.Op.Kind == BottomKind {
+			fn = "bottom"
+		}
+		fmt.Fprintf(b, "  |> %s(n: %d)\n", fn, int64(n.Val))
+		// The selected samples keep the labels of their series.
+		fmt.Fprintf(b, "  |> group(mode: \"except\", columns: [%s])\n", strings.Join(series, ", "))
+		return nil
+	default:
+		return fmt.Errorf("unsupported aggregation operator %d", a.Op.Kind)
+	}
+
+	if without {
+		fmt.Fprintf(b, "  |> group(mode: \"except\", columns: [%s])\n", strings.Join(append(labels, append(series, `"_measurement"`, `"_field"`)...), ", "))
+	} else {
+		fmt.Fprintf(b, "  |> group(columns: [%s])\n", strings.Join(labels, ", "))
+	}
+	return nil
+}
+
+// matcherPredicate returns the Flux predicate of a label matcher. As in
+// Prometheus, a series without a label matches the empty value.
+func matcherPredicate(m *LabelMatcher) string {
+	ref := fmt.Sprintf("r[%s]", fluxString(columnName(m.Name)))
+	value := matcherValue(m.Value)
+
+	switch m.Kind {
+	case NotEqual:
+		if value == "" {
+			return fmt.Sprintf("exists %s", ref)
+		}
+		return fmt.Sprintf("(not exists %s or %s != %s)", ref, ref, fluxString(value))
+	case RegexMatch, RegexNoMatch:
+		re := "^(?:" + value + ")$"
+		op := "=~"
+		if m.Kind == RegexNoMatch {
+			op = "!~"
+		}
+		// The regular expression is invalid when it fails to compile; the
+		// query then fails on the Flux regular expression.
+		matchesEmpty := false
+		if r, err := regexp.Compile(re); err == nil {
+			matchesEmpty = r.MatchString("")
+		}
+		if matchesEmpty == (m.Kind == RegexMatch) {
+			return fmt.Sprintf("(not exists %s or %s %s %s)", ref, ref, op, fluxRegexp(re))
+		}
+		return fmt.Sprintf("(exists %s and %s %s %s)", ref, ref, op, fluxRegexp(re))
+	default:
+		if value == "" {
+			return fmt.Sprintf("not exists %s", ref)
+		}
+		return fmt.Sprintf("%s == %s", ref, fluxString(value))
+	}
+}
+
+func matcherValue(v Arg) string {
+	switch v := v.(type) {
+	case *StringLiteral:
+		return v.String
+	case *Number:
+		return strconv.FormatFloat(v.Val, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v.Value())
+	}
+}
+
+// columnName returns the column of a label.
+func columnName(label string) string {
+	if label == metricNameLabel {
+		return "_measurement"
+	}
+	return label
+}
+
+// fluxString returns a Flux string literal.
+func fluxString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '$':
+			// Escape the start of string interpolations.
+			if i+1 < len(s) && s[i+1] == '{' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// fluxRegexp returns a Flux regular expression literal.
+func fluxRegexp(re string) string {
+	return "/" + strings.ReplaceAll(re, "/", `\/`) + "/"
+}
+
+func fluxTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// fluxDuration returns a Flux duration literal of a positive duration.
+func fluxDuration(d time.Duration) string {
+	for _, unit := range []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+		{time.Millisecond, "ms"},
+	} {
+		if d%unit.d == 0 {
+			return strconv.FormatInt(int64(d/unit.d), 10) + unit.name
+		}
+	}
+	return strconv.FormatInt(int64(d), 10) + "ns"
+}
+
+// fluxAddend returns the addition of an integer in Flux.
+func fluxAddend(n int64) string {
+	if n < 0 {
+		return "- " + strconv.FormatInt(-n, 10)
+	}
+	return "+ " + strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,164 @@
+package promql
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlux(t *testing.T) {
+	end := time.Date(2020, 10, 12, 0, 0, 0, 0, time.UTC)
+	instant := FluxOptions{Bucket: "prometheus", End: end}
+	ranged := FluxOptions{Bucket: "prometheus", Start: end.Add(-time.Hour), End: end, Step: time.Minute}
+
+	tests := []struct {
+		name    string
+		promql  string
+		opts    FluxOptions
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "instant vector selector",
+			promql: `up{job="node"}`,
+			opts:   instant,
+			want: `from(bucket: "prometheus")
+  |> range(start: 2020-10-11T23:55:00.000000001Z, stop: 2020-10-12T00:00:00.000000001Z)
+  |> filter(fn: (r) => r._field == "value" and r._measurement == "up" and r["job"] == "node")
+  |> window(every: 5m, period: 5m, offset: 1ns)
+  |> last()
+  |> filter(fn: (r) => int(v: r._stop) - int(v: r._start) == 300000000000)
+  |> map(fn: (r) => ({r with _time: time(v: int(v: r._stop) - 1)}))
+  |> group(mode: "except", columns: ["_start", "_stop", "_time", "_value"])
+`,
+		},
+		{
+			name:   "range query with offset",
+			promql: `up offset 1m`,
+			opts:   ranged,
+			want: `from(bucket: "prometheus")
+  |> range(start: 2020-10-11T22:54:00.000000001Z, stop: 2020-10-11T23:59:00.000000001Z)
+  |> filter(fn: (r) => r._field == "value" and r._measurement == "up")
+  |> window(every: 1m, period: 5m, offset: 1ns)
+  |> last()
+  |> filter(fn: (r) => int(v: r._stop) - int(v: r._start) == 300000000000)
+  |> map(fn: (r) => ({r with _time: time(v: int(v: r._stop) + 59999999999)}))
+  |> group(mode: "except", columns: ["_start", "_stop", "_time", "_value"])
+`,
+		},
+		{
+			name:   "range vector selector",
+			promql: `http_requests_total[5m]`,
+			opts:   instant,
+			want: `from(bucket: "prometheus")
+  |> range(start: 2020-10-11T23:55:00.000000001Z, stop: 2020-10-12T00:00:00.000000001Z)
+  |> filter(fn: (r) => r._field == "value" and r._measurement == "http_requests_total")
+`,
+		},
+		{
+			name:    "range vector selector in range query",
+			promql:  `http_requests_total[5m]`,
+			opts:    ranged,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported aggregation",
+			promql:  `quantile(0.9, up)`,
+			opts:    instant,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Flux(tt.promql, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Flux() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Flux() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlux_Aggregations(t *testing.T) {
+	opts := FluxOptions{Bucket: "prometheus", End: time.Date(2020, 10, 12, 0, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		promql string
+		want   string
+	}{
+		{
+			promql: `sum(up) by (job)`,
+			want: `  |> group(columns: ["job", "_time"])
+  |> sum()
+  |> group(columns: ["job"])
+`,
+		},
+		{
+			promql: `avg(up) without (instance)`,
+			want: `  |> group(mode: "except", columns: ["instance", "_measurement", "_field", "_start", "_stop", "_value"])
+  |> mean()
+  |> group(mode: "except", columns: ["instance", "_start", "_stop", "_time", "_value", "_measurement", "_field"])
+`,
+		},
+		{
+			promql: `topk(3, up)`,
+			want: `  |> group(columns: ["_time"])
+  |> top(n: 3)
+  |> group(mode: "except", columns: ["_start", "_stop", "_time", "_value"])
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.promql, func(t *testing.T) {
+			got, err := Flux(tt.promql, opts)
+			if err != nil {
+				t.Fatalf("Flux() error = %v", err)
+			}
+			if !strings.HasSuffix(got, tt.want) {
+				t.Errorf("Flux() =\n%s\nwant suffix\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherPredicate(t *testing.T) {
+	tests := []struct {
+		matcher *LabelMatcher
+		want    string
+	}{
+		{
+			matcher: &LabelMatcher{Name: "__name__", Kind: Equal, Value: &StringLiteral{String: "up"}},
+			want:    `r["_measurement"] == "up"`,
+		},
+		{
+			matcher: &LabelMatcher{Name: "job", Kind: Equal, Value: &StringLiteral{String: ""}},
+			want:    `not exists r["job"]`,
+		},
+		{
+			matcher: &LabelMatcher{Name: "job", Kind: NotEqual, Value: &StringLiteral{String: "a"}},
+			want:    `(not exists r["job"] or r["job"] != "a")`,
+		},
+		{
+			matcher: &LabelMatcher{Name: "job", Kind: RegexMatch, Value: &StringLiteral{String: "a/.*"}},
+			want:    `(exists r["job"] and r["job"] =~ /^(?:a\/.*)$/)`,
+		},
+		{
+			matcher: &LabelMatcher{Name: "job", Kind: RegexMatch, Value: &StringLiteral{String: "a|"}},
+			want:    `(not exists r["job"] or r["job"] =~ /^(?:a|)$/)`,
+		},
+		{
+			matcher: &LabelMatcher{Name: "job", Kind: RegexNoMatch, Value: &StringLiteral{String: ""}},
+			want:    `(exists r["job"] and r["job"] !~ /^(?:)$/)`,
+		},
+		{
+			matcher: &LabelMatcher{Name: "path", Kind: Equal, Value: &StringLiteral{String: `"${x}"`}},
+			want:    `r["path"] == "\"\${x}\""`,
+		},
+	}
+	for _, tt := range tests {
+		if got := matcherPredicate(tt.matcher); got != tt.want {
+			t.Errorf("matcherPredicate(%v) = %s, want %s", tt.matcher, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package query
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// Tracker is implemented by the queries added to a Registry.
+type Tracker interface {
+	// Cancel interrupts the execution of the query.
+	Cancel()
+
+	// Progress reports the state of the query and the number
+	// of bytes of memory it has allocated.
+	Progress() (state string, memory int64)
+}
+
+// Registry tracks the Flux and InfluxQL queries that are running so that
+// they can be listed and cancelled with a single, process wide, set of IDs.
+type Registry struct {
+	mu      sync.RWMutex
+	lastID  uint64
+	queries map[uint64]*registryEntry
+
+	now func() time.Time
+}
+
+type registryEntry struct {
+	query   influxdb.RunningQuery
+	tracker Tracker
+}
+
+var _ influxdb.RunningQueryService = (*Registry)(nil)
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		queries: make(map[uint64]*registryEntry),
+		now:     time.Now,
+	}
+}
+
+// Register adds a query to the registry. The ID, start time, state,
+// duration and memory of the query are set by the registry.
+// The returned function removes the query from the registry and must
+// be called once the query has finished.
+func (r *Registry) Register(q influxdb.RunningQuery, t Tracker) (uint64, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	q.ID = r.lastID
+	q.StartTime = r.now().UTC()
+	r.queries[q.ID] = &registryEntry{query: q, tracker: t}
+
+	id := q.ID
+	return id, func() {
+		r.mu.Lock()
+		delete(r.queries, id)
+		r.mu.Unlock()
+	}
+}
+
+// FindRunningQueries returns the running queries that match the filter, ordered by ID.
+func (r *Registry) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := r.now()
+	queries := make([]*influxdb.RunningQuery, 0, len(r.queries))
+	for id, e := range r.queries {
+		if filter.ID != nil && *filter.ID != id {
+			continue
+		}
+		if filter.OrgID != nil && *filter.OrgID != e.query.OrgID {
+			continue
+		}
+
+		q := e.query
+		q.Duration = now.Sub(q.StartTime)
+		q.State, q.Memory = e.tracker.Progress()
+		queries = append(queries, &q)
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].ID < queries[j].ID
+	})
+	return queries, nil
+}
+
+// KillQuery cancels a running query. The query remains in the
+// registry until its execution has stopped.
+func (r *Registry) KillQuery(ctx context.Context, id uint64) error {
+	r.mu.RLock()
+	e, ok := r.queries[id]
+	r.mu.RUnlock()
+	if !ok {
+		return influxdb.ErrRunningQueryNotFound
+	}
+	e.tracker.Cancel()
+	return nil
+}
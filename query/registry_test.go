@@ -0,0 +1,79 @@
+package query_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/query"
+)
+
+type fakeTracker struct {
+	cancelled bool
+}
+
+func (t *fakeTracker) Cancel() { t.cancelled = true }
+
+func (t *fakeTracker) Progress() (string, int64) { return "executing", 1024 }
+
+func TestRegistry(t *testing.T) {
+	otherOrgID := MustIDBase16("aaaaaaaaaaaaaaaa")
+
+	r := query.NewRegistry()
+	fluxTracker := &fakeTracker{}
+	id1, unregister1 := r.Register(platform.RunningQuery{
+		OrgID:    orgID,
+		Language: platform.FluxQueryLanguage,
+		Query:    `from(bucket: "b")`,
+	}, fluxTracker)
+	id2, unregister2 := r.Register(platform.RunningQuery{
+		OrgID:    otherOrgID,
+		Language: platform.InfluxQLQueryLanguage,
+		Query:    `SELECT * FROM m`,
+		Database: "db",
+	}, &fakeTracker{})
+	defer unregister2()
+
+	if id1 == id2 {
+		t.Fatalf("expected unique query ids, got %d twice", id1)
+	}
+
+	queries, err := r.FindRunningQueries(context.Background(), platform.RunningQueryFilter{OrgID: &orgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*platform.RunningQuery{{
+		ID:       id1,
+		OrgID:    orgID,
+		Language: platform.FluxQueryLanguage,
+		Query:    `from(bucket: "b")`,
+		State:    "executing",
+		Memory:   1024,
+	}}
+	opts := cmpopts.IgnoreFields(platform.RunningQuery{}, "StartTime", "Duration")
+	if diff := cmp.Diff(want, queries, opts); diff != "" {
+		t.Fatalf("unexpected queries -want/+got:\n%s", diff)
+	}
+
+	queries, err = r.FindRunningQueries(context.Background(), platform.RunningQueryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(queries), 2; got != want {
+		t.Fatalf("unexpected number of queries: got %d, want %d", got, want)
+	}
+
+	if err := r.KillQuery(context.Background(), id1); err != nil {
+		t.Fatal(err)
+	}
+	if !fluxTracker.cancelled {
+		t.Fatal("expected query to be cancelled")
+	}
+
+	unregister1()
+	if err := r.KillQuery(context.Background(), id1); err != platform.ErrRunningQueryNotFound {
+		t.Fatalf("unexpected error killing unregistered query: %v", err)
+	}
+}
@@ -2,8 +2,21 @@ package influxdb
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/pkg/jsonpath"
+	"golang.org/x/net/http/httpguts"
 )
 
+// MinScraperTargetInterval is the shortest interval between the scrapes of a target.
+const MinScraperTargetInterval = time.Second
+
 // ErrScraperTargetNotFound is the error msg for a missing scraper target.
 const ErrScraperTargetNotFound = "scraper target not found"
 
@@ -24,6 +37,246 @@ type ScraperTarget struct {
 	URL      string      `json:"url"`
 	OrgID    ID          `json:"orgID,omitempty"`
 	BucketID ID          `json:"bucketID,omitempty"`
+
+	// JSON maps the responses of a json target to metrics.
+	JSON *ScraperJSONConfig `json:"json,omitempty"`
+	// Auth authenticates the requests to the target.
+	Auth *ScraperAuth `json:"auth,omitempty"`
+	// TLS configures the connections to an https target.
+	TLS *ScraperTLSConfig `json:"tls,omitempty"`
+	// Headers are added to the requests to the target. Values are the keys
+	// of secrets of the organization of the target.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Filter selects the metrics written by the scrapes of the target.
+	Filter *ScraperFilter `json:"filter,omitempty"`
+
+	// Interval between the scrapes of the target, defaulting to the interval
+	// of the scraper scheduler.
+	Interval *Duration `json:"interval,omitempty"`
+	// Timeout of a scrape of the target, defaulting to the timeout of the
+	// scraper scheduler.
+	Timeout *Duration `json:"timeout,omitempty"`
+
+	// Health of the scrapes of the target. It is recorded by the scrapes and
+	// ignored by the creation and update of the target.
+	Health *ScraperTargetHealth `json:"health,omitempty"`
+}
+
+// ScraperFilter selects the metrics of a scrape. Metrics are written when they
+// match an allowed metric, or when nothing is allowed, and no denied metric.
+type ScraperFilter struct {
+	Allow []ScraperMetricMatch `json:"allow,omitempty"`
+	Deny  []ScraperMetricMatch `json:"deny,omitempty"`
+}
+
+// ScraperMetricMatch matches the metrics named Name, or only those with
+// exactly Labels for tags when Labels are given.
+type ScraperMetricMatch struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// ScraperTargetHealth is the health of the scrapes of a target.
+type ScraperTargetHealth struct {
+	// LastScrape is when the last scrape started.
+	LastScrape time.Time `json:"lastScrape"`
+	// LastSuccess is when the last successful scrape started.
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	// LastError is the error of the last scrape, empty when it succeeded.
+	LastError    string   `json:"lastError,omitempty"`
+	LastDuration Duration `json:"lastDuration"`
+}
+
+// ScraperJSONConfig maps the JSON document returned by a target to metrics
+// using JSONPaths. The paths of tags, fields and timestamp are relative to
+// each object selected by Path, which they start with $ or @ to refer to.
+type ScraperJSONConfig struct {
+	// Path selects the objects, or arrays of objects, of the document that
+	// are each read as a metric. Defaults to the document itself.
+	Path        string            `json:"path,omitempty"`
+	Measurement string            `json:"measurement"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Fields      map[string]string `json:"fields"`
+	// Timestamp selects the time of the metric, as RFC3339 or a number of
+	// seconds since the epoch. Defaults to the time of the scrape.
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// ScraperAuth authenticates the requests to a scraper target with either a
+// bearer token or basic auth. Credentials are the keys of secrets of the
+// organization of the target.
+type ScraperAuth struct {
+	BearerTokenSecret string `json:"bearerTokenSecret,omitempty"`
+	Username          string `json:"username,omitempty"`
+	PasswordSecret    string `json:"passwordSecret,omitempty"`
+}
+
+// ScraperTLSConfig configures the TLS connections to a scraper target.
+type ScraperTLSConfig struct {
+	// CACert is the PEM encoded certificate of the authority that signed the
+	// certificate of the target, in place of the system authorities.
+	CACert             string `json:"caCert,omitempty"`
+	ServerName         string `json:"serverName,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+	// ClientCert is the PEM encoded certificate presented to the target,
+	// and ClientKeySecret the key of the secret holding its PEM encoded key.
+	ClientCert      string `json:"clientCert,omitempty"`
+	ClientKeySecret string `json:"clientKeySecret,omitempty"`
+}
+
+// Validate returns an error if the target is invalid for its type.
+func (t *ScraperTarget) Validate() error {
+	if t.Type != "" && !ValidScraperType(string(t.Type)) {
+		return invalidScraperTarget("unsupported scraper target type %q", t.Type)
+	}
+
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return invalidScraperTarget("invalid scraper target URL: %v", err)
+	}
+	// prometheus targets predate the URL validation
+	if t.Type != "" && t.Type != PrometheusScraperType {
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return invalidScraperTarget("scraper target URL must be an absolute http or https URL")
+		}
+	}
+
+	if t.Type == JSONScraperType {
+		if t.JSON == nil {
+			return invalidScraperTarget("json scraper target requires a json config")
+		}
+		if err := t.JSON.validate(); err != nil {
+			return err
+		}
+	} else if t.JSON != nil {
+		return invalidScraperTarget("json config is only valid for json scraper targets")
+	}
+
+	if a := t.Auth; a != nil {
+		if a.BearerTokenSecret != "" && (a.Username != "" || a.PasswordSecret != "") {
+			return invalidScraperTarget("scraper target auth must use either a bearer token or basic auth")
+		}
+		if a.PasswordSecret != "" && a.Username == "" {
+			return invalidScraperTarget("scraper target basic auth requires a username")
+		}
+	}
+
+	if t.TLS != nil {
+		if u.Scheme == "http" {
+			return invalidScraperTarget("tls config requires an https scraper target URL")
+		}
+		if err := t.TLS.validate(); err != nil {
+			return err
+		}
+	}
+
+	for name := range t.Headers {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return invalidScraperTarget("invalid scraper target header %q", name)
+		}
+		if t.Auth != nil && strings.EqualFold(name, "Authorization") {
+			return invalidScraperTarget("scraper target auth and Authorization header are exclusive")
+		}
+	}
+
+	if t.Filter != nil {
+		for _, m := range append(t.Filter.Allow, t.Filter.Deny...) {
+			if m.Name == "" {
+				return invalidScraperTarget("scraper target filter requires metric names")
+			}
+		}
+	}
+
+	if t.Interval != nil && t.Interval.Duration < MinScraperTargetInterval {
+		return invalidScraperTarget("scraper target interval must be at least %s", MinScraperTargetInterval)
+	}
+	if t.Timeout != nil {
+		if t.Timeout.Duration <= 0 {
+			return invalidScraperTarget("scraper target timeout must be positive")
+		}
+		if t.Interval != nil && t.Timeout.Duration > t.Interval.Duration {
+			return invalidScraperTarget("scraper target timeout must not exceed its interval")
+		}
+	}
+
+	return nil
+}
+
+// SecretKeys returns the keys of the secrets of its organization that the
+// target sends with its scrapes: its credentials, the values of its headers
+// and the key of its client certificate.
+func (t *ScraperTarget) SecretKeys() []string {
+	var keys []string
+	if a := t.Auth; a != nil {
+		if a.BearerTokenSecret != "" {
+			keys = append(keys, a.BearerTokenSecret)
+		}
+		if a.PasswordSecret != "" {
+			keys = append(keys, a.PasswordSecret)
+		}
+	}
+	headers := make([]string, 0, len(t.Headers))
+	for _, key := range t.Headers {
+		headers = append(headers, key)
+	}
+	sort.Strings(headers)
+	keys = append(keys, headers...)
+	if t.TLS != nil && t.TLS.ClientKeySecret != "" {
+		keys = append(keys, t.TLS.ClientKeySecret)
+	}
+	return keys
+}
+
+func (c *ScraperTLSConfig) validate() error {
+	if c.CACert != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(c.CACert)) {
+		return invalidScraperTarget("tls caCert must hold a PEM encoded certificate")
+	}
+	if (c.ClientCert == "") != (c.ClientKeySecret == "") {
+		return invalidScraperTarget("tls clientCert and clientKeySecret are required together")
+	}
+	if c.ClientCert != "" {
+		block, _ := pem.Decode([]byte(c.ClientCert))
+		if block == nil || block.Type != "CERTIFICATE" {
+			return invalidScraperTarget("tls clientCert must hold a PEM encoded certificate")
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return invalidScraperTarget("tls clientCert must hold a PEM encoded certificate")
+		}
+	}
+	return nil
+}
+
+func (c *ScraperJSONConfig) validate() error {
+	if c.Measurement == "" {
+		return invalidScraperTarget("json config requires a measurement")
+	}
+	if len(c.Fields) == 0 {
+		return invalidScraperTarget("json config requires at least one field")
+	}
+
+	paths := []string{c.Timestamp, c.Path}
+	for _, p := range c.Tags {
+		paths = append(paths, p)
+	}
+	for _, p := range c.Fields {
+		paths = append(paths, p)
+	}
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		if _, err := jsonpath.Compile(p); err != nil {
+			return invalidScraperTarget("json config: %v", err)
+		}
+	}
+	return nil
+}
+
+func invalidScraperTarget(format string, args ...interface{}) error {
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf(format, args...),
+	}
 }
 
 // ScraperTargetStoreService defines the crud service for ScraperTarget.
@@ -35,6 +288,13 @@ type ScraperTargetStoreService interface {
 	UpdateTarget(ctx context.Context, t *ScraperTarget, userID ID) (*ScraperTarget, error)
 }
 
+// ScraperTargetHealthService records the health of the scrapes of targets.
+type ScraperTargetHealthService interface {
+	// UpdateTargetsHealth records the health of the last scrapes of targets
+	// at once. A failed scrape keeps the time of the last successful one.
+	UpdateTargetsHealth(ctx context.Context, healths map[ID]ScraperTargetHealth) error
+}
+
 // ScraperTargetFilter represents a set of filter that restrict the returned results.
 type ScraperTargetFilter struct {
 	IDs   map[ID]bool `json:"ids"`
@@ -50,12 +310,18 @@ type ScraperType string
 const (
 	// PrometheusScraperType parses metrics from a prometheus endpoint.
 	PrometheusScraperType = "prometheus"
+	// OpenMetricsScraperType parses metrics and their exemplars from an OpenMetrics endpoint.
+	OpenMetricsScraperType = "openmetrics"
+	// JSONScraperType maps the JSON document of an HTTP endpoint to metrics.
+	JSONScraperType = "json"
+	// InfluxDBScraperType parses the /metrics endpoint of another InfluxDB instance.
+	InfluxDBScraperType = "influxdb"
 )
 
 // ValidScraperType returns true is the type string is valid
 func ValidScraperType(s string) bool {
 	switch s {
-	case PrometheusScraperType:
+	case PrometheusScraperType, OpenMetricsScraperType, JSONScraperType, InfluxDBScraperType:
 		return true
 	default:
 		return false
@@ -0,0 +1,173 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestScraperTargetValidate(t *testing.T) {
+	jsonConfig := &influxdb.ScraperJSONConfig{
+		Path:        "$.items[*]",
+		Measurement: "items",
+		Tags:        map[string]string{"name": "@.name"},
+		Fields:      map[string]string{"count": "@.count"},
+	}
+
+	tests := []struct {
+		name    string
+		target  influxdb.ScraperTarget
+		wantErr bool
+	}{
+		{
+			name:   "prometheus target",
+			target: influxdb.ScraperTarget{Type: influxdb.PrometheusScraperType, URL: "http://localhost:9100/metrics"},
+		},
+		{
+			name:   "openmetrics target with bearer token",
+			target: influxdb.ScraperTarget{Type: influxdb.OpenMetricsScraperType, URL: "https://exporter/metrics", Auth: &influxdb.ScraperAuth{BearerTokenSecret: "token"}},
+		},
+		{
+			name:   "json target",
+			target: influxdb.ScraperTarget{Type: influxdb.JSONScraperType, URL: "http://service/stats", JSON: jsonConfig},
+		},
+		{
+			name:   "influxdb target with tls",
+			target: influxdb.ScraperTarget{Type: influxdb.InfluxDBScraperType, URL: "https://influxdb:8086", TLS: &influxdb.ScraperTLSConfig{InsecureSkipVerify: true}},
+		},
+		{
+			name:    "unsupported type",
+			target:  influxdb.ScraperTarget{Type: "snmp", URL: "http://localhost"},
+			wantErr: true,
+		},
+		{
+			name:    "relative URL",
+			target:  influxdb.ScraperTarget{Type: influxdb.OpenMetricsScraperType, URL: "exporter/metrics"},
+			wantErr: true,
+		},
+		{
+			name:    "json target without config",
+			target:  influxdb.ScraperTarget{Type: influxdb.JSONScraperType, URL: "http://service/stats"},
+			wantErr: true,
+		},
+		{
+			name:    "json config on prometheus target",
+			target:  influxdb.ScraperTarget{Type: influxdb.PrometheusScraperType, URL: "http://localhost:9100/metrics", JSON: jsonConfig},
+			wantErr: true,
+		},
+		{
+			name: "json config with invalid path",
+			target: influxdb.ScraperTarget{Type: influxdb.JSONScraperType, URL: "http://service/stats", JSON: &influxdb.ScraperJSONConfig{
+				Measurement: "items",
+				Fields:      map[string]string{"count": "count"},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "json config without fields",
+			target:  influxdb.ScraperTarget{Type: influxdb.JSONScraperType, URL: "http://service/stats", JSON: &influxdb.ScraperJSONConfig{Measurement: "items"}},
+			wantErr: true,
+		},
+		{
+			name:    "bearer token and basic auth",
+			target:  influxdb.ScraperTarget{Type: influxdb.PrometheusScraperType, URL: "http://localhost", Auth: &influxdb.ScraperAuth{BearerTokenSecret: "token", Username: "me"}},
+			wantErr: true,
+		},
+		{
+			name:    "password without username",
+			target:  influxdb.ScraperTarget{Type: influxdb.PrometheusScraperType, URL: "http://localhost", Auth: &influxdb.ScraperAuth{PasswordSecret: "password"}},
+			wantErr: true,
+		},
+		{
+			name:    "tls config on http target",
+			target:  influxdb.ScraperTarget{Type: influxdb.PrometheusScraperType, URL: "http://localhost", TLS: &influxdb.ScraperTLSConfig{ServerName: "localhost"}},
+			wantErr: true,
+		},
+		{
+			name: "headers, filter, interval and timeout",
+			target: influxdb.ScraperTarget{
+				Type:     influxdb.PrometheusScraperType,
+				URL:      "http://localhost",
+				Headers:  map[string]string{"X-Api-Key": "api-key"},
+				Filter:   &influxdb.ScraperFilter{Deny: []influxdb.ScraperMetricMatch{{Name: "go_info"}}},
+				Interval: &influxdb.Duration{Duration: time.Minute},
+				Timeout:  &influxdb.Duration{Duration: 10 * time.Second},
+			},
+		},
+		{
+			name:    "invalid header",
+			target:  influxdb.ScraperTarget{Type: influxdb.PrometheusScraperType, URL: "http://localhost", Headers: map[string]string{"X Api Key": "api-key"}},
+			wantErr: true,
+		},
+		{
+			name: "authorization header and auth",
+			target: influxdb.ScraperTarget{
+				Type:    influxdb.PrometheusScraperType,
+				URL:     "http://localhost",
+				Auth:    &influxdb.ScraperAuth{BearerTokenSecret: "token"},
+				Headers: map[string]string{"authorization": "token"},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "filter without metric name",
+			target:  influxdb.ScraperTarget{Type: influxdb.PrometheusScraperType, URL: "http://localhost", Filter: &influxdb.ScraperFilter{Allow: []influxdb.ScraperMetricMatch{{}}}},
+			wantErr: true,
+		},
+		{
+			name:    "interval too short",
+			target:  influxdb.ScraperTarget{Type: influxdb.PrometheusScraperType, URL: "http://localhost", Interval: &influxdb.Duration{Duration: time.Millisecond}},
+			wantErr: true,
+		},
+		{
+			name: "timeout longer than interval",
+			target: influxdb.ScraperTarget{
+				Type:     influxdb.PrometheusScraperType,
+				URL:      "http://localhost",
+				Interval: &influxdb.Duration{Duration: time.Second},
+				Timeout:  &influxdb.Duration{Duration: time.Minute},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "client key without certificate",
+			target:  influxdb.ScraperTarget{Type: influxdb.PrometheusScraperType, URL: "https://localhost", TLS: &influxdb.ScraperTLSConfig{ClientKeySecret: "key"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid client certificate",
+			target:  influxdb.ScraperTarget{Type: influxdb.PrometheusScraperType, URL: "https://localhost", TLS: &influxdb.ScraperTLSConfig{ClientCert: "not a certificate", ClientKeySecret: "key"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid ca certificate",
+			target:  influxdb.ScraperTarget{Type: influxdb.PrometheusScraperType, URL: "https://localhost", TLS: &influxdb.ScraperTLSConfig{CACert: "not a certificate"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.target.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("ScraperTarget.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScraperTarget_SecretKeys(t *testing.T) {
+	target := influxdb.ScraperTarget{
+		Auth:    &influxdb.ScraperAuth{Username: "me", PasswordSecret: "password"},
+		Headers: map[string]string{"X-Tenant": "tenant", "X-Api-Key": "api-key"},
+		TLS:     &influxdb.ScraperTLSConfig{ClientKeySecret: "client-key"},
+	}
+	want := []string{"password", "api-key", "tenant", "client-key"}
+	if diff := cmp.Diff(want, target.SecretKeys()); diff != "" {
+		t.Errorf("unexpected secret keys -want/+got:\n%s", diff)
+	}
+
+	if keys := (&influxdb.ScraperTarget{TLS: &influxdb.ScraperTLSConfig{InsecureSkipVerify: true}}).SecretKeys(); len(keys) != 0 {
+		t.Errorf("got secret keys %v, want none", keys)
+	}
+}
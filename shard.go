@@ -0,0 +1,30 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// Shard is a block of the time series data of a bucket, covering the time
+// range of the shard group it belongs to.
+type Shard struct {
+	ID           uint64    `json:"id"`
+	BucketID     ID        `json:"bucketID"`
+	ShardGroupID uint64    `json:"shardGroupID"`
+	StartTime    time.Time `json:"startTime"`
+	EndTime      time.Time `json:"endTime"`
+	// ExpiryTime is the time after which the shard is removed by retention
+	// enforcement. It is the zero time if the bucket retains data forever.
+	ExpiryTime time.Time `json:"expiryTime"`
+	// Size is the size of the shard on disk in bytes.
+	Size int64 `json:"size"`
+}
+
+// ShardService represents a service for inspecting and removing the shards of buckets.
+type ShardService interface {
+	// FindShards returns the shards of a bucket ordered by start time.
+	FindShards(ctx context.Context, bucketID ID) ([]*Shard, error)
+
+	// DeleteShard removes a shard and all of the data it contains.
+	DeleteShard(ctx context.Context, id uint64) error
+}
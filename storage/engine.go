@@ -4,14 +4,21 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/influxql/query"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator"
+	intar "github.com/influxdata/influxdb/v2/pkg/tar"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	_ "github.com/influxdata/influxdb/v2/tsdb/engine"
 	_ "github.com/influxdata/influxdb/v2/tsdb/index/inmem"
@@ -56,6 +63,9 @@ type Engine struct {
 
 	writePointsValidationEnabled bool
 
+	// lastBackupID is the ID of the most recent backup created by the engine.
+	lastBackupID int64
+
 	logger *zap.Logger
 }
 
@@ -74,6 +84,7 @@ type MetaClient interface {
 	Database(name string) (di *meta.DatabaseInfo)
 	Databases() []meta.DatabaseInfo
 	DeleteShardGroup(database, policy string, id uint64) error
+	DropShard(id uint64) error
 	PrecreateShardGroups(now, cutoff time.Time) error
 	PruneShardGroups() error
 	RetentionPolicy(database, policy string) (*meta.RetentionPolicyInfo, error)
@@ -85,6 +96,8 @@ type TSDBStore interface {
 	DeleteMeasurement(database, name string) error
 	DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error
 	MeasurementNames(auth query.Authorizer, database string, cond influxql.Expr) ([][]byte, error)
+	MeasurementsSketches(database string) (estimator.Sketch, estimator.Sketch, error)
+	SeriesCardinality(database string) (int64, error)
 	ShardGroup(ids []uint64) tsdb.ShardGroup
 	Shards(ids []uint64) []*tsdb.Shard
 	TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error)
@@ -303,31 +316,211 @@ func (e *Engine) DeleteBucketRange(ctx context.Context, orgID, bucketID influxdb
 // DeleteBucketRangePredicate deletes data within a bucket from the storage engine. Any data
 // deleted must be in [min, max], and the key must match the predicate if provided.
 func (e *Engine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
-	return ErrNotImplemented
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	return e.tsdbStore.DeleteSeriesWithPredicate(bucketID.String(), min, max, pred)
+}
+
+// FindShards returns the shards of a bucket ordered by start time.
+func (e *Engine) FindShards(ctx context.Context, bucketID influxdb.ID) ([]*influxdb.Shard, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	db := e.metaClient.Database(bucketID.String())
+	if db == nil {
+		return nil, nil
+	}
+
+	var shards []*influxdb.Shard
+	for _, rpi := range db.RetentionPolicies {
+		for _, sgi := range rpi.ShardGroups {
+			if sgi.Deleted() {
+				continue
+			}
+			for _, si := range sgi.Shards {
+				shard := &influxdb.Shard{
+					ID:           si.ID,
+					BucketID:     bucketID,
+					ShardGroupID: sgi.ID,
+					StartTime:    sgi.StartTime.UTC(),
+					EndTime:      sgi.EndTime.UTC(),
+				}
+				if rpi.Duration != 0 {
+					shard.ExpiryTime = sgi.EndTime.Add(rpi.Duration).UTC()
+				}
+				// A shard that has not been opened on this node has no data on disk.
+				if sh := e.tsdbStore.Shard(si.ID); sh != nil {
+					size, err := sh.DiskSize()
+					if err != nil {
+						return nil, err
+					}
+					shard.Size = size
+				}
+				shards = append(shards, shard)
+			}
+		}
+	}
+	sort.SliceStable(shards, func(i, j int) bool {
+		return shards[i].StartTime.Before(shards[j].StartTime)
+	})
+	return shards, nil
 }
 
-// CreateBackup creates a "snapshot" of all TSM data in the Engine.
-//   1) Snapshot the cache to ensure the backup includes all data written before now.
-//   2) Create hard links to all TSM files, in a new directory within the engine root directory.
+// DeleteShard removes a shard from the meta data and deletes its data from disk.
+func (e *Engine) DeleteShard(ctx context.Context, id uint64) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	if err := e.metaClient.DropShard(id); err != nil {
+		return err
+	}
+	return e.tsdbStore.DeleteShard(id)
+}
+
+// CreateBackup creates a "snapshot" of the TSM data of the buckets matching filter.
+//   1) Snapshot the cache of each shard to ensure the backup includes all data written before now.
+//   2) Archive the TSM files of each shard, in a new directory within the engine root directory.
+//      Shards outside the time range of the filter, or not modified since filter.Since, are skipped.
 //   3) Return a unique backup ID (invalid after the process terminates) and list of files.
-//
-// TODO - do we need this?
-//
-func (e *Engine) CreateBackup(ctx context.Context) (int, []string, error) {
+func (e *Engine) CreateBackup(ctx context.Context, filter influxdb.BackupFilter) (int, []string, error) {
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	if e.closing == nil {
 		return 0, nil, ErrEngineClosed
 	}
 
-	return 0, nil, nil
+	bucketIDs := filter.BucketIDs
+	if !filter.Selective() {
+		for _, db := range e.metaClient.Databases() {
+			bucketID, err := influxdb.IDFromString(db.Name)
+			if err != nil {
+				// Only databases named after a bucket hold bucket data.
+				continue
+			}
+			bucketIDs = append(bucketIDs, *bucketID)
+		}
+	}
+
+	backupID := int(atomic.AddInt64(&e.lastBackupID, 1))
+	path := e.internalBackupPath(backupID)
+	if err := os.RemoveAll(path); err != nil {
+		return 0, nil, err
+	}
+	if err := os.MkdirAll(path, 0777); err != nil {
+		return 0, nil, err
+	}
+
+	var files []string
+	for _, bucketID := range bucketIDs {
+		db := e.metaClient.Database(bucketID.String())
+		if db == nil {
+			continue
+		}
+		for _, rpi := range db.RetentionPolicies {
+			for _, sgi := range rpi.ShardGroups {
+				if sgi.Deleted() || !filter.Overlaps(sgi.StartTime, sgi.EndTime) {
+					continue
+				}
+				for _, si := range sgi.Shards {
+					// A shard that has not been opened on this node has no data on disk.
+					sh := e.tsdbStore.Shard(si.ID)
+					if sh == nil {
+						continue
+					}
+					if filter.Incremental() && !sh.LastModified().After(filter.Since) {
+						continue
+					}
+					name := influxdb.ShardBackupFilename(bucketID, si.ID)
+					if err := e.backupShard(si.ID, filter.Since, filepath.Join(path, name)); err != nil {
+						return 0, nil, multierr.Append(err, os.RemoveAll(path))
+					}
+					files = append(files, name)
+				}
+			}
+		}
+	}
+
+	return backupID, files, nil
+}
+
+// backupShard archives the files of a shard changed after since to path.
+func (e *Engine) backupShard(id uint64, since time.Time, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0660)
+	if err != nil {
+		return err
+	}
+	if err := e.tsdbStore.BackupShard(id, since, f); err != nil {
+		return multierr.Append(err, f.Close())
+	}
+	return f.Close()
 }
 
 // FetchBackupFile writes a given backup file to the provided writer.
 // After a successful write, the internal copy is removed.
 func (e *Engine) FetchBackupFile(ctx context.Context, backupID int, backupFile string, w io.Writer) error {
-	// TODO - need?
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if filepath.Base(backupFile) != backupFile {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid backup file %q", backupFile),
+		}
+	}
+
+	path := e.InternalBackupPath(backupID)
+	if path == "" {
+		return ErrEngineClosed
+	}
+
+	f, err := os.Open(filepath.Join(path, backupFile))
+	if os.IsNotExist(err) {
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  fmt.Sprintf("backup file %q not found", backupFile),
+		}
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(w, f); err != nil {
+		return err
+	}
+
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	// The backup directory is removed along with its last file.
+	if files, err := ioutil.ReadDir(path); err == nil && len(files) == 0 {
+		return os.Remove(path)
+	}
 	return nil
 }
 
@@ -339,8 +532,60 @@ func (e *Engine) InternalBackupPath(backupID int) string {
 	if e.closing == nil {
 		return ""
 	}
-	// TODO - need?
-	return ""
+	return e.internalBackupPath(backupID)
+}
+
+func (e *Engine) internalBackupPath(backupID int) string {
+	return filepath.Join(e.path, "backup", strconv.Itoa(backupID))
+}
+
+// RestoreShard imports a shard archive created by CreateBackup into the shard of
+// the bucket covering start. The shard group is created if it does not exist, so
+// the archive may come from another bucket or another instance.
+func (e *Engine) RestoreShard(ctx context.Context, bucketID influxdb.ID, start time.Time, r io.Reader) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	db := bucketID.String()
+	if e.metaClient.Database(db) == nil {
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  fmt.Sprintf("bucket %q not found", db),
+		}
+	}
+
+	sgi, err := e.metaClient.CreateShardGroup(db, meta.DefaultRetentionPolicyName, start)
+	if err != nil {
+		return err
+	}
+	if len(sgi.Shards) == 0 {
+		return fmt.Errorf("shard group %d has no shards", sgi.ID)
+	}
+	shardID := sgi.Shards[0].ID
+
+	if err := e.tsdbStore.CreateShard(db, meta.DefaultRetentionPolicyName, shardID, true); err != nil {
+		return err
+	}
+	path, err := e.tsdbStore.ShardRelativePath(shardID)
+	if err != nil {
+		return err
+	}
+
+	// The archive names its files after the shard it was taken from.
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(intar.Rebase(pw, r, path))
+	}()
+	defer pr.Close()
+
+	return e.tsdbStore.ImportShard(shardID, pr)
 }
 
 // SeriesCardinality returns the number of series in the engine.
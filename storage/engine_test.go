@@ -0,0 +1,146 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/v1/services/meta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestEngine_BackupRestoreShard(t *testing.T) {
+	ctx := context.Background()
+	engine, closeFn := newTestEngine(t)
+	defer closeFn()
+
+	orgID := influxdb.ID(1)
+	src := &influxdb.Bucket{ID: 2, OrgID: orgID}
+	dst := &influxdb.Bucket{ID: 3, OrgID: orgID}
+	other := &influxdb.Bucket{ID: 4, OrgID: orgID}
+	for _, b := range []*influxdb.Bucket{src, dst, other} {
+		require.NoError(t, engine.CreateBucket(ctx, b))
+	}
+
+	points, err := models.ParsePointsString("cpu,host=a value=1 1000000000\ncpu,host=b value=2 2000000000")
+	require.NoError(t, err)
+	require.NoError(t, engine.WritePoints(ctx, orgID, src.ID, points))
+	require.NoError(t, engine.WritePoints(ctx, orgID, other.ID, points))
+
+	shards, err := engine.FindShards(ctx, src.ID)
+	require.NoError(t, err)
+	require.Len(t, shards, 1)
+
+	id, files, err := engine.CreateBackup(ctx, influxdb.BackupFilter{BucketIDs: []influxdb.ID{src.ID}})
+	require.NoError(t, err)
+	require.Equal(t, []string{influxdb.ShardBackupFilename(src.ID, shards[0].ID)}, files)
+
+	var buf bytes.Buffer
+	require.NoError(t, engine.FetchBackupFile(ctx, id, files[0], &buf))
+	_, err = os.Stat(filepath.Join(engine.InternalBackupPath(id), files[0]))
+	assert.True(t, os.IsNotExist(err), "fetched backup file should be removed")
+
+	err = engine.FetchBackupFile(ctx, id, "../"+files[0], ioutil.Discard)
+	assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+
+	require.NoError(t, engine.RestoreShard(ctx, dst.ID, shards[0].StartTime, &buf))
+
+	restored, err := engine.FindShards(ctx, dst.ID)
+	require.NoError(t, err)
+	require.Len(t, restored, 1)
+	assert.Equal(t, shards[0].StartTime, restored[0].StartTime)
+	assert.Equal(t, int64(2), engine.SeriesCardinality(orgID, dst.ID))
+
+	err = engine.RestoreShard(ctx, influxdb.ID(5), shards[0].StartTime, &buf)
+	assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+}
+
+func TestEngine_CreateBackup_AllBuckets(t *testing.T) {
+	ctx := context.Background()
+	engine, closeFn := newTestEngine(t)
+	defer closeFn()
+
+	orgID := influxdb.ID(1)
+	var want []string
+	for _, bucketID := range []influxdb.ID{2, 3} {
+		require.NoError(t, engine.CreateBucket(ctx, &influxdb.Bucket{ID: bucketID, OrgID: orgID}))
+
+		points, err := models.ParsePointsString("cpu value=1 1000000000")
+		require.NoError(t, err)
+		require.NoError(t, engine.WritePoints(ctx, orgID, bucketID, points))
+
+		shards, err := engine.FindShards(ctx, bucketID)
+		require.NoError(t, err)
+		require.Len(t, shards, 1)
+		want = append(want, influxdb.ShardBackupFilename(bucketID, shards[0].ID))
+	}
+
+	_, files, err := engine.CreateBackup(ctx, influxdb.BackupFilter{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, want, files)
+}
+
+func TestEngine_CreateBackup_Filter(t *testing.T) {
+	ctx := context.Background()
+	engine, closeFn := newTestEngine(t)
+	defer closeFn()
+
+	orgID, bucketID := influxdb.ID(1), influxdb.ID(2)
+	require.NoError(t, engine.CreateBucket(ctx, &influxdb.Bucket{ID: bucketID, OrgID: orgID}))
+
+	// The default shard group duration of an infinite bucket is a week.
+	first := time.Date(2020, 10, 5, 0, 0, 0, 0, time.UTC)
+	second := first.Add(7 * 24 * time.Hour)
+	points, err := models.ParsePointsString(fmt.Sprintf("cpu value=1 %d\ncpu value=2 %d", first.UnixNano(), second.UnixNano()))
+	require.NoError(t, err)
+	require.NoError(t, engine.WritePoints(ctx, orgID, bucketID, points))
+
+	shards, err := engine.FindShards(ctx, bucketID)
+	require.NoError(t, err)
+	require.Len(t, shards, 2)
+
+	_, files, err := engine.CreateBackup(ctx, influxdb.BackupFilter{Start: second, End: second.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	_, files, err = engine.CreateBackup(ctx, influxdb.BackupFilter{Since: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	_, files, err = engine.CreateBackup(ctx, influxdb.BackupFilter{Since: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+}
+
+func newTestEngine(t *testing.T) (*storage.Engine, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "storage-engine")
+	require.NoError(t, err)
+
+	store := inmem.NewKVStore()
+	require.NoError(t, all.Up(context.Background(), zaptest.NewLogger(t), store))
+
+	metaClient := meta.NewClient(meta.NewConfig(), store)
+	require.NoError(t, metaClient.Open())
+
+	engine := storage.NewEngine(dir, storage.NewConfig(), storage.WithMetaClient(metaClient))
+	require.NoError(t, engine.Open(context.Background()))
+
+	return engine, func() {
+		engine.Close()
+		os.RemoveAll(dir)
+	}
+}
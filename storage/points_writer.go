@@ -67,6 +67,31 @@ func (w *LoggingPointsWriter) WritePoints(ctx context.Context, orgID influxdb.ID
 	return err
 }
 
+// PointsNotifier is notified of the points written to a bucket.
+type PointsNotifier interface {
+	PointsWritten(ctx context.Context, orgID influxdb.ID, bucketID influxdb.ID, points []models.Point)
+}
+
+// NotifyingPointsWriter wraps an underlying points writer and notifies of the
+// points it writes successfully.
+type NotifyingPointsWriter struct {
+	// Wrapped points writer.
+	Underlying PointsWriter
+
+	// Notifier of the points written.
+	Notifier PointsNotifier
+}
+
+// WritePoints writes points to the underlying PointsWriter and notifies of them on success.
+func (w *NotifyingPointsWriter) WritePoints(ctx context.Context, orgID influxdb.ID, bucketID influxdb.ID, p []models.Point) error {
+	if err := w.Underlying.WritePoints(ctx, orgID, bucketID, p); err != nil {
+		return err
+	}
+
+	w.Notifier.PointsWritten(ctx, orgID, bucketID, p)
+	return nil
+}
+
 type BufferedPointsWriter struct {
 	buf      []models.Point
 	orgID    influxdb.ID
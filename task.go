@@ -27,6 +27,9 @@ const (
 var (
 	// TaskSystemType is the type set in tasks' for all crud requests
 	TaskSystemType = "system"
+
+	// TaskContinuousQueryType is the type set in tasks created from InfluxQL continuous queries
+	TaskContinuousQueryType = "continuous_query"
 )
 
 // Task is a task. 🎊
@@ -52,6 +55,14 @@ type Task struct {
 	CreatedAt       time.Time              `json:"createdAt,omitempty"`
 	UpdatedAt       time.Time              `json:"updatedAt,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+
+	// DependsOn lists the upstream tasks whose runs must succeed before
+	// the run of this task scheduled for the same time executes.
+	DependsOn []ID `json:"dependsOn,omitempty"`
+
+	// Trigger describes the writes that run the task, set from the trigger
+	// option of its script.
+	Trigger *options.Trigger `json:"trigger,omitempty"`
 }
 
 // EffectiveCron returns the effective cron string of the options.
@@ -142,6 +153,7 @@ type TaskCreate struct {
 	Organization   string                 `json:"org,omitempty"`
 	OwnerID        ID                     `json:"-"`
 	Metadata       map[string]interface{} `json:"-"` // not to be set through a web request but rather used by a http service using tasks backend.
+	DependsOn      []ID                   `json:"dependsOn,omitempty"`
 }
 
 func (t TaskCreate) Validate() error {
@@ -162,6 +174,9 @@ type TaskUpdate struct {
 	Status      *string `json:"status,omitempty"`
 	Description *string `json:"description,omitempty"`
 
+	// DependsOn replaces the upstream tasks of the task when set.
+	DependsOn *[]ID `json:"dependsOn,omitempty"`
+
 	// LatestCompleted us to set latest completed on startup to skip task catchup
 	LatestCompleted *time.Time             `json:"-"`
 	LatestScheduled *time.Time             `json:"-"`
@@ -197,6 +212,8 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 		Concurrency *int64 `json:"concurrency,omitempty"`
 
 		Retry *int64 `json:"retry,omitempty"`
+
+		DependsOn *[]ID `json:"dependsOn,omitempty"`
 	}{}
 
 	if err := json.Unmarshal(data, &jo); err != nil {
@@ -214,6 +231,7 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 	t.Options.Retry = jo.Retry
 	t.Flux = jo.Flux
 	t.Status = jo.Status
+	t.DependsOn = jo.DependsOn
 	return nil
 }
 
@@ -236,6 +254,8 @@ func (t *TaskUpdate) MarshalJSON() ([]byte, error) {
 		Concurrency *int64 `json:"concurrency,omitempty"`
 
 		Retry *int64 `json:"retry,omitempty"`
+
+		DependsOn *[]ID `json:"dependsOn,omitempty"`
 	}{}
 	jo.Name = t.Options.Name
 	jo.Cron = t.Options.Cron
@@ -249,6 +269,7 @@ func (t *TaskUpdate) MarshalJSON() ([]byte, error) {
 	jo.Retry = t.Options.Retry
 	jo.Flux = t.Flux
 	jo.Status = t.Status
+	jo.DependsOn = t.DependsOn
 	return json.Marshal(jo)
 }
 
@@ -264,7 +285,7 @@ func (t *TaskUpdate) Validate() error {
 		if _, err := time.ParseDuration(t.Options.Offset.String()); err != nil {
 			return fmt.Errorf("offset: %s, %s is invalid, the largest unit supported is h", t.Options.Offset.String(), err)
 		}
-	case t.Flux == nil && t.Status == nil && t.Options.IsZero():
+	case t.Flux == nil && t.Status == nil && t.DependsOn == nil && t.Options.IsZero():
 		return errors.New("cannot update task without content")
 	case t.Status != nil && *t.Status != TaskStatusActive && *t.Status != TaskStatusInactive:
 		return fmt.Errorf("invalid task status: %q", *t.Status)
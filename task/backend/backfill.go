@@ -0,0 +1,227 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+)
+
+const (
+	// MaxBackfillRuns is the maximum number of runs a single backfill queues.
+	MaxBackfillRuns = 10000
+	// MaxBackfillConcurrency is the maximum number of runs a single backfill
+	// queues at once.
+	MaxBackfillConcurrency = 100
+
+	defaultBackfillPollInterval = time.Second
+)
+
+// BackfillService provides the API a backfill uses to force runs of a task and
+// follow them to completion.
+type BackfillService interface {
+	ForceRun(ctx context.Context, taskID influxdb.ID, scheduledFor int64) (*influxdb.Run, error)
+	FindRunByID(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error)
+}
+
+// BackfillOptions configures a backfill.
+type BackfillOptions struct {
+	// Concurrency is the number of backfilled runs queued at once, defaults to 1
+	// and is at most MaxBackfillConcurrency. Runs are further limited by the
+	// concurrency option of the task.
+	Concurrency int
+
+	// PollInterval is how often a queued run is checked for completion, defaults to 1s.
+	PollInterval time.Duration
+
+	// Progress, when set, is called each time a backfilled run completes.
+	Progress func(BackfillProgress)
+}
+
+// BackfillProgress reports the state of a backfill.
+type BackfillProgress struct {
+	// Total is the number of runs the task was scheduled for in the backfilled range.
+	Total int
+
+	Succeeded int
+	Failed    int
+	Canceled  int
+	// Skipped counts the runs that were not queued because a run for the
+	// same time was already queued.
+	Skipped int
+
+	// Run is the run that completed last, nil if it was skipped.
+	Run *influxdb.Run
+}
+
+// Completed returns the number of runs of the backfill that are done.
+func (p BackfillProgress) Completed() int {
+	return p.Succeeded + p.Failed + p.Canceled + p.Skipped
+}
+
+// BackfillTimes returns the times a task was scheduled for between start and
+// stop, inclusive.
+func BackfillTimes(task *influxdb.Task, start, stop time.Time) ([]time.Time, error) {
+	if !start.Before(stop) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "backfill start must be before stop",
+		}
+	}
+	if task.EffectiveCron() == "" {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "task has no cron or every",
+		}
+	}
+
+	sch, ts, err := scheduler.NewSchedule(task.EffectiveCron(), start.Add(-time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	var times []time.Time
+	for {
+		next, err := sch.Next(ts)
+		if err != nil {
+			return nil, err
+		}
+		if next.After(stop) {
+			return times, nil
+		}
+		if len(times) == MaxBackfillRuns {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("backfill covers more than %d runs", MaxBackfillRuns),
+			}
+		}
+		times = append(times, next)
+		ts = next
+	}
+}
+
+// Backfill forces the runs of a task scheduled between start and stop,
+// keeping at most opts.Concurrency of them queued or running at once, and
+// waits for them to complete. Failed runs are retried by the executor as the
+// retry option of the task allows; the progress only reports the first attempt.
+func Backfill(ctx context.Context, ts BackfillService, task *influxdb.Task, start, stop time.Time, opts BackfillOptions) (BackfillProgress, error) {
+	times, err := BackfillTimes(task, start, stop)
+	if err != nil {
+		return BackfillProgress{}, err
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.Concurrency > MaxBackfillConcurrency {
+		opts.Concurrency = MaxBackfillConcurrency
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultBackfillPollInterval
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		progress = BackfillProgress{Total: len(times)}
+		sem      = make(chan struct{}, opts.Concurrency)
+	)
+	report := func(run *influxdb.Run, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+			return
+		}
+
+		switch {
+		case run == nil:
+			progress.Skipped++
+		case run.Status == influxdb.RunSuccess.String():
+			progress.Succeeded++
+		case run.Status == influxdb.RunFail.String():
+			progress.Failed++
+		default:
+			progress.Canceled++
+		}
+		progress.Run = run
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+	}
+
+	for _, t := range times {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(scheduledFor time.Time) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			report(backfillRun(ctx, ts, task.ID, scheduledFor, opts.PollInterval))
+		}(t)
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return progress, firstErr
+}
+
+// backfillRun forces the run of a task scheduled for a time and waits for it
+// to complete. It returns a nil run if a run for the time was already queued.
+//
+// The task service of the server returns from ForceRun once the run
+// completed, with the error of the run if it failed; the run is then reported
+// by its status.
+func backfillRun(ctx context.Context, ts BackfillService, taskID influxdb.ID, scheduledFor time.Time, poll time.Duration) (*influxdb.Run, error) {
+	run, forceErr := ts.ForceRun(ctx, taskID, scheduledFor.Unix())
+	if run == nil {
+		if influxdb.ErrorCode(forceErr) == influxdb.EConflict {
+			return nil, nil
+		}
+		return nil, forceErr
+	}
+
+	for {
+		r, err := ts.FindRunByID(ctx, taskID, run.ID)
+		switch {
+		case err == nil:
+			switch r.Status {
+			case influxdb.RunSuccess.String(), influxdb.RunFail.String(), influxdb.RunCanceled.String():
+				return r, nil
+			}
+		case influxdb.ErrorCode(err) != influxdb.ENotFound:
+			return nil, err
+		}
+		// manual runs are not found until the executor starts them, unless
+		// they failed to start
+		if forceErr != nil {
+			return nil, forceErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(poll):
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestBackfillTimes(t *testing.T) {
+	start := time.Date(2020, 10, 12, 10, 0, 0, 0, time.UTC)
+
+	for _, test := range []struct {
+		name    string
+		task    *influxdb.Task
+		stop    time.Time
+		want    []time.Time
+		wantErr bool
+	}{
+		{
+			name: "every",
+			task: &influxdb.Task{Every: "1h"},
+			stop: start.Add(3 * time.Hour),
+			want: []time.Time{start, start.Add(time.Hour), start.Add(2 * time.Hour), start.Add(3 * time.Hour)},
+		},
+		{
+			name: "every with unaligned range",
+			task: &influxdb.Task{Every: "1h"},
+			stop: start.Add(150 * time.Minute),
+			want: []time.Time{start, start.Add(time.Hour), start.Add(2 * time.Hour)},
+		},
+		{
+			name: "cron",
+			task: &influxdb.Task{Cron: "30 * * * *"},
+			stop: start.Add(2 * time.Hour),
+			want: []time.Time{start.Add(30 * time.Minute), start.Add(90 * time.Minute)},
+		},
+		{
+			name:    "stop before start",
+			task:    &influxdb.Task{Every: "1h"},
+			stop:    start.Add(-time.Hour),
+			wantErr: true,
+		},
+		{
+			name:    "too many runs",
+			task:    &influxdb.Task{Every: "1s"},
+			stop:    start.Add(24 * time.Hour),
+			wantErr: true,
+		},
+		{
+			name:    "no schedule",
+			task:    &influxdb.Task{},
+			stop:    start.Add(time.Hour),
+			wantErr: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := BackfillTimes(test.task, start, test.stop)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("BackfillTimes() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("unexpected backfill times: %s", diff)
+			}
+		})
+	}
+}
+
+// backfillTaskService runs forced runs instantly, failing the runs scheduled
+// for the failAt time and rejecting the ones for the queuedAt time. Runs are
+// found once the first poll missed them.
+type backfillTaskService struct {
+	failAt, queuedAt time.Time
+
+	mu             sync.Mutex
+	runs           map[influxdb.ID]*influxdb.Run
+	polled         map[influxdb.ID]bool
+	running, limit int
+}
+
+func (s *backfillTaskService) ForceRun(_ context.Context, taskID influxdb.ID, scheduledFor int64) (*influxdb.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := time.Unix(scheduledFor, 0).UTC()
+	if t.Equal(s.queuedAt) {
+		return nil, influxdb.ErrTaskRunAlreadyQueued
+	}
+
+	status := influxdb.RunSuccess
+	if t.Equal(s.failAt) {
+		status = influxdb.RunFail
+	}
+	r := &influxdb.Run{ID: influxdb.ID(len(s.runs) + 1), TaskID: taskID, ScheduledFor: t, Status: status.String()}
+	s.runs[r.ID] = r
+
+	s.running++
+	if s.running > s.limit {
+		s.limit = s.running
+	}
+	if status == influxdb.RunFail {
+		// like the task service of the server, return once the run failed
+		s.polled[r.ID] = true
+		return r, errors.New("run failed")
+	}
+	return r, nil
+}
+
+func (s *backfillTaskService) FindRunByID(_ context.Context, _, runID influxdb.ID) (*influxdb.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the first poll finds the run still queued
+	if !s.polled[runID] {
+		s.polled[runID] = true
+		return nil, influxdb.ErrRunNotFound
+	}
+	s.running--
+	return s.runs[runID], nil
+}
+
+func TestBackfill(t *testing.T) {
+	start := time.Date(2020, 10, 12, 10, 0, 0, 0, time.UTC)
+	ts := &backfillTaskService{
+		failAt:   start.Add(time.Hour),
+		queuedAt: start.Add(2 * time.Hour),
+		runs:     make(map[influxdb.ID]*influxdb.Run),
+		polled:   make(map[influxdb.ID]bool),
+	}
+
+	var reports []BackfillProgress
+	progress, err := Backfill(context.Background(), ts, &influxdb.Task{ID: 1, Every: "1h"}, start, start.Add(5*time.Hour), BackfillOptions{
+		Concurrency:  2,
+		PollInterval: time.Millisecond,
+		Progress: func(p BackfillProgress) {
+			reports = append(reports, p)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := BackfillProgress{Total: 6, Succeeded: 4, Failed: 1, Skipped: 1}
+	progress.Run = nil
+	if progress != want {
+		t.Errorf("got progress %+v, want %+v", progress, want)
+	}
+	if len(reports) != 6 || reports[5].Completed() != 6 {
+		t.Errorf("got %d progress reports, want 6", len(reports))
+	}
+	if ts.limit != 2 {
+		t.Errorf("got %d concurrent runs, want 2", ts.limit)
+	}
+}
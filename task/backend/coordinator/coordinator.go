@@ -24,11 +24,18 @@ type Executor interface {
 	Cancel(ctx context.Context, runID influxdb.ID) error
 }
 
+// TriggerDispatcher runs tasks when data is written to the bucket of their trigger
+type TriggerDispatcher interface {
+	Register(task *influxdb.Task)
+	Release(id influxdb.ID)
+}
+
 // Coordinator is the intermediary between the scheduling/executing system and the rest of the task system
 type Coordinator struct {
 	log *zap.Logger
 	sch scheduler.Scheduler
 	ex  Executor
+	td  TriggerDispatcher
 
 	limit int
 }
@@ -67,6 +74,13 @@ func WithLimitOpt(i int) CoordinatorOption {
 	}
 }
 
+// WithTriggerDispatcherOpt registers the tasks with a trigger with the dispatcher
+func WithTriggerDispatcherOpt(td TriggerDispatcher) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.td = td
+	}
+}
+
 // NewSchedulableTask transforms an influxdb task to a schedulable task type
 func NewSchedulableTask(task *influxdb.Task) (SchedulableTask, error) {
 
@@ -105,8 +119,16 @@ func NewCoordinator(log *zap.Logger, scheduler scheduler.Scheduler, executor Exe
 	return c
 }
 
-// TaskCreated asks the Scheduler to schedule the newly created task
+// TaskCreated asks the Scheduler to schedule the newly created task, and registers its trigger
 func (c *Coordinator) TaskCreated(ctx context.Context, task *influxdb.Task) error {
+	if c.td != nil {
+		c.td.Register(task)
+	}
+	// a task with a trigger may only run on writes
+	if task.Trigger != nil && task.EffectiveCron() == "" {
+		return nil
+	}
+
 	t, err := NewSchedulableTask(task)
 
 	if err != nil {
@@ -121,23 +143,28 @@ func (c *Coordinator) TaskCreated(ctx context.Context, task *influxdb.Task) erro
 	return nil
 }
 
-// TaskUpdated releases the task if it is being disabled, and schedules it otherwise
+// TaskUpdated releases the task if it is being disabled or loses its schedule, and schedules it otherwise
 func (c *Coordinator) TaskUpdated(ctx context.Context, from, to *influxdb.Task) error {
-	sid := scheduler.ID(to.ID)
-	t, err := NewSchedulableTask(to)
-	if err != nil {
-		return err
+	if c.td != nil {
+		c.td.Register(to)
 	}
 
-	// if disabling the task, release it before schedule update
-	if to.Status != from.Status && to.Status == string(influxdb.TaskInactive) {
+	sid := scheduler.ID(to.ID)
+	// if disabling the task, or it only runs on writes, release it before schedule update
+	if (to.Status != from.Status && to.Status == string(influxdb.TaskInactive)) ||
+		(to.Trigger != nil && to.EffectiveCron() == "") {
 		if err := c.sch.Release(sid); err != nil && err != influxdb.ErrTaskNotClaimed {
 			return err
 		}
-	} else {
-		if err := c.sch.Schedule(t); err != nil {
-			return err
-		}
+		return nil
+	}
+
+	t, err := NewSchedulableTask(to)
+	if err != nil {
+		return err
+	}
+	if err := c.sch.Schedule(t); err != nil {
+		return err
 	}
 
 	return nil
@@ -145,6 +172,10 @@ func (c *Coordinator) TaskUpdated(ctx context.Context, from, to *influxdb.Task)
 
 //TaskDeleted asks the Scheduler to release the deleted task
 func (c *Coordinator) TaskDeleted(ctx context.Context, id influxdb.ID) error {
+	if c.td != nil {
+		c.td.Release(id)
+	}
+
 	tid := scheduler.ID(id)
 	if err := c.sch.Release(tid); err != nil && err != influxdb.ErrTaskNotClaimed {
 		return err
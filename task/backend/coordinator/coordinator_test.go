@@ -10,6 +10,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/task/options"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -259,3 +260,67 @@ func Test_Coordinator_Scheduler_Methods(t *testing.T) {
 		})
 	}
 }
+
+func Test_Coordinator_Trigger_Methods(t *testing.T) {
+	var (
+		one = influxdb.ID(1)
+		now = time.Now().UTC()
+
+		taskScheduled = &influxdb.Task{ID: one, Status: "active", CreatedAt: now, Cron: "* * * * *"}
+		taskTriggered = &influxdb.Task{ID: one, Status: "active", CreatedAt: now, Trigger: &options.Trigger{Bucket: "raw"}}
+	)
+
+	for _, test := range []struct {
+		name       string
+		call       func(*testing.T, *Coordinator)
+		scheduler  []interface{}
+		dispatcher []interface{}
+	}{
+		{
+			name: "TaskCreated",
+			call: func(t *testing.T, c *Coordinator) {
+				if err := c.TaskCreated(context.Background(), taskTriggered); err != nil {
+					t.Errorf("expected nil error found %q", err)
+				}
+			},
+			dispatcher: []interface{}{registerCall{one}},
+		},
+		{
+			name: "TaskUpdated - remove schedule",
+			call: func(t *testing.T, c *Coordinator) {
+				if err := c.TaskUpdated(context.Background(), taskScheduled, taskTriggered); err != nil {
+					t.Errorf("expected nil error found %q", err)
+				}
+			},
+			scheduler:  []interface{}{releaseCallC{scheduler.ID(one)}},
+			dispatcher: []interface{}{registerCall{one}},
+		},
+		{
+			name: "TaskDeleted",
+			call: func(t *testing.T, c *Coordinator) {
+				if err := c.TaskDeleted(context.Background(), one); err != nil {
+					t.Errorf("expected nil error found %q", err)
+				}
+			},
+			scheduler:  []interface{}{releaseCallC{scheduler.ID(one)}},
+			dispatcher: []interface{}{releaseTriggerCall{one}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				sch   = &schedulerC{}
+				td    = &triggerDispatcherC{}
+				coord = NewCoordinator(zaptest.NewLogger(t), sch, &executorE{}, WithTriggerDispatcherOpt(td))
+			)
+
+			test.call(t, coord)
+
+			if diff := cmp.Diff(test.scheduler, sch.calls); diff != "" {
+				t.Errorf("unexpected scheduler contents %s", diff)
+			}
+			if diff := cmp.Diff(test.dispatcher, td.calls); diff != "" {
+				t.Errorf("unexpected dispatcher contents %s", diff)
+			}
+		})
+	}
+}
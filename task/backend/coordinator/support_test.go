@@ -41,6 +41,20 @@ type (
 	}
 )
 
+type (
+	triggerDispatcherC struct {
+		calls []interface{}
+	}
+
+	registerCall struct {
+		TaskID influxdb.ID
+	}
+
+	releaseTriggerCall struct {
+		TaskID influxdb.ID
+	}
+)
+
 type (
 	promise struct {
 		run *influxdb.Run
@@ -112,3 +126,11 @@ func (e *executorE) Cancel(ctx context.Context, runID influxdb.ID) error {
 	e.calls = append(e.calls, cancelCallC{runID})
 	return nil
 }
+
+func (d *triggerDispatcherC) Register(task *influxdb.Task) {
+	d.calls = append(d.calls, registerCall{task.ID})
+}
+
+func (d *triggerDispatcherC) Release(id influxdb.ID) {
+	d.calls = append(d.calls, releaseTriggerCall{id})
+}
@@ -0,0 +1,201 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+const (
+	// maxTrackedRuns is the number of scheduled times per task whose run
+	// status is kept for the downstream tasks.
+	maxTrackedRuns = 10
+
+	defaultUpstreamPollInterval = time.Second
+)
+
+// runTracker keeps the status of the latest runs of every task by scheduled
+// time, and wakes up the runs of downstream tasks waiting on them.
+type runTracker struct {
+	mu       sync.Mutex
+	statuses map[influxdb.ID]map[int64]influxdb.RunStatus
+	changed  chan struct{}
+	stopped  chan struct{}
+}
+
+func newRunTracker() *runTracker {
+	return &runTracker{
+		statuses: make(map[influxdb.ID]map[int64]influxdb.RunStatus),
+		changed:  make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// record sets the status of the run of a task for a scheduled time. Runs that
+// will be retried are recorded as scheduled.
+func (t *runTracker) record(taskID influxdb.ID, scheduledFor time.Time, status influxdb.RunStatus) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	runs, ok := t.statuses[taskID]
+	if !ok {
+		runs = make(map[int64]influxdb.RunStatus)
+		t.statuses[taskID] = runs
+	}
+	key := scheduledFor.Unix()
+	runs[key] = status
+	if len(runs) > maxTrackedRuns {
+		// a late run must not push out its own status
+		oldest, found := int64(0), false
+		for sf := range runs {
+			if sf != key && (!found || sf < oldest) {
+				oldest, found = sf, true
+			}
+		}
+		delete(runs, oldest)
+	}
+
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// stop releases the runs parked for their upstream runs. Their runs are
+// resumed when the executor starts again.
+func (t *runTracker) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	select {
+	case <-t.stopped:
+	default:
+		close(t.stopped)
+	}
+}
+
+// status returns the status of the run of a task for a scheduled time if it
+// is tracked, and a channel that is closed on the next recorded status.
+func (t *runTracker) status(taskID influxdb.ID, scheduledFor time.Time) (influxdb.RunStatus, bool, <-chan struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status, ok := t.statuses[taskID][scheduledFor.Unix()]
+	return status, ok, t.changed
+}
+
+// upstreamReady reports whether the runs of the upstream tasks of the task of
+// a promise scheduled for the same time have succeeded. While one of them is
+// pending it returns a channel that is closed when a run status changes. It
+// fails if one of them cannot succeed or the promise is canceled.
+func (e *Executor) upstreamReady(p *promise) (<-chan struct{}, error) {
+	if p.ctx.Err() != nil {
+		return nil, influxdb.ErrRunCanceled
+	}
+	for _, id := range p.task.DependsOn {
+		status, changed, err := e.upstreamStatus(p.ctx, id, p.run.ScheduledFor)
+		if err != nil {
+			return nil, err
+		}
+		if status == influxdb.RunSuccess {
+			continue
+		}
+		if status != influxdb.RunScheduled {
+			return nil, influxdb.ErrUpstreamTaskFailed(id, "run "+status.String())
+		}
+
+		if p.waitingFor != id {
+			e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Waiting for upstream task %s", id))
+			p.waitingFor = id
+		}
+		return changed, nil
+	}
+	return nil, nil
+}
+
+// park requeues the promise of a run waiting for an upstream run once a run
+// status changes, the poll interval passes or the promise is canceled. The
+// promise does not hold a worker meanwhile, so the upstream runs can execute.
+func (e *Executor) park(p *promise, changed <-chan struct{}) {
+	stopped := e.upstream.stopped
+	go func() {
+		select {
+		case <-changed:
+		case <-time.After(e.upstreamPollInterval):
+		case <-p.ctx.Done():
+		case <-stopped:
+			return
+		}
+
+		select {
+		case e.promiseQueue <- p:
+			e.startWorker()
+		case <-stopped:
+		}
+	}()
+}
+
+// upstreamStatus returns the status of the run of an upstream task for a
+// scheduled time, which is scheduled while the run is pending. Runs that
+// completed before the executor started are looked up in the runs of the
+// task.
+func (e *Executor) upstreamStatus(ctx context.Context, id influxdb.ID, scheduledFor time.Time) (influxdb.RunStatus, <-chan struct{}, error) {
+	status, ok, changed := e.upstream.status(id, scheduledFor)
+	if ok {
+		return status, changed, nil
+	}
+
+	t, err := e.ts.FindTaskByID(ctx, id)
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return influxdb.RunFail, nil, influxdb.ErrUpstreamTaskFailed(id, "was deleted")
+		}
+		return influxdb.RunFail, nil, err
+	}
+
+	switch {
+	case t.LatestCompleted.Before(scheduledFor) && t.Status != influxdb.TaskStatusActive:
+		return influxdb.RunFail, nil, influxdb.ErrUpstreamTaskFailed(id, "is inactive")
+	case t.LatestCompleted.Before(scheduledFor):
+		return influxdb.RunScheduled, changed, nil
+	}
+
+	status, err = e.upstreamRunStatus(ctx, id, scheduledFor)
+	if err != nil {
+		return influxdb.RunFail, nil, err
+	}
+	return status, changed, nil
+}
+
+// upstreamRunStatus returns the status of the runs of a task for a scheduled
+// time. One successful attempt is enough, and a pending retry keeps the run
+// scheduled. A task that was not scheduled for that time does not hold back
+// its downstream tasks.
+func (e *Executor) upstreamRunStatus(ctx context.Context, id influxdb.ID, scheduledFor time.Time) (influxdb.RunStatus, error) {
+	runs, _, err := e.ts.FindRuns(ctx, influxdb.RunFilter{Task: id, Limit: influxdb.TaskMaxPageSize})
+	if err != nil {
+		return influxdb.RunFail, err
+	}
+
+	status, found := influxdb.RunSuccess, false
+	for _, r := range runs {
+		if !r.ScheduledFor.Equal(scheduledFor) {
+			continue
+		}
+		switch r.Status {
+		case influxdb.RunSuccess.String():
+			return influxdb.RunSuccess, nil
+		case influxdb.RunScheduled.String(), influxdb.RunStarted.String():
+			status, found = influxdb.RunScheduled, true
+		case influxdb.RunFail.String(), influxdb.RunCanceled.String():
+			if !found {
+				status, found = influxdb.RunFail, true
+				if r.Status == influxdb.RunCanceled.String() {
+					status = influxdb.RunCanceled
+				}
+			}
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,61 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestRunTracker(t *testing.T) {
+	var (
+		tracker = newRunTracker()
+		taskID  = influxdb.ID(1)
+		start   = time.Unix(1602462600, 0)
+	)
+
+	if _, ok, _ := tracker.status(taskID, start); ok {
+		t.Fatal("expected no status before a run is recorded")
+	}
+
+	_, _, changed := tracker.status(taskID, start)
+	tracker.record(taskID, start, influxdb.RunScheduled)
+	select {
+	case <-changed:
+	default:
+		t.Fatal("expected recording a status to close the changed channel")
+	}
+
+	tracker.record(taskID, start, influxdb.RunSuccess)
+	if status, ok, _ := tracker.status(taskID, start); !ok || status != influxdb.RunSuccess {
+		t.Fatalf("got status %v, want %v", status, influxdb.RunSuccess)
+	}
+
+	for i := 1; i <= maxTrackedRuns; i++ {
+		tracker.record(taskID, start.Add(time.Duration(i)*time.Minute), influxdb.RunFail)
+	}
+	if _, ok, _ := tracker.status(taskID, start); ok {
+		t.Fatal("expected the oldest run to be pruned")
+	}
+	if status, ok, _ := tracker.status(taskID, start.Add(maxTrackedRuns*time.Minute)); !ok || status != influxdb.RunFail {
+		t.Fatalf("got status %v, want %v", status, influxdb.RunFail)
+	}
+
+	// a run older than the tracked ones keeps its status and pushes out the oldest other run
+	late := start.Add(-time.Minute)
+	tracker.record(taskID, late, influxdb.RunSuccess)
+	if status, ok, _ := tracker.status(taskID, late); !ok || status != influxdb.RunSuccess {
+		t.Fatalf("got status %v, want %v", status, influxdb.RunSuccess)
+	}
+	if _, ok, _ := tracker.status(taskID, start.Add(time.Minute)); ok {
+		t.Fatal("expected the oldest other run to be pruned")
+	}
+
+	tracker.stop()
+	tracker.stop()
+	select {
+	case <-tracker.stopped:
+	default:
+		t.Fatal("expected stop to close the stopped channel")
+	}
+}
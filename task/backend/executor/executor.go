@@ -3,6 +3,7 @@ package executor
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -25,7 +26,14 @@ const (
 	maxPromises       = 1000
 	defaultMaxWorkers = 100
 
+	defaultRetryBackoff    = 5 * time.Second
+	defaultMaxRetryBackoff = 5 * time.Minute
+
 	lastSuccessOption = "tasks.lastSuccessTime"
+
+	// attemptLog is the log a retried run starts with, which is how the
+	// attempt of a resumed run is known.
+	attemptLog = "Attempt %d of %d"
 )
 
 var _ scheduler.Executor = (*Executor)(nil)
@@ -56,11 +64,18 @@ func MultiLimit(limits ...LimitFunc) LimitFunc {
 // LimitFunc is a function the executor will use to
 type LimitFunc func(*influxdb.Task, *influxdb.Run) error
 
+// AttemptsFunc is a function the executor will use to determine how many times
+// it may execute a run of the task for the same scheduled time.
+type AttemptsFunc func(*influxdb.Task) (int, error)
+
 type executorConfig struct {
 	maxWorkers             int
 	systemBuildCompiler    CompilerBuilderFunc
 	nonSystemBuildCompiler CompilerBuilderFunc
 	flagger                feature.Flagger
+	attemptsFunc           AttemptsFunc
+	retryBackoff           time.Duration
+	maxRetryBackoff        time.Duration
 }
 
 type executorOption func(*executorConfig)
@@ -72,6 +87,24 @@ func WithMaxWorkers(n int) executorOption {
 	}
 }
 
+// WithAttemptsFunc is an Executor option that configures how many times a run
+// of a task is executed before its failure is final. By default failed runs
+// are not retried.
+func WithAttemptsFunc(a AttemptsFunc) executorOption {
+	return func(o *executorConfig) {
+		o.attemptsFunc = a
+	}
+}
+
+// WithRetryBackoff specifies the delay before the first retry of a failed run,
+// which doubles with every further attempt up to max.
+func WithRetryBackoff(initial, max time.Duration) executorOption {
+	return func(o *executorConfig) {
+		o.retryBackoff = initial
+		o.maxRetryBackoff = max
+	}
+}
+
 // CompilerBuilderFunc is a function that yields a new flux.Compiler. The
 // context.Context provided can be assumed to be an authorized context.
 type CompilerBuilderFunc func(ctx context.Context, query string, ts CompilerBuilderTimestamps) (flux.Compiler, error)
@@ -130,6 +163,9 @@ func NewExecutor(log *zap.Logger, qs query.QueryService, us PermissionService, t
 		maxWorkers:             defaultMaxWorkers,
 		systemBuildCompiler:    NewASTCompiler,
 		nonSystemBuildCompiler: NewASTCompiler,
+		attemptsFunc:           func(*influxdb.Task) (int, error) { return 1, nil },
+		retryBackoff:           defaultRetryBackoff,
+		maxRetryBackoff:        defaultMaxRetryBackoff,
 	}
 	for _, opt := range opts {
 		opt(cfg)
@@ -146,9 +182,15 @@ func NewExecutor(log *zap.Logger, qs query.QueryService, us PermissionService, t
 		promiseQueue:           make(chan *promise, maxPromises),
 		workerLimit:            make(chan struct{}, cfg.maxWorkers),
 		limitFunc:              func(*influxdb.Task, *influxdb.Run) error { return nil }, // noop
+		attemptsFunc:           cfg.attemptsFunc,
 		systemBuildCompiler:    cfg.systemBuildCompiler,
 		nonSystemBuildCompiler: cfg.nonSystemBuildCompiler,
 		flagger:                cfg.flagger,
+		retryBackoff:           cfg.retryBackoff,
+		maxRetryBackoff:        cfg.maxRetryBackoff,
+		delayed:                delayedRuns{runs: make(map[influxdb.ID]delayedRun)},
+		upstream:               newRunTracker(),
+		upstreamPollInterval:   defaultUpstreamPollInterval,
 	}
 
 	e.metrics = NewExecutorMetrics(e)
@@ -180,6 +222,19 @@ type Executor struct {
 
 	limitFunc LimitFunc
 
+	// attemptsFunc bounds the automatic retries of failed runs, which are
+	// delayed by an exponential backoff.
+	attemptsFunc    AttemptsFunc
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+
+	// delayed holds the retries waiting for their backoff to pass.
+	delayed delayedRuns
+
+	// upstream tracks the runs that the runs of downstream tasks wait for.
+	upstream             *runTracker
+	upstreamPollInterval time.Duration
+
 	// keep a pool of execution workers.
 	workerPool  sync.Pool
 	workerLimit chan struct{}
@@ -208,7 +263,7 @@ func (e *Executor) Execute(ctx context.Context, id scheduler.ID, scheduledFor ti
 func (e *Executor) PromisedExecute(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) (Promise, error) {
 	iid := influxdb.ID(id)
 	// create a run
-	p, err := e.createRun(ctx, iid, scheduledFor, runAt)
+	p, err := e.createRun(ctx, iid, scheduledFor, runAt, 1)
 	if err != nil {
 		return nil, err
 	}
@@ -217,13 +272,89 @@ func (e *Executor) PromisedExecute(ctx context.Context, id scheduler.ID, schedul
 	return p, nil
 }
 
+// retryLater creates the run of the next attempt of the failed run of a
+// promise, and executes it once delay has passed. The run is stored right
+// away, so it is resumed with the other current runs if the executor stops
+// before then.
+func (e *Executor) retryLater(p *promise, attempts int, delay time.Duration) {
+	ctx := icontext.SetAuthorizer(context.Background(), p.auth)
+	r, err := e.tcs.CreateRun(ctx, p.task.ID, p.run.ScheduledFor, time.Now().UTC().Add(delay))
+	if err != nil {
+		e.log.Error("Failed to retry run", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
+		e.upstream.record(p.task.ID, p.run.ScheduledFor, influxdb.RunFail)
+		return
+	}
+	e.tcs.AddRunLog(ctx, p.task.ID, r.ID, time.Now().UTC(), fmt.Sprintf(attemptLog, p.attempt+1, attempts))
+
+	attempt := p.attempt + 1
+	e.delayed.add(r, delay, func() {
+		e.retry(ctx, r, attempt)
+	})
+}
+
+// retry executes a run retrying a failed one, if the task still exists and
+// is active.
+func (e *Executor) retry(ctx context.Context, r *influxdb.Run, attempt int) {
+	t, err := e.ts.FindTaskByID(ctx, r.TaskID)
+	if err == nil && t.Status != influxdb.TaskStatusActive {
+		err = errors.New("task is inactive")
+	}
+	if err != nil {
+		e.log.Info("Not retrying run", zap.String("taskID", r.TaskID.String()), zap.String("runID", r.ID.String()), zap.Error(err))
+		e.abandonRun(ctx, r, influxdb.RunCanceled, fmt.Sprintf("Not retrying run: %s", err))
+		return
+	}
+
+	if _, err := e.createPromise(ctx, r, attempt); err != nil {
+		e.abandonRun(ctx, r, influxdb.RunFail, fmt.Sprintf("Failed to enqueue run: %s", err))
+		return
+	}
+
+	e.startWorker()
+	e.metrics.retryRunsCounter.WithLabelValues(t.ID.String()).Inc()
+}
+
+// abandonRun finishes a run that was never executed in state rs.
+func (e *Executor) abandonRun(ctx context.Context, r *influxdb.Run, rs influxdb.RunStatus, msg string) {
+	if err := e.tcs.AddRunLog(ctx, r.TaskID, r.ID, time.Now().UTC(), msg); err != nil {
+		e.log.Error("Failed to abandon run: AddRunLog", zap.Error(err))
+	}
+	if err := e.tcs.UpdateRunState(ctx, r.TaskID, r.ID, time.Now().UTC(), rs); err != nil {
+		e.log.Error("Failed to abandon run: UpdateRunState", zap.Error(err))
+	}
+	if _, err := e.tcs.FinishRun(ctx, r.TaskID, r.ID); err != nil {
+		e.log.Error("Failed to abandon run: FinishRun", zap.Error(err))
+	}
+	e.upstream.record(r.TaskID, r.ScheduledFor, rs)
+}
+
+// Stop stops the retries waiting for their backoff and the runs waiting for
+// their upstream runs. Their runs are resumed when the executor starts again.
+func (e *Executor) Stop() {
+	e.delayed.stop()
+	e.upstream.stop()
+}
+
+// retryDelay returns the exponential backoff before the attempt following
+// the given one.
+func (e *Executor) retryDelay(attempt int) time.Duration {
+	delay := e.retryBackoff
+	for i := 1; i < attempt && delay < e.maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > e.maxRetryBackoff {
+		delay = e.maxRetryBackoff
+	}
+	return delay
+}
+
 func (e *Executor) ManualRun(ctx context.Context, id influxdb.ID, runID influxdb.ID) (Promise, error) {
 	// create promises for any manual runs
 	r, err := e.tcs.StartManualRun(ctx, id, runID)
 	if err != nil {
 		return nil, err
 	}
-	p, err := e.createPromise(ctx, r)
+	p, err := e.createPromise(ctx, r, 1)
 
 	e.startWorker()
 	e.metrics.manualRunsCounter.WithLabelValues(id.String()).Inc()
@@ -242,7 +373,7 @@ func (e *Executor) ResumeCurrentRun(ctx context.Context, id influxdb.ID, runID i
 				continue
 			}
 
-			p, err := e.createPromise(ctx, run)
+			p, err := e.createPromise(ctx, run, runAttempt(run))
 
 			e.startWorker()
 			e.metrics.resumeRunsCounter.WithLabelValues(id.String()).Inc()
@@ -252,22 +383,14 @@ func (e *Executor) ResumeCurrentRun(ctx context.Context, id influxdb.ID, runID i
 	return nil, influxdb.ErrRunNotFound
 }
 
-func (e *Executor) createRun(ctx context.Context, id influxdb.ID, scheduledFor time.Time, runAt time.Time) (*promise, error) {
+func (e *Executor) createRun(ctx context.Context, id influxdb.ID, scheduledFor time.Time, runAt time.Time, attempt int) (*promise, error) {
 	r, err := e.tcs.CreateRun(ctx, id, scheduledFor.UTC(), runAt.UTC())
 	if err != nil {
 		return nil, err
 	}
-	p, err := e.createPromise(ctx, r)
+	p, err := e.createPromise(ctx, r, attempt)
 	if err != nil {
-		if err := e.tcs.AddRunLog(ctx, id, r.ID, time.Now().UTC(), fmt.Sprintf("Failed to enqueue run: %s", err.Error())); err != nil {
-			e.log.Error("failed to fail create run: AddRunLog:", zap.Error(err))
-		}
-		if err := e.tcs.UpdateRunState(ctx, id, r.ID, time.Now().UTC(), influxdb.RunFail); err != nil {
-			e.log.Error("failed to fail create run: UpdateRunState:", zap.Error(err))
-		}
-		if _, err := e.tcs.FinishRun(ctx, id, r.ID); err != nil {
-			e.log.Error("failed to fail create run: FinishRun:", zap.Error(err))
-		}
+		e.abandonRun(ctx, r, influxdb.RunFail, fmt.Sprintf("Failed to enqueue run: %s", err.Error()))
 	}
 
 	return p, err
@@ -298,6 +421,12 @@ func (e *Executor) startWorker() {
 
 // Cancel a run of a specific task.
 func (e *Executor) Cancel(ctx context.Context, runID influxdb.ID) error {
+	// a retry waiting for its backoff has no promise yet
+	if r, ok := e.delayed.take(runID); ok {
+		e.abandonRun(ctx, r, influxdb.RunCanceled, "Run canceled")
+		return nil
+	}
+
 	// find the promise
 	val, ok := e.currentPromises.Load(runID)
 	if !ok {
@@ -311,7 +440,7 @@ func (e *Executor) Cancel(ctx context.Context, runID influxdb.ID) error {
 	return nil
 }
 
-func (e *Executor) createPromise(ctx context.Context, run *influxdb.Run) (*promise, error) {
+func (e *Executor) createPromise(ctx context.Context, run *influxdb.Run, attempt int) (*promise, error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
@@ -337,6 +466,7 @@ func (e *Executor) createPromise(ctx context.Context, run *influxdb.Run) (*promi
 			OrgID:       t.OrganizationID,
 			Permissions: perm,
 		},
+		attempt:    attempt,
 		createdAt:  time.Now().UTC(),
 		done:       make(chan struct{}),
 		ctx:        ctx,
@@ -417,6 +547,24 @@ func (w *worker) work() {
 			}
 		}
 
+		// hold the run back until the runs of its upstream tasks succeeded
+		changed, err := w.e.upstreamReady(prom)
+		if err != nil {
+			prom.startedAt = time.Now()
+			if err == influxdb.ErrRunCanceled {
+				w.finish(prom, influxdb.RunCanceled, err)
+			} else {
+				w.finish(prom, influxdb.RunFail, err)
+			}
+			close(prom.done)
+			w.e.currentPromises.Delete(prom.run.ID)
+			continue
+		}
+		if changed != nil {
+			w.e.park(prom, changed)
+			continue
+		}
+
 		// execute the promise
 		w.executeQuery(prom)
 
@@ -456,6 +604,24 @@ func (w *worker) finish(p *promise, rs influxdb.RunStatus, err error) {
 	rd := time.Since(p.startedAt)
 	w.e.metrics.FinishRun(p.task, rs, rd)
 
+	// decide whether the failed run gets another attempt
+	var (
+		retryIn  time.Duration
+		attempts int
+	)
+	if rs == influxdb.RunFail && p.ctx.Err() == nil && !backend.IsUnrecoverable(err) {
+		var aerr error
+		attempts, aerr = w.e.attemptsFunc(p.task)
+		if aerr != nil {
+			w.e.log.Info("Unable to determine the number of attempts of task", zap.String("taskID", p.task.ID.String()), zap.Error(aerr))
+		} else if p.attempt < attempts {
+			retryIn = w.e.retryDelay(p.attempt)
+			w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Retrying in %s (attempt %d of %d)", retryIn, p.attempt+1, attempts))
+		} else if attempts > 1 {
+			w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Giving up after %d attempts", attempts))
+		}
+	}
+
 	// log error
 	if err != nil {
 		w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), err.Error())
@@ -479,9 +645,20 @@ func (w *worker) finish(p *promise, rs influxdb.RunStatus, err error) {
 		w.e.log.Debug("Completed successfully", zap.String("taskID", p.task.ID.String()))
 	}
 
+	// release the runs of downstream tasks waiting for this one
+	if retryIn > 0 {
+		w.e.upstream.record(p.task.ID, p.run.ScheduledFor, influxdb.RunScheduled)
+	} else {
+		w.e.upstream.record(p.task.ID, p.run.ScheduledFor, rs)
+	}
+
 	if _, err := w.e.tcs.FinishRun(p.ctx, p.task.ID, p.run.ID); err != nil {
 		w.e.log.Error("Failed to finish run", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
 	}
+
+	if retryIn > 0 {
+		w.e.retryLater(p, attempts, retryIn)
+	}
 }
 
 func (w *worker) executeQuery(p *promise) {
@@ -575,6 +752,12 @@ type promise struct {
 	done chan struct{}
 	err  error
 
+	// attempt counts the executions of the run's scheduled time, starting at 1.
+	attempt int
+
+	// waitingFor is the upstream task the run was last logged waiting for.
+	waitingFor influxdb.ID
+
 	createdAt time.Time
 	startedAt time.Time
 
@@ -611,6 +794,70 @@ func (p *promise) Error() error {
 	return p.err
 }
 
+// runAttempt returns the attempt of a run, which retries record in their log.
+func runAttempt(r *influxdb.Run) int {
+	for _, l := range r.Log {
+		var attempt, attempts int
+		if n, _ := fmt.Sscanf(l.Message, attemptLog, &attempt, &attempts); n == 2 {
+			return attempt
+		}
+	}
+	return 1
+}
+
+// delayedRuns holds the runs executed once their delay has passed.
+type delayedRuns struct {
+	mu      sync.Mutex
+	runs    map[influxdb.ID]delayedRun
+	stopped bool
+}
+
+type delayedRun struct {
+	run   *influxdb.Run
+	timer *time.Timer
+}
+
+// add calls f once delay has passed, unless the run is taken or the runs are
+// stopped before then.
+func (d *delayedRuns) add(r *influxdb.Run, delay time.Duration, f func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return
+	}
+	d.runs[r.ID] = delayedRun{
+		run: r,
+		timer: time.AfterFunc(delay, func() {
+			if _, ok := d.take(r.ID); ok {
+				f()
+			}
+		}),
+	}
+}
+
+// take removes a run and reports whether it was still waiting.
+func (d *delayedRuns) take(runID influxdb.ID) (*influxdb.Run, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	dr, ok := d.runs[runID]
+	if !ok {
+		return nil, false
+	}
+	dr.timer.Stop()
+	delete(d.runs, runID)
+	return dr.run, true
+}
+
+func (d *delayedRuns) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+	for id, dr := range d.runs {
+		dr.timer.Stop()
+		delete(d.runs, id)
+	}
+}
+
 // exhaustResultIterators drains all the iterators from a flux query Result.
 func exhaustResultIterators(res flux.Result) error {
 	return res.Tables().Do(func(tbl flux.Table) error {
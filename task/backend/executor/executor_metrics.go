@@ -15,6 +15,7 @@ type ExecutorMetrics struct {
 	errorsCounter        *prometheus.CounterVec
 	manualRunsCounter    *prometheus.CounterVec
 	resumeRunsCounter    *prometheus.CounterVec
+	retryRunsCounter     *prometheus.CounterVec
 	unrecoverableCounter *prometheus.CounterVec
 	runLatency           *prometheus.HistogramVec
 }
@@ -84,6 +85,13 @@ func NewExecutorMetrics(ex *Executor) *ExecutorMetrics {
 			Help:      "Total number of runs resumed by task ID",
 		}, []string{"taskID"}),
 
+		retryRunsCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "retry_runs_counter",
+			Help:      "Total number of failed runs automatically retried by task ID",
+		}, []string{"taskID"}),
+
 		runLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: subsystem,
@@ -128,6 +136,7 @@ func (em *ExecutorMetrics) PrometheusCollectors() []prometheus.Collector {
 		em.runDuration,
 		em.manualRunsCounter,
 		em.resumeRunsCounter,
+		em.retryRunsCounter,
 		em.unrecoverableCounter,
 		em.runLatency,
 	}
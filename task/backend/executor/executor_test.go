@@ -92,6 +92,9 @@ func TestTaskExecutor(t *testing.T) {
 	t.Run("ResumeRun", testResumingRun)
 	t.Run("WorkerLimit", testWorkerLimit)
 	t.Run("LimitFunc", testLimitFunc)
+	t.Run("Retry", testRetry)
+	t.Run("CancelRetry", testCancelRetry)
+	t.Run("Dependencies", testDependencies)
 	t.Run("Metrics", testMetrics)
 	t.Run("IteratorFailure", testIteratorFailure)
 	t.Run("ErrorHandling", testErrorHandling)
@@ -360,6 +363,173 @@ func testLimitFunc(t *testing.T) {
 	}
 }
 
+func testRetry(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+	tes.ex.retryBackoff = time.Millisecond
+	tes.ex.attemptsFunc = func(*influxdb.Task) (int, error) {
+		return 2, nil
+	}
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tes.svc.FailNextQuery(errors.New("forced"))
+
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-promise.Done()
+
+	if got := promise.Error(); got == nil {
+		t.Fatal("got no error when I should have")
+	}
+
+	// the second attempt runs the query for the same scheduled time
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.SucceedQuery(script)
+
+	for i := 0; ; i++ {
+		task, err = tes.i.FindTaskByID(ctx, task.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.LastRunStatus == influxdb.RunSuccess.String() {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("retried run did not succeed, last run status %q", task.LastRunStatus)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if !task.LatestSuccess.Equal(time.Unix(123, 0)) {
+		t.Fatalf("retried run has the wrong scheduled time: %v", task.LatestSuccess)
+	}
+}
+
+func testDependencies(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+	tes.ex.upstreamPollInterval = time.Millisecond
+
+	var (
+		upstreamScript   = fmt.Sprintf(fmtTestScript, t.Name()+"-upstream")
+		downstreamScript = fmt.Sprintf(fmtTestScript, t.Name()+"-downstream")
+		ctx              = icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	)
+	upstream, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: upstreamScript})
+	if err != nil {
+		t.Fatal(err)
+	}
+	downstream, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: downstreamScript, DependsOn: []influxdb.ID{upstream.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// runs are scheduled after the tasks were created, so the upstream runs are pending
+	scheduledFor := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(downstream.ID), scheduledFor, scheduledFor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-promise.Done():
+		t.Fatalf("downstream run completed before the upstream run: %v", promise.Error())
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	upstreamPromise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(upstream.ID), scheduledFor, scheduledFor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tes.svc.WaitForQueryLive(t, upstreamScript)
+	tes.svc.SucceedQuery(upstreamScript)
+	<-upstreamPromise.Done()
+
+	tes.svc.WaitForQueryLive(t, downstreamScript)
+	tes.svc.SucceedQuery(downstreamScript)
+	<-promise.Done()
+
+	if got := promise.Error(); got != nil {
+		t.Fatal(got)
+	}
+
+	// a failed upstream run fails the downstream run
+	scheduledFor = scheduledFor.Add(time.Minute)
+	tes.ex.upstream.record(upstream.ID, scheduledFor, influxdb.RunFail)
+
+	promise, err = tes.ex.PromisedExecute(ctx, scheduler.ID(downstream.ID), scheduledFor, scheduledFor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-promise.Done()
+
+	if got := promise.Error(); influxdb.ErrorCode(got) != influxdb.EConflict {
+		t.Fatalf("expected upstream task failure, got %v", got)
+	}
+}
+
+func testCancelRetry(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+	tes.ex.retryBackoff = time.Hour
+	tes.ex.attemptsFunc = func(*influxdb.Task) (int, error) {
+		return 2, nil
+	}
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tes.svc.FailNextQuery(errors.New("forced"))
+
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-promise.Done()
+
+	// the retry is stored as a current run until its backoff passed
+	runs, err := tes.tcs.CurrentlyRunning(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 current run, got %d", len(runs))
+	}
+	retry := runs[0]
+	if !retry.ScheduledFor.Equal(time.Unix(123, 0)) {
+		t.Fatalf("retry has the wrong scheduled time: %v", retry.ScheduledFor)
+	}
+	if got := runAttempt(retry); got != 2 {
+		t.Fatalf("expected attempt 2, got %d", got)
+	}
+
+	if err := tes.ex.Cancel(ctx, retry.ID); err != nil {
+		t.Fatal(err)
+	}
+	run, err := tes.i.FindRunByID(ctx, task.ID, retry.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Status != influxdb.RunCanceled.String() {
+		t.Fatalf("expected canceled retry, got status %q", run.Status)
+	}
+	if _, ok := tes.ex.delayed.take(retry.ID); ok {
+		t.Fatal("canceled retry is still waiting")
+	}
+}
+
 func testMetrics(t *testing.T) {
 	t.Parallel()
 	tes := taskExecutorSystem(t)
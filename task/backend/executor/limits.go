@@ -50,3 +50,18 @@ func ConcurrencyLimit(exec *Executor, lang influxdb.FluxLanguageService) LimitFu
 		return nil
 	}
 }
+
+// RetryLimit creates an attempts func that reads the number of times a run may
+// be executed from the retry option of the task.
+func RetryLimit(lang influxdb.FluxLanguageService) AttemptsFunc {
+	return func(t *influxdb.Task) (int, error) {
+		o, err := options.FromScript(lang, t.Flux)
+		if err != nil {
+			return 0, err
+		}
+		if o.Retry == nil {
+			return 1, nil
+		}
+		return int(*o.Retry), nil
+	}
+}
@@ -56,3 +56,27 @@ func TestTaskConcurrency(t *testing.T) {
 	// TODO(lh): add testing around infinite concurrency once the task options
 	// are not setting a default concurrency to 1.
 }
+
+func TestTaskRetry(t *testing.T) {
+	attemptsFunc := RetryLimit(fluxlang.DefaultService)
+
+	for _, tt := range []struct {
+		flux string
+		want int
+	}{
+		{flux: `option task = {name:"x", every:1m} from(bucket:"b-src") |> range(start:-1m) |> to(bucket:"b-dst", org:"o")`, want: 1},
+		{flux: `option task = {retry: 3, name:"x", every:1m} from(bucket:"b-src") |> range(start:-1m) |> to(bucket:"b-dst", org:"o")`, want: 3},
+	} {
+		got, err := attemptsFunc(&influxdb.Task{ID: 1, Flux: tt.flux})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("got %d attempts for %q, want %d", got, tt.flux, tt.want)
+		}
+	}
+
+	if _, err := attemptsFunc(&influxdb.Task{ID: 1, Flux: `option task = {retry: 11, name:"x", every:1m} from(bucket:"b-src")`}); err == nil {
+		t.Fatal("failed to error when exceeding max retry")
+	}
+}
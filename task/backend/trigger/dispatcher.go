@@ -0,0 +1,275 @@
+// Package trigger runs tasks when data is written to the buckets named by
+// the trigger option of their scripts.
+package trigger
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultDebounce is how long the dispatcher waits for more writes after
+	// a write that triggers a task before running it.
+	DefaultDebounce = time.Second
+
+	// DefaultMaxDelay is how long a steady stream of writes can hold back the
+	// run of a triggered task.
+	DefaultMaxDelay = 10 * time.Second
+
+	// bucketNameTTL is how long the name of a bucket written to is cached, so
+	// that renaming a bucket takes that long to change the triggered tasks.
+	bucketNameTTL = time.Minute
+)
+
+// BucketFinder finds the buckets written to.
+type BucketFinder interface {
+	FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error)
+}
+
+// DispatcherOption configures a Dispatcher.
+type DispatcherOption func(*Dispatcher)
+
+// WithDebounce sets how long the dispatcher waits for more writes before
+// running a triggered task, and how long it waits at most.
+func WithDebounce(debounce, maxDelay time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.debounce = debounce
+		d.maxDelay = maxDelay
+	}
+}
+
+// trigger is the trigger option of a registered task.
+type trigger struct {
+	taskID      influxdb.ID
+	orgID       influxdb.ID
+	bucket      string
+	measurement string
+}
+
+// bucketName is the cached name of a bucket.
+type bucketName struct {
+	name    string
+	expires time.Time
+}
+
+// pendingRun batches the writes triggering a task until it runs.
+type pendingRun struct {
+	timer *time.Timer
+	first time.Time
+}
+
+// Dispatcher runs the registered tasks through an executor when the points
+// written to a bucket match their trigger. The writes are debounced: a task
+// runs once the writes that trigger it pause for the debounce duration, or
+// after the max delay since the first of them.
+type Dispatcher struct {
+	log      *zap.Logger
+	ex       scheduler.Executor
+	buckets  BucketFinder
+	debounce time.Duration
+	maxDelay time.Duration
+
+	mu sync.Mutex
+	// triggers are the triggers of the tasks by organization and bucket name.
+	triggers map[influxdb.ID]map[string][]trigger
+	tasks    map[influxdb.ID]trigger
+	pending  map[influxdb.ID]*pendingRun
+	// lastRun is the time the last triggered run of each task was scheduled for.
+	lastRun map[influxdb.ID]time.Time
+	// bucketNames caches the names of the buckets written to by id.
+	bucketNames map[influxdb.ID]bucketName
+}
+
+// NewDispatcher creates a Dispatcher running the triggered tasks through ex.
+func NewDispatcher(log *zap.Logger, ex scheduler.Executor, buckets BucketFinder, opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{
+		log:      log,
+		ex:       ex,
+		buckets:  buckets,
+		debounce: DefaultDebounce,
+		maxDelay: DefaultMaxDelay,
+		triggers: make(map[influxdb.ID]map[string][]trigger),
+		tasks:    make(map[influxdb.ID]trigger),
+		pending:  make(map[influxdb.ID]*pendingRun),
+		lastRun:  make(map[influxdb.ID]time.Time),
+
+		bucketNames: make(map[influxdb.ID]bucketName),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Register starts running an active task with a trigger on the writes that
+// match it, replacing its previous trigger. Other tasks are released.
+func (d *Dispatcher) Register(task *influxdb.Task) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.release(task.ID)
+	if task.Trigger == nil || task.Status != influxdb.TaskStatusActive {
+		return
+	}
+
+	t := trigger{
+		taskID:      task.ID,
+		orgID:       task.OrganizationID,
+		bucket:      task.Trigger.Bucket,
+		measurement: task.Trigger.Measurement,
+	}
+	buckets, ok := d.triggers[t.orgID]
+	if !ok {
+		buckets = make(map[string][]trigger)
+		d.triggers[t.orgID] = buckets
+	}
+	buckets[t.bucket] = append(buckets[t.bucket], t)
+	d.tasks[t.taskID] = t
+}
+
+// Release stops running a task on writes, dropping its pending run.
+func (d *Dispatcher) Release(id influxdb.ID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.release(id)
+}
+
+func (d *Dispatcher) release(id influxdb.ID) {
+	if p, ok := d.pending[id]; ok {
+		p.timer.Stop()
+		delete(d.pending, id)
+	}
+	delete(d.lastRun, id)
+
+	t, ok := d.tasks[id]
+	if !ok {
+		return
+	}
+	delete(d.tasks, id)
+
+	triggers := d.triggers[t.orgID][t.bucket]
+	for i := range triggers {
+		if triggers[i].taskID == id {
+			triggers = append(triggers[:i], triggers[i+1:]...)
+			break
+		}
+	}
+	if len(triggers) > 0 {
+		d.triggers[t.orgID][t.bucket] = triggers
+		return
+	}
+	delete(d.triggers[t.orgID], t.bucket)
+	if len(d.triggers[t.orgID]) == 0 {
+		delete(d.triggers, t.orgID)
+	}
+}
+
+// PointsWritten schedules the runs of the tasks triggered by the points
+// written to a bucket.
+func (d *Dispatcher) PointsWritten(ctx context.Context, orgID, bucketID influxdb.ID, points []models.Point) {
+	d.mu.Lock()
+	n := len(d.triggers[orgID])
+	d.mu.Unlock()
+	if n == 0 || len(points) == 0 {
+		return
+	}
+
+	name, err := d.bucketName(ctx, bucketID)
+	if err != nil {
+		d.log.Debug("Failed to find bucket of triggering write", zap.String("bucketID", bucketID.String()), zap.Error(err))
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, t := range d.triggers[orgID][name] {
+		if t.measurement == "" || hasMeasurement(points, t.measurement) {
+			d.schedule(t.taskID)
+		}
+	}
+}
+
+// bucketName returns the name of a bucket, looking it up only once per
+// bucketNameTTL rather than on every write.
+func (d *Dispatcher) bucketName(ctx context.Context, id influxdb.ID) (string, error) {
+	now := time.Now()
+
+	d.mu.Lock()
+	cached, ok := d.bucketNames[id]
+	d.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.name, nil
+	}
+
+	b, err := d.buckets.FindBucketByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	d.mu.Lock()
+	d.bucketNames[id] = bucketName{name: b.Name, expires: now.Add(bucketNameTTL)}
+	d.mu.Unlock()
+	return b.Name, nil
+}
+
+func hasMeasurement(points []models.Point, measurement string) bool {
+	for _, p := range points {
+		if string(p.Name()) == measurement {
+			return true
+		}
+	}
+	return false
+}
+
+// schedule runs a task after the debounce duration, or delays its pending run.
+func (d *Dispatcher) schedule(id influxdb.ID) {
+	now := time.Now()
+
+	p, ok := d.pending[id]
+	if !ok {
+		p = &pendingRun{first: now}
+		p.timer = time.AfterFunc(d.debounce, func() { d.run(id, p) })
+		d.pending[id] = p
+		return
+	}
+
+	delay := d.debounce
+	if remaining := p.first.Add(d.maxDelay).Sub(now); remaining < delay {
+		delay = remaining
+	}
+	// a timer that already fired runs again, which run ignores
+	p.timer.Reset(delay)
+}
+
+// run executes the pending run of a task.
+func (d *Dispatcher) run(id influxdb.ID, p *pendingRun) {
+	d.mu.Lock()
+	if d.pending[id] != p {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.pending, id)
+
+	now := time.Now()
+	scheduledFor := now.UTC().Truncate(time.Second)
+	// keep the scheduled times of the runs of a task distinct
+	if last, ok := d.lastRun[id]; ok && !scheduledFor.After(last) {
+		scheduledFor = last.Add(time.Second)
+	}
+	d.lastRun[id] = scheduledFor
+	d.mu.Unlock()
+
+	if err := d.ex.Execute(context.Background(), scheduler.ID(id), scheduledFor, now); err != nil {
+		d.log.Error("Failed to execute triggered task", zap.String("taskID", id.String()), zap.Error(err))
+	}
+}
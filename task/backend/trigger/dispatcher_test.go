@@ -0,0 +1,159 @@
+package trigger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/task/options"
+	"go.uber.org/zap/zaptest"
+)
+
+type executeCall struct {
+	id           scheduler.ID
+	scheduledFor time.Time
+}
+
+type executor struct {
+	calls chan executeCall
+}
+
+func (e *executor) Execute(_ context.Context, id scheduler.ID, scheduledFor time.Time, _ time.Time) error {
+	e.calls <- executeCall{id: id, scheduledFor: scheduledFor}
+	return nil
+}
+
+func newTestDispatcher(t *testing.T, debounce, maxDelay time.Duration) (*Dispatcher, *executor) {
+	ex := &executor{calls: make(chan executeCall, 10)}
+	buckets := &mock.BucketService{
+		FindBucketByIDFn: func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+			names := map[influxdb.ID]string{1: "raw", 2: "other"}
+			return &influxdb.Bucket{ID: id, Name: names[id]}, nil
+		},
+	}
+	return NewDispatcher(zaptest.NewLogger(t), ex, buckets, WithDebounce(debounce, maxDelay)), ex
+}
+
+func points(measurement string) []models.Point {
+	return []models.Point{models.MustNewPoint(measurement, nil, models.Fields{"value": 1.0}, time.Now())}
+}
+
+func expectRuns(t *testing.T, ex *executor, n int, within time.Duration) []executeCall {
+	t.Helper()
+
+	var calls []executeCall
+	timeout := time.After(within)
+	for {
+		select {
+		case c := <-ex.calls:
+			calls = append(calls, c)
+		case <-timeout:
+			if len(calls) != n {
+				t.Fatalf("got %d runs, want %d", len(calls), n)
+			}
+			return calls
+		}
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	var (
+		orgID = influxdb.ID(10)
+		task  = &influxdb.Task{
+			ID:             1,
+			OrganizationID: orgID,
+			Status:         influxdb.TaskStatusActive,
+			Trigger:        &options.Trigger{Bucket: "raw", Measurement: "cpu"},
+		}
+	)
+
+	t.Run("batches writes", func(t *testing.T) {
+		d, ex := newTestDispatcher(t, 20*time.Millisecond, time.Second)
+		d.Register(task)
+
+		for i := 0; i < 5; i++ {
+			d.PointsWritten(context.Background(), orgID, 1, points("cpu"))
+		}
+		calls := expectRuns(t, ex, 1, 100*time.Millisecond)
+		if calls[0].id != scheduler.ID(task.ID) {
+			t.Fatalf("got run of task %d, want %d", calls[0].id, task.ID)
+		}
+
+		// the next batch is scheduled for a later time
+		d.PointsWritten(context.Background(), orgID, 1, points("cpu"))
+		next := expectRuns(t, ex, 1, 100*time.Millisecond)
+		if !next[0].scheduledFor.After(calls[0].scheduledFor) {
+			t.Fatalf("got run scheduled for %v after run scheduled for %v", next[0].scheduledFor, calls[0].scheduledFor)
+		}
+	})
+
+	t.Run("ignores other writes", func(t *testing.T) {
+		d, ex := newTestDispatcher(t, 10*time.Millisecond, time.Second)
+		d.Register(task)
+
+		d.PointsWritten(context.Background(), orgID, 1, points("mem"))
+		d.PointsWritten(context.Background(), orgID, 2, points("cpu"))
+		d.PointsWritten(context.Background(), orgID+1, 1, points("cpu"))
+		expectRuns(t, ex, 0, 50*time.Millisecond)
+	})
+
+	t.Run("max delay", func(t *testing.T) {
+		d, ex := newTestDispatcher(t, 30*time.Millisecond, 60*time.Millisecond)
+		d.Register(task)
+
+		// writes keep coming faster than the debounce
+		stop := time.Now().Add(150 * time.Millisecond)
+		for time.Now().Before(stop) {
+			d.PointsWritten(context.Background(), orgID, 1, points("cpu"))
+			time.Sleep(10 * time.Millisecond)
+		}
+		if len(ex.calls) == 0 {
+			t.Fatal("expected writes to run the task before the max delay")
+		}
+	})
+
+	t.Run("release", func(t *testing.T) {
+		d, ex := newTestDispatcher(t, 20*time.Millisecond, time.Second)
+		d.Register(task)
+
+		d.PointsWritten(context.Background(), orgID, 1, points("cpu"))
+		d.Release(task.ID)
+		d.PointsWritten(context.Background(), orgID, 1, points("cpu"))
+		expectRuns(t, ex, 0, 50*time.Millisecond)
+	})
+
+	t.Run("inactive task", func(t *testing.T) {
+		d, ex := newTestDispatcher(t, 10*time.Millisecond, time.Second)
+		inactive := *task
+		inactive.Status = influxdb.TaskStatusInactive
+		d.Register(task)
+		d.Register(&inactive)
+
+		d.PointsWritten(context.Background(), orgID, 1, points("cpu"))
+		expectRuns(t, ex, 0, 50*time.Millisecond)
+	})
+	t.Run("caches bucket names", func(t *testing.T) {
+		ex := &executor{calls: make(chan executeCall, 10)}
+		lookups := 0
+		buckets := &mock.BucketService{
+			FindBucketByIDFn: func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+				lookups++
+				return &influxdb.Bucket{ID: id, Name: "raw"}, nil
+			},
+		}
+		d := NewDispatcher(zaptest.NewLogger(t), ex, buckets, WithDebounce(10*time.Millisecond, time.Second))
+		d.Register(task)
+
+		for i := 0; i < 5; i++ {
+			d.PointsWritten(context.Background(), orgID, 1, points("cpu"))
+		}
+		expectRuns(t, ex, 1, 50*time.Millisecond)
+		if lookups != 1 {
+			t.Fatalf("got %d bucket lookups, want 1", lookups)
+		}
+	})
+}
@@ -40,6 +40,20 @@ type Options struct {
 	Concurrency *int64 `json:"concurrency,omitempty"`
 
 	Retry *int64 `json:"retry,omitempty"`
+
+	// Trigger runs the task when data is written to a bucket, in addition to
+	// or in place of Cron and Every.
+	Trigger *Trigger `json:"trigger,omitempty"`
+}
+
+// Trigger describes the writes that trigger a run of a task.
+type Trigger struct {
+	// Bucket is the name of the bucket written to.
+	Bucket string `json:"bucket"`
+
+	// Measurement, when set, limits the triggering writes to the ones with
+	// points of the measurement.
+	Measurement string `json:"measurement,omitempty"`
 }
 
 // Duration is a time span that supports the same units as the flux parser's time duration, as well as negative length time spans.
@@ -118,6 +132,7 @@ func (o *Options) Clear() {
 	o.Offset = nil
 	o.Concurrency = nil
 	o.Retry = nil
+	o.Trigger = nil
 }
 
 // IsZero tells us if the options has been zeroed out.
@@ -127,7 +142,8 @@ func (o *Options) IsZero() bool {
 		o.Every.IsZero() &&
 		(o.Offset == nil || o.Offset.IsZero()) &&
 		o.Concurrency == nil &&
-		o.Retry == nil
+		o.Retry == nil &&
+		o.Trigger == nil
 }
 
 // All the task option names we accept.
@@ -138,6 +154,10 @@ const (
 	optOffset      = "offset"
 	optConcurrency = "concurrency"
 	optRetry       = "retry"
+	optTrigger     = "trigger"
+
+	optTriggerBucket      = "bucket"
+	optTriggerMeasurement = "measurement"
 )
 
 // contains is a helper function to see if an array of strings contains a string
@@ -277,6 +297,7 @@ type extractFn func(*Options, *ast.ObjectExpression) error
 
 var taskOptionExtractors = []extractFn{
 	extractNameOption,
+	extractTriggerOption,
 	extractScheduleOptions,
 	extractOffsetOption,
 	extractConcurrencyOption,
@@ -304,6 +325,9 @@ func extractScheduleOptions(opts *Options, objExpr *ast.ObjectExpression) error
 		return ErrDuplicateIntervalField
 	}
 	if cronErr != nil && everyErr != nil {
+		if opts.Trigger != nil {
+			return nil
+		}
 		return errMissingRequiredTaskOption("cron or every")
 	}
 
@@ -326,6 +350,36 @@ func extractScheduleOptions(opts *Options, objExpr *ast.ObjectExpression) error
 	return nil
 }
 
+func extractTriggerOption(opts *Options, objExpr *ast.ObjectExpression) error {
+	triggerExpr, err := edit.GetProperty(objExpr, optTrigger)
+	if err != nil {
+		return nil
+	}
+
+	triggerObj, ok := triggerExpr.(*ast.ObjectExpression)
+	if !ok {
+		return errParseTaskOptionField(optTrigger)
+	}
+
+	opts.Trigger = &Trigger{}
+	for _, prop := range triggerObj.Properties {
+		str, ok := prop.Value.(*ast.StringLiteral)
+		if !ok {
+			return errParseTaskOptionField(optTrigger)
+		}
+		switch prop.Key.Key() {
+		case optTriggerBucket:
+			opts.Trigger.Bucket = ast.StringFromLiteral(str)
+		case optTriggerMeasurement:
+			opts.Trigger.Measurement = ast.StringFromLiteral(str)
+		default:
+			return errParseTaskOptionField(optTrigger)
+		}
+	}
+
+	return nil
+}
+
 func extractOffsetOption(opts *Options, objExpr *ast.ObjectExpression) error {
 	offsetExpr, offsetErr := edit.GetProperty(objExpr, optOffset)
 	if offsetErr != nil {
@@ -425,7 +479,15 @@ func FromScript(lang FluxLanguageService, script string) (Options, error) {
 		return opt, ErrDuplicateIntervalField
 	}
 
-	if !cronOK && !everyOK {
+	if triggerVal, ok := optObject.Get(optTrigger); ok {
+		trigger, err := triggerFromValue(triggerVal)
+		if err != nil {
+			return opt, err
+		}
+		opt.Trigger = trigger
+	}
+
+	if !cronOK && !everyOK && opt.Trigger == nil {
 		return opt, errMissingRequiredTaskOption("cron or every is required")
 	}
 
@@ -498,6 +560,37 @@ func FromScript(lang FluxLanguageService, script string) (Options, error) {
 	return opt, nil
 }
 
+// triggerFromValue reads the trigger option from its evaluated object.
+func triggerFromValue(v values.Value) (*Trigger, error) {
+	if err := checkNature(v.Type().Nature(), semantic.Object); err != nil {
+		return nil, err
+	}
+
+	trigger := &Trigger{}
+	var err error
+	v.Object().Range(func(name string, v values.Value) {
+		if err != nil {
+			return
+		}
+		if v.Type().Nature() != semantic.String {
+			err = errParseTaskOptionField(optTrigger)
+			return
+		}
+		switch name {
+		case optTriggerBucket:
+			trigger.Bucket = v.Str()
+		case optTriggerMeasurement:
+			trigger.Measurement = v.Str()
+		default:
+			err = errParseTaskOptionField(optTrigger)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trigger, nil
+}
+
 // Validate returns an error if the options aren't valid.
 func (o *Options) Validate() error {
 	now := time.Now()
@@ -508,8 +601,8 @@ func (o *Options) Validate() error {
 
 	cronPresent := o.Cron != ""
 	everyPresent := !o.Every.IsZero()
-	if cronPresent == everyPresent {
-		// They're both present or both missing.
+	if cronPresent == everyPresent && (cronPresent || o.Trigger == nil) {
+		// They're both present or both missing without a trigger.
 		errs = append(errs, "must specify exactly one of either cron or every")
 	} else if cronPresent {
 		_, err := cron.ParseUTC(o.Cron)
@@ -537,6 +630,9 @@ func (o *Options) Validate() error {
 			errs = append(errs, "offset option must be expressible as whole seconds")
 		}
 	}
+	if o.Trigger != nil && o.Trigger.Bucket == "" {
+		errs = append(errs, "trigger bucket required")
+	}
 	if o.Concurrency != nil {
 		if *o.Concurrency < 1 {
 			errs = append(errs, "concurrency must be at least 1")
@@ -592,7 +688,7 @@ func validateOptionNames(o values.Object) error {
 	var unexpected []string
 	o.Range(func(name string, _ values.Value) {
 		switch name {
-		case optName, optCron, optEvery, optOffset, optConcurrency, optRetry:
+		case optName, optCron, optEvery, optOffset, optConcurrency, optRetry, optTrigger:
 			// Known option. Nothing to do.
 		default:
 			unexpected = append(unexpected, name)
@@ -601,7 +697,7 @@ func validateOptionNames(o values.Object) error {
 
 	if len(unexpected) > 0 {
 		u := strings.Join(unexpected, ", ")
-		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optConcurrency, optRetry}, ", ")
+		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optConcurrency, optRetry, optTrigger}, ", ")
 		return fmt.Errorf("unknown task option(s): %s. valid options are %s", u, v)
 	}
 
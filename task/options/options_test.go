@@ -36,6 +36,9 @@ func scriptGenerator(opt options.Options, body string) string {
 	if opt.Retry != nil && *opt.Retry != 0 {
 		taskData = fmt.Sprintf("%s  retry: %d,\n", taskData, *opt.Retry)
 	}
+	if opt.Trigger != nil {
+		taskData = fmt.Sprintf("%s  trigger: {bucket: %q, measurement: %q},\n", taskData, opt.Trigger.Bucket, opt.Trigger.Measurement)
+	}
 	if body == "" {
 		body = `from(bucket: "test")
     |> range(start:-1h)`
@@ -104,6 +107,12 @@ func TestFromScriptAST(t *testing.T) {
 		},
 		{script: "option task = {name:\"test_task_smoke_name\", every:30s} from(bucket:\"test_tasks_smoke_bucket_source\") |> range(start: -1h) |> map(fn: (r) => ({r with _time: r._time, _value:r._value, t : \"quality_rocks\"}))|> to(bucket:\"test_tasks_smoke_bucket_dest\", orgID:\"3e73e749495d37d5\")",
 			exp: options.Options{Name: "test_task_smoke_name", Every: *(options.MustParseDuration("30s")), Retry: pointer.Int64(1), Concurrency: pointer.Int64(1)}, shouldErr: false}, // TODO(docmerlin): remove this once tasks fully supports all flux duration units.
+		{script: scriptGenerator(options.Options{Name: "name12", Trigger: &options.Trigger{Bucket: "raw", Measurement: "cpu"}}, ""),
+			exp: options.Options{Name: "name12", Trigger: &options.Trigger{Bucket: "raw", Measurement: "cpu"}, Concurrency: pointer.Int64(1), Retry: pointer.Int64(1)}},
+		{script: scriptGenerator(options.Options{Name: "name13", Every: *(options.MustParseDuration("1h")), Trigger: &options.Trigger{Bucket: "raw"}}, ""),
+			exp: options.Options{Name: "name13", Every: *(options.MustParseDuration("1h")), Trigger: &options.Trigger{Bucket: "raw"}, Concurrency: pointer.Int64(1), Retry: pointer.Int64(1)}},
+		{script: scriptGenerator(options.Options{Name: "name14", Trigger: &options.Trigger{Measurement: "cpu"}}, ""), shouldErr: true},
+		{script: "option task = {name: \"name15\", trigger: {bucket: \"raw\", field: \"usage\"}} from(bucket: \"raw\") |> range(start: -1h)", shouldErr: true},
 
 	} {
 		o, err := options.FromScriptAST(fluxlang.DefaultService, c.script)
@@ -170,6 +179,12 @@ func TestFromScript(t *testing.T) {
 		},
 		{script: "option task = {name:\"test_task_smoke_name\", every:30s} from(bucket:\"test_tasks_smoke_bucket_source\") |> range(start: -1h) |> map(fn: (r) => ({r with _time: r._time, _value:r._value, t : \"quality_rocks\"}))|> to(bucket:\"test_tasks_smoke_bucket_dest\", orgID:\"3e73e749495d37d5\")",
 			exp: options.Options{Name: "test_task_smoke_name", Every: *(options.MustParseDuration("30s")), Retry: pointer.Int64(1), Concurrency: pointer.Int64(1)}, shouldErr: false}, // TODO(docmerlin): remove this once tasks fully supports all flux duration units.
+		{script: scriptGenerator(options.Options{Name: "name12", Trigger: &options.Trigger{Bucket: "raw", Measurement: "cpu"}}, ""),
+			exp: options.Options{Name: "name12", Trigger: &options.Trigger{Bucket: "raw", Measurement: "cpu"}, Concurrency: pointer.Int64(1), Retry: pointer.Int64(1)}},
+		{script: scriptGenerator(options.Options{Name: "name13", Every: *(options.MustParseDuration("1h")), Trigger: &options.Trigger{Bucket: "raw"}}, ""),
+			exp: options.Options{Name: "name13", Every: *(options.MustParseDuration("1h")), Trigger: &options.Trigger{Bucket: "raw"}, Concurrency: pointer.Int64(1), Retry: pointer.Int64(1)}},
+		{script: scriptGenerator(options.Options{Name: "name14", Trigger: &options.Trigger{Measurement: "cpu"}}, ""), shouldErr: true},
+		{script: "option task = {name: \"name15\", trigger: {bucket: \"raw\", field: \"usage\"}} from(bucket: \"raw\") |> range(start: -1h)", shouldErr: true},
 
 	} {
 		o, err := options.FromScript(fluxlang.DefaultService, c.script)
@@ -215,7 +230,7 @@ func TestFromScriptWithUnknownOptions(t *testing.T) {
 		t.Errorf("expected error to mention unrecognized options, but it said: %v", err)
 	}
 
-	validOpts := []string{"name", "cron", "every", "offset", "concurrency", "retry"}
+	validOpts := []string{"name", "cron", "every", "offset", "concurrency", "retry", "trigger"}
 	for _, o := range validOpts {
 		if !strings.Contains(msg, o) {
 			t.Errorf("expected error to mention valid option %q but it said: %v", o, err)
@@ -291,6 +306,13 @@ func TestValidate(t *testing.T) {
 		t.Error("expected error for retry too large")
 	}
 
+	*bad = good
+	bad.Cron = ""
+	bad.Trigger = &options.Trigger{Measurement: "cpu"}
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for trigger without bucket")
+	}
+
 	notbad := new(options.Options)
 	*notbad = good
 	notbad.Cron = ""
@@ -299,6 +321,13 @@ func TestValidate(t *testing.T) {
 		t.Error("expected no error for days every")
 	}
 
+	*notbad = good
+	notbad.Cron = ""
+	notbad.Trigger = &options.Trigger{Bucket: "raw"}
+	if err := notbad.Validate(); err != nil {
+		t.Error("expected no error for trigger without cron or every")
+	}
+
 }
 
 func TestEffectiveCronString(t *testing.T) {
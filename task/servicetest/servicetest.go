@@ -98,6 +98,16 @@ func TestTaskService(t *testing.T, fn BackendComponentFactory, testCategory ...s
 					testTaskType(t, sys)
 				})
 
+				t.Run("Task Dependencies", func(t *testing.T) {
+					t.Parallel()
+					testTaskDependencies(t, sys)
+				})
+
+				t.Run("Task Trigger", func(t *testing.T) {
+					t.Parallel()
+					testTaskTrigger(t, sys)
+				})
+
 			})
 		case "analytical":
 			t.Run("AnalyticalTaskService", func(t *testing.T) {
@@ -1321,6 +1331,107 @@ func testManualRun(t *testing.T, s *System) {
 	}
 }
 
+func testTaskDependencies(t *testing.T, sys *System) {
+	cr := creds(t, sys)
+	authorizedCtx := icontext.SetAuthorizer(sys.Ctx, cr.Authorizer())
+
+	upstream, err := sys.TaskService.CreateTask(authorizedCtx, influxdb.TaskCreate{
+		OrganizationID: cr.OrgID,
+		Flux:           fmt.Sprintf(scriptFmt, 0),
+		OwnerID:        cr.UserID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	downstream, err := sys.TaskService.CreateTask(authorizedCtx, influxdb.TaskCreate{
+		OrganizationID: cr.OrgID,
+		Flux:           fmt.Sprintf(scriptFmt, 1),
+		OwnerID:        cr.UserID,
+		DependsOn:      []influxdb.ID{upstream.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := sys.TaskService.FindTaskByID(authorizedCtx, downstream.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]influxdb.ID{upstream.ID}, found.DependsOn); diff != "" {
+		t.Fatalf("unexpected upstream tasks: %s", diff)
+	}
+
+	// a task cannot depend on a missing task
+	if _, err := sys.TaskService.CreateTask(authorizedCtx, influxdb.TaskCreate{
+		OrganizationID: cr.OrgID,
+		Flux:           fmt.Sprintf(scriptFmt, 2),
+		OwnerID:        cr.UserID,
+		DependsOn:      []influxdb.ID{influxdb.ID(math.MaxUint64 - 1)},
+	}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid dependency error, got %v", err)
+	}
+
+	// nor form a cycle
+	for _, upd := range []struct {
+		id        influxdb.ID
+		dependsOn []influxdb.ID
+	}{
+		{id: upstream.ID, dependsOn: []influxdb.ID{downstream.ID}},
+		{id: downstream.ID, dependsOn: []influxdb.ID{downstream.ID}},
+	} {
+		_, err := sys.TaskService.UpdateTask(authorizedCtx, upd.id, influxdb.TaskUpdate{DependsOn: &upd.dependsOn})
+		if influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Fatalf("expected dependency cycle error, got %v", err)
+		}
+	}
+
+	none := []influxdb.ID{}
+	updated, err := sys.TaskService.UpdateTask(authorizedCtx, downstream.ID, influxdb.TaskUpdate{DependsOn: &none})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.DependsOn) != 0 {
+		t.Fatalf("expected no upstream tasks, got %v", updated.DependsOn)
+	}
+}
+
+func testTaskTrigger(t *testing.T, sys *System) {
+	cr := creds(t, sys)
+	authorizedCtx := icontext.SetAuthorizer(sys.Ctx, cr.Authorizer())
+
+	task, err := sys.TaskService.CreateTask(authorizedCtx, influxdb.TaskCreate{
+		OrganizationID: cr.OrgID,
+		Flux: `option task = {name: "triggered", trigger: {bucket: "raw", measurement: "cpu"}}
+from(bucket: "raw") |> range(start: -1m) |> to(bucket: "derived")`,
+		OwnerID: cr.UserID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := sys.TaskService.FindTaskByID(authorizedCtx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&options.Trigger{Bucket: "raw", Measurement: "cpu"}, found.Trigger); diff != "" {
+		t.Fatalf("unexpected trigger: %s", diff)
+	}
+	if found.Every != "" || found.Cron != "" {
+		t.Fatalf("expected no schedule, got every %q and cron %q", found.Every, found.Cron)
+	}
+
+	// the trigger is removed with the option
+	flux := fmt.Sprintf(scriptFmt, 0)
+	updated, err := sys.TaskService.UpdateTask(authorizedCtx, task.ID, influxdb.TaskUpdate{Flux: &flux})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Trigger != nil {
+		t.Fatalf("expected no trigger, got %+v", updated.Trigger)
+	}
+}
+
 func testRunStorage(t *testing.T, sys *System) {
 	cr := creds(t, sys)
 
@@ -88,8 +88,33 @@ var (
 		Code: EInvalid,
 		Msg:  "cannot create task with invalid ownerID",
 	}
+
+	// ErrTaskDependencyCycle is returned when a task would depend, directly or not, on itself.
+	ErrTaskDependencyCycle = &Error{
+		Code: EInvalid,
+		Msg:  "task dependencies cannot form a cycle",
+	}
 )
 
+// ErrInvalidTaskDependency is returned when a task depends on a task that does not exist
+// or belongs to another organization.
+func ErrInvalidTaskDependency(id ID) *Error {
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("upstream task %s must be a task of the same organization", id),
+	}
+}
+
+// ErrUpstreamTaskFailed is returned in the task executor when a run is not executed
+// because the run of an upstream task scheduled for the same time cannot succeed.
+func ErrUpstreamTaskFailed(id ID, reason string) *Error {
+	return &Error{
+		Code: EConflict,
+		Msg:  fmt.Sprintf("upstream task %s %s", id, reason),
+		Op:   "taskExecutor",
+	}
+}
+
 // ErrFluxParseError is returned when an error is thrown by Flux.Parse in the task executor
 func ErrFluxParseError(err error) *Error {
 	return &Error{
@@ -248,6 +248,50 @@ func AddTarget(
 				},
 			},
 		},
+		{
+			name: "create json target without json config",
+			fields: TargetFields{
+				IDGenerator:   mock.NewIDGenerator(targetTwoID, t),
+				Organizations: []*influxdb.Organization{&org1},
+				Targets: []*influxdb.ScraperTarget{
+					{
+						Name:     "name1",
+						Type:     influxdb.PrometheusScraperType,
+						OrgID:    idOne,
+						BucketID: idOne,
+						URL:      "url1",
+						ID:       MustIDBase16(targetOneID),
+					},
+				},
+			},
+			args: args{
+				target: &influxdb.ScraperTarget{
+					ID:       MustIDBase16(targetTwoID),
+					Name:     "name2",
+					Type:     influxdb.JSONScraperType,
+					OrgID:    idOne,
+					BucketID: idOne,
+					URL:      "http://localhost:8080/stats",
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "json scraper target requires a json config",
+					Op:   influxdb.OpAddTarget,
+				},
+				targets: []influxdb.ScraperTarget{
+					{
+						Name:     "name1",
+						Type:     influxdb.PrometheusScraperType,
+						OrgID:    idOne,
+						BucketID: idOne,
+						URL:      "url1",
+						ID:       MustIDBase16(targetOneID),
+					},
+				},
+			},
+		},
 		{
 			name: "basic create target",
 			fields: TargetFields{
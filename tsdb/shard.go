@@ -114,12 +114,35 @@ type PartialWriteError struct {
 
 	// A sorted slice of series keys that were dropped.
 	DroppedKeys [][]byte
+
+	// The points that were dropped and why, where known.
+	DroppedPoints []DroppedPoint
 }
 
 func (e PartialWriteError) Error() string {
 	return fmt.Sprintf("partial write: %s dropped=%d", e.Reason, e.Dropped)
 }
 
+// Merge adds the points dropped by e to err, which is nil or a
+// PartialWriteError of an earlier write, and returns the result. The reason
+// of err is kept.
+func (e PartialWriteError) Merge(err error) error {
+	merged, ok := err.(PartialWriteError)
+	if !ok {
+		return e
+	}
+	merged.Dropped += e.Dropped
+	merged.DroppedKeys = append(merged.DroppedKeys, e.DroppedKeys...)
+	merged.DroppedPoints = append(merged.DroppedPoints, e.DroppedPoints...)
+	return merged
+}
+
+// DroppedPoint is a point a write dropped and the reason it was dropped.
+type DroppedPoint struct {
+	Point  models.Point
+	Reason string
+}
+
 // Shard represents a self-contained time series database. An inverted index of
 // the measurement and tag data is kept along with the raw time series data.
 // Data can be split across many shards. The query engine in TSDB is responsible
@@ -540,6 +563,7 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 		err            error
 		dropped        int
 		reason         string // only first error reason is set unless returned from CreateSeriesListIfNotExists
+		droppedPoints  []DroppedPoint
 	)
 
 	// Create all series against the index in bulk.
@@ -557,20 +581,24 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 		// Drop any series w/ a "time" tag, these are illegal
 		if v := tags.Get(timeBytes); v != nil {
 			dropped++
+			pointReason := fmt.Sprintf(
+				"invalid tag key: input tag \"%s\" on measurement \"%s\" is invalid",
+				"time", string(p.Name()))
 			if reason == "" {
-				reason = fmt.Sprintf(
-					"invalid tag key: input tag \"%s\" on measurement \"%s\" is invalid",
-					"time", string(p.Name()))
+				reason = pointReason
 			}
+			droppedPoints = append(droppedPoints, DroppedPoint{Point: p, Reason: pointReason})
 			continue
 		}
 
 		// Drop any series with invalid unicode characters in the key.
 		if validateKeys && !models.ValidKeyTokens(string(p.Name()), tags) {
 			dropped++
+			pointReason := fmt.Sprintf("key contains invalid unicode: \"%s\"", string(p.Key()))
 			if reason == "" {
-				reason = fmt.Sprintf("key contains invalid unicode: \"%s\"", string(p.Key()))
+				reason = pointReason
 			}
+			droppedPoints = append(droppedPoints, DroppedPoint{Point: p, Reason: pointReason})
 			continue
 		}
 
@@ -588,7 +616,10 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 	}
 
 	// Add new series. Check for partial writes.
-	var droppedKeys [][]byte
+	var (
+		droppedKeys  [][]byte
+		seriesReason string
+	)
 	if err := engine.CreateSeriesListIfNotExists(keys, names, tagsSlice); err != nil {
 		switch err := err.(type) {
 		// TODO(jmw): why is this a *PartialWriteError when everything else is not a pointer?
@@ -596,6 +627,7 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 		// the places that construct it.
 		case *PartialWriteError:
 			reason = err.Reason
+			seriesReason = err.Reason
 			dropped += err.Dropped
 			droppedKeys = err.DroppedKeys
 			atomic.AddInt64(&s.stats.WritePointsDropped, int64(err.Dropped))
@@ -617,17 +649,20 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 			break
 		}
 		if !validField {
+			pointReason := fmt.Sprintf(
+				"invalid field name: input field \"%s\" on measurement \"%s\" is invalid",
+				"time", string(p.Name()))
 			if reason == "" {
-				reason = fmt.Sprintf(
-					"invalid field name: input field \"%s\" on measurement \"%s\" is invalid",
-					"time", string(p.Name()))
+				reason = pointReason
 			}
 			dropped++
+			droppedPoints = append(droppedPoints, DroppedPoint{Point: p, Reason: pointReason})
 			continue
 		}
 
 		// Skip any points whos keys have been dropped. Dropped has already been incremented for them.
 		if len(droppedKeys) > 0 && bytesutil.Contains(droppedKeys, keys[i]) {
+			droppedPoints = append(droppedPoints, DroppedPoint{Point: p, Reason: seriesReason})
 			continue
 		}
 
@@ -642,6 +677,7 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 					reason = err.Reason
 				}
 				dropped += err.Dropped
+				droppedPoints = append(droppedPoints, DroppedPoint{Point: p, Reason: err.Reason})
 				atomic.AddInt64(&s.stats.WritePointsDropped, int64(err.Dropped))
 			default:
 				return nil, nil, err
@@ -682,7 +718,7 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 	}
 
 	if dropped > 0 {
-		err = PartialWriteError{Reason: reason, Dropped: dropped}
+		err = PartialWriteError{Reason: reason, Dropped: dropped, DroppedPoints: droppedPoints}
 	}
 
 	return points[:j], fieldsToCreate, err
@@ -102,6 +102,24 @@ func TestShardWriteAndIndex(t *testing.T) {
 	}
 }
 
+func TestPartialWriteError_Merge(t *testing.T) {
+	first := tsdb.PartialWriteError{Reason: "field type conflict", Dropped: 1, DroppedKeys: [][]byte{[]byte("cpu")}}
+	second := tsdb.PartialWriteError{Reason: "max series exceeded", Dropped: 2, DroppedKeys: [][]byte{[]byte("mem"), []byte("disk")}}
+
+	if got := first.Merge(nil); !reflect.DeepEqual(got, first) {
+		t.Fatalf("got %#v, want %#v", got, first)
+	}
+
+	want := tsdb.PartialWriteError{
+		Reason:      "field type conflict",
+		Dropped:     3,
+		DroppedKeys: [][]byte{[]byte("cpu"), []byte("mem"), []byte("disk")},
+	}
+	if got := second.Merge(first); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
 func TestShard_Open_CorruptFieldsIndex(t *testing.T) {
 	tmpDir, _ := ioutil.TempDir("", "shard_test")
 	defer os.RemoveAll(tmpDir)
@@ -17,6 +17,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/influxql/query"
 	"github.com/influxdata/influxdb/v2/logger"
 	"github.com/influxdata/influxdb/v2/models"
@@ -1362,6 +1363,84 @@ func (s *Store) DeleteSeries(database string, sources []influxql.Source, conditi
 	})
 }
 
+// DeleteSeriesWithPredicate deletes the data in [min, max] of all series in
+// the database whose key matches pred. Keys are matched with the measurement
+// name stored under the models.MeasurementTagKey tag. A nil predicate matches
+// every series.
+func (s *Store) DeleteSeriesWithPredicate(database string, min, max int64, pred influxdb.Predicate) error {
+	s.mu.RLock()
+	if s.databases[database].hasMultipleIndexTypes() {
+		s.mu.RUnlock()
+		return ErrMultipleIndexTypes
+	}
+	sfile := s.sfiles[database]
+	if sfile == nil {
+		s.mu.RUnlock()
+		// No series file means nothing has been written to this DB and thus nothing to delete.
+		return nil
+	}
+	shards := s.filterShards(byDatabase(database))
+	epochs := s.epochsForShards(shards)
+	s.mu.RUnlock()
+
+	// Limit to 1 delete for each shard since expanding the measurement into the list
+	// of series keys can be very memory intensive if run concurrently.
+	limit := limiter.NewFixed(1)
+
+	return s.walkShards(shards, func(sh *Shard) error {
+		limit.Take()
+		defer limit.Release()
+
+		// install our guard and wait for any prior deletes to finish. the
+		// guard ensures future deletes that could conflict wait for us.
+		waiter := epochs[sh.id].WaitDelete(newGuard(min, max, nil, nil))
+		waiter.Wait()
+		defer waiter.Done()
+
+		index, err := sh.Index()
+		if err != nil {
+			return err
+		}
+
+		// Predicates keep matching state, so each shard requires its own copy.
+		var p influxdb.Predicate
+		if pred != nil {
+			p = pred.Clone()
+		}
+
+		var names [][]byte
+		if err := sh.ForEachMeasurementName(func(name []byte) error {
+			names = append(names, name)
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			itr, err := index.MeasurementSeriesIDIterator(name)
+			if err != nil {
+				return err
+			} else if itr == nil {
+				continue
+			}
+
+			err = sh.DeleteSeriesRangeWithPredicate(NewSeriesIteratorAdapter(sfile, itr), func(name []byte, tags models.Tags) (int64, int64, bool) {
+				if p == nil {
+					return min, max, true
+				}
+				tags = append(models.Tags{models.NewTag(models.MeasurementTagKeyBytes, name)}, tags...)
+				return min, max, p.Matches(models.MakeKey(nil, tags))
+			})
+			itr.Close()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 // ExpandSources expands sources against all local shards.
 func (s *Store) ExpandSources(sources influxql.Sources) (influxql.Sources, error) {
 	shards := func() Shards {
@@ -20,12 +20,14 @@ import (
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/influxql/query"
 	"github.com/influxdata/influxdb/v2/internal"
 	"github.com/influxdata/influxdb/v2/logger"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/pkg/deep"
 	"github.com/influxdata/influxdb/v2/pkg/slices"
+	"github.com/influxdata/influxdb/v2/predicate"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"github.com/influxdata/influxdb/v2/tsdb/index/inmem"
 	"github.com/influxdata/influxql"
@@ -392,6 +394,75 @@ func TestStore_DeleteSeries_NonExistentDB(t *testing.T) {
 	}
 }
 
+// Ensure the store can delete series matching a predicate.
+func TestStore_DeleteSeriesWithPredicate(t *testing.T) {
+
+	test := func(index string) error {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 0,
+			`cpu,host=serverA value=1 0`,
+			`cpu,host=serverB value=2 10`,
+			`mem,host=serverA value=3 20`,
+		)
+
+		pred, err := predicate.New(&predicate.LogicalNode{
+			Operator: predicate.LogicalAnd,
+			Children: [2]predicate.Node{
+				&predicate.TagRuleNode{Tag: influxdb.Tag{Key: "_measurement", Value: "cpu"}, Operator: influxdb.Equal},
+				&predicate.TagRuleNode{Tag: influxdb.Tag{Key: "host", Value: "serverA"}, Operator: influxdb.Equal},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := s.DeleteSeriesWithPredicate("db0", influxql.MinTime, influxql.MaxTime, pred); err != nil {
+			return err
+		}
+
+		// Use a series-level authorizer so that deleted series are filtered out.
+		authorizer := &internal.AuthorizerMock{
+			AuthorizeSeriesReadFn: func(database string, measurement []byte, tags models.Tags) bool {
+				return true
+			},
+		}
+
+		values, err := s.TagValues(authorizer, []uint64{0}, &influxql.BinaryExpr{
+			Op:  influxql.EQ,
+			LHS: &influxql.VarRef{Val: "_tagKey"},
+			RHS: &influxql.StringLiteral{Val: "host"},
+		})
+		if err != nil {
+			return err
+		}
+
+		got := make(map[string][]string)
+		for _, tv := range values {
+			for _, v := range tv.Values {
+				got[tv.Measurement] = append(got[tv.Measurement], v.Value)
+			}
+		}
+		exp := map[string][]string{
+			"cpu": {"serverB"},
+			"mem": {"serverA"},
+		}
+		if !reflect.DeepEqual(got, exp) {
+			return fmt.Errorf("unexpected tag values: got %v, exp %v", got, exp)
+		}
+		return nil
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) {
+			if err := test(index); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
 // Ensure the store can delete an existing shard.
 func TestStore_DeleteShard(t *testing.T) {
 
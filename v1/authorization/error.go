@@ -0,0 +1,71 @@
+package authorization
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrInvalidAuthID is used when the Authorization's ID cannot be encoded
+	ErrInvalidAuthID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "authorization ID is invalid",
+	}
+
+	// ErrAuthNotFound is used when the specified auth cannot be found
+	ErrAuthNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "authorization not found",
+	}
+
+	// ErrUsernameRequired is used when attempting to create an authorization
+	// without a username.
+	ErrUsernameRequired = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "username is required",
+	}
+
+	// ErrUsernameAlreadyExists is used when attempting to create an authorization
+	// with a username that already exists
+	ErrUsernameAlreadyExists = &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "username already exists",
+	}
+
+	// ErrFailureGeneratingID occurs ony when the random number generator
+	// cannot generate an ID in MaxIDGenerationN times.
+	ErrFailureGeneratingID = &influxdb.Error{
+		Code: influxdb.EInternal,
+		Msg:  "unable to generate valid id",
+	}
+
+	// EIncorrectPassword is returned when any password operation fails in which
+	// we do not want to leak information.
+	EIncorrectPassword = &influxdb.Error{
+		Code: influxdb.EForbidden,
+		Msg:  "your username or password is incorrect",
+	}
+
+	// ErrPasswordRequired is used when attempting to set an empty password.
+	ErrPasswordRequired = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "password is required",
+	}
+)
+
+// ErrInternalServiceError is used when the error comes from an internal system.
+func ErrInternalServiceError(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
+
+// UnexpectedAuthIndexError is used when the error comes from an internal system.
+func UnexpectedAuthIndexError(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Msg:  fmt.Sprintf("unexpected error retrieving auth index; Err: %v", err),
+	}
+}
@@ -0,0 +1,162 @@
+package authorization
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+)
+
+var _ AuthorizationService = (*Client)(nil)
+
+// Client connects to Influx via HTTP using tokens to manage the authorizations of v1 users.
+type Client struct {
+	Client *httpc.Client
+}
+
+// CreateAuthorization creates a new authorization and sets a.ID with the new identifier.
+func (s *Client) CreateAuthorization(ctx context.Context, a *influxdb.Authorization) error {
+	req := &postAuthorizationRequest{
+		Token:       a.Token,
+		Status:      a.Status,
+		OrgID:       a.OrgID,
+		Description: a.Description,
+		Permissions: a.Permissions,
+	}
+	if a.UserID.Valid() {
+		req.UserID = &a.UserID
+	}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	var res authResponse
+	err := s.Client.
+		PostJSON(req, prefixAuthorization).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	*a = *res.toInfluxdb()
+	return nil
+}
+
+// FindAuthorizations returns a list of authorizations that match filter and the total count of matching authorizations.
+func (s *Client) FindAuthorizations(ctx context.Context, filter influxdb.AuthorizationFilter, opt ...influxdb.FindOptions) ([]*influxdb.Authorization, int, error) {
+	var params [][2]string
+	if filter.ID != nil {
+		params = append(params, [2]string{"id", filter.ID.String()})
+	}
+	if filter.Token != nil {
+		params = append(params, [2]string{"token", *filter.Token})
+	}
+	if filter.UserID != nil {
+		params = append(params, [2]string{"userID", filter.UserID.String()})
+	}
+	if filter.User != nil {
+		params = append(params, [2]string{"user", *filter.User})
+	}
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+	if filter.Org != nil {
+		params = append(params, [2]string{"org", *filter.Org})
+	}
+
+	var as authsResponse
+	err := s.Client.
+		Get(prefixAuthorization).
+		QueryParams(params...).
+		DecodeJSON(&as).
+		Do(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	auths := make([]*influxdb.Authorization, 0, len(as.Auths))
+	for _, a := range as.Auths {
+		auths = append(auths, a.toInfluxdb())
+	}
+
+	return auths, len(auths), nil
+}
+
+// FindAuthorizationByToken finds the authorization of the v1 user named token.
+func (s *Client) FindAuthorizationByToken(ctx context.Context, token string) (*influxdb.Authorization, error) {
+	as, _, err := s.FindAuthorizations(ctx, influxdb.AuthorizationFilter{Token: &token})
+	if err != nil {
+		return nil, err
+	}
+	if len(as) == 0 {
+		return nil, ErrAuthNotFound
+	}
+	return as[0], nil
+}
+
+// FindAuthorizationByID finds a single Authorization by its ID against a remote influx server.
+func (s *Client) FindAuthorizationByID(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+	var res authResponse
+	err := s.Client.
+		Get(prefixAuthorization, id.String()).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.toInfluxdb(), nil
+}
+
+// UpdateAuthorization updates the status and description if available.
+func (s *Client) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
+	return s.patchAuthorization(ctx, id, &updateAuthorizationRequest{
+		Status:      upd.Status,
+		Description: upd.Description,
+	})
+}
+
+// SetPermissions replaces the permissions of an authorization.
+func (s *Client) SetPermissions(ctx context.Context, id influxdb.ID, permissions []influxdb.Permission) (*influxdb.Authorization, error) {
+	if permissions == nil {
+		permissions = []influxdb.Permission{}
+	}
+	return s.patchAuthorization(ctx, id, &updateAuthorizationRequest{
+		Permissions: &permissions,
+	})
+}
+
+func (s *Client) patchAuthorization(ctx context.Context, id influxdb.ID, upd *updateAuthorizationRequest) (*influxdb.Authorization, error) {
+	var res authResponse
+	err := s.Client.
+		PatchJSON(upd, prefixAuthorization, id.String()).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.toInfluxdb(), nil
+}
+
+// DeleteAuthorization removes a authorization by id.
+func (s *Client) DeleteAuthorization(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(prefixAuthorization, id.String()).
+		Do(ctx)
+}
+
+// SetPassword overrides the password of an authorization.
+func (s *Client) SetPassword(ctx context.Context, id influxdb.ID, password string) error {
+	return s.Client.
+		PostJSON(passwordSetRequest{Password: password}, prefixAuthorization, id.String(), "password").
+		Do(ctx)
+}
+
+// ComparePassword is not supported by the HTTP client.
+func (s *Client) ComparePassword(ctx context.Context, id influxdb.ID, password string) error {
+	return &influxdb.Error{
+		Code: influxdb.EMethodNotAllowed,
+		Msg:  "not supported in HTTP legacy authorization service",
+	}
+}
@@ -0,0 +1,416 @@
+package authorization
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const prefixAuthorization = "/api/v2/legacy/authorizations"
+
+// AuthHandler is the http handler of the authorizations of v1 users.
+type AuthHandler struct {
+	chi.Router
+	api           *kithttp.API
+	log           *zap.Logger
+	authSvc       AuthorizationService
+	tenantService TenantService
+}
+
+// NewHTTPAuthHandler constructs a new http server.
+func NewHTTPAuthHandler(log *zap.Logger, authService AuthorizationService, tenantService TenantService) *AuthHandler {
+	h := &AuthHandler{
+		api:           kithttp.NewAPI(kithttp.WithLog(log)),
+		log:           log,
+		authSvc:       authService,
+		tenantService: tenantService,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Post("/", h.handlePostAuthorization)
+		r.Get("/", h.handleGetAuthorizations)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetAuthorization)
+			r.Patch("/", h.handleUpdateAuthorization)
+			r.Delete("/", h.handleDeleteAuthorization)
+			r.Post("/password", h.handlePostPassword)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+func (h *AuthHandler) Prefix() string {
+	return prefixAuthorization
+}
+
+type postAuthorizationRequest struct {
+	Token       string                `json:"token"`
+	Status      influxdb.Status       `json:"status"`
+	OrgID       influxdb.ID           `json:"orgID"`
+	UserID      *influxdb.ID          `json:"userID,omitempty"`
+	Description string                `json:"description"`
+	Permissions []influxdb.Permission `json:"permissions"`
+}
+
+func (p *postAuthorizationRequest) Validate() error {
+	if p.Token == "" {
+		return ErrUsernameRequired
+	}
+
+	for _, perm := range p.Permissions {
+		if err := perm.Valid(); err != nil {
+			return &influxdb.Error{
+				Err: err,
+			}
+		}
+	}
+
+	if !p.OrgID.Valid() {
+		return &influxdb.Error{
+			Err:  influxdb.ErrInvalidID,
+			Code: influxdb.EInvalid,
+			Msg:  "org id required",
+		}
+	}
+
+	if p.Status == "" {
+		p.Status = influxdb.Active
+	}
+
+	return p.Status.Valid()
+}
+
+func (p *postAuthorizationRequest) toInfluxdb(userID influxdb.ID) *influxdb.Authorization {
+	return &influxdb.Authorization{
+		Token:       p.Token,
+		OrgID:       p.OrgID,
+		Status:      p.Status,
+		Description: p.Description,
+		Permissions: p.Permissions,
+		UserID:      userID,
+	}
+}
+
+type authResponse struct {
+	ID          influxdb.ID           `json:"id"`
+	Token       string                `json:"token"`
+	Status      influxdb.Status       `json:"status"`
+	Description string                `json:"description"`
+	OrgID       influxdb.ID           `json:"orgID"`
+	UserID      influxdb.ID           `json:"userID"`
+	Permissions []influxdb.Permission `json:"permissions"`
+	Links       map[string]string     `json:"links"`
+	CreatedAt   time.Time             `json:"createdAt"`
+	UpdatedAt   time.Time             `json:"updatedAt"`
+}
+
+func newAuthResponse(a *influxdb.Authorization) *authResponse {
+	ps := a.Permissions
+	if ps == nil {
+		ps = []influxdb.Permission{}
+	}
+	return &authResponse{
+		ID:          a.ID,
+		Token:       a.Token,
+		Status:      a.Status,
+		Description: a.Description,
+		OrgID:       a.OrgID,
+		UserID:      a.UserID,
+		Permissions: ps,
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s/%s", prefixAuthorization, a.ID),
+			"user": fmt.Sprintf("/api/v2/users/%s", a.UserID),
+		},
+		CreatedAt: a.CreatedAt,
+		UpdatedAt: a.UpdatedAt,
+	}
+}
+
+func (a *authResponse) toInfluxdb() *influxdb.Authorization {
+	return &influxdb.Authorization{
+		ID:          a.ID,
+		Token:       a.Token,
+		Status:      a.Status,
+		Description: a.Description,
+		OrgID:       a.OrgID,
+		UserID:      a.UserID,
+		Permissions: a.Permissions,
+		CRUDLog: influxdb.CRUDLog{
+			CreatedAt: a.CreatedAt,
+			UpdatedAt: a.UpdatedAt,
+		},
+	}
+}
+
+type authsResponse struct {
+	Links map[string]string `json:"links"`
+	Auths []*authResponse   `json:"authorizations"`
+}
+
+// handlePostAuthorization is the HTTP handler for the POST /api/v2/legacy/authorizations route.
+func (h *AuthHandler) handlePostAuthorization(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postAuthorizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	userID, err := h.authorizedUserID(ctx)
+	if err != nil {
+		h.api.Err(w, r, influxdb.ErrUnableToCreateToken)
+		return
+	}
+	if req.UserID != nil && req.UserID.Valid() {
+		userID = *req.UserID
+	}
+
+	auth := req.toInfluxdb(userID)
+	if err := h.authSvc.CreateAuthorization(ctx, auth); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Legacy auth created ", zap.String("auth", fmt.Sprint(auth)))
+
+	h.api.Respond(w, r, http.StatusCreated, newAuthResponse(auth))
+}
+
+func (h *AuthHandler) authorizedUserID(ctx context.Context) (influxdb.ID, error) {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	u, err := h.tenantService.FindUserByID(ctx, a.GetUserID())
+	if err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+// handleGetAuthorizations is the HTTP handler for the GET /api/v2/legacy/authorizations route.
+func (h *AuthHandler) handleGetAuthorizations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := h.decodeAuthorizationFilter(ctx, r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	as, _, err := h.authSvc.FindAuthorizations(ctx, filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	auths := make([]*authResponse, 0, len(as))
+	for _, a := range as {
+		auths = append(auths, newAuthResponse(a))
+	}
+	h.log.Debug("Legacy auths retrieved ", zap.String("auths", fmt.Sprint(auths)))
+
+	h.api.Respond(w, r, http.StatusOK, &authsResponse{
+		Links: map[string]string{
+			"self": prefixAuthorization,
+		},
+		Auths: auths,
+	})
+}
+
+func (h *AuthHandler) decodeAuthorizationFilter(ctx context.Context, r *http.Request) (influxdb.AuthorizationFilter, error) {
+	qp := r.URL.Query()
+
+	var filter influxdb.AuthorizationFilter
+	if userID := qp.Get("userID"); userID != "" {
+		id, err := influxdb.IDFromString(userID)
+		if err != nil {
+			return filter, err
+		}
+		filter.UserID = id
+	} else if user := qp.Get("user"); user != "" {
+		u, err := h.tenantService.FindUser(ctx, influxdb.UserFilter{Name: &user})
+		if err != nil {
+			return filter, err
+		}
+		filter.UserID = &u.ID
+	}
+
+	if orgID := qp.Get("orgID"); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			return filter, err
+		}
+		filter.OrgID = id
+	} else if org := qp.Get("org"); org != "" {
+		o, err := h.tenantService.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &org})
+		if err != nil {
+			return filter, err
+		}
+		filter.OrgID = &o.ID
+	}
+
+	if authID := qp.Get("id"); authID != "" {
+		id, err := influxdb.IDFromString(authID)
+		if err != nil {
+			return filter, err
+		}
+		filter.ID = id
+	}
+
+	if token := qp.Get("token"); token != "" {
+		filter.Token = &token
+	}
+
+	return filter, nil
+}
+
+// handleGetAuthorization is the HTTP handler for the GET /api/v2/legacy/authorizations/:id route.
+func (h *AuthHandler) handleGetAuthorization(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	a, err := h.authSvc.FindAuthorizationByID(ctx, *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Legacy auth retrieved ", zap.String("auth", fmt.Sprint(a)))
+
+	h.api.Respond(w, r, http.StatusOK, newAuthResponse(a))
+}
+
+// updateAuthorizationRequest updates the status, description and
+// permissions of an authorization.
+type updateAuthorizationRequest struct {
+	Status      *influxdb.Status       `json:"status,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	Permissions *[]influxdb.Permission `json:"permissions,omitempty"`
+}
+
+// handleUpdateAuthorization is the HTTP handler for the PATCH /api/v2/legacy/authorizations/:id route.
+func (h *AuthHandler) handleUpdateAuthorization(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var req updateAuthorizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		})
+		return
+	}
+
+	a, err := h.authSvc.UpdateAuthorization(ctx, *id, &influxdb.AuthorizationUpdate{
+		Status:      req.Status,
+		Description: req.Description,
+	})
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if req.Permissions != nil {
+		for _, perm := range *req.Permissions {
+			if err := perm.Valid(); err != nil {
+				h.api.Err(w, r, &influxdb.Error{Err: err})
+				return
+			}
+		}
+		a, err = h.authSvc.SetPermissions(ctx, *id, *req.Permissions)
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+	}
+	h.log.Debug("Legacy auth updated", zap.String("auth", fmt.Sprint(a)))
+
+	h.api.Respond(w, r, http.StatusOK, newAuthResponse(a))
+}
+
+// handleDeleteAuthorization is the HTTP handler for the DELETE /api/v2/legacy/authorizations/:id route.
+func (h *AuthHandler) handleDeleteAuthorization(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.authSvc.DeleteAuthorization(r.Context(), *id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Legacy auth deleted", zap.String("authID", fmt.Sprint(id)))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type passwordSetRequest struct {
+	Password string `json:"password"`
+}
+
+// handlePostPassword is the HTTP handler for the POST /api/v2/legacy/authorizations/:id/password route.
+func (h *AuthHandler) handlePostPassword(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var req passwordSetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		})
+		return
+	}
+
+	if err := h.authSvc.SetPassword(r.Context(), *id, req.Password); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Legacy auth password set", zap.String("authID", fmt.Sprint(id)))
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,122 @@
+package authorization
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+)
+
+type AuthedAuthorizationService struct {
+	s AuthorizationService
+}
+
+var _ AuthorizationService = (*AuthedAuthorizationService)(nil)
+
+func NewAuthedAuthorizationService(s AuthorizationService) *AuthedAuthorizationService {
+	return &AuthedAuthorizationService{s: s}
+}
+
+func (s *AuthedAuthorizationService) CreateAuthorization(ctx context.Context, a *influxdb.Authorization) error {
+	if _, _, err := authorizer.AuthorizeCreate(ctx, influxdb.AuthorizationsResourceType, a.OrgID); err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeWriteResource(ctx, influxdb.UsersResourceType, a.UserID); err != nil {
+		return err
+	}
+	if err := authorizer.VerifyPermissions(ctx, a.Permissions); err != nil {
+		return err
+	}
+
+	return s.s.CreateAuthorization(ctx, a)
+}
+
+func (s *AuthedAuthorizationService) FindAuthorizationByToken(ctx context.Context, t string) (*influxdb.Authorization, error) {
+	a, err := s.s.FindAuthorizationByToken(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeRead(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *AuthedAuthorizationService) FindAuthorizationByID(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+	a, err := s.s.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeRead(ctx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *AuthedAuthorizationService) FindAuthorizations(ctx context.Context, filter influxdb.AuthorizationFilter, opt ...influxdb.FindOptions) ([]*influxdb.Authorization, int, error) {
+	as, _, err := s.s.FindAuthorizations(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return authorizer.AuthorizeFindAuthorizations(ctx, as)
+}
+
+func (s *AuthedAuthorizationService) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
+	if err := s.authorizeWrite(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateAuthorization(ctx, id, upd)
+}
+
+func (s *AuthedAuthorizationService) SetPermissions(ctx context.Context, id influxdb.ID, permissions []influxdb.Permission) (*influxdb.Authorization, error) {
+	if err := s.authorizeWrite(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := authorizer.VerifyPermissions(ctx, permissions); err != nil {
+		return nil, err
+	}
+	return s.s.SetPermissions(ctx, id, permissions)
+}
+
+func (s *AuthedAuthorizationService) DeleteAuthorization(ctx context.Context, id influxdb.ID) error {
+	if err := s.authorizeWrite(ctx, id); err != nil {
+		return err
+	}
+	return s.s.DeleteAuthorization(ctx, id)
+}
+
+func (s *AuthedAuthorizationService) SetPassword(ctx context.Context, id influxdb.ID, password string) error {
+	if err := s.authorizeWrite(ctx, id); err != nil {
+		return err
+	}
+	return s.s.SetPassword(ctx, id, password)
+}
+
+// ComparePassword is used to authenticate v1 users and requires no authorization.
+func (s *AuthedAuthorizationService) ComparePassword(ctx context.Context, id influxdb.ID, password string) error {
+	return s.s.ComparePassword(ctx, id, password)
+}
+
+func (s *AuthedAuthorizationService) authorizeWrite(ctx context.Context, id influxdb.ID) error {
+	a, err := s.s.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.AuthorizationsResourceType, a.ID, a.OrgID); err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeWriteResource(ctx, influxdb.UsersResourceType, a.UserID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func authorizeRead(ctx context.Context, a *influxdb.Authorization) error {
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.AuthorizationsResourceType, a.ID, a.OrgID); err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeReadResource(ctx, influxdb.UsersResourceType, a.UserID); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package authorization
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+// AuthorizationService manages the authorizations of v1 users. The token of
+// such an authorization is the username of the v1 user, who authenticates
+// with the password set for the authorization rather than with the token.
+type AuthorizationService interface {
+	influxdb.AuthorizationService
+
+	// SetPermissions replaces the permissions of an authorization.
+	SetPermissions(ctx context.Context, id influxdb.ID, permissions []influxdb.Permission) (*influxdb.Authorization, error)
+
+	// SetPassword overrides the password of an authorization.
+	SetPassword(ctx context.Context, id influxdb.ID, password string) error
+
+	// ComparePassword checks if the password matches the password of an authorization.
+	ComparePassword(ctx context.Context, id influxdb.ID, password string) error
+}
+
+// TenantService is used to look up the Organization and User of an Authorization.
+type TenantService interface {
+	FindOrganizationByID(ctx context.Context, id influxdb.ID) (*influxdb.Organization, error)
+	FindOrganization(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error)
+	FindUserByID(ctx context.Context, id influxdb.ID) (*influxdb.User, error)
+	FindUser(ctx context.Context, filter influxdb.UserFilter) (*influxdb.User, error)
+}
+
+var _ AuthorizationService = (*Service)(nil)
+
+type Service struct {
+	store         *Store
+	tenantService TenantService
+
+	// Hash is the hashing function used for passwords, bcrypt by default.
+	Hash kv.Crypt
+}
+
+func NewService(st *Store, ts TenantService) *Service {
+	return &Service{
+		store:         st,
+		tenantService: ts,
+		Hash:          &kv.Bcrypt{},
+	}
+}
+
+func (s *Service) CreateAuthorization(ctx context.Context, a *influxdb.Authorization) error {
+	if a.Token == "" {
+		return ErrUsernameRequired
+	}
+	if err := a.Valid(); err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	if _, err := s.tenantService.FindUserByID(ctx, a.UserID); err != nil {
+		return influxdb.ErrUnableToCreateToken
+	}
+
+	if _, err := s.tenantService.FindOrganizationByID(ctx, a.OrgID); err != nil {
+		return influxdb.ErrUnableToCreateToken
+	}
+
+	now := time.Now()
+	a.SetCreatedAt(now)
+	a.SetUpdatedAt(now)
+
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		return s.store.CreateAuthorization(ctx, tx, a)
+	})
+}
+
+func (s *Service) FindAuthorizationByID(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+	var a *influxdb.Authorization
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		auth, err := s.store.GetAuthorizationByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		a = auth
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// FindAuthorizationByToken returns the authorization of the v1 user named token.
+func (s *Service) FindAuthorizationByToken(ctx context.Context, token string) (*influxdb.Authorization, error) {
+	var a *influxdb.Authorization
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		auth, err := s.store.GetAuthorizationByToken(ctx, tx, token)
+		if err != nil {
+			return err
+		}
+
+		a = auth
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// FindAuthorizations retrieves all authorizations that match an arbitrary authorization filter.
+// Filters using ID, or Token should be efficient.
+// Other filters will do a linear scan across all authorizations searching for a match.
+func (s *Service) FindAuthorizations(ctx context.Context, filter influxdb.AuthorizationFilter, opt ...influxdb.FindOptions) ([]*influxdb.Authorization, int, error) {
+	if filter.ID != nil {
+		a, err := s.FindAuthorizationByID(ctx, *filter.ID)
+		if err != nil {
+			return nil, 0, &influxdb.Error{
+				Err: err,
+				Op:  influxdb.OpFindAuthorizations,
+			}
+		}
+
+		return []*influxdb.Authorization{a}, 1, nil
+	}
+
+	if filter.Token != nil {
+		a, err := s.FindAuthorizationByToken(ctx, *filter.Token)
+		if err != nil {
+			return nil, 0, &influxdb.Error{
+				Err: err,
+				Op:  influxdb.OpFindAuthorizations,
+			}
+		}
+
+		return []*influxdb.Authorization{a}, 1, nil
+	}
+
+	as := []*influxdb.Authorization{}
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		auths, err := s.store.ListAuthorizations(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+		as = auths
+		return nil
+	})
+
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	return as, len(as), nil
+}
+
+// UpdateAuthorization updates the status and description if available.
+func (s *Service) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
+	return s.updateAuthorization(ctx, id, func(a *influxdb.Authorization) error {
+		if upd.Status != nil {
+			a.Status = *upd.Status
+		}
+		if upd.Description != nil {
+			a.Description = *upd.Description
+		}
+		return nil
+	})
+}
+
+// SetPermissions replaces the permissions of an authorization.
+func (s *Service) SetPermissions(ctx context.Context, id influxdb.ID, permissions []influxdb.Permission) (*influxdb.Authorization, error) {
+	return s.updateAuthorization(ctx, id, func(a *influxdb.Authorization) error {
+		a.Permissions = permissions
+		return a.Valid()
+	})
+}
+
+func (s *Service) updateAuthorization(ctx context.Context, id influxdb.ID, fn func(a *influxdb.Authorization) error) (*influxdb.Authorization, error) {
+	var auth *influxdb.Authorization
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		a, err := s.store.GetAuthorizationByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(a); err != nil {
+			return err
+		}
+		a.SetUpdatedAt(time.Now())
+
+		auth, err = s.store.UpdateAuthorization(ctx, tx, id, a)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return auth, nil
+}
+
+// DeleteAuthorization removes an authorization and its password.
+func (s *Service) DeleteAuthorization(ctx context.Context, id influxdb.ID) error {
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		return s.store.DeleteAuthorization(ctx, tx, id)
+	})
+}
+
+// SetPassword overrides the password of an authorization.
+func (s *Service) SetPassword(ctx context.Context, id influxdb.ID, password string) error {
+	if password == "" {
+		return ErrPasswordRequired
+	}
+
+	hash, err := s.Hash.GenerateFromPassword([]byte(password), kv.DefaultCost)
+	if err != nil {
+		return ErrInternalServiceError(err)
+	}
+
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		if _, err := s.store.GetAuthorizationByID(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.store.SetPasswordHash(ctx, tx, id, hash)
+	})
+}
+
+// ComparePassword checks if the password matches the password of an authorization.
+// Passwords that do not match return errors.
+func (s *Service) ComparePassword(ctx context.Context, id influxdb.ID, password string) error {
+	var hash []byte
+	err := s.store.View(ctx, func(tx kv.Tx) (err error) {
+		hash, err = s.store.GetPasswordHash(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.Hash.CompareHashAndPassword(hash, []byte(password)); err != nil {
+		return EIncorrectPassword
+	}
+	return nil
+}
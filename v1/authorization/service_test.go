@@ -0,0 +1,122 @@
+package authorization_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/tenant"
+	itesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/influxdata/influxdb/v2/v1/authorization"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestService(t *testing.T) (*authorization.Service, *influxdb.User, *influxdb.Organization) {
+	t.Helper()
+
+	ctx := context.Background()
+	store := inmem.NewKVStore()
+	if err := all.Up(ctx, zaptest.NewLogger(t), store); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := tenant.NewService(tenant.NewStore(store))
+	user := &influxdb.User{Name: "owner"}
+	if err := ts.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := ts.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := authorization.NewStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return authorization.NewService(st, ts), user, org
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	svc, user, org := newTestService(t)
+
+	a := &influxdb.Authorization{
+		Token:  "telegraf",
+		OrgID:  org.ID,
+		UserID: user.ID,
+	}
+	if err := svc.CreateAuthorization(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if !a.ID.Valid() || a.Status != influxdb.Active {
+		t.Fatalf("unexpected authorization: %+v", a)
+	}
+
+	// Usernames are unique.
+	err := svc.CreateAuthorization(ctx, &influxdb.Authorization{Token: "telegraf", OrgID: org.ID, UserID: user.ID})
+	if got, want := influxdb.ErrorCode(err), influxdb.EConflict; got != want {
+		t.Fatalf("unexpected error code creating a duplicate username: got %q, want %q", got, want)
+	}
+	err = svc.CreateAuthorization(ctx, &influxdb.Authorization{OrgID: org.ID, UserID: user.ID})
+	if err != authorization.ErrUsernameRequired {
+		t.Fatalf("unexpected error creating an authorization without a username: %v", err)
+	}
+
+	// A password must be set before the user can authenticate.
+	if err := svc.ComparePassword(ctx, a.ID, "secret"); err != authorization.EIncorrectPassword {
+		t.Fatalf("unexpected error comparing an unset password: %v", err)
+	}
+	if err := svc.SetPassword(ctx, a.ID, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ComparePassword(ctx, a.ID, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ComparePassword(ctx, a.ID, "wrong"); err != authorization.EIncorrectPassword {
+		t.Fatalf("unexpected error comparing a wrong password: %v", err)
+	}
+
+	bucketID := influxdb.ID(0xff)
+	perms := []influxdb.Permission{
+		*itesting.MustNewPermissionAtID(bucketID, influxdb.ReadAction, influxdb.BucketsResourceType, org.ID),
+	}
+	if _, err := svc.SetPermissions(ctx, a.ID, perms); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := svc.FindAuthorizationByToken(ctx, "telegraf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != a.ID || len(got.Permissions) != 1 || got.Permissions[0].String() != perms[0].String() {
+		t.Fatalf("unexpected authorization: %+v", got)
+	}
+
+	// Permissions of another organization are not valid.
+	otherOrgID := org.ID + 1
+	if _, err := svc.SetPermissions(ctx, a.ID, []influxdb.Permission{
+		*itesting.MustNewPermissionAtID(bucketID, influxdb.ReadAction, influxdb.BucketsResourceType, otherOrgID),
+	}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("unexpected error setting permissions of another organization: %v", err)
+	}
+
+	as, n, err := svc.FindAuthorizations(ctx, influxdb.AuthorizationFilter{OrgID: &org.ID})
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 1 || as[0].ID != a.ID {
+		t.Fatalf("unexpected authorizations: %+v", as)
+	}
+
+	if err := svc.DeleteAuthorization(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindAuthorizationByToken(ctx, "telegraf"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("unexpected error finding a deleted authorization: %v", err)
+	}
+	if err := svc.ComparePassword(ctx, a.ID, "secret"); err != authorization.EIncorrectPassword {
+		t.Fatalf("unexpected error comparing the password of a deleted authorization: %v", err)
+	}
+}
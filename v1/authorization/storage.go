@@ -0,0 +1,76 @@
+package authorization
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+)
+
+const MaxIDGenerationN = 100
+const ReservedIDs = 1000
+
+var (
+	authBucket     = []byte("legacy/authorizationsv1")
+	authIndex      = []byte("legacy/authorizationindexv1")
+	passwordBucket = []byte("legacy/authorizationpasswordv1")
+)
+
+// Store persists the authorizations of v1 users and their passwords.
+type Store struct {
+	kvStore kv.Store
+	IDGen   influxdb.IDGenerator
+}
+
+func NewStore(kvStore kv.Store) (*Store, error) {
+	st := &Store{
+		kvStore: kvStore,
+		IDGen:   snowflake.NewDefaultIDGenerator(),
+	}
+	return st, st.setup()
+}
+
+// View opens up a transaction that will not write to any data. Implementing interfaces
+// should take care to ensure that all view transactions do not mutate any data.
+func (s *Store) View(ctx context.Context, fn func(kv.Tx) error) error {
+	return s.kvStore.View(ctx, fn)
+}
+
+// Update opens up a transaction that will mutate data.
+func (s *Store) Update(ctx context.Context, fn func(kv.Tx) error) error {
+	return s.kvStore.Update(ctx, fn)
+}
+
+func (s *Store) setup() error {
+	return s.View(context.Background(), func(tx kv.Tx) error {
+		for _, b := range [][]byte{authBucket, authIndex, passwordBucket} {
+			if _, err := tx.Bucket(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// generateSafeID attempts to create an authorization id that does not already exist.
+func (s *Store) generateSafeID(ctx context.Context, tx kv.Tx) (influxdb.ID, error) {
+	for i := 0; i < MaxIDGenerationN; i++ {
+		id := s.IDGen.ID()
+		if id < ReservedIDs {
+			continue
+		}
+
+		err := uniqueID(ctx, tx, id)
+		if err == nil {
+			return id, nil
+		}
+
+		if err == kv.NotUniqueError {
+			continue
+		}
+
+		return influxdb.InvalidID(), err
+	}
+	return influxdb.InvalidID(), ErrFailureGeneratingID
+}
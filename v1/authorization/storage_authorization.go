@@ -0,0 +1,303 @@
+package authorization
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+func authIndexKey(n string) []byte {
+	return []byte(n)
+}
+
+func authIndexBucket(tx kv.Tx) (kv.Bucket, error) {
+	b, err := tx.Bucket(authIndex)
+	if err != nil {
+		return nil, UnexpectedAuthIndexError(err)
+	}
+
+	return b, nil
+}
+
+func encodeAuthorization(a *influxdb.Authorization) ([]byte, error) {
+	switch a.Status {
+	case influxdb.Active, influxdb.Inactive:
+	case "":
+		a.Status = influxdb.Active
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "unknown authorization status",
+		}
+	}
+
+	return json.Marshal(a)
+}
+
+func decodeAuthorization(b []byte, a *influxdb.Authorization) error {
+	if err := json.Unmarshal(b, a); err != nil {
+		return err
+	}
+	if a.Status == "" {
+		a.Status = influxdb.Active
+	}
+	return nil
+}
+
+// CreateAuthorization takes an Authorization object and saves it in storage
+// using its token, the username of the v1 user, as an index.
+func (s *Store) CreateAuthorization(ctx context.Context, tx kv.Tx, a *influxdb.Authorization) error {
+	// if the provided ID is invalid, or already maps to an existing Auth, then generate a new one
+	if !a.ID.Valid() || uniqueID(ctx, tx, a.ID) != nil {
+		id, err := s.generateSafeID(ctx, tx)
+		if err != nil {
+			return err
+		}
+		a.ID = id
+	}
+
+	if err := uniqueAuthToken(ctx, tx, a.Token); err != nil {
+		return err
+	}
+
+	return s.putAuthorization(ctx, tx, a)
+}
+
+// GetAuthorizationByID gets an authorization by its ID from the auth bucket in kv
+func (s *Store) GetAuthorizationByID(ctx context.Context, tx kv.Tx, id influxdb.ID) (*influxdb.Authorization, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidAuthID
+	}
+
+	b, err := tx.Bucket(authBucket)
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	v, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		return nil, ErrAuthNotFound
+	}
+
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	a := &influxdb.Authorization{}
+	if err := decodeAuthorization(v, a); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	return a, nil
+}
+
+// GetAuthorizationByToken gets an authorization by its token, the username
+// of the v1 user.
+func (s *Store) GetAuthorizationByToken(ctx context.Context, tx kv.Tx, token string) (*influxdb.Authorization, error) {
+	idx, err := authIndexBucket(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// use the token to look up the authorization's ID
+	idKey, err := idx.Get(authIndexKey(token))
+	if kv.IsNotFound(err) {
+		return nil, ErrAuthNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	var id influxdb.ID
+	if err := id.Decode(idKey); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	return s.GetAuthorizationByID(ctx, tx, id)
+}
+
+// ListAuthorizations returns all the authorizations matching the filter.
+func (s *Store) ListAuthorizations(ctx context.Context, tx kv.Tx, f influxdb.AuthorizationFilter) ([]*influxdb.Authorization, error) {
+	b, err := tx.Bucket(authBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	var as []*influxdb.Authorization
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		a := &influxdb.Authorization{}
+		if err := decodeAuthorization(v, a); err != nil {
+			return nil, err
+		}
+		if filterAuthorization(f, a) {
+			as = append(as, a)
+		}
+	}
+
+	return as, cur.Err()
+}
+
+// UpdateAuthorization replaces an authorization in storage.
+// The token of the authorization cannot be changed.
+func (s *Store) UpdateAuthorization(ctx context.Context, tx kv.Tx, id influxdb.ID, a *influxdb.Authorization) (*influxdb.Authorization, error) {
+	a.ID = id
+	if err := s.putAuthorization(ctx, tx, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// DeleteAuthorization removes an authorization and its password from storage.
+func (s *Store) DeleteAuthorization(ctx context.Context, tx kv.Tx, id influxdb.ID) error {
+	a, err := s.GetAuthorizationByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	encodedID, err := id.Encode()
+	if err != nil {
+		return ErrInvalidAuthID
+	}
+
+	idx, err := authIndexBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(authBucket)
+	if err != nil {
+		return err
+	}
+
+	pw, err := tx.Bucket(passwordBucket)
+	if err != nil {
+		return err
+	}
+
+	if err := idx.Delete(authIndexKey(a.Token)); err != nil {
+		return ErrInternalServiceError(err)
+	}
+
+	if err := b.Delete(encodedID); err != nil {
+		return ErrInternalServiceError(err)
+	}
+
+	if err := pw.Delete(encodedID); err != nil {
+		return ErrInternalServiceError(err)
+	}
+
+	return nil
+}
+
+func (s *Store) putAuthorization(ctx context.Context, tx kv.Tx, a *influxdb.Authorization) error {
+	v, err := encodeAuthorization(a)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	encodedID, err := a.ID.Encode()
+	if err != nil {
+		return ErrInvalidAuthID
+	}
+
+	idx, err := authIndexBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := idx.Put(authIndexKey(a.Token), encodedID); err != nil {
+		return ErrInternalServiceError(err)
+	}
+
+	b, err := tx.Bucket(authBucket)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Put(encodedID, v); err != nil {
+		return ErrInternalServiceError(err)
+	}
+
+	return nil
+}
+
+func uniqueAuthToken(ctx context.Context, tx kv.Tx, token string) error {
+	idx, err := authIndexBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	_, err = idx.Get(authIndexKey(token))
+	// if not found then this username is unique.
+	if kv.IsNotFound(err) {
+		return nil
+	}
+
+	// no error means this is not unique
+	if err == nil {
+		return ErrUsernameAlreadyExists
+	}
+
+	// any other error is some sort of internal server error
+	return kv.UnexpectedIndexError(err)
+}
+
+// uniqueID returns nil if the ID provided is unique, returns an error otherwise
+func uniqueID(ctx context.Context, tx kv.Tx, id influxdb.ID) error {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return ErrInvalidAuthID
+	}
+
+	b, err := tx.Bucket(authBucket)
+	if err != nil {
+		return ErrInternalServiceError(err)
+	}
+
+	_, err = b.Get(encodedID)
+	// if not found then the ID is unique
+	if kv.IsNotFound(err) {
+		return nil
+	}
+	// no error means this is not unique
+	if err == nil {
+		return kv.NotUniqueError
+	}
+
+	// any other error is some sort of internal server error
+	return kv.UnexpectedIndexError(err)
+}
+
+func filterAuthorization(f influxdb.AuthorizationFilter, a *influxdb.Authorization) bool {
+	if f.ID != nil && a.ID != *f.ID {
+		return false
+	}
+	if f.Token != nil && a.Token != *f.Token {
+		return false
+	}
+	if f.OrgID != nil && a.OrgID != *f.OrgID {
+		return false
+	}
+	if f.UserID != nil && a.UserID != *f.UserID {
+		return false
+	}
+	return true
+}
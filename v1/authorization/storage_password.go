@@ -0,0 +1,49 @@
+package authorization
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+// SetPasswordHash stores the password hash of an authorization.
+func (s *Store) SetPasswordHash(ctx context.Context, tx kv.Tx, id influxdb.ID, hash []byte) error {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return ErrInvalidAuthID
+	}
+
+	b, err := tx.Bucket(passwordBucket)
+	if err != nil {
+		return ErrInternalServiceError(err)
+	}
+
+	if err := b.Put(encodedID, hash); err != nil {
+		return ErrInternalServiceError(err)
+	}
+	return nil
+}
+
+// GetPasswordHash returns the password hash of an authorization.
+func (s *Store) GetPasswordHash(ctx context.Context, tx kv.Tx, id influxdb.ID) ([]byte, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidAuthID
+	}
+
+	b, err := tx.Bucket(passwordBucket)
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	hash, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		// The authorization exists but no password has been set.
+		return nil, EIncorrectPassword
+	}
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+	return hash, nil
+}
@@ -305,7 +305,8 @@ func (w *PointsWriter) WritePointsPrivileged(database, retentionPolicy string, c
 		go func(shard *meta.ShardInfo, database, retentionPolicy string, points []models.Point) {
 			err := w.writeToShard(shard, database, retentionPolicy, points)
 			if err == tsdb.ErrShardDeletion {
-				err = tsdb.PartialWriteError{Reason: fmt.Sprintf("shard %d is pending deletion", shard.ID), Dropped: len(points)}
+				reason := fmt.Sprintf("shard %d is pending deletion", shard.ID)
+				err = tsdb.PartialWriteError{Reason: reason, Dropped: len(points), DroppedPoints: droppedPoints(points, reason)}
 			}
 			ch <- err
 		}(shardMappings.Shards[shardID], database, retentionPolicy, points)
@@ -335,7 +336,8 @@ func (w *PointsWriter) WritePointsPrivileged(database, retentionPolicy string, c
 	}
 
 	if err == nil && len(shardMappings.Dropped) > 0 {
-		err = tsdb.PartialWriteError{Reason: "points beyond retention policy", Dropped: len(shardMappings.Dropped)}
+		reason := "points beyond retention policy"
+		err = tsdb.PartialWriteError{Reason: reason, Dropped: len(shardMappings.Dropped), DroppedPoints: droppedPoints(shardMappings.Dropped, reason)}
 	}
 	timeout := time.NewTimer(w.WriteTimeout)
 	defer timeout.Stop()
@@ -347,15 +349,31 @@ func (w *PointsWriter) WritePointsPrivileged(database, retentionPolicy string, c
 			atomic.AddInt64(&w.stats.WriteTimeout, 1)
 			// return timeout error to caller
 			return ErrTimeout
-		case err := <-ch:
-			if err != nil {
-				return err
+		case werr := <-ch:
+			if werr == nil {
+				continue
 			}
+			partial, ok := werr.(tsdb.PartialWriteError)
+			if !ok {
+				return werr
+			}
+			// The other shards are still written; report the points
+			// dropped by all of them.
+			err = partial.Merge(err)
 		}
 	}
 	return err
 }
 
+// droppedPoints returns points dropped for reason.
+func droppedPoints(points []models.Point, reason string) []tsdb.DroppedPoint {
+	dropped := make([]tsdb.DroppedPoint, len(points))
+	for i, p := range points {
+		dropped[i] = tsdb.DroppedPoint{Point: p, Reason: reason}
+	}
+	return dropped
+}
+
 // writeToShards writes points to a shard.
 func (w *PointsWriter) writeToShard(shard *meta.ShardInfo, database, retentionPolicy string, points []models.Point) error {
 	atomic.AddInt64(&w.stats.PointWriteReqLocal, int64(len(points)))
@@ -423,8 +423,12 @@ func TestPointsWriter_WritePoints_Dropped(t *testing.T) {
 	defer c.Close()
 
 	err := c.WritePointsPrivileged(pr.Database, pr.RetentionPolicy, models.ConsistencyLevelOne, pr.Points)
-	if _, ok := err.(tsdb.PartialWriteError); !ok {
-		t.Errorf("PointsWriter.WritePoints(): got %v, exp %v", err, tsdb.PartialWriteError{})
+	perr, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		t.Fatalf("PointsWriter.WritePoints(): got %v, exp %v", err, tsdb.PartialWriteError{})
+	}
+	if len(perr.DroppedPoints) != 1 || perr.DroppedPoints[0].Point != pr.Points[0] {
+		t.Errorf("PointsWriter.WritePoints(): unexpected dropped points %v", perr.DroppedPoints)
 	}
 }
 
@@ -4,17 +4,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/authorizer"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	iql "github.com/influxdata/influxdb/v2/influxql"
 	"github.com/influxdata/influxdb/v2/influxql/query"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator"
 	"github.com/influxdata/influxdb/v2/pkg/tracing"
 	"github.com/influxdata/influxdb/v2/pkg/tracing/fields"
+	"github.com/influxdata/influxdb/v2/predicate"
+	iqlflux "github.com/influxdata/influxdb/v2/query/influxql"
 	"github.com/influxdata/influxdb/v2/tsdb"
+	v1auth "github.com/influxdata/influxdb/v2/v1/authorization"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
 	"github.com/influxdata/influxql"
 )
@@ -23,6 +30,20 @@ import (
 // when a database has not been provided.
 var ErrDatabaseNameRequired = errors.New("database name required")
 
+var (
+	// ErrContinuousQueryExists is returned when creating a continuous query
+	// with the name of a different continuous query on the same database.
+	ErrContinuousQueryExists = errors.New("continuous query already exists")
+
+	// ErrContinuousQueryNotFound is returned when dropping a continuous query
+	// that does not exist.
+	ErrContinuousQueryNotFound = errors.New("continuous query not found")
+
+	// ErrShardNotFound is returned when dropping a shard that does not belong
+	// to a bucket of the organization.
+	ErrShardNotFound = errors.New("shard not found")
+)
+
 // StatementExecutor executes a statement in the query.
 type StatementExecutor struct {
 	MetaClient MetaClient
@@ -35,6 +56,29 @@ type StatementExecutor struct {
 
 	DBRP influxdb.DBRPMappingServiceV2
 
+	// PointsWriter is used to write the results of SELECT INTO statements.
+	PointsWriter BucketWriter
+
+	// DeleteService is used to delete data for DELETE and DROP SERIES statements.
+	DeleteService influxdb.DeleteService
+
+	// TaskService is used to store continuous queries as tasks.
+	TaskService influxdb.TaskService
+
+	// BucketService is used to create and drop the buckets of databases
+	// and retention policies.
+	BucketService influxdb.BucketService
+
+	// ShardService is used to show and drop the shards of buckets.
+	ShardService influxdb.ShardService
+
+	// RunningQueryService is used to show and kill the running queries.
+	RunningQueryService influxdb.RunningQueryService
+
+	// V1AuthorizationService is used to manage the v1 users of organizations,
+	// which are backed by authorizations.
+	V1AuthorizationService v1auth.AuthorizationService
+
 	// Select statement limits
 	MaxSelectPointN   int
 	MaxSelectSeriesN  int
@@ -53,35 +97,35 @@ func (e *StatementExecutor) ExecuteStatement(ctx context.Context, stmt influxql.
 	var err error
 	switch stmt := stmt.(type) {
 	case *influxql.AlterRetentionPolicyStatement:
-		err = iql.ErrNotImplemented("ALTER RETENTION POLICY")
+		err = e.executeAlterRetentionPolicyStatement(ctx, stmt, ectx)
 	case *influxql.CreateContinuousQueryStatement:
-		err = iql.ErrNotImplemented("CREATE CONTINUOUS QUERY")
+		err = e.executeCreateContinuousQueryStatement(ctx, stmt, ectx)
 	case *influxql.CreateDatabaseStatement:
-		err = iql.ErrNotImplemented("CREATE DATABASE")
+		err = e.executeCreateDatabaseStatement(ctx, stmt, ectx)
 	case *influxql.CreateRetentionPolicyStatement:
-		err = iql.ErrNotImplemented("CREATE RETENTION POLICY")
+		err = e.executeCreateRetentionPolicyStatement(ctx, stmt, ectx)
 	case *influxql.CreateSubscriptionStatement:
 		err = iql.ErrNotImplemented("CREATE SUBSCRIPTION")
 	case *influxql.CreateUserStatement:
-		err = iql.ErrNotImplemented("CREATE USER")
+		err = e.executeCreateUserStatement(ctx, stmt, ectx)
 	case *influxql.DeleteSeriesStatement:
-		return e.executeDeleteSeriesStatement(ctx, stmt, ectx.Database, ectx)
+		err = e.executeDeleteSeriesStatement(ctx, stmt, ectx.Database, ectx)
 	case *influxql.DropContinuousQueryStatement:
-		err = iql.ErrNotImplemented("DROP CONTINUOUS QUERY")
+		err = e.executeDropContinuousQueryStatement(ctx, stmt, ectx)
 	case *influxql.DropDatabaseStatement:
-		err = iql.ErrNotImplemented("DROP DATABASE")
+		err = e.executeDropDatabaseStatement(ctx, stmt, ectx)
 	case *influxql.DropMeasurementStatement:
 		return e.executeDropMeasurementStatement(ctx, stmt, ectx.Database, ectx)
 	case *influxql.DropSeriesStatement:
-		err = iql.ErrNotImplemented("DROP SERIES")
+		err = e.executeDropSeriesStatement(ctx, stmt, ectx.Database, ectx)
 	case *influxql.DropRetentionPolicyStatement:
-		err = iql.ErrNotImplemented("DROP RETENTION POLICY")
+		err = e.executeDropRetentionPolicyStatement(ctx, stmt, ectx)
 	case *influxql.DropShardStatement:
-		err = iql.ErrNotImplemented("DROP SHARD")
+		err = e.executeDropShardStatement(ctx, stmt, ectx)
 	case *influxql.DropSubscriptionStatement:
 		err = iql.ErrNotImplemented("DROP SUBSCRIPTION")
 	case *influxql.DropUserStatement:
-		err = iql.ErrNotImplemented("DROP USER")
+		err = e.executeDropUserStatement(ctx, stmt, ectx)
 	case *influxql.ExplainStatement:
 		if stmt.Analyze {
 			rows, err = e.executeExplainAnalyzeStatement(ctx, stmt, ectx)
@@ -89,33 +133,33 @@ func (e *StatementExecutor) ExecuteStatement(ctx context.Context, stmt influxql.
 			rows, err = e.executeExplainStatement(ctx, stmt, ectx)
 		}
 	case *influxql.GrantStatement:
-		err = iql.ErrNotImplemented("GRANT")
+		err = e.executeGrantStatement(ctx, stmt, ectx)
 	case *influxql.GrantAdminStatement:
-		err = iql.ErrNotImplemented("GRANT ALL")
+		err = e.executeGrantAdminStatement(ctx, stmt, ectx)
 	case *influxql.RevokeStatement:
-		err = iql.ErrNotImplemented("REVOKE")
+		err = e.executeRevokeStatement(ctx, stmt, ectx)
 	case *influxql.RevokeAdminStatement:
-		err = iql.ErrNotImplemented("REVOKE ALL")
+		err = e.executeRevokeAdminStatement(ctx, stmt, ectx)
 	case *influxql.ShowContinuousQueriesStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW CONTINUOUS QUERIES")
+		rows, err = e.executeShowContinuousQueriesStatement(ctx, stmt, ectx)
 	case *influxql.ShowDatabasesStatement:
 		rows, err = e.executeShowDatabasesStatement(ctx, stmt, ectx)
 	case *influxql.ShowDiagnosticsStatement:
 		rows, err = nil, iql.ErrNotImplemented("SHOW DIAGNOSTICS")
 	case *influxql.ShowGrantsForUserStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW GRANTS")
+		rows, err = e.executeShowGrantsForUserStatement(ctx, stmt, ectx)
 	case *influxql.ShowMeasurementsStatement:
 		return e.executeShowMeasurementsStatement(ctx, stmt, ectx)
 	case *influxql.ShowMeasurementCardinalityStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW MEASUREMENT CARDINALITY")
+		rows, err = e.executeShowMeasurementCardinalityStatement(ctx, stmt, ectx)
 	case *influxql.ShowRetentionPoliciesStatement:
 		rows, err = e.executeShowRetentionPoliciesStatement(ctx, stmt, ectx)
 	case *influxql.ShowSeriesCardinalityStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW SERIES CARDINALITY")
+		rows, err = e.executeShowSeriesCardinalityStatement(ctx, stmt, ectx)
 	case *influxql.ShowShardsStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW SHARDS")
+		rows, err = e.executeShowShardsStatement(ctx, stmt, ectx)
 	case *influxql.ShowShardGroupsStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW SHARD GROUPS")
+		rows, err = e.executeShowShardGroupsStatement(ctx, stmt, ectx)
 	case *influxql.ShowStatsStatement:
 		rows, err = nil, iql.ErrNotImplemented("SHOW STATS")
 	case *influxql.ShowSubscriptionsStatement:
@@ -125,11 +169,13 @@ func (e *StatementExecutor) ExecuteStatement(ctx context.Context, stmt influxql.
 	case *influxql.ShowTagValuesStatement:
 		return e.executeShowTagValues(ctx, stmt, ectx)
 	case *influxql.ShowUsersStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW USERS")
+		rows, err = e.executeShowUsersStatement(ctx, stmt, ectx)
 	case *influxql.SetPasswordUserStatement:
-		err = iql.ErrNotImplemented("SET PASSWORD")
-	case *influxql.ShowQueriesStatement, *influxql.KillQueryStatement:
-		err = iql.ErrNotImplemented("SHOW QUERIES")
+		err = e.executeSetPasswordUserStatement(ctx, stmt, ectx)
+	case *influxql.ShowQueriesStatement:
+		rows, err = e.executeShowQueriesStatement(ctx, stmt, ectx)
+	case *influxql.KillQueryStatement:
+		err = e.executeKillQueryStatement(ctx, stmt, ectx)
 	default:
 		return query.ErrInvalidQuery
 	}
@@ -158,273 +204,1579 @@ func (e *StatementExecutor) executeExplainStatement(ctx context.Context, q *infl
 	if err != nil {
 		return nil, err
 	}
-	defer p.Close()
+	defer p.Close()
+
+	plan, err := p.Explain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan = strings.TrimSpace(plan)
+
+	row := &models.Row{
+		Columns: []string{"QUERY PLAN"},
+	}
+	for _, s := range strings.Split(plan, "\n") {
+		row.Values = append(row.Values, []interface{}{s})
+	}
+	return models.Rows{row}, nil
+}
+
+func (e *StatementExecutor) executeExplainAnalyzeStatement(ctx context.Context, q *influxql.ExplainStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	stmt := q.Statement
+	t, span := tracing.NewTrace("select")
+	ctx = tracing.NewContextWithTrace(ctx, t)
+	ctx = tracing.NewContextWithSpan(ctx, span)
+	var aux query.Iterators
+	ctx = query.NewContextWithIterators(ctx, &aux)
+	start := time.Now()
+
+	cur, err := e.createIterators(ctx, stmt, ectx.ExecutionOptions, ectx.StatisticsGatherer)
+	if err != nil {
+		return nil, err
+	}
+
+	iterTime := time.Since(start)
+
+	// Generate a row emitter from the iterator set.
+	em := query.NewEmitter(cur, ectx.ChunkSize)
+
+	// Emit rows to the results channel.
+	var writeN int64
+	for {
+		var row *models.Row
+		row, _, err = em.Emit()
+		if err != nil {
+			goto CLEANUP
+		} else if row == nil {
+			// Check if the query was interrupted while emitting.
+			if err = ctx.Err(); err != nil {
+				goto CLEANUP
+			}
+			break
+		}
+
+		writeN += int64(len(row.Values))
+	}
+
+CLEANUP:
+	em.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// close auxiliary iterators deterministically to finalize any captured measurements
+	aux.Close()
+
+	totalTime := time.Since(start)
+	span.MergeFields(
+		fields.Duration("total_time", totalTime),
+		fields.Duration("planning_time", iterTime),
+		fields.Duration("execution_time", totalTime-iterTime),
+	)
+	span.Finish()
+
+	row := &models.Row{
+		Columns: []string{"EXPLAIN ANALYZE"},
+	}
+	for _, s := range strings.Split(t.Tree().String(), "\n") {
+		row.Values = append(row.Values, []interface{}{s})
+	}
+
+	return models.Rows{row}, nil
+}
+
+func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *influxql.SelectStatement, ectx *query.ExecutionContext) error {
+	cur, err := e.createIterators(ctx, stmt, ectx.ExecutionOptions, ectx.StatisticsGatherer)
+	if err != nil {
+		return err
+	}
+
+	// Generate a row emitter from the iterator set.
+	em := query.NewEmitter(cur, ectx.ChunkSize)
+	defer em.Close()
+
+	// Emit rows to the results channel.
+	var writeN int64
+	var emitted bool
+
+	var pointsWriter *bufferedPointsWriter
+	if stmt.Target != nil {
+		if e.PointsWriter == nil {
+			return iql.ErrNotImplemented("SELECT INTO")
+		}
+		mapping, err := e.getTargetDBRP(ctx, stmt.Target, ectx)
+		if err != nil {
+			return err
+		}
+		pointsWriter = newBufferedPointsWriter(e.PointsWriter, mapping.OrganizationID, mapping.BucketID, 10000)
+	}
+
+	for {
+		row, partial, err := em.Emit()
+		if err != nil {
+			return err
+		} else if row == nil {
+			// Check if the query was interrupted while emitting.
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			break
+		}
+
+		// Write points back into system for INTO statements.
+		if stmt.Target != nil {
+			n, err := e.writeInto(ctx, pointsWriter, stmt, row)
+			if err != nil {
+				return err
+			}
+			writeN += n
+			continue
+		}
+
+		result := &query.Result{
+			Series:  []*models.Row{row},
+			Partial: partial,
+		}
+
+		// Send results or exit if closing.
+		if err := ectx.Send(ctx, result); err != nil {
+			return err
+		}
+
+		emitted = true
+	}
+
+	// Flush remaining points and emit write count if an INTO statement.
+	if stmt.Target != nil {
+		if err := pointsWriter.Flush(ctx); err != nil {
+			return err
+		}
+
+		return ectx.Send(ctx, &query.Result{
+			Series: []*models.Row{{
+				Name:    "result",
+				Columns: []string{"time", "written"},
+				Values:  [][]interface{}{{time.Unix(0, 0).UTC(), writeN}},
+			}},
+		})
+	}
+
+	// Always emit at least one result.
+	if !emitted {
+		return ectx.Send(ctx, &query.Result{
+			Series: make([]*models.Row, 0),
+		})
+	}
+
+	return nil
+}
+
+// getTargetDBRP returns the DBRP mapping of the target of a SELECT INTO
+// statement and verifies that the caller is allowed to write to its bucket.
+func (e *StatementExecutor) getTargetDBRP(ctx context.Context, target *influxql.Target, ectx *query.ExecutionContext) (*influxdb.DBRPMappingV2, error) {
+	m := target.Measurement
+	if m.Database == "" {
+		return nil, errNoDatabaseInTarget
+	}
+
+	mappings, n, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:           &ectx.OrgID,
+		Database:        &m.Database,
+		RetentionPolicy: &m.RetentionPolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("finding DBRP mappings: %v", err)
+	} else if n == 0 {
+		return nil, fmt.Errorf("retention policy not found: %s.%s", m.Database, m.RetentionPolicy)
+	} else if n != 1 {
+		return nil, fmt.Errorf("finding DBRP mappings: expected 1, found %d", n)
+	}
+
+	mapping := mappings[0]
+	perm, err := influxdb.NewPermissionAtID(mapping.BucketID, influxdb.WriteAction, influxdb.BucketsResourceType, mapping.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+func (e *StatementExecutor) writeInto(ctx context.Context, w *bufferedPointsWriter, stmt *influxql.SelectStatement, row *models.Row) (n int64, err error) {
+	name := stmt.Target.Measurement.Name
+	if name == "" {
+		name = row.Name
+	}
+
+	points, err := convertRowToPoints(name, row)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := w.WritePoints(ctx, points); err != nil {
+		return 0, err
+	}
+
+	return int64(len(points)), nil
+}
+
+var errNoDatabaseInTarget = errors.New("no database in target")
+
+// convertRowToPoints will convert a query result Row into Points that can be written back in.
+func convertRowToPoints(measurementName string, row *models.Row) ([]models.Point, error) {
+	// figure out which parts of the result are the time and which are the fields
+	timeIndex := -1
+	fieldIndexes := make(map[string]int)
+	for i, c := range row.Columns {
+		if c == "time" {
+			timeIndex = i
+		} else {
+			fieldIndexes[c] = i
+		}
+	}
+
+	if timeIndex == -1 {
+		return nil, errors.New("error finding time index in result")
+	}
+
+	points := make([]models.Point, 0, len(row.Values))
+	for _, v := range row.Values {
+		vals := make(map[string]interface{})
+		for fieldName, fieldIndex := range fieldIndexes {
+			val := v[fieldIndex]
+			// Check specifically for nil or a NullFloat. This is because
+			// the NullFloat represents float numbers that don't have an internal representation
+			// (like NaN) that cannot be written back, but will not equal nil so there will be
+			// an attempt to write them if we do not check for it.
+			if val != nil && val != query.NullFloat {
+				vals[fieldName] = v[fieldIndex]
+			}
+		}
+
+		p, err := models.NewPoint(measurementName, models.NewTags(row.Tags), vals, v[timeIndex].(time.Time))
+		if err != nil {
+			// Drop points that can't be stored
+			continue
+		}
+
+		points = append(points, p)
+	}
+
+	return points, nil
+}
+
+// bufferedPointsWriter adds buffering to a points writer for a single bucket.
+type bufferedPointsWriter struct {
+	w        BucketWriter
+	buf      []models.Point
+	orgID    influxdb.ID
+	bucketID influxdb.ID
+}
+
+// newBufferedPointsWriter returns a new bufferedPointsWriter.
+func newBufferedPointsWriter(w BucketWriter, orgID, bucketID influxdb.ID, capacity int) *bufferedPointsWriter {
+	return &bufferedPointsWriter{
+		w:        w,
+		buf:      make([]models.Point, 0, capacity),
+		orgID:    orgID,
+		bucketID: bucketID,
+	}
+}
+
+// WritePoints buffers and writes points.
+func (w *bufferedPointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	for len(points) > 0 {
+		// Copy points into the buffer.
+		n := copy(w.buf[len(w.buf):cap(w.buf)], points)
+		w.buf = w.buf[:len(w.buf)+n]
+
+		// Remove copied points from the points slice.
+		points = points[n:]
+
+		// Flush the buffer if it is full.
+		if len(w.buf) == cap(w.buf) {
+			if err := w.Flush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Flush writes all buffered points to the underlying writer.
+func (w *bufferedPointsWriter) Flush(ctx context.Context) error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	if err := w.w.WritePoints(ctx, w.orgID, w.bucketID, w.buf); err != nil {
+		return err
+	}
+
+	// Clear the buffer.
+	w.buf = w.buf[:0]
+	return nil
+}
+
+func (e *StatementExecutor) createIterators(ctx context.Context, stmt *influxql.SelectStatement, opt query.ExecutionOptions, gatherer *iql.StatisticsGatherer) (query.Cursor, error) {
+	defer func(start time.Time) {
+		dur := time.Since(start)
+		gatherer.Append(iql.NewImmutableCollector(iql.Statistics{PlanDuration: dur}))
+	}(time.Now())
+
+	sopt := query.SelectOptions{
+		OrgID:              opt.OrgID,
+		NodeID:             opt.NodeID,
+		MaxSeriesN:         e.MaxSelectSeriesN,
+		MaxPointN:          e.MaxSelectPointN,
+		MaxBucketsN:        e.MaxSelectBucketsN,
+		StatisticsGatherer: gatherer,
+	}
+
+	// Create a set of iterators from a selection.
+	cur, err := query.Select(ctx, stmt, e.ShardMapper, sopt)
+	if err != nil {
+		return nil, err
+	}
+	return cur, nil
+}
+
+func (e *StatementExecutor) executeCreateContinuousQueryStatement(ctx context.Context, q *influxql.CreateContinuousQueryStatement, ectx *query.ExecutionContext) error {
+	if e.TaskService == nil {
+		return iql.ErrNotImplemented("CREATE CONTINUOUS QUERY")
+	}
+
+	perm, err := influxdb.NewPermission(influxdb.WriteAction, influxdb.TasksResourceType, ectx.OrgID)
+	if err != nil {
+		return err
+	}
+	if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+		return err
+	}
+
+	userID, err := icontext.GetUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The task writes to the target bucket on behalf of the caller,
+	// so verify the caller is allowed to do so.
+	mapping, err := e.getTargetDBRP(ctx, q.Source.Target, ectx)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := e.findContinuousQueries(ctx, ectx)
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		if continuousQueryMetadata(t, "database") != q.Database || continuousQueryMetadata(t, "name") != q.Name {
+			continue
+		}
+		// Creating the same continuous query twice is a no-op.
+		if continuousQueryMetadata(t, "query") == q.String() {
+			return nil
+		}
+		return ErrContinuousQueryExists
+	}
+
+	transpiler := iqlflux.NewTranspilerWithConfig(e.DBRP, iqlflux.Config{
+		DefaultDatabase: q.Database,
+		OrgID:           ectx.OrgID,
+	})
+	file, err := transpiler.TranspileContinuousQuery(ctx, q, mapping.OrganizationID, mapping.BucketID)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.TaskService.CreateTask(ctx, influxdb.TaskCreate{
+		Type:           influxdb.TaskContinuousQueryType,
+		Flux:           ast.Format(file),
+		OrganizationID: ectx.OrgID,
+		OwnerID:        userID,
+		Metadata: map[string]interface{}{
+			"database": q.Database,
+			"name":     q.Name,
+			"query":    q.String(),
+		},
+	})
+	return err
+}
+
+func (e *StatementExecutor) executeDropContinuousQueryStatement(ctx context.Context, q *influxql.DropContinuousQueryStatement, ectx *query.ExecutionContext) error {
+	if e.TaskService == nil {
+		return iql.ErrNotImplemented("DROP CONTINUOUS QUERY")
+	}
+
+	tasks, err := e.findContinuousQueries(ctx, ectx)
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		if continuousQueryMetadata(t, "database") != q.Database || continuousQueryMetadata(t, "name") != q.Name {
+			continue
+		}
+
+		perm, err := influxdb.NewPermissionAtID(t.ID, influxdb.WriteAction, influxdb.TasksResourceType, t.OrganizationID)
+		if err != nil {
+			return err
+		}
+		if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+			return err
+		}
+		return e.TaskService.DeleteTask(ctx, t.ID)
+	}
+	return ErrContinuousQueryNotFound
+}
+
+func (e *StatementExecutor) executeShowContinuousQueriesStatement(ctx context.Context, q *influxql.ShowContinuousQueriesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if e.TaskService == nil {
+		return nil, iql.ErrNotImplemented("SHOW CONTINUOUS QUERIES")
+	}
+
+	tasks, err := e.findContinuousQueries(ctx, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows models.Rows
+	byDatabase := make(map[string]*models.Row)
+	for _, t := range tasks {
+		database := continuousQueryMetadata(t, "database")
+		row, ok := byDatabase[database]
+		if !ok {
+			row = &models.Row{Name: database, Columns: []string{"name", "query"}}
+			byDatabase[database] = row
+			rows = append(rows, row)
+		}
+		row.Values = append(row.Values, []interface{}{continuousQueryMetadata(t, "name"), continuousQueryMetadata(t, "query")})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	return rows, nil
+}
+
+// findContinuousQueries returns the tasks of the organization that were
+// created from continuous queries.
+func (e *StatementExecutor) findContinuousQueries(ctx context.Context, ectx *query.ExecutionContext) ([]*influxdb.Task, error) {
+	perm, err := influxdb.NewPermission(influxdb.ReadAction, influxdb.TasksResourceType, ectx.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+		return nil, err
+	}
+
+	typ := influxdb.TaskContinuousQueryType
+	filter := influxdb.TaskFilter{
+		Type:           &typ,
+		OrganizationID: &ectx.OrgID,
+		Limit:          influxdb.TaskMaxPageSize,
+	}
+	var tasks []*influxdb.Task
+	for {
+		page, _, err := e.TaskService.FindTasks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page...)
+		if len(page) < filter.Limit {
+			return tasks, nil
+		}
+		filter.After = &page[len(page)-1].ID
+	}
+}
+
+// continuousQueryMetadata returns the metadata value stored under key
+// in a task created from a continuous query.
+func continuousQueryMetadata(t *influxdb.Task, key string) string {
+	v, _ := t.Metadata[key].(string)
+	return v
+}
+
+func (e *StatementExecutor) executeCreateDatabaseStatement(ctx context.Context, stmt *influxql.CreateDatabaseStatement, ectx *query.ExecutionContext) error {
+	if e.BucketService == nil {
+		return iql.ErrNotImplemented("CREATE DATABASE")
+	}
+
+	rpName := meta.DefaultRetentionPolicyName
+	var duration time.Duration
+	if stmt.RetentionPolicyCreate {
+		if stmt.RetentionPolicyName != "" {
+			rpName = stmt.RetentionPolicyName
+		}
+		if stmt.RetentionPolicyDuration != nil {
+			duration = *stmt.RetentionPolicyDuration
+		}
+	}
+
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &stmt.Name,
+	})
+	if err != nil {
+		return err
+	}
+	if len(mappings) > 0 {
+		// Creating an existing database is a no-op, unless the retention
+		// policy in the statement differs from the existing default.
+		if !stmt.RetentionPolicyCreate {
+			return nil
+		}
+		for _, m := range mappings {
+			if m.RetentionPolicy != rpName || !m.Default {
+				continue
+			}
+			b, err := e.BucketService.FindBucketByID(ctx, m.BucketID)
+			if err != nil {
+				return err
+			}
+			if b.RetentionPeriod == duration {
+				return nil
+			}
+		}
+		return meta.ErrRetentionPolicyConflict
+	}
+
+	return e.createRetentionPolicy(ctx, stmt.Name, rpName, duration, true, ectx)
+}
+
+func (e *StatementExecutor) executeCreateRetentionPolicyStatement(ctx context.Context, stmt *influxql.CreateRetentionPolicyStatement, ectx *query.ExecutionContext) error {
+	if e.BucketService == nil {
+		return iql.ErrNotImplemented("CREATE RETENTION POLICY")
+	}
+
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &stmt.Database,
+	})
+	if err != nil {
+		return err
+	} else if len(mappings) == 0 {
+		return query.ErrDatabaseNotFound(stmt.Database)
+	}
+
+	for _, m := range mappings {
+		if m.RetentionPolicy != stmt.Name {
+			continue
+		}
+		// The retention policy already exists. This is only an error
+		// if the statement would change it.
+		b, err := e.BucketService.FindBucketByID(ctx, m.BucketID)
+		if err != nil {
+			return err
+		}
+		if b.RetentionPeriod != stmt.Duration || (stmt.Default && !m.Default) {
+			return meta.ErrRetentionPolicyExists
+		}
+		return nil
+	}
+
+	return e.createRetentionPolicy(ctx, stmt.Database, stmt.Name, stmt.Duration, stmt.Default, ectx)
+}
+
+// createRetentionPolicy creates a bucket for the retention policy of a
+// database and maps the database and retention policy to it.
+func (e *StatementExecutor) createRetentionPolicy(ctx context.Context, database, name string, duration time.Duration, isDefault bool, ectx *query.ExecutionContext) error {
+	if duration != 0 && duration < meta.MinRetentionPolicyDuration {
+		return meta.ErrRetentionPolicyDurationTooLow
+	}
+
+	bucket := &influxdb.Bucket{
+		OrgID:               ectx.OrgID,
+		Name:                database + "/" + name,
+		RetentionPolicyName: name,
+		RetentionPeriod:     duration,
+	}
+	if err := e.BucketService.CreateBucket(ctx, bucket); err != nil {
+		return err
+	}
+
+	mapping := &influxdb.DBRPMappingV2{
+		Database:        database,
+		RetentionPolicy: name,
+		Default:         isDefault,
+		OrganizationID:  ectx.OrgID,
+		BucketID:        bucket.ID,
+	}
+	if err := e.DBRP.Create(ctx, mapping); err != nil {
+		// Don't leave behind a bucket that cannot be reached through the 1.x API.
+		if derr := e.BucketService.DeleteBucket(ctx, bucket.ID); derr != nil {
+			return fmt.Errorf("%v; cleaning up bucket: %v", err, derr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeAlterRetentionPolicyStatement(ctx context.Context, stmt *influxql.AlterRetentionPolicyStatement, ectx *query.ExecutionContext) error {
+	if e.BucketService == nil {
+		return iql.ErrNotImplemented("ALTER RETENTION POLICY")
+	}
+
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:           &ectx.OrgID,
+		Database:        &stmt.Database,
+		RetentionPolicy: &stmt.Name,
+	})
+	if err != nil {
+		return err
+	} else if len(mappings) == 0 {
+		return meta.ErrRetentionPolicyNotFound
+	}
+	mapping := mappings[0]
+
+	if stmt.Duration != nil {
+		if *stmt.Duration != 0 && *stmt.Duration < meta.MinRetentionPolicyDuration {
+			return meta.ErrRetentionPolicyDurationTooLow
+		}
+		if _, err := e.BucketService.UpdateBucket(ctx, mapping.BucketID, influxdb.BucketUpdate{
+			RetentionPeriod: stmt.Duration,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if stmt.Default && !mapping.Default {
+		mapping.Default = true
+		if err := e.DBRP.Update(ctx, mapping); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeDropDatabaseStatement(ctx context.Context, stmt *influxql.DropDatabaseStatement, ectx *query.ExecutionContext) error {
+	if e.BucketService == nil {
+		return iql.ErrNotImplemented("DROP DATABASE")
+	}
+
+	// Dropping a database that does not exist is not an error.
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &stmt.Name,
+	})
+	if err != nil {
+		return err
+	}
+	for _, m := range mappings {
+		if err := e.dropRetentionPolicy(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeDropRetentionPolicyStatement(ctx context.Context, stmt *influxql.DropRetentionPolicyStatement, ectx *query.ExecutionContext) error {
+	if e.BucketService == nil {
+		return iql.ErrNotImplemented("DROP RETENTION POLICY")
+	}
+
+	// Dropping a retention policy that does not exist is not an error.
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:           &ectx.OrgID,
+		Database:        &stmt.Database,
+		RetentionPolicy: &stmt.Name,
+	})
+	if err != nil {
+		return err
+	}
+	for _, m := range mappings {
+		if err := e.dropRetentionPolicy(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropRetentionPolicy deletes a DBRP mapping and the bucket it maps to.
+// The bucket is kept if other mappings still refer to it.
+func (e *StatementExecutor) dropRetentionPolicy(ctx context.Context, mapping *influxdb.DBRPMappingV2) error {
+	if err := e.DBRP.Delete(ctx, mapping.OrganizationID, mapping.ID); err != nil {
+		return err
+	}
+
+	_, n, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &mapping.OrganizationID,
+		BucketID: &mapping.BucketID,
+	})
+	if err != nil {
+		return err
+	} else if n > 0 {
+		return nil
+	}
+
+	if err := e.BucketService.DeleteBucket(ctx, mapping.BucketID); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeShowDatabasesStatement(ctx context.Context, q *influxql.ShowDatabasesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	row := &models.Row{Name: "databases", Columns: []string{"name"}}
+	// TODO(gianarb): How pagination works here?
+	dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID: &ectx.OrgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, dbrp := range dbrps {
+		perm, err := influxdb.NewPermissionAtID(dbrp.BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, dbrp.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		err = authorizer.IsAllowed(ctx, *perm)
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+				continue
+			}
+			return nil, err
+		}
+		row.Values = append(row.Values, []interface{}{dbrp.Database})
+	}
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) getDefaultRP(ctx context.Context, database string, ectx *query.ExecutionContext) (*influxdb.DBRPMappingV2, error) {
+	defaultRP := true
+	mappings, n, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &database,
+		Default:  &defaultRP,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("finding DBRP mappings: %v", err)
+	} else if n == 0 {
+		return nil, fmt.Errorf("default retention policy not set for: %s", database)
+	} else if n != 1 {
+		return nil, fmt.Errorf("finding DBRP mappings: expected 1, found %d", n)
+	}
+	return mappings[0], nil
+}
+
+func (e *StatementExecutor) executeDeleteSeriesStatement(ctx context.Context, q *influxql.DeleteSeriesStatement, database string, ectx *query.ExecutionContext) error {
+	if database == "" {
+		return ErrDatabaseNameRequired
+	}
+
+	mapping, err := e.getDefaultRP(ctx, database, ectx)
+	if err != nil {
+		return err
+	}
+
+	// Convert "now()" to current time.
+	q.Condition = influxql.Reduce(q.Condition, &influxql.NowValuer{Now: time.Now().UTC()})
+
+	return e.deleteSeries(ctx, mapping, q.Sources, q.Condition)
+}
+
+func (e *StatementExecutor) executeDropSeriesStatement(ctx context.Context, q *influxql.DropSeriesStatement, database string, ectx *query.ExecutionContext) error {
+	if database == "" {
+		return ErrDatabaseNameRequired
+	}
+
+	mapping, err := e.getDefaultRP(ctx, database, ectx)
+	if err != nil {
+		return err
+	}
+
+	_, timeRange, err := influxql.ConditionExpr(q.Condition, &influxql.NowValuer{Now: time.Now().UTC()})
+	if err != nil {
+		return err
+	} else if !timeRange.IsZero() {
+		return errors.New("DROP SERIES doesn't support time in WHERE clause")
+	}
+
+	return e.deleteSeries(ctx, mapping, q.Sources, q.Condition)
+}
+
+// deleteSeries deletes the data of the series in the mapped bucket that match
+// the sources and the condition, including its time range.
+func (e *StatementExecutor) deleteSeries(ctx context.Context, mapping *influxdb.DBRPMappingV2, sources influxql.Sources, condition influxql.Expr) error {
+	if e.DeleteService == nil {
+		return iql.ErrNotImplemented("DELETE")
+	}
+
+	perm, err := influxdb.NewPermissionAtID(mapping.BucketID, influxdb.WriteAction, influxdb.BucketsResourceType, mapping.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+		return err
+	}
+
+	cond, timeRange, err := influxql.ConditionExpr(condition, nil)
+	if err != nil {
+		return err
+	}
+
+	node, err := predicate.FromInfluxQL(cond)
+	if influxdb.ErrorCode(err) == influxdb.ENotImplemented {
+		// Conditions with OR or regular expressions have no delete
+		// predicate, so they are evaluated by the store against the index.
+		return e.TSDBStore.DeleteSeries(mapping.BucketID.String(), sources, condition)
+	} else if err != nil {
+		return err
+	}
+
+	min, max := int64(influxql.MinTime), int64(influxql.MaxTime)
+	if !timeRange.Min.IsZero() {
+		min = timeRange.Min.UnixNano()
+	}
+	if !timeRange.Max.IsZero() {
+		max = timeRange.Max.UnixNano()
+	}
+
+	// Without a FROM clause, the series of all measurements are deleted.
+	if len(sources) == 0 {
+		pred, err := predicate.New(node)
+		if err != nil {
+			return err
+		}
+		return e.DeleteService.DeleteBucketRangePredicate(ctx, mapping.OrganizationID, mapping.BucketID, min, max, pred)
+	}
+
+	names, err := e.sourceMeasurementNames(mapping, sources)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		var n predicate.Node = predicate.TagRuleNode{
+			Tag:      influxdb.Tag{Key: "_measurement", Value: name},
+			Operator: influxdb.Equal,
+		}
+		if node != nil {
+			n = predicate.LogicalNode{Operator: predicate.LogicalAnd, Children: [2]predicate.Node{n, node}}
+		}
+
+		pred, err := predicate.New(n)
+		if err != nil {
+			return err
+		}
+		if err := e.DeleteService.DeleteBucketRangePredicate(ctx, mapping.OrganizationID, mapping.BucketID, min, max, pred); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sourceMeasurementNames returns the names of the measurements in sources,
+// expanding regular expressions against the measurements of the mapped bucket.
+func (e *StatementExecutor) sourceMeasurementNames(mapping *influxdb.DBRPMappingV2, sources influxql.Sources) ([]string, error) {
+	var all [][]byte
+	var names []string
+	for _, source := range sources {
+		m, ok := source.(*influxql.Measurement)
+		if !ok {
+			return nil, fmt.Errorf("invalid source: %s", source)
+		}
+
+		if m.Regex == nil {
+			names = append(names, m.Name)
+			continue
+		}
+
+		if all == nil {
+			var err error
+			if all, err = e.TSDBStore.MeasurementNames(query.OpenAuthorizer, mapping.BucketID.String(), nil); err != nil {
+				return nil, err
+			}
+		}
+		for _, name := range all {
+			if m.Regex.Val.Match(name) {
+				names = append(names, string(name))
+			}
+		}
+	}
+	return names, nil
+}
+
+func (e *StatementExecutor) executeDropMeasurementStatement(ctx context.Context, q *influxql.DropMeasurementStatement, database string, ectx *query.ExecutionContext) error {
+	mapping, err := e.getDefaultRP(ctx, database, ectx)
+	if err != nil {
+		return err
+	}
+	return e.TSDBStore.DeleteMeasurement(mapping.BucketID.String(), q.Name)
+}
+
+func (e *StatementExecutor) executeShowMeasurementsStatement(ctx context.Context, q *influxql.ShowMeasurementsStatement, ectx *query.ExecutionContext) error {
+	if q.Database == "" {
+		return ErrDatabaseNameRequired
+	}
+
+	mapping, err := e.getDefaultRP(ctx, q.Database, ectx)
+	if err != nil {
+		return err
+	}
+
+	names, err := e.TSDBStore.MeasurementNames(ectx.Authorizer, mapping.BucketID.String(), q.Condition)
+	if err != nil || len(names) == 0 {
+		return ectx.Send(ctx, &query.Result{
+			Err: err,
+		})
+	}
+
+	if q.Offset > 0 {
+		if q.Offset >= len(names) {
+			names = nil
+		} else {
+			names = names[q.Offset:]
+		}
+	}
+
+	if q.Limit > 0 {
+		if q.Limit < len(names) {
+			names = names[:q.Limit]
+		}
+	}
+
+	values := make([][]interface{}, len(names))
+	for i, name := range names {
+		values[i] = []interface{}{string(name)}
+	}
+
+	if len(values) == 0 {
+		return ectx.Send(ctx, &query.Result{})
+	}
+
+	return ectx.Send(ctx, &query.Result{
+		Series: []*models.Row{{
+			Name:    "measurements",
+			Columns: []string{"name"},
+			Values:  values,
+		}},
+	})
+}
+
+func (e *StatementExecutor) executeShowMeasurementCardinalityStatement(ctx context.Context, stmt *influxql.ShowMeasurementCardinalityStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if stmt.Database == "" {
+		return nil, ErrDatabaseNameRequired
+	}
+
+	mappings, err := e.getCardinalityDBRPs(ctx, stmt.Database, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Measurements with the same name in different buckets are counted
+	// once, so merge the sketches of each bucket before estimating.
+	var ss, ts estimator.Sketch
+	for _, m := range mappings {
+		s, t, err := e.TSDBStore.MeasurementsSketches(m.BucketID.String())
+		if err != nil {
+			return nil, err
+		}
+		if ss == nil {
+			ss, ts = s.Clone(), t.Clone()
+			continue
+		}
+		if err := ss.Merge(s); err != nil {
+			return nil, err
+		}
+		if err := ts.Merge(t); err != nil {
+			return nil, err
+		}
+	}
+
+	var n int64
+	if ss != nil {
+		n = int64(ss.Count() - ts.Count())
+	}
+	return []*models.Row{{
+		Columns: []string{"cardinality estimation"},
+		Values:  [][]interface{}{{n}},
+	}}, nil
+}
+
+func (e *StatementExecutor) executeShowSeriesCardinalityStatement(ctx context.Context, stmt *influxql.ShowSeriesCardinalityStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if stmt.Database == "" {
+		return nil, ErrDatabaseNameRequired
+	}
+
+	mappings, err := e.getCardinalityDBRPs(ctx, stmt.Database, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Series are never shared between buckets.
+	var n int64
+	for _, m := range mappings {
+		c, err := e.TSDBStore.SeriesCardinality(m.BucketID.String())
+		if err != nil {
+			return nil, err
+		}
+		n += c
+	}
+	return []*models.Row{{
+		Columns: []string{"cardinality estimation"},
+		Values:  [][]interface{}{{n}},
+	}}, nil
+}
+
+// getCardinalityDBRPs returns the DBRP mappings whose buckets are included in
+// the cardinality of a database. Only the bucket of the retention policy is
+// included if one was given with the query, otherwise all of the buckets
+// of the database are. The caller must be allowed to read each bucket.
+func (e *StatementExecutor) getCardinalityDBRPs(ctx context.Context, database string, ectx *query.ExecutionContext) ([]*influxdb.DBRPMappingV2, error) {
+	filter := influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &database,
+	}
+	if ectx.RetentionPolicy != "" {
+		filter.RetentionPolicy = &ectx.RetentionPolicy
+	}
+
+	mappings, _, err := e.DBRP.FindMany(ctx, filter)
+	if err != nil {
+		return nil, err
+	} else if len(mappings) == 0 {
+		return nil, query.ErrDatabaseNotFound(database)
+	}
+
+	for _, m := range mappings {
+		perm, err := influxdb.NewPermissionAtID(m.BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, m.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+			return nil, err
+		}
+	}
+	return mappings, nil
+}
+
+func (e *StatementExecutor) executeShowShardsStatement(ctx context.Context, stmt *influxql.ShowShardsStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if e.ShardService == nil {
+		return nil, iql.ErrNotImplemented("SHOW SHARDS")
+	}
+
+	mappings, err := e.findReadableDBRPs(ctx, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []*models.Row{}
+	var row *models.Row
+	for _, m := range mappings {
+		shards, err := e.ShardService.FindShards(ctx, m.BucketID)
+		if err != nil {
+			return nil, err
+		}
+
+		if row == nil || row.Name != m.Database {
+			row = &models.Row{Columns: []string{"id", "database", "retention_policy", "shard_group", "start_time", "end_time", "expiry_time", "size"}, Name: m.Database}
+			rows = append(rows, row)
+		}
+		for _, sh := range shards {
+			row.Values = append(row.Values, []interface{}{
+				sh.ID,
+				m.Database,
+				m.RetentionPolicy,
+				sh.ShardGroupID,
+				formatShardTime(sh.StartTime),
+				formatShardTime(sh.EndTime),
+				formatShardTime(sh.ExpiryTime),
+				sh.Size,
+			})
+		}
+	}
+	return rows, nil
+}
+
+func (e *StatementExecutor) executeShowShardGroupsStatement(ctx context.Context, stmt *influxql.ShowShardGroupsStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if e.ShardService == nil {
+		return nil, iql.ErrNotImplemented("SHOW SHARD GROUPS")
+	}
+
+	mappings, err := e.findReadableDBRPs(ctx, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &models.Row{Columns: []string{"id", "database", "retention_policy", "start_time", "end_time", "expiry_time"}, Name: "shard groups"}
+	for _, m := range mappings {
+		shards, err := e.ShardService.FindShards(ctx, m.BucketID)
+		if err != nil {
+			return nil, err
+		}
+
+		seen := make(map[uint64]bool)
+		for _, sh := range shards {
+			if seen[sh.ShardGroupID] {
+				continue
+			}
+			seen[sh.ShardGroupID] = true
+			row.Values = append(row.Values, []interface{}{
+				sh.ShardGroupID,
+				m.Database,
+				m.RetentionPolicy,
+				formatShardTime(sh.StartTime),
+				formatShardTime(sh.EndTime),
+				formatShardTime(sh.ExpiryTime),
+			})
+		}
+	}
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) executeDropShardStatement(ctx context.Context, stmt *influxql.DropShardStatement, ectx *query.ExecutionContext) error {
+	if e.ShardService == nil {
+		return iql.ErrNotImplemented("DROP SHARD")
+	}
+
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID: &ectx.OrgID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The shard must belong to a bucket of the organization. Several
+	// mappings may share a bucket, so each bucket is searched once.
+	seen := make(map[influxdb.ID]bool, len(mappings))
+	for _, m := range mappings {
+		if seen[m.BucketID] {
+			continue
+		}
+		seen[m.BucketID] = true
+
+		shards, err := e.ShardService.FindShards(ctx, m.BucketID)
+		if err != nil {
+			return err
+		}
+		for _, sh := range shards {
+			if sh.ID != stmt.ID {
+				continue
+			}
+
+			perm, err := influxdb.NewPermissionAtID(m.BucketID, influxdb.WriteAction, influxdb.BucketsResourceType, m.OrganizationID)
+			if err != nil {
+				return err
+			}
+			if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+				return err
+			}
+			return e.ShardService.DeleteShard(ctx, sh.ID)
+		}
+	}
+	return ErrShardNotFound
+}
+
+// findReadableDBRPs returns the mappings of the organization whose buckets
+// the caller is allowed to read, ordered by database and retention policy.
+func (e *StatementExecutor) findReadableDBRPs(ctx context.Context, ectx *query.ExecutionContext) ([]*influxdb.DBRPMappingV2, error) {
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID: &ectx.OrgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	readable := make([]*influxdb.DBRPMappingV2, 0, len(mappings))
+	for _, m := range mappings {
+		perm, err := influxdb.NewPermissionAtID(m.BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, m.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+				continue
+			}
+			return nil, err
+		}
+		readable = append(readable, m)
+	}
+
+	sort.Slice(readable, func(i, j int) bool {
+		if readable[i].Database != readable[j].Database {
+			return readable[i].Database < readable[j].Database
+		}
+		return readable[i].RetentionPolicy < readable[j].RetentionPolicy
+	})
+	return readable, nil
+}
+
+// formatShardTime formats a shard time as RFC3339, or as an empty
+// string for the zero time.
+func formatShardTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func (e *StatementExecutor) executeShowQueriesStatement(ctx context.Context, stmt *influxql.ShowQueriesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if e.RunningQueryService == nil {
+		return nil, iql.ErrNotImplemented("SHOW QUERIES")
+	}
+
+	queries, err := e.RunningQueryService.FindRunningQueries(ctx, influxdb.RunningQueryFilter{
+		OrgID: &ectx.OrgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([][]interface{}, 0, len(queries))
+	for _, q := range queries {
+		var user string
+		if q.UserID.Valid() {
+			user = q.UserID.String()
+		}
+		values = append(values, []interface{}{
+			q.ID,
+			q.Query,
+			q.Database,
+			formatDuration(q.Duration),
+			q.State,
+			q.Language,
+			user,
+			q.Memory,
+		})
+	}
+	return []*models.Row{{
+		Columns: []string{"qid", "query", "database", "duration", "status", "language", "user", "memory"},
+		Values:  values,
+	}}, nil
+}
+
+func (e *StatementExecutor) executeKillQueryStatement(ctx context.Context, stmt *influxql.KillQueryStatement, ectx *query.ExecutionContext) error {
+	if e.RunningQueryService == nil {
+		return iql.ErrNotImplemented("KILL QUERY")
+	}
+	if stmt.Host != "" {
+		return errors.New("killing queries on another host is not supported")
+	}
+
+	// Only the queries of the organization may be killed.
+	queries, err := e.RunningQueryService.FindRunningQueries(ctx, influxdb.RunningQueryFilter{
+		ID:    &stmt.QueryID,
+		OrgID: &ectx.OrgID,
+	})
+	if err != nil {
+		return err
+	} else if len(queries) == 0 {
+		return influxdb.ErrRunningQueryNotFound
+	}
+	return e.RunningQueryService.KillQuery(ctx, stmt.QueryID)
+}
+
+func (e *StatementExecutor) executeCreateUserStatement(ctx context.Context, stmt *influxql.CreateUserStatement, ectx *query.ExecutionContext) error {
+	if e.V1AuthorizationService == nil {
+		return iql.ErrNotImplemented("CREATE USER")
+	}
+	if stmt.Name == "" {
+		return meta.ErrUsernameRequired
+	}
 
-	plan, err := p.Explain(ctx)
+	// The authorization of the v1 user is owned by the caller.
+	userID, err := icontext.GetUserID(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	plan = strings.TrimSpace(plan)
 
-	row := &models.Row{
-		Columns: []string{"QUERY PLAN"},
+	a := &influxdb.Authorization{
+		Token:       stmt.Name,
+		Status:      influxdb.Active,
+		Description: fmt.Sprintf("v1 user %s", stmt.Name),
+		OrgID:       ectx.OrgID,
+		UserID:      userID,
+		Permissions: []influxdb.Permission{},
 	}
-	for _, s := range strings.Split(plan, "\n") {
-		row.Values = append(row.Values, []interface{}{s})
+	if stmt.Admin {
+		a.Permissions = adminPermissions(ectx.OrgID)
 	}
-	return models.Rows{row}, nil
+	if err := e.V1AuthorizationService.CreateAuthorization(ctx, a); err != nil {
+		if influxdb.ErrorCode(err) == influxdb.EConflict {
+			return meta.ErrUserExists
+		}
+		return err
+	}
+
+	if err := e.V1AuthorizationService.SetPassword(ctx, a.ID, stmt.Password); err != nil {
+		// Do not leave behind a user that cannot authenticate.
+		_ = e.V1AuthorizationService.DeleteAuthorization(ctx, a.ID)
+		return err
+	}
+	return nil
 }
 
-func (e *StatementExecutor) executeExplainAnalyzeStatement(ctx context.Context, q *influxql.ExplainStatement, ectx *query.ExecutionContext) (models.Rows, error) {
-	stmt := q.Statement
-	t, span := tracing.NewTrace("select")
-	ctx = tracing.NewContextWithTrace(ctx, t)
-	ctx = tracing.NewContextWithSpan(ctx, span)
-	var aux query.Iterators
-	ctx = query.NewContextWithIterators(ctx, &aux)
-	start := time.Now()
+func (e *StatementExecutor) executeDropUserStatement(ctx context.Context, stmt *influxql.DropUserStatement, ectx *query.ExecutionContext) error {
+	if e.V1AuthorizationService == nil {
+		return iql.ErrNotImplemented("DROP USER")
+	}
 
-	cur, err := e.createIterators(ctx, stmt, ectx.ExecutionOptions, ectx.StatisticsGatherer)
+	a, err := e.findV1User(ctx, stmt.Name, ectx)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return e.V1AuthorizationService.DeleteAuthorization(ctx, a.ID)
+}
 
-	iterTime := time.Since(start)
-
-	// Generate a row emitter from the iterator set.
-	em := query.NewEmitter(cur, ectx.ChunkSize)
-
-	// Emit rows to the results channel.
-	var writeN int64
-	for {
-		var row *models.Row
-		row, _, err = em.Emit()
-		if err != nil {
-			goto CLEANUP
-		} else if row == nil {
-			// Check if the query was interrupted while emitting.
-			if err = ctx.Err(); err != nil {
-				goto CLEANUP
-			}
-			break
-		}
-
-		writeN += int64(len(row.Values))
+func (e *StatementExecutor) executeSetPasswordUserStatement(ctx context.Context, stmt *influxql.SetPasswordUserStatement, ectx *query.ExecutionContext) error {
+	if e.V1AuthorizationService == nil {
+		return iql.ErrNotImplemented("SET PASSWORD")
 	}
 
-CLEANUP:
-	em.Close()
+	a, err := e.findV1User(ctx, stmt.Name, ectx)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return e.V1AuthorizationService.SetPassword(ctx, a.ID, stmt.Password)
+}
 
-	// close auxiliary iterators deterministically to finalize any captured measurements
-	aux.Close()
-
-	totalTime := time.Since(start)
-	span.MergeFields(
-		fields.Duration("total_time", totalTime),
-		fields.Duration("planning_time", iterTime),
-		fields.Duration("execution_time", totalTime-iterTime),
-	)
-	span.Finish()
+func (e *StatementExecutor) executeGrantStatement(ctx context.Context, stmt *influxql.GrantStatement, ectx *query.ExecutionContext) error {
+	if e.V1AuthorizationService == nil {
+		return iql.ErrNotImplemented("GRANT")
+	}
 
-	row := &models.Row{
-		Columns: []string{"EXPLAIN ANALYZE"},
+	a, err := e.findV1User(ctx, stmt.User, ectx)
+	if err != nil {
+		return err
 	}
-	for _, s := range strings.Split(t.Tree().String(), "\n") {
-		row.Values = append(row.Values, []interface{}{s})
+	perms, err := e.databasePermissions(ctx, stmt.On, stmt.Privilege, ectx)
+	if err != nil {
+		return err
 	}
-
-	return models.Rows{row}, nil
+	_, err = e.V1AuthorizationService.SetPermissions(ctx, a.ID, addPermissions(a.Permissions, perms))
+	return err
 }
 
-func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *influxql.SelectStatement, ectx *query.ExecutionContext) error {
-	cur, err := e.createIterators(ctx, stmt, ectx.ExecutionOptions, ectx.StatisticsGatherer)
+func (e *StatementExecutor) executeGrantAdminStatement(ctx context.Context, stmt *influxql.GrantAdminStatement, ectx *query.ExecutionContext) error {
+	if e.V1AuthorizationService == nil {
+		return iql.ErrNotImplemented("GRANT ALL")
+	}
+
+	a, err := e.findV1User(ctx, stmt.User, ectx)
 	if err != nil {
 		return err
 	}
+	_, err = e.V1AuthorizationService.SetPermissions(ctx, a.ID, addPermissions(a.Permissions, adminPermissions(ectx.OrgID)))
+	return err
+}
 
-	// Generate a row emitter from the iterator set.
-	em := query.NewEmitter(cur, ectx.ChunkSize)
-	defer em.Close()
-
-	// Emit rows to the results channel.
-	var emitted bool
-
-	if stmt.Target != nil {
-		// SELECT INTO is unsupported
-		return iql.ErrNotImplemented("SELECT INTO")
+func (e *StatementExecutor) executeRevokeStatement(ctx context.Context, stmt *influxql.RevokeStatement, ectx *query.ExecutionContext) error {
+	if e.V1AuthorizationService == nil {
+		return iql.ErrNotImplemented("REVOKE")
 	}
 
-	for {
-		row, partial, err := em.Emit()
-		if err != nil {
-			return err
-		} else if row == nil {
-			// Check if the query was interrupted while emitting.
-			if err := ctx.Err(); err != nil {
-				return err
-			}
-			break
-		}
+	a, err := e.findV1User(ctx, stmt.User, ectx)
+	if err != nil {
+		return err
+	}
+	perms, err := e.databasePermissions(ctx, stmt.On, stmt.Privilege, ectx)
+	if err != nil {
+		return err
+	}
+	_, err = e.V1AuthorizationService.SetPermissions(ctx, a.ID, removePermissions(a.Permissions, perms))
+	return err
+}
 
-		result := &query.Result{
-			Series:  []*models.Row{row},
-			Partial: partial,
-		}
+func (e *StatementExecutor) executeRevokeAdminStatement(ctx context.Context, stmt *influxql.RevokeAdminStatement, ectx *query.ExecutionContext) error {
+	if e.V1AuthorizationService == nil {
+		return iql.ErrNotImplemented("REVOKE ALL")
+	}
 
-		// Send results or exit if closing.
-		if err := ectx.Send(ctx, result); err != nil {
-			return err
-		}
+	a, err := e.findV1User(ctx, stmt.User, ectx)
+	if err != nil {
+		return err
+	}
+	_, err = e.V1AuthorizationService.SetPermissions(ctx, a.ID, removePermissions(a.Permissions, adminPermissions(ectx.OrgID)))
+	return err
+}
 
-		emitted = true
+func (e *StatementExecutor) executeShowUsersStatement(ctx context.Context, stmt *influxql.ShowUsersStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if e.V1AuthorizationService == nil {
+		return nil, iql.ErrNotImplemented("SHOW USERS")
 	}
 
-	// Always emit at least one result.
-	if !emitted {
-		return ectx.Send(ctx, &query.Result{
-			Series: make([]*models.Row, 0),
-		})
+	auths, _, err := e.V1AuthorizationService.FindAuthorizations(ctx, influxdb.AuthorizationFilter{
+		OrgID: &ectx.OrgID,
+	})
+	if err != nil {
+		return nil, err
 	}
+	sort.Slice(auths, func(i, j int) bool {
+		return auths[i].Token < auths[j].Token
+	})
 
-	return nil
+	row := &models.Row{Columns: []string{"user", "admin"}}
+	for _, a := range auths {
+		admin := len(removePermissions(adminPermissions(ectx.OrgID), a.Permissions)) == 0
+		row.Values = append(row.Values, []interface{}{a.Token, admin})
+	}
+	return []*models.Row{row}, nil
 }
 
-func (e *StatementExecutor) createIterators(ctx context.Context, stmt *influxql.SelectStatement, opt query.ExecutionOptions, gatherer *iql.StatisticsGatherer) (query.Cursor, error) {
-	defer func(start time.Time) {
-		dur := time.Since(start)
-		gatherer.Append(iql.NewImmutableCollector(iql.Statistics{PlanDuration: dur}))
-	}(time.Now())
-
-	sopt := query.SelectOptions{
-		OrgID:              opt.OrgID,
-		NodeID:             opt.NodeID,
-		MaxSeriesN:         e.MaxSelectSeriesN,
-		MaxPointN:          e.MaxSelectPointN,
-		MaxBucketsN:        e.MaxSelectBucketsN,
-		StatisticsGatherer: gatherer,
+func (e *StatementExecutor) executeShowGrantsForUserStatement(ctx context.Context, stmt *influxql.ShowGrantsForUserStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if e.V1AuthorizationService == nil {
+		return nil, iql.ErrNotImplemented("SHOW GRANTS")
 	}
 
-	// Create a set of iterators from a selection.
-	cur, err := query.Select(ctx, stmt, e.ShardMapper, sopt)
+	a, err := e.findV1User(ctx, stmt.Name, ectx)
 	if err != nil {
 		return nil, err
 	}
-	return cur, nil
-}
 
-func (e *StatementExecutor) executeShowDatabasesStatement(ctx context.Context, q *influxql.ShowDatabasesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
-	row := &models.Row{Name: "databases", Columns: []string{"name"}}
-	// TODO(gianarb): How pagination works here?
-	dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
 		OrgID: &ectx.OrgID,
 	})
 	if err != nil {
 		return nil, err
 	}
-	for _, dbrp := range dbrps {
-		perm, err := influxdb.NewPermissionAtID(dbrp.BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, dbrp.OrganizationID)
-		if err != nil {
-			return nil, err
-		}
-		err = authorizer.IsAllowed(ctx, *perm)
-		if err != nil {
-			if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+
+	// A privilege is granted on a database when it is granted on
+	// any of the buckets of its retention policies.
+	granted := permissionSet(a.Permissions)
+	privileges := make(map[string]influxql.Privilege)
+	for _, m := range mappings {
+		p := privileges[m.Database]
+		for _, action := range []influxdb.Action{influxdb.ReadAction, influxdb.WriteAction} {
+			perm, err := influxdb.NewPermissionAtID(m.BucketID, action, influxdb.BucketsResourceType, m.OrganizationID)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := granted[perm.String()]; !ok {
 				continue
 			}
-			return nil, err
+			if action == influxdb.ReadAction {
+				p |= influxql.ReadPrivilege
+			} else {
+				p |= influxql.WritePrivilege
+			}
 		}
-		row.Values = append(row.Values, []interface{}{dbrp.Database})
+		privileges[m.Database] = p
 	}
-	return []*models.Row{row}, nil
-}
 
-func (e *StatementExecutor) getDefaultRP(ctx context.Context, database string, ectx *query.ExecutionContext) (*influxdb.DBRPMappingV2, error) {
-	defaultRP := true
-	mappings, n, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
-		OrgID:    &ectx.OrgID,
-		Database: &database,
-		Default:  &defaultRP,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("finding DBRP mappings: %v", err)
-	} else if n == 0 {
-		return nil, fmt.Errorf("default retention policy not set for: %s", database)
-	} else if n != 1 {
-		return nil, fmt.Errorf("finding DBRP mappings: expected 1, found %d", n)
+	databases := make([]string, 0, len(privileges))
+	for db, p := range privileges {
+		if p != influxql.NoPrivileges {
+			databases = append(databases, db)
+		}
 	}
-	return mappings[0], nil
-}
+	sort.Strings(databases)
 
-func (e *StatementExecutor) executeDeleteSeriesStatement(ctx context.Context, q *influxql.DeleteSeriesStatement, database string, ectx *query.ExecutionContext) error {
-	mapping, err := e.getDefaultRP(ctx, database, ectx)
-	if err != nil {
-		return err
+	row := &models.Row{Columns: []string{"database", "privilege"}}
+	for _, db := range databases {
+		row.Values = append(row.Values, []interface{}{db, privileges[db].String()})
 	}
+	return []*models.Row{row}, nil
+}
 
-	// Convert "now()" to current time.
-	q.Condition = influxql.Reduce(q.Condition, &influxql.NowValuer{Now: time.Now().UTC()})
-
-	return e.TSDBStore.DeleteSeries(mapping.BucketID.String(), q.Sources, q.Condition)
+// findV1User returns the authorization of the v1 user of the organization with the given name.
+func (e *StatementExecutor) findV1User(ctx context.Context, name string, ectx *query.ExecutionContext) (*influxdb.Authorization, error) {
+	a, err := e.V1AuthorizationService.FindAuthorizationByToken(ctx, name)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return nil, meta.ErrUserNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if a.OrgID != ectx.OrgID {
+		return nil, meta.ErrUserNotFound
+	}
+	return a, nil
 }
 
-func (e *StatementExecutor) executeDropMeasurementStatement(ctx context.Context, q *influxql.DropMeasurementStatement, database string, ectx *query.ExecutionContext) error {
-	mapping, err := e.getDefaultRP(ctx, database, ectx)
+// databasePermissions returns the bucket permissions of a privilege on all
+// of the retention policies of a database.
+func (e *StatementExecutor) databasePermissions(ctx context.Context, database string, privilege influxql.Privilege, ectx *query.ExecutionContext) ([]influxdb.Permission, error) {
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &database,
+	})
 	if err != nil {
-		return err
+		return nil, err
+	} else if len(mappings) == 0 {
+		return nil, query.ErrDatabaseNotFound(database)
 	}
-	return e.TSDBStore.DeleteMeasurement(mapping.BucketID.String(), q.Name)
-}
 
-func (e *StatementExecutor) executeShowMeasurementsStatement(ctx context.Context, q *influxql.ShowMeasurementsStatement, ectx *query.ExecutionContext) error {
-	if q.Database == "" {
-		return ErrDatabaseNameRequired
+	var actions []influxdb.Action
+	if privilege&influxql.ReadPrivilege != 0 {
+		actions = append(actions, influxdb.ReadAction)
+	}
+	if privilege&influxql.WritePrivilege != 0 {
+		actions = append(actions, influxdb.WriteAction)
 	}
 
-	mapping, err := e.getDefaultRP(ctx, q.Database, ectx)
-	if err != nil {
-		return err
+	var perms []influxdb.Permission
+	for _, m := range mappings {
+		for _, action := range actions {
+			perm, err := influxdb.NewPermissionAtID(m.BucketID, action, influxdb.BucketsResourceType, m.OrganizationID)
+			if err != nil {
+				return nil, err
+			}
+			perms = append(perms, *perm)
+		}
 	}
+	return perms, nil
+}
 
-	names, err := e.TSDBStore.MeasurementNames(ectx.Authorizer, mapping.BucketID.String(), q.Condition)
-	if err != nil || len(names) == 0 {
-		return ectx.Send(ctx, &query.Result{
-			Err: err,
+// adminPermissions returns the permissions of an admin v1 user, which
+// may read and write all the buckets of the organization.
+func adminPermissions(orgID influxdb.ID) []influxdb.Permission {
+	perms := make([]influxdb.Permission, 0, 2)
+	for _, action := range []influxdb.Action{influxdb.ReadAction, influxdb.WriteAction} {
+		perms = append(perms, influxdb.Permission{
+			Action: action,
+			Resource: influxdb.Resource{
+				Type:  influxdb.BucketsResourceType,
+				OrgID: &orgID,
+			},
 		})
 	}
+	return perms
+}
 
-	if q.Offset > 0 {
-		if q.Offset >= len(names) {
-			names = nil
-		} else {
-			names = names[q.Offset:]
-		}
+func permissionSet(ps []influxdb.Permission) map[string]struct{} {
+	set := make(map[string]struct{}, len(ps))
+	for _, p := range ps {
+		set[p.String()] = struct{}{}
 	}
+	return set
+}
 
-	if q.Limit > 0 {
-		if q.Limit < len(names) {
-			names = names[:q.Limit]
+// addPermissions returns the permissions of ps followed by those of add
+// that are not already in ps.
+func addPermissions(ps, add []influxdb.Permission) []influxdb.Permission {
+	set := permissionSet(ps)
+	res := append([]influxdb.Permission{}, ps...)
+	for _, p := range add {
+		if _, ok := set[p.String()]; ok {
+			continue
 		}
+		set[p.String()] = struct{}{}
+		res = append(res, p)
 	}
+	return res
+}
 
-	values := make([][]interface{}, len(names))
-	for i, name := range names {
-		values[i] = []interface{}{string(name)}
+// removePermissions returns the permissions of ps that are not in rm.
+func removePermissions(ps, rm []influxdb.Permission) []influxdb.Permission {
+	set := permissionSet(rm)
+	res := make([]influxdb.Permission, 0, len(ps))
+	for _, p := range ps {
+		if _, ok := set[p.String()]; !ok {
+			res = append(res, p)
+		}
 	}
+	return res
+}
 
-	if len(values) == 0 {
-		return ectx.Send(ctx, &query.Result{})
+// formatDuration formats the duration of a query with a precision
+// that depends on its magnitude.
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%dms", int(d.Seconds()*1000))
+	case d >= time.Microsecond:
+		return fmt.Sprintf("%dµs", int(d.Seconds()*1000000))
 	}
-
-	return ectx.Send(ctx, &query.Result{
-		Series: []*models.Row{{
-			Name:    "measurements",
-			Columns: []string{"name"},
-			Values:  values,
-		}},
-	})
+	return fmt.Sprintf("%dns", int(d.Nanoseconds()))
 }
 
 func (e *StatementExecutor) executeShowRetentionPoliciesStatement(ctx context.Context, q *influxql.ShowRetentionPoliciesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
@@ -648,6 +2000,11 @@ func (e *StatementExecutor) executeShowTagValues(ctx context.Context, q *influxq
 // NormalizeStatement adds a default database and policy to the measurements in statement.
 // Parameter defaultRetentionPolicy can be "".
 func (e *StatementExecutor) NormalizeStatement(ctx context.Context, stmt influxql.Statement, defaultDatabase, defaultRetentionPolicy string, ectx *query.ExecutionContext) (err error) {
+	// Measurements in a continuous query belong to the database it is created on.
+	if stmt, ok := stmt.(*influxql.CreateContinuousQueryStatement); ok {
+		defaultDatabase, defaultRetentionPolicy = stmt.Database, ""
+	}
+
 	influxql.WalkFunc(stmt, func(node influxql.Node) {
 		if err != nil {
 			return
@@ -747,11 +2104,18 @@ func (m mappings) DefaultRetentionPolicy(db string) string {
 	return ""
 }
 
+// BucketWriter writes points into a bucket of the storage engine.
+type BucketWriter interface {
+	WritePoints(ctx context.Context, orgID influxdb.ID, bucketID influxdb.ID, points []models.Point) error
+}
+
 // TSDBStore is an interface for accessing the time series data store.
 type TSDBStore interface {
 	DeleteMeasurement(database, name string) error
 	DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error
 	MeasurementNames(auth query.Authorizer, database string, cond influxql.Expr) ([][]byte, error)
+	MeasurementsSketches(database string) (estimator.Sketch, estimator.Sketch, error)
+	SeriesCardinality(database string) (int64, error)
 	TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error)
 	TagValues(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error)
 }
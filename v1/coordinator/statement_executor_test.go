@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,10 +19,17 @@ import (
 	influxql2 "github.com/influxdata/influxdb/v2/influxql"
 	"github.com/influxdata/influxdb/v2/influxql/control"
 	"github.com/influxdata/influxdb/v2/influxql/query"
+	"github.com/influxdata/influxdb/v2/inmem"
 	"github.com/influxdata/influxdb/v2/internal"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/mock"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator"
+	"github.com/influxdata/influxdb/v2/pkg/estimator/hll"
+	"github.com/influxdata/influxdb/v2/tenant"
 	itesting "github.com/influxdata/influxdb/v2/testing"
 	"github.com/influxdata/influxdb/v2/tsdb"
+	v1auth "github.com/influxdata/influxdb/v2/v1/authorization"
 	"github.com/influxdata/influxdb/v2/v1/coordinator"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
 	"github.com/influxdata/influxql"
@@ -102,6 +110,131 @@ func TestQueryExecutor_ExecuteQuery_SelectStatement(t *testing.T) {
 	}
 }
 
+// Ensure query executor can write the results of a SELECT INTO statement to the target bucket.
+func TestQueryExecutor_ExecuteQuery_SelectIntoStatement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := influxdb.ID(0xff00)
+	bucketID := influxdb.ID(0xffe1)
+	empty := ""
+	filt := influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &empty, RetentionPolicy: &empty}
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), filt).
+		Return([]*influxdb.DBRPMappingV2{{}}, 1, nil)
+	db, rp := "db1", "rp1"
+	targetFilt := influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), targetFilt).
+		Return([]*influxdb.DBRPMappingV2{{Database: db, RetentionPolicy: rp, OrganizationID: orgID, BucketID: bucketID}}, 1, nil)
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+
+	var pw mock.PointsWriter
+	pw.WritePointsFn = func(ctx context.Context, gotOrgID influxdb.ID, gotBucketID influxdb.ID, points []models.Point) error {
+		if gotOrgID != orgID || gotBucketID != bucketID {
+			t.Fatalf("unexpected write destination: org=%s bucket=%s", gotOrgID, gotBucketID)
+		}
+		pw.Points = append(pw.Points, points...)
+		return nil
+	}
+	e.StatementExecutor.PointsWriter = &pw
+
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(_ context.Context, _ *influxql.Measurement, _ query.IteratorOptions) (query.Iterator, error) {
+			return &FloatIterator{Points: []query.FloatPoint{
+				{Name: "cpu", Time: int64(0 * time.Second), Aux: []interface{}{float64(100)}},
+				{Name: "cpu", Time: int64(1 * time.Second), Aux: []interface{}{float64(200)}},
+			}}, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			return map[string]influxql.DataType{"value": influxql.Float}, nil, nil
+		}
+		return &sh
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(bucketID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	if a := ReadAllResults(e.ExecuteQuery(ctx, `SELECT * INTO db1.rp1.cpu_copy FROM cpu`, "db0", 0, orgID)); !reflect.DeepEqual(a, []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "result",
+				Columns: []string{"time", "written"},
+				Values:  [][]interface{}{{time.Unix(0, 0).UTC(), int64(2)}},
+			}},
+		},
+	}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	if got, exp := len(pw.Points), 2; got != exp {
+		t.Fatalf("unexpected number of points written: got %d, exp %d", got, exp)
+	}
+	if got, exp := pw.Points[0].String(), "cpu_copy value=100 0"; got != exp {
+		t.Fatalf("unexpected point: got %q, exp %q", got, exp)
+	}
+}
+
+// Ensure a SELECT INTO statement is rejected without write permission on the target bucket.
+func TestQueryExecutor_ExecuteQuery_SelectIntoStatement_Unauthorized(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := influxdb.ID(0xff00)
+	bucketID := influxdb.ID(0xffe1)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), gomock.Any()).
+		Return([]*influxdb.DBRPMappingV2{{Database: "db1", RetentionPolicy: "rp1", OrganizationID: orgID, BucketID: bucketID}}, 1, nil).
+		AnyTimes()
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.PointsWriter = &mock.PointsWriter{
+		WritePointsFn: func(ctx context.Context, orgID influxdb.ID, bucketID influxdb.ID, points []models.Point) error {
+			t.Fatal("points should not be written")
+			return nil
+		},
+	}
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return nil, nil
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(bucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	a := ReadAllResults(e.ExecuteQuery(ctx, `SELECT * INTO db1.rp1.cpu_copy FROM cpu`, "db0", 0, orgID))
+	if len(a) != 1 || a[0].Err == nil {
+		t.Fatalf("expected an authorization error, got: %s", spew.Sdump(a))
+	}
+	if got, exp := influxdb.ErrorCode(a[0].Err), influxdb.EUnauthorized; got != exp {
+		t.Fatalf("unexpected error code: got %q, exp %q", got, exp)
+	}
+}
+
 // Ensure query executor can enforce a maximum bucket selection count.
 func TestQueryExecutor_ExecuteQuery_MaxSelectBucketsN(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -325,6 +458,848 @@ func TestStatementExecutor_NormalizeDeleteSeries(t *testing.T) {
 	}
 }
 
+func TestQueryExecutor_ExecuteQuery_DeleteSeries(t *testing.T) {
+	orgID := influxdb.ID(0xff00)
+	bucketID := influxdb.ID(0xffe1)
+
+	type deleteCall struct {
+		min, max int64
+		pred     influxdb.Predicate
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		matches []string
+		skips   []string
+		min     int64
+		max     int64
+		n       int
+		err     string
+
+		// fallback is the condition expected to be deleted by the store
+		// when it cannot be converted to a delete predicate.
+		fallback string
+	}{
+		{
+			name:    "delete with tags and time",
+			query:   `DELETE FROM cpu WHERE host = 'a' AND time < '2020-01-01T00:00:00Z'`,
+			matches: []string{"cpu,host=a"},
+			skips:   []string{"cpu,host=b", "mem,host=a"},
+			min:     influxql.MinTime,
+			max:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano() - 1,
+			n:       1,
+		},
+		{
+			name:    "delete without sources",
+			query:   `DELETE WHERE host = 'a'`,
+			matches: []string{"cpu,host=a", "mem,host=a"},
+			skips:   []string{"cpu,host=b"},
+			min:     influxql.MinTime,
+			max:     influxql.MaxTime,
+			n:       1,
+		},
+		{
+			name:    "drop series from regex",
+			query:   `DROP SERIES FROM /c.*/ WHERE host != 'a'`,
+			matches: []string{"cpu,host=b"},
+			skips:   []string{"cpu,host=a", "mem,host=b"},
+			min:     influxql.MinTime,
+			max:     influxql.MaxTime,
+			n:       1,
+		},
+		{
+			name:  "drop series with time",
+			query: `DROP SERIES FROM cpu WHERE time > now() - 1h`,
+			err:   "DROP SERIES doesn't support time in WHERE clause",
+		},
+		{
+			name:     "delete with OR",
+			query:    `DELETE FROM cpu WHERE (host = 'a' OR host = 'b') AND time < '2020-01-01T00:00:00Z'`,
+			fallback: `(host = 'a' OR host = 'b') AND time < '2020-01-01T00:00:00Z'`,
+		},
+		{
+			name:     "drop series with regex",
+			query:    `DROP SERIES FROM cpu WHERE host =~ /^a/`,
+			fallback: `host =~ /^a/`,
+		},
+		{
+			name:  "delete with field",
+			query: `DELETE FROM cpu WHERE value = 1 AND host = 'a'`,
+			err:   "fields not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+			db, defaultRP := "db0", true
+			dbrp.EXPECT().
+				FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, Default: &defaultRP}).
+				Return([]*influxdb.DBRPMappingV2{{Database: db, RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: bucketID, Default: true}}, 1, nil)
+
+			e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+			e.TSDBStore.MeasurementNamesFn = func(auth query.Authorizer, database string, cond influxql.Expr) ([][]byte, error) {
+				return [][]byte{[]byte("cpu"), []byte("mem")}, nil
+			}
+
+			var fallback influxql.Expr
+			e.TSDBStore.DeleteSeriesFn = func(database string, sources []influxql.Source, condition influxql.Expr) error {
+				if database != bucketID.String() {
+					t.Fatalf("unexpected delete database: %s", database)
+				}
+				if condition == nil || len(sources) != 1 || sources[0].(*influxql.Measurement).Name != "cpu" {
+					t.Fatalf("unexpected delete sources %s and condition %s", sources, condition)
+				}
+				fallback = condition
+				return nil
+			}
+
+			var calls []deleteCall
+			e.StatementExecutor.DeleteService = &mock.DeleteService{
+				DeleteBucketRangePredicateF: func(ctx context.Context, gotOrgID, gotBucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+					if gotOrgID != orgID || gotBucketID != bucketID {
+						t.Fatalf("unexpected delete destination: org=%s bucket=%s", gotOrgID, gotBucketID)
+					}
+					calls = append(calls, deleteCall{min: min, max: max, pred: pred})
+					return nil
+				},
+			}
+
+			ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+				ID:     orgID,
+				OrgID:  orgID,
+				Status: influxdb.Active,
+				Permissions: []influxdb.Permission{
+					*itesting.MustNewPermissionAtID(bucketID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+				},
+			})
+
+			results := ReadAllResults(e.ExecuteQuery(ctx, tt.query, "db0", 0, orgID))
+			if len(results) != 1 {
+				t.Fatalf("unexpected results: %s", spew.Sdump(results))
+			}
+			if tt.err != "" {
+				if results[0].Err == nil || !strings.Contains(results[0].Err.Error(), tt.err) {
+					t.Fatalf("unexpected error: got %v, exp %q", results[0].Err, tt.err)
+				}
+				return
+			} else if results[0].Err != nil {
+				t.Fatalf("unexpected error: %v", results[0].Err)
+			}
+
+			if tt.fallback != "" {
+				if len(calls) != 0 {
+					t.Fatalf("unexpected predicate deletes: %d", len(calls))
+				}
+				if fallback == nil || fallback.String() != influxql.MustParseExpr(tt.fallback).String() {
+					t.Fatalf("unexpected condition deleted by the store: got %v, exp %s", fallback, tt.fallback)
+				}
+				return
+			}
+
+			if len(calls) != tt.n {
+				t.Fatalf("unexpected number of deletes: got %d, exp %d", len(calls), tt.n)
+			}
+			call := calls[0]
+			if call.min != tt.min || call.max != tt.max {
+				t.Errorf("unexpected time range: got [%d, %d], exp [%d, %d]", call.min, call.max, tt.min, tt.max)
+			}
+			for _, key := range tt.matches {
+				if !call.pred.Matches(seriesPredicateKey(key)) {
+					t.Errorf("expected predicate to match %q", key)
+				}
+			}
+			for _, key := range tt.skips {
+				if call.pred.Matches(seriesPredicateKey(key)) {
+					t.Errorf("expected predicate not to match %q", key)
+				}
+			}
+		})
+	}
+}
+
+// seriesPredicateKey converts a series key to the form matched by delete predicates.
+func seriesPredicateKey(key string) []byte {
+	name, tags := models.ParseKeyBytes([]byte(key))
+	tags = append(models.Tags{models.NewTag(models.MeasurementTagKeyBytes, name)}, tags...)
+	return models.MakeKey(nil, tags)
+}
+
+func TestQueryExecutor_ExecuteQuery_ContinuousQueries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orgID := influxdb.ID(0xff00)
+	userID := influxdb.ID(0xff01)
+	bucketID := influxdb.ID(0xffe0)
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), gomock.Any()).
+		Return([]*influxdb.DBRPMappingV2{{Database: "db0", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: bucketID}}, 1, nil).
+		AnyTimes()
+
+	var tasks []*influxdb.Task
+	ts := mock.NewTaskService()
+	ts.CreateTaskFn = func(ctx context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+		task := &influxdb.Task{
+			ID:             influxdb.ID(len(tasks) + 1),
+			Type:           tc.Type,
+			OrganizationID: tc.OrganizationID,
+			OwnerID:        tc.OwnerID,
+			Flux:           tc.Flux,
+			Metadata:       tc.Metadata,
+		}
+		tasks = append(tasks, task)
+		return task, nil
+	}
+	ts.FindTasksFn = func(ctx context.Context, filter influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+		if filter.Type == nil || *filter.Type != influxdb.TaskContinuousQueryType {
+			t.Fatalf("unexpected task type filter: %v", filter.Type)
+		}
+		return tasks, len(tasks), nil
+	}
+	ts.DeleteTaskFn = func(ctx context.Context, id influxdb.ID) error {
+		for i, task := range tasks {
+			if task.ID == id {
+				tasks = append(tasks[:i], tasks[i+1:]...)
+				return nil
+			}
+		}
+		return influxdb.ErrTaskNotFound
+	}
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.TaskService = ts
+	e.Executor.StatementNormalizer = e.StatementExecutor
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		UserID: userID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.TasksResourceType, OrgID: &orgID}},
+			{Action: influxdb.WriteAction, Resource: influxdb.Resource{Type: influxdb.TasksResourceType, OrgID: &orgID}},
+			*itesting.MustNewPermissionAtID(bucketID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	const create = `CREATE CONTINUOUS QUERY cq0 ON db0 RESAMPLE FOR 1h BEGIN SELECT mean(value) INTO cpu_mean FROM cpu GROUP BY time(10m) END`
+	if a := ReadAllResults(e.ExecuteQuery(ctx, create, "", 0, orgID)); len(a) != 1 || a[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+	if got, exp := len(tasks), 1; got != exp {
+		t.Fatalf("unexpected number of tasks: got %d, exp %d", got, exp)
+	}
+
+	task := tasks[0]
+	if got, exp := task.Type, influxdb.TaskContinuousQueryType; got != exp {
+		t.Errorf("unexpected task type: got %q, exp %q", got, exp)
+	}
+	if got, exp := task.OwnerID, userID; got != exp {
+		t.Errorf("unexpected task owner: got %s, exp %s", got, exp)
+	}
+	stored := `CREATE CONTINUOUS QUERY cq0 ON db0 RESAMPLE FOR 1h BEGIN SELECT mean(value) INTO db0.rp0.cpu_mean FROM db0.rp0.cpu GROUP BY time(10m) END`
+	if exp := map[string]interface{}{"database": "db0", "name": "cq0", "query": stored}; !reflect.DeepEqual(task.Metadata, exp) {
+		t.Errorf("unexpected task metadata: %v", task.Metadata)
+	}
+	for _, s := range []string{
+		`option task = {name: "cq0", every: 10m}`,
+		`|> range(start: date.truncate(t: -50m1ns, unit: 10m), stop: date.truncate(t: 0s, unit: 10m))`,
+		`|> to(bucketID: "000000000000ffe0", orgID: "000000000000ff00"`,
+	} {
+		if !strings.Contains(task.Flux, s) {
+			t.Errorf("expected task flux to contain %q:\n%s", s, task.Flux)
+		}
+	}
+
+	// Creating the same continuous query again does nothing, but a different
+	// query with the same name is rejected.
+	if a := ReadAllResults(e.ExecuteQuery(ctx, create, "", 0, orgID)); len(a) != 1 || a[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+	if a := ReadAllResults(e.ExecuteQuery(ctx, `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT max(value) INTO cpu_max FROM cpu GROUP BY time(10m) END`, "", 0, orgID)); len(a) != 1 || a[0].Err != coordinator.ErrContinuousQueryExists {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	if a := ReadAllResults(e.ExecuteQuery(ctx, `SHOW CONTINUOUS QUERIES`, "", 0, orgID)); !reflect.DeepEqual(a, []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "db0",
+				Columns: []string{"name", "query"},
+				Values:  [][]interface{}{{"cq0", stored}},
+			}},
+		},
+	}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	if a := ReadAllResults(e.ExecuteQuery(ctx, `DROP CONTINUOUS QUERY cq0 ON db0`, "", 0, orgID)); len(a) != 1 || a[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+	if got := len(tasks); got != 0 {
+		t.Fatalf("expected the task to be deleted, got %d tasks", got)
+	}
+	if a := ReadAllResults(e.ExecuteQuery(ctx, `DROP CONTINUOUS QUERY cq0 ON db0`, "", 0, orgID)); len(a) != 1 || a[0].Err != coordinator.ErrContinuousQueryNotFound {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_CreateDatabase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orgID := influxdb.ID(0xff00)
+	bucketID := influxdb.ID(0xffe0)
+	db := "db0"
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return(nil, 0, nil)
+	dbrp.EXPECT().
+		Create(gomock.Any(), &influxdb.DBRPMappingV2{
+			Database:        "db0",
+			RetentionPolicy: "rp0",
+			Default:         true,
+			OrganizationID:  orgID,
+			BucketID:        bucketID,
+		}).
+		Return(nil)
+
+	var created []*influxdb.Bucket
+	bs := mock.NewBucketService()
+	bs.CreateBucketFn = func(ctx context.Context, b *influxdb.Bucket) error {
+		b.ID = bucketID
+		created = append(created, b)
+		return nil
+	}
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.BucketService = bs
+
+	if a := ReadAllResults(e.ExecuteQuery(context.Background(), `CREATE DATABASE db0 WITH DURATION 24h NAME rp0`, "", 0, orgID)); len(a) != 1 || a[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	exp := []*influxdb.Bucket{{
+		ID:                  bucketID,
+		OrgID:               orgID,
+		Name:                "db0/rp0",
+		RetentionPolicyName: "rp0",
+		RetentionPeriod:     24 * time.Hour,
+	}}
+	if !reflect.DeepEqual(created, exp) {
+		t.Fatalf("unexpected buckets created: %s", spew.Sdump(created))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_CreateRetentionPolicy_DurationTooLow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orgID := influxdb.ID(0xff00)
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), gomock.Any()).
+		Return([]*influxdb.DBRPMappingV2{{Database: "db0", RetentionPolicy: "autogen", Default: true, OrganizationID: orgID, BucketID: influxdb.ID(0xffe0)}}, 1, nil)
+
+	bs := mock.NewBucketService()
+	bs.CreateBucketFn = func(ctx context.Context, b *influxdb.Bucket) error {
+		t.Fatal("bucket should not be created")
+		return nil
+	}
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.BucketService = bs
+
+	a := ReadAllResults(e.ExecuteQuery(context.Background(), `CREATE RETENTION POLICY rp0 ON db0 DURATION 30m REPLICATION 1`, "", 0, orgID))
+	if len(a) != 1 || a[0].Err != meta.ErrRetentionPolicyDurationTooLow {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_AlterRetentionPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orgID := influxdb.ID(0xff00)
+	bucketID := influxdb.ID(0xffe0)
+	db, rp := "db0", "rp0"
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+		Return([]*influxdb.DBRPMappingV2{{ID: 1, Database: db, RetentionPolicy: rp, OrganizationID: orgID, BucketID: bucketID}}, 1, nil)
+	dbrp.EXPECT().
+		Update(gomock.Any(), &influxdb.DBRPMappingV2{ID: 1, Database: db, RetentionPolicy: rp, Default: true, OrganizationID: orgID, BucketID: bucketID}).
+		Return(nil)
+
+	var updated time.Duration
+	bs := mock.NewBucketService()
+	bs.UpdateBucketFn = func(ctx context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+		if id != bucketID {
+			t.Fatalf("unexpected bucket updated: %s", id)
+		}
+		updated = *upd.RetentionPeriod
+		return &influxdb.Bucket{ID: id}, nil
+	}
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.BucketService = bs
+
+	if a := ReadAllResults(e.ExecuteQuery(context.Background(), `ALTER RETENTION POLICY rp0 ON db0 DURATION 48h DEFAULT`, "", 0, orgID)); len(a) != 1 || a[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+	if got, exp := updated, 48*time.Hour; got != exp {
+		t.Fatalf("unexpected retention period: got %s, exp %s", got, exp)
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_DropDatabase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orgID := influxdb.ID(0xff00)
+	db := "db0"
+	mappings := []*influxdb.DBRPMappingV2{
+		{ID: 1, Database: db, RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: influxdb.ID(0xffe0)},
+		{ID: 2, Database: db, RetentionPolicy: "rp1", OrganizationID: orgID, BucketID: influxdb.ID(0xffe1)},
+	}
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return(mappings, len(mappings), nil)
+	for _, m := range mappings {
+		dbrp.EXPECT().
+			Delete(gomock.Any(), orgID, m.ID).
+			Return(nil)
+		dbrp.EXPECT().
+			FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, BucketID: &m.BucketID}).
+			Return(nil, 0, nil)
+	}
+
+	var deleted []influxdb.ID
+	bs := mock.NewBucketService()
+	bs.DeleteBucketFn = func(ctx context.Context, id influxdb.ID) error {
+		deleted = append(deleted, id)
+		return nil
+	}
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.BucketService = bs
+
+	if a := ReadAllResults(e.ExecuteQuery(context.Background(), `DROP DATABASE db0`, "", 0, orgID)); len(a) != 1 || a[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+	if exp := []influxdb.ID{0xffe0, 0xffe1}; !reflect.DeepEqual(deleted, exp) {
+		t.Fatalf("unexpected buckets deleted: %v", deleted)
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowCardinality(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orgID := influxdb.ID(0xff00)
+	db, rp := "db0", "rp1"
+	res := []*influxdb.DBRPMappingV2{
+		{Database: db, RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: 0xffe0},
+		{Database: db, RetentionPolicy: "rp1", OrganizationID: orgID, BucketID: 0xffe1},
+	}
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return(res, len(res), nil).
+		Times(2)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+		Return(res[1:], 1, nil)
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.TSDBStore.SeriesCardinalityFn = func(database string) (int64, error) {
+		switch database {
+		case res[0].BucketID.String():
+			return 3, nil
+		case res[1].BucketID.String():
+			return 4, nil
+		}
+		return 0, fmt.Errorf("unexpected database %q", database)
+	}
+	e.TSDBStore.MeasurementsSketchesFn = func(database string) (estimator.Sketch, estimator.Sketch, error) {
+		ss, ts := hll.NewDefaultPlus(), hll.NewDefaultPlus()
+		ss.Add([]byte("cpu"))
+		if database == res[1].BucketID.String() {
+			ss.Add([]byte("mem"))
+		}
+		return ss, ts, nil
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(res[0].BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+			*itesting.MustNewPermissionAtID(res[1].BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	for _, tt := range []struct {
+		q    string
+		rp   string
+		want int64
+	}{
+		{q: `SHOW SERIES CARDINALITY ON db0`, want: 7},
+		{q: `SHOW MEASUREMENT CARDINALITY ON db0`, want: 2},
+		{q: `SHOW SERIES CARDINALITY ON db0`, rp: rp, want: 4},
+	} {
+		a := ReadAllResults(e.Executor.ExecuteQuery(ctx, MustParseQuery(tt.q), query.ExecutionOptions{
+			OrgID:           orgID,
+			RetentionPolicy: tt.rp,
+		}))
+		if !reflect.DeepEqual(a, []*query.Result{{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Columns: []string{"cardinality estimation"},
+				Values:  [][]interface{}{{tt.want}},
+			}},
+		}}) {
+			t.Errorf("%s: unexpected results: %s", tt.q, spew.Sdump(a))
+		}
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_Shards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	orgID := influxdb.ID(0xff00)
+	res := []*influxdb.DBRPMappingV2{
+		{Database: "db1", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: 0xffe1},
+		{Database: "db0", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: 0xffe0},
+		{Database: "db2", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: 0xffe2},
+	}
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID}).
+		Return(res, len(res), nil).
+		AnyTimes()
+
+	start := time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+	end := start.Add(7 * 24 * time.Hour)
+	shards := map[influxdb.ID][]*influxdb.Shard{
+		res[0].BucketID: {
+			{ID: 3, BucketID: res[0].BucketID, ShardGroupID: 2, StartTime: start, EndTime: end, Size: 2048},
+		},
+		res[1].BucketID: {
+			{ID: 1, BucketID: res[1].BucketID, ShardGroupID: 1, StartTime: start, EndTime: end, ExpiryTime: end.Add(24 * time.Hour), Size: 1024},
+			{ID: 2, BucketID: res[1].BucketID, ShardGroupID: 1, StartTime: start, EndTime: end, ExpiryTime: end.Add(24 * time.Hour), Size: 512},
+		},
+		res[2].BucketID: {
+			{ID: 4, BucketID: res[2].BucketID, ShardGroupID: 3, StartTime: start, EndTime: end},
+		},
+	}
+
+	var deleted []uint64
+	shardSvc := mock.NewShardService()
+	shardSvc.FindShardsF = func(ctx context.Context, bucketID influxdb.ID) ([]*influxdb.Shard, error) {
+		return shards[bucketID], nil
+	}
+	shardSvc.DeleteShardF = func(ctx context.Context, id uint64) error {
+		deleted = append(deleted, id)
+		return nil
+	}
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.ShardService = shardSvc
+
+	// The caller may read db0 and db1, and may only write to db0.
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(res[0].BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+			*itesting.MustNewPermissionAtID(res[1].BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+			*itesting.MustNewPermissionAtID(res[1].BucketID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+	opts := query.ExecutionOptions{OrgID: orgID}
+
+	startTime, endTime, expiryTime := "2020-01-06T00:00:00Z", "2020-01-13T00:00:00Z", "2020-01-14T00:00:00Z"
+	if a := ReadAllResults(e.Executor.ExecuteQuery(ctx, MustParseQuery(`SHOW SHARDS`), opts)); !reflect.DeepEqual(a, []*query.Result{{
+		StatementID: 0,
+		Series: []*models.Row{
+			{
+				Name:    "db0",
+				Columns: []string{"id", "database", "retention_policy", "shard_group", "start_time", "end_time", "expiry_time", "size"},
+				Values: [][]interface{}{
+					{uint64(1), "db0", "rp0", uint64(1), startTime, endTime, expiryTime, int64(1024)},
+					{uint64(2), "db0", "rp0", uint64(1), startTime, endTime, expiryTime, int64(512)},
+				},
+			},
+			{
+				Name:    "db1",
+				Columns: []string{"id", "database", "retention_policy", "shard_group", "start_time", "end_time", "expiry_time", "size"},
+				Values: [][]interface{}{
+					{uint64(3), "db1", "rp0", uint64(2), startTime, endTime, "", int64(2048)},
+				},
+			},
+		},
+	}}) {
+		t.Errorf("SHOW SHARDS: unexpected results: %s", spew.Sdump(a))
+	}
+
+	if a := ReadAllResults(e.Executor.ExecuteQuery(ctx, MustParseQuery(`SHOW SHARD GROUPS`), opts)); !reflect.DeepEqual(a, []*query.Result{{
+		StatementID: 0,
+		Series: []*models.Row{{
+			Name:    "shard groups",
+			Columns: []string{"id", "database", "retention_policy", "start_time", "end_time", "expiry_time"},
+			Values: [][]interface{}{
+				{uint64(1), "db0", "rp0", startTime, endTime, expiryTime},
+				{uint64(2), "db1", "rp0", startTime, endTime, ""},
+			},
+		}},
+	}}) {
+		t.Errorf("SHOW SHARD GROUPS: unexpected results: %s", spew.Sdump(a))
+	}
+
+	for _, tt := range []struct {
+		q   string
+		err string
+	}{
+		{q: `DROP SHARD 2`},
+		{q: `DROP SHARD 3`, err: "write:orgs/000000000000ff00/buckets/000000000000ffe1 is unauthorized"},
+		{q: `DROP SHARD 5`, err: coordinator.ErrShardNotFound.Error()},
+	} {
+		a := ReadAllResults(e.Executor.ExecuteQuery(ctx, MustParseQuery(tt.q), opts))
+		if len(a) != 1 {
+			t.Fatalf("%s: expected 1 result, got %d", tt.q, len(a))
+		}
+		if got := a[0].Err; (got == nil) != (tt.err == "") || (got != nil && !strings.Contains(got.Error(), tt.err)) {
+			t.Errorf("%s: unexpected error: %v", tt.q, got)
+		}
+	}
+	if !reflect.DeepEqual(deleted, []uint64{2}) {
+		t.Errorf("unexpected deleted shards: %v", deleted)
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_Queries(t *testing.T) {
+	orgID, otherOrgID, userID := influxdb.ID(0xff00), influxdb.ID(0xff01), influxdb.ID(0xaa00)
+	running := []*influxdb.RunningQuery{
+		{ID: 1, OrgID: orgID, UserID: userID, Language: influxdb.InfluxQLQueryLanguage, Query: "SELECT * FROM cpu", Database: "db0", State: "running", Duration: 2 * time.Second},
+		{ID: 2, OrgID: orgID, Language: influxdb.FluxQueryLanguage, Query: `from(bucket: "b")`, State: "executing", Duration: 3 * time.Millisecond, Memory: 1024},
+		{ID: 3, OrgID: otherOrgID, Language: influxdb.FluxQueryLanguage, Query: `from(bucket: "o")`, State: "executing"},
+	}
+
+	var killed []uint64
+	svc := mock.NewRunningQueryService()
+	svc.FindRunningQueriesF = func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+		var queries []*influxdb.RunningQuery
+		for _, q := range running {
+			if filter.ID != nil && *filter.ID != q.ID {
+				continue
+			}
+			if filter.OrgID != nil && *filter.OrgID != q.OrgID {
+				continue
+			}
+			queries = append(queries, q)
+		}
+		return queries, nil
+	}
+	svc.KillQueryF = func(ctx context.Context, id uint64) error {
+		killed = append(killed, id)
+		return nil
+	}
+
+	e := DefaultQueryExecutor(t)
+	e.StatementExecutor.RunningQueryService = svc
+	opts := query.ExecutionOptions{OrgID: orgID}
+
+	if a := ReadAllResults(e.Executor.ExecuteQuery(context.Background(), MustParseQuery(`SHOW QUERIES`), opts)); !reflect.DeepEqual(a, []*query.Result{{
+		StatementID: 0,
+		Series: []*models.Row{{
+			Columns: []string{"qid", "query", "database", "duration", "status", "language", "user", "memory"},
+			Values: [][]interface{}{
+				{uint64(1), "SELECT * FROM cpu", "db0", "2s", "running", "influxql", userID.String(), int64(0)},
+				{uint64(2), `from(bucket: "b")`, "", "3ms", "executing", "flux", "", int64(1024)},
+			},
+		}},
+	}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	if a := ReadAllResults(e.Executor.ExecuteQuery(context.Background(), MustParseQuery(`KILL QUERY 2`), opts)); !reflect.DeepEqual(a, []*query.Result{{StatementID: 0}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	// Queries of other organizations cannot be killed.
+	if a := ReadAllResults(e.Executor.ExecuteQuery(context.Background(), MustParseQuery(`KILL QUERY 3`), opts)); !reflect.DeepEqual(a, []*query.Result{{
+		StatementID: 0,
+		Err:         influxdb.ErrRunningQueryNotFound,
+	}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	if !reflect.DeepEqual(killed, []uint64{2}) {
+		t.Errorf("unexpected killed queries: %v", killed)
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_Users(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	store := inmem.NewKVStore()
+	if err := all.Up(ctx, zaptest.NewLogger(t), store); err != nil {
+		t.Fatal(err)
+	}
+	ts := tenant.NewService(tenant.NewStore(store))
+	owner := &influxdb.User{Name: "owner"}
+	if err := ts.CreateUser(ctx, owner); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := ts.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	authStore, err := v1auth.NewStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authSvc := v1auth.NewService(authStore, ts)
+
+	orgID := org.ID
+	mappings := []*influxdb.DBRPMappingV2{
+		{Database: "db0", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: 0xffe0},
+		{Database: "db0", RetentionPolicy: "rp1", OrganizationID: orgID, BucketID: 0xffe1},
+		{Database: "db1", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: 0xffe2},
+	}
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, filter influxdb.DBRPMappingFilterV2, _ ...influxdb.FindOptions) ([]*influxdb.DBRPMappingV2, int, error) {
+			var res []*influxdb.DBRPMappingV2
+			for _, m := range mappings {
+				if filter.Database != nil && *filter.Database != m.Database {
+					continue
+				}
+				res = append(res, m)
+			}
+			return res, len(res), nil
+		}).
+		AnyTimes()
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.V1AuthorizationService = authSvc
+
+	ctx = icontext.SetAuthorizer(ctx, &influxdb.Authorization{
+		OrgID:  orgID,
+		UserID: owner.ID,
+		Status: influxdb.Active,
+	})
+	opts := query.ExecutionOptions{OrgID: orgID}
+	exec := func(q string) []*query.Result {
+		return ReadAllResults(e.Executor.ExecuteQuery(ctx, MustParseQuery(q), opts))
+	}
+	mustExec := func(q string) {
+		t.Helper()
+		for _, r := range exec(q) {
+			if r.Err != nil {
+				t.Fatalf("unexpected error executing %q: %v", q, r.Err)
+			}
+		}
+	}
+
+	mustExec(`CREATE USER admin WITH PASSWORD 'adminpass' WITH ALL PRIVILEGES`)
+	mustExec(`CREATE USER grafana WITH PASSWORD 'secret'`)
+	mustExec(`GRANT READ ON db0 TO grafana`)
+	mustExec(`GRANT ALL ON db1 TO grafana`)
+	mustExec(`REVOKE READ ON db1 FROM grafana`)
+	mustExec(`SET PASSWORD FOR grafana = 'changed'`)
+
+	if a := exec(`SHOW USERS`); !reflect.DeepEqual(a, []*query.Result{{
+		StatementID: 0,
+		Series: []*models.Row{{
+			Columns: []string{"user", "admin"},
+			Values: [][]interface{}{
+				{"admin", true},
+				{"grafana", false},
+			},
+		}},
+	}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	if a := exec(`SHOW GRANTS FOR grafana`); !reflect.DeepEqual(a, []*query.Result{{
+		StatementID: 0,
+		Series: []*models.Row{{
+			Columns: []string{"database", "privilege"},
+			Values: [][]interface{}{
+				{"db0", "READ"},
+				{"db1", "WRITE"},
+			},
+		}},
+	}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	// The grants apply to every retention policy of the database.
+	a, err := authSvc.FindAuthorizationByToken(ctx, "grafana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bucketID := range []influxdb.ID{0xffe0, 0xffe1} {
+		p := itesting.MustNewPermissionAtID(bucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+		if !influxdb.PermissionAllowed(*p, a.Permissions) {
+			t.Errorf("expected permission %s to be granted", p)
+		}
+	}
+	if err := authSvc.ComparePassword(ctx, a.ID, "changed"); err != nil {
+		t.Fatalf("unexpected error comparing the new password: %v", err)
+	}
+
+	if a := exec(`CREATE USER grafana WITH PASSWORD 'secret'`); !reflect.DeepEqual(a, []*query.Result{{
+		StatementID: 0,
+		Err:         meta.ErrUserExists,
+	}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+	if a := exec(`GRANT READ ON db2 TO grafana`); !reflect.DeepEqual(a, []*query.Result{{
+		StatementID: 0,
+		Err:         query.ErrDatabaseNotFound("db2"),
+	}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	mustExec(`REVOKE ALL PRIVILEGES FROM admin`)
+	mustExec(`DROP USER grafana`)
+
+	if a := exec(`SHOW USERS`); !reflect.DeepEqual(a, []*query.Result{{
+		StatementID: 0,
+		Series: []*models.Row{{
+			Columns: []string{"user", "admin"},
+			Values: [][]interface{}{
+				{"admin", false},
+			},
+		}},
+	}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+	if a := exec(`SHOW GRANTS FOR grafana`); !reflect.DeepEqual(a, []*query.Result{{
+		StatementID: 0,
+		Err:         meta.ErrUserNotFound,
+	}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+}
+
 func TestQueryExecutor_ExecuteQuery_ShowDatabases(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()